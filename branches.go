@@ -145,10 +145,14 @@ func switchToTargetBranch(rc requestContext) error {
 	return nil
 }
 
-func switchToCommitBranch(rc requestContext) (string, error) {
+// switchToCommitBranch switches to the branch that rendered manifests should
+// be committed to, creating it if necessary, and cleans it. It returns the
+// name of the branch and a bool indicating whether it was created.
+func switchToCommitBranch(rc requestContext) (string, bool, error) {
 	logger := rc.logger.WithField("targetBranch", rc.request.TargetBranch)
 
 	var commitBranch string
+	var created bool
 	if !rc.target.branchConfig.PRs.Enabled {
 		commitBranch = rc.request.TargetBranch
 		logger.Debug(
@@ -164,19 +168,20 @@ func switchToCommitBranch(rc requestContext) (string, error) {
 		logger.Debug("changes will be PR'ed to the target branch")
 		commitBranchExists, err := rc.repo.RemoteBranchExists(commitBranch)
 		if err != nil {
-			return "",
+			return "", false,
 				fmt.Errorf("error checking for existence of commit branch: %w", err)
 		}
 		if commitBranchExists {
 			logger.Debug("commit branch exists on remote")
 			if err = rc.repo.Checkout(commitBranch); err != nil {
-				return "", fmt.Errorf("error checking out commit branch: %w", err)
+				return "", false, fmt.Errorf("error checking out commit branch: %w", err)
 			}
 			logger.Debug("checked out commit branch")
 		} else {
 			if err := rc.repo.CreateChildBranch(commitBranch); err != nil {
-				return "", fmt.Errorf("error creating child of target branch: %w", err)
+				return "", false, fmt.Errorf("error creating child of target branch: %w", err)
 			}
+			created = true
 			logger.Debug("created commit branch")
 		}
 	}
@@ -186,11 +191,11 @@ func switchToCommitBranch(rc requestContext) (string, error) {
 		rc.repo.WorkingDir(),
 		rc.target.branchConfig.PreservedPaths,
 	); err != nil {
-		return "", fmt.Errorf("error cleaning commit branch: %w", err)
+		return "", false, fmt.Errorf("error cleaning commit branch: %w", err)
 	}
 	logger.Debug("cleaned commit branch")
 
-	return commitBranch, nil
+	return commitBranch, created, nil
 }
 
 // cleanCommitBranch deletes the entire contents of the specified directory
@@ -4,12 +4,14 @@ const (
 	flagAllowEmpty    = "allow-empty"
 	flagCommitMessage = "commit-message"
 	flagDebug         = "debug"
+	flagGitProvider   = "git-provider"
 	flagImage         = "image"
 	flagLocalInPath   = "local-in-path"
 	flagLocalOutPath  = "local-out-path"
 	flagOutput        = "output"
 	flagOutputJSON    = "json"
 	flagOutputYAML    = "yaml"
+	flagPRToken       = "pr-token"
 	flagRef           = "ref"
 	flagRepo          = "repo"
 	flagRepoPassword  = "repo-password"
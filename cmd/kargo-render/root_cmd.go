@@ -47,6 +47,7 @@ func newRootCommand() *cobra.Command {
 
 	// Register the subcommands.
 	cmd.AddCommand(newActionCommand())
+	cmd.AddCommand(newValidateCommand())
 	cmd.AddCommand(newVersionCommand())
 
 	return cmd
@@ -78,6 +79,15 @@ func (o *rootOptions) addFlags(cmd *cobra.Command) {
 		"Display debug output.",
 	)
 
+	cmd.Flags().StringVar(
+		(*string)(&o.GitProvider),
+		flagGitProvider,
+		"",
+		"The git provider whose API should be used to open pull requests "+
+			"(azuredevops, bitbucket, github, or gitlab). If not specified, the "+
+			"provider is inferred from the repository URL.",
+	)
+
 	cmd.Flags().StringArrayVarP(
 		&o.Images,
 		flagImage,
@@ -110,6 +120,15 @@ func (o *rootOptions) addFlags(cmd *cobra.Command) {
 		"Specify a format for command output (json or yaml).",
 	)
 
+	cmd.Flags().StringVar(
+		&o.PRCreds.Token,
+		flagPRToken,
+		"",
+		"Token for the git provider's API, used for opening pull requests, if it "+
+			"differs from the repository password. Can alternatively be specified "+
+			"using the KARGO_RENDER_PR_TOKEN environment variable.",
+	)
+
 	cmd.Flags().StringVarP(
 		&o.Ref,
 		flagRef,
@@ -179,7 +198,7 @@ func (o *rootOptions) preRun(cmd *cobra.Command, _ []string) {
 	cmd.Flags().VisitAll(
 		func(flag *pflag.Flag) {
 			switch flag.Name {
-			case flagRepoPassword, flagRepoUsername:
+			case flagPRToken, flagRepoPassword, flagRepoUsername:
 				if !flag.Changed {
 					envVarName := fmt.Sprintf(
 						"KARGO_RENDER_%s",
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	render "github.com/akuity/kargo-render"
+)
+
+func newValidateCommand() *cobra.Command {
+	cmdOpts := &rootOptions{
+		Request: &render.Request{},
+	}
+
+	cmd := &cobra.Command{
+		Use: "validate",
+		Short: "Check a rendering request and the configuration it would be " +
+			"handled with, without making any changes",
+		Args:   cobra.NoArgs,
+		PreRun: cmdOpts.preRun,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdOpts.validate(cmd.Context(), cmd.OutOrStdout())
+		},
+	}
+
+	// Accept the same flags as the rendering request being validated.
+	cmdOpts.addFlags(cmd)
+
+	return cmd
+}
+
+// validate performs read-only checks of a rendering request and reports any
+// problems found. An error is returned if there are any.
+func (o *rootOptions) validate(ctx context.Context, out io.Writer) error {
+	logLevel := render.LogLevelError
+	if o.debug {
+		logLevel = render.LogLevelDebug
+	}
+
+	report := render.NewValidator(
+		&render.ServiceOptions{
+			LogLevel: logLevel,
+		},
+	).Validate(ctx, o.Request, render.RepoCredentials{})
+
+	if o.outputFormat == "" {
+		if report.Valid() {
+			fmt.Fprintln(out, "\nNo problems were found.")
+		} else {
+			fmt.Fprintln(out, "\nThe following problems were found:")
+			for _, problem := range report.Problems {
+				fmt.Fprintf(out, "  [%s] %s\n", problem.Check, problem.Message)
+			}
+		}
+	} else if err := output(report, out, o.outputFormat); err != nil {
+		return err
+	}
+
+	if !report.Valid() {
+		return fmt.Errorf("validation found %d problem(s)", len(report.Problems))
+	}
+	return nil
+}
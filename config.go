@@ -1,12 +1,14 @@
 package render
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"text/template"
 
 	"github.com/xeipuuv/gojsonschema"
 	"sigs.k8s.io/yaml"
@@ -89,6 +91,27 @@ type branchConfig struct {
 	PreservedPaths []string `json:"preservedPaths,omitempty"`
 }
 
+// identifier returns a human-friendly identifier for the branch configuration.
+// This is the branch name, if specified, and the pattern otherwise.
+func (b branchConfig) identifier() string {
+	if b.Name != "" {
+		return b.Name
+	}
+	return b.Pattern
+}
+
+// hash returns a hash of the branch configuration, suitable for recording
+// which configuration produced a given change. Equivalent configurations,
+// regardless of how they were formatted in the configuration file, have the
+// same hash.
+func (b branchConfig) hash() (string, error) {
+	cfgBytes, err := json.Marshal(b)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling branch configuration: %w", err)
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(cfgBytes)), nil
+}
+
 func (b branchConfig) expand(values []string) (branchConfig, error) {
 	cfg := b
 	cfg.AppConfigs = map[string]appConfig{}
@@ -148,6 +171,96 @@ type pullRequestConfig struct {
 	// other automation is involved. There are valid reasons for using either
 	// approach.
 	UseUniqueBranchNames bool `json:"useUniqueBranchNames,omitempty"`
+	// TitleTemplate optionally specifies a Go template that is executed to
+	// produce the title of any PR opened against a given environment-specific
+	// branch. Refer to the prTitleContext type for the fields that are available
+	// to the template. When this is empty, a default title is used. A template
+	// that produces an empty title is an error.
+	TitleTemplate string `json:"titleTemplate,omitempty"`
+	// Footer optionally specifies text that is appended to the description of
+	// any PR opened against a given environment-specific branch. This is useful,
+	// for instance, for satisfying compliance requirements that all automated
+	// PRs carry a standard disclaimer.
+	Footer string `json:"footer,omitempty"`
+	// DeleteBranchOnFailure specifies whether a branch that was created for a PR
+	// should be deleted if the PR then cannot be opened, so that orphaned
+	// branches do not accumulate. Branches that already existed are never
+	// deleted. This is currently only honored by Azure DevOps.
+	DeleteBranchOnFailure bool `json:"deleteBranchOnFailure,omitempty"`
+	// Labels optionally specifies labels to apply to any PR opened against a
+	// given environment-specific branch. This is currently only honored by
+	// Azure DevOps.
+	Labels []string `json:"labels,omitempty"`
+	// LabelRules optionally specifies rules for automatically applying
+	// additional labels to any PR opened against a given environment-specific
+	// branch, based on the paths of the files it changes. This is currently
+	// only honored by Azure DevOps.
+	LabelRules []labelRuleConfig `json:"labelRules,omitempty"`
+	// AzureDevOpsAuthMode optionally specifies how credentials are presented to
+	// Azure DevOps when opening PRs. Valid values are "pat" (the default), which
+	// presents a Personal Access Token, and "basic", which presents the
+	// repository username and password using basic authentication, as some
+	// Azure DevOps Server instances require.
+	AzureDevOpsAuthMode string `json:"azureDevOpsAuthMode,omitempty"`
+	// AzureDevOpsDefaults optionally specifies defaults for any PR opened in
+	// Azure DevOps against a given environment-specific branch.
+	AzureDevOpsDefaults *azureDevOpsPRDefaultsConfig `json:"azureDevOpsDefaults,omitempty"`
+	// IgnoreUnsupportedFeatures specifies whether features requested by this
+	// configuration that are not supported by the Git provider used to open
+	// PRs should be ignored, with a warning. By default, requesting such a
+	// feature is an error wrapping ErrUnsupportedFeature.
+	IgnoreUnsupportedFeatures bool `json:"ignoreUnsupportedFeatures,omitempty"`
+}
+
+// azureDevOpsPRDefaultsConfig encapsulates defaults for PRs opened in Azure
+// DevOps.
+type azureDevOpsPRDefaultsConfig struct {
+	// Draft specifies whether PRs should be opened as drafts. This is
+	// disregarded when AutoComplete is specified, since drafts cannot be
+	// completed.
+	Draft bool `json:"draft,omitempty"`
+	// Reviewers optionally specifies the names or email addresses of users or
+	// groups to request as optional reviewers.
+	Reviewers []string `json:"reviewers,omitempty"`
+	// AutoComplete optionally specifies that auto-complete should be enabled,
+	// using the specified settings.
+	AutoComplete *autoCompleteConfig `json:"autoComplete,omitempty"`
+}
+
+// autoCompleteConfig encapsulates settings for auto-completing PRs.
+type autoCompleteConfig struct {
+	// MergeStrategy optionally specifies the strategy used to merge a PR once
+	// it is completed. When this is empty, the repository's default is used.
+	MergeStrategy string `json:"mergeStrategy,omitempty"`
+	// DeleteSourceBranch specifies whether a PR's source branch should be
+	// deleted once the PR is completed.
+	DeleteSourceBranch bool `json:"deleteSourceBranch,omitempty"`
+	// MergeCommitMessage optionally specifies the message of the merge commit
+	// created when a PR is completed.
+	MergeCommitMessage string `json:"mergeCommitMessage,omitempty"`
+}
+
+// labelRuleConfig specifies a label to apply to any PR that changes a file
+// within a given path.
+type labelRuleConfig struct {
+	// Path is a path, relative to the root of the repository, to a file or
+	// directory.
+	Path string `json:"path,omitempty"`
+	// Label is the label to apply to any PR that changes the file, or any file
+	// within the directory, specified by Path.
+	Label string `json:"label,omitempty"`
+}
+
+// validate performs validation of the pull request configuration that cannot
+// be expressed in the JSON schema.
+func (p pullRequestConfig) validate() error {
+	if p.TitleTemplate == "" {
+		return nil
+	}
+	if _, err := template.New("title").Parse(p.TitleTemplate); err != nil {
+		return fmt.Errorf("error parsing PR title template: %w", err)
+	}
+	return nil
 }
 
 // loadRepoConfig attempts to load configuration from a kargo-render.json or
@@ -192,6 +305,15 @@ func loadRepoConfig(repoPath string) (*repoConfig, error) {
 	if err = json.Unmarshal(configBytes, cfg); err != nil {
 		return cfg, fmt.Errorf("error unmarshaling Kargo Render configuration: %w", err)
 	}
+	for _, branchCfg := range cfg.BranchConfigs {
+		if err = branchCfg.PRs.validate(); err != nil {
+			return cfg, fmt.Errorf(
+				"error validating configuration for branch %q: %w",
+				branchCfg.identifier(),
+				err,
+			)
+		}
+	}
 	return cfg, nil
 }
 
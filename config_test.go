@@ -89,6 +89,49 @@ func TestLoadRepoConfig(t *testing.T) {
 				require.NoError(t, err)
 			},
 		},
+		{
+			name: "invalid PR title template",
+			setup: func() string {
+				dir := t.TempDir()
+				err := os.WriteFile(
+					filepath.Join(dir, "kargo-render.yaml"),
+					[]byte(`configVersion: v1alpha1
+branchConfigs:
+  - name: env/prod
+    prs:
+      enabled: true
+      titleTemplate: "{{.Env"`),
+					0600,
+				)
+				require.NoError(t, err)
+				return dir
+			},
+			assertions: func(t *testing.T, err error) {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "error parsing PR title template")
+			},
+		},
+		{
+			name: "valid PR title template",
+			setup: func() string {
+				dir := t.TempDir()
+				err := os.WriteFile(
+					filepath.Join(dir, "kargo-render.yaml"),
+					[]byte(`configVersion: v1alpha1
+branchConfigs:
+  - name: env/prod
+    prs:
+      enabled: true
+      titleTemplate: "Promote {{.Version}} to {{.Env}}"`),
+					0600,
+				)
+				require.NoError(t, err)
+				return dir
+			},
+			assertions: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -198,6 +241,67 @@ branchConfigs:
         outputPath: prod/my-proj
         combineManifests: true`),
 		},
+		{
+			name: "valid PR label rules",
+			assertions: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+			config: []byte(`configVersion: v1alpha1
+branchConfigs:
+  - name: env/prod
+    prs:
+      enabled: true
+      labels:
+        - kargo-render
+      labelRules:
+        - path: charts/
+          label: helm`),
+		},
+		{
+			name: "valid Azure DevOps PR defaults",
+			assertions: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+			config: []byte(`configVersion: v1alpha1
+branchConfigs:
+  - name: env/prod
+    prs:
+      enabled: true
+      azureDevOpsDefaults:
+        draft: true
+        reviewers:
+          - team-a
+        autoComplete:
+          mergeStrategy: squash
+          deleteSourceBranch: true`),
+		},
+		{
+			name: "Azure DevOps PR defaults with invalid merge strategy",
+			assertions: func(t *testing.T, err error) {
+				require.Error(t, err)
+			},
+			config: []byte(`configVersion: v1alpha1
+branchConfigs:
+  - name: env/prod
+    prs:
+      enabled: true
+      azureDevOpsDefaults:
+        autoComplete:
+          mergeStrategy: octopus`),
+		},
+		{
+			name: "PR label rule without label",
+			assertions: func(t *testing.T, err error) {
+				require.Error(t, err)
+			},
+			config: []byte(`configVersion: v1alpha1
+branchConfigs:
+  - name: env/prod
+    prs:
+      enabled: true
+      labelRules:
+        - path: charts/`),
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -213,3 +317,20 @@ branchConfigs:
 		})
 	}
 }
+
+func TestBranchConfigHash(t *testing.T) {
+	cfg := branchConfig{
+		Name: "env/prod",
+		PRs:  pullRequestConfig{Enabled: true},
+	}
+	hash, err := cfg.hash()
+	require.NoError(t, err)
+	require.Regexp(t, `^sha256:[0-9a-f]{64}$`, hash)
+	sameHash, err := cfg.hash()
+	require.NoError(t, err)
+	require.Equal(t, hash, sameHash)
+	cfg.PRs.Footer = "footer"
+	differentHash, err := cfg.hash()
+	require.NoError(t, err)
+	require.NotEqual(t, hash, differentHash)
+}
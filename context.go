@@ -34,6 +34,7 @@ type targetContext struct {
 
 type commitContext struct {
 	branch            string
+	branchCreated     bool
 	oldBranchMetadata *branchMetadata
 	id                string
 	message           string
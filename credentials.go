@@ -0,0 +1,77 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/akuity/kargo-render/pkg/git"
+)
+
+// credentialResolver maps the credentials specified by a request to those a
+// specific Git provider's API requires for opening PRs, returning an error if
+// the required credentials are missing.
+type credentialResolver func(req *Request) (git.RepoCredentials, error)
+
+// credentialResolvers is a registry of credentialResolvers indexed by Git
+// provider. Every provider with a registered prOpener must also have a
+// registered credentialResolver.
+var credentialResolvers = map[GitProvider]credentialResolver{
+	GitProviderAzureDevOps: resolveAzureDevOpsCredentials,
+	GitProviderGitHub:      resolveGitHubCredentials,
+}
+
+// resolvePRCredentials returns the credentials that should be used for opening
+// PRs for the specified request using the specified Git provider's API.
+func resolvePRCredentials(
+	provider GitProvider,
+	req *Request,
+) (git.RepoCredentials, error) {
+	resolve, ok := credentialResolvers[provider]
+	if !ok {
+		return git.RepoCredentials{}, fmt.Errorf(
+			"no registered credential resolver for git provider %q",
+			provider,
+		)
+	}
+	return resolve(req)
+}
+
+// prToken returns the token explicitly specified for the Git provider's API
+// or, if there is none, the password for the repository.
+func prToken(req *Request) string {
+	if req.PRCreds.Token != "" {
+		return req.PRCreds.Token
+	}
+	return req.RepoCreds.Password
+}
+
+// resolveAzureDevOpsCredentials resolves credentials for the Azure DevOps API,
+// which requires a personal access token (PAT).
+func resolveAzureDevOpsCredentials(req *Request) (git.RepoCredentials, error) {
+	pat := prToken(req)
+	if pat == "" {
+		return git.RepoCredentials{}, fmt.Errorf(
+			"Azure DevOps requires a Personal Access Token (PAT), specified either " +
+				"as a PR token or as the repository password",
+		)
+	}
+	return git.RepoCredentials{
+		Username: req.RepoCreds.Username,
+		Password: pat,
+	}, nil
+}
+
+// resolveGitHubCredentials resolves credentials for the GitHub API, which
+// requires a token.
+func resolveGitHubCredentials(req *Request) (git.RepoCredentials, error) {
+	token := prToken(req)
+	if token == "" {
+		return git.RepoCredentials{}, fmt.Errorf(
+			"GitHub requires a token, specified either as a PR token or as the " +
+				"repository password",
+		)
+	}
+	return git.RepoCredentials{
+		Username: req.RepoCreds.Username,
+		Password: token,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestResolvePRCredentials(t *testing.T) {
+	testCases := []struct {
+		name       string
+		provider   GitProvider
+		req        *Request
+		assertions func(*testing.T, git.RepoCredentials, error)
+	}{
+		{
+			name:     "Azure DevOps PAT from repository password",
+			provider: GitProviderAzureDevOps,
+			req: &Request{
+				RepoCreds: RepoCredentials{Username: "user", Password: "pat"},
+			},
+			assertions: func(t *testing.T, creds git.RepoCredentials, err error) {
+				require.NoError(t, err)
+				require.Equal(t, git.RepoCredentials{Username: "user", Password: "pat"}, creds)
+			},
+		},
+		{
+			name:     "Azure DevOps PAT from PR token",
+			provider: GitProviderAzureDevOps,
+			req: &Request{
+				RepoCreds: RepoCredentials{SSHPrivateKey: "key"},
+				PRCreds:   PRCredentials{Token: "pat"},
+			},
+			assertions: func(t *testing.T, creds git.RepoCredentials, err error) {
+				require.NoError(t, err)
+				require.Equal(t, git.RepoCredentials{Password: "pat"}, creds)
+			},
+		},
+		{
+			name:     "Azure DevOps PAT missing",
+			provider: GitProviderAzureDevOps,
+			req: &Request{
+				RepoCreds: RepoCredentials{SSHPrivateKey: "key"},
+			},
+			assertions: func(t *testing.T, _ git.RepoCredentials, err error) {
+				require.ErrorContains(t, err, "Azure DevOps requires a Personal Access Token")
+			},
+		},
+		{
+			name:     "GitHub token missing",
+			provider: GitProviderGitHub,
+			req:      &Request{},
+			assertions: func(t *testing.T, _ git.RepoCredentials, err error) {
+				require.ErrorContains(t, err, "GitHub requires a token")
+			},
+		},
+		{
+			name:     "unregistered provider",
+			provider: GitProviderGitLab,
+			req:      &Request{PRCreds: PRCredentials{Token: "token"}},
+			assertions: func(t *testing.T, _ git.RepoCredentials, err error) {
+				require.ErrorContains(t, err, "no registered credential resolver")
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			creds, err := resolvePRCredentials(testCase.provider, testCase.req)
+			testCase.assertions(t, creds, err)
+		})
+	}
+}
+
+func TestCredentialResolversCoverPROpeners(t *testing.T) {
+	for provider := range prOpeners {
+		require.Contains(t, credentialResolvers, provider)
+	}
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedFeature is returned when PR configuration requests a feature
+// that is not supported by the Git provider used to open PRs, unless the
+// configuration opts into ignoring such features.
+var ErrUnsupportedFeature = errors.New("feature is not supported by git provider")
+
+// prFeature identifies an optional PR feature that only some Git providers
+// support.
+type prFeature string
+
+const (
+	prFeatureDeleteBranchOnFailure prFeature = "deleteBranchOnFailure"
+	prFeatureLabels                prFeature = "labels"
+	prFeatureLabelRules            prFeature = "labelRules"
+	prFeatureAzureDevOpsDefaults   prFeature = "azureDevOpsDefaults"
+)
+
+// prFeatureSupport is a registry of the optional PR features supported by each
+// Git provider. Providers that are not present support none.
+var prFeatureSupport = map[GitProvider]map[prFeature]struct{}{
+	GitProviderAzureDevOps: {
+		prFeatureDeleteBranchOnFailure: {},
+		prFeatureLabels:                {},
+		prFeatureLabelRules:            {},
+		prFeatureAzureDevOpsDefaults:   {},
+	},
+}
+
+// requestedPRFeatures returns the optional PR features requested by the
+// specified PR configuration.
+func requestedPRFeatures(cfg pullRequestConfig) []prFeature {
+	var features []prFeature
+	if cfg.DeleteBranchOnFailure {
+		features = append(features, prFeatureDeleteBranchOnFailure)
+	}
+	if len(cfg.Labels) > 0 {
+		features = append(features, prFeatureLabels)
+	}
+	if len(cfg.LabelRules) > 0 {
+		features = append(features, prFeatureLabelRules)
+	}
+	if cfg.AzureDevOpsDefaults != nil {
+		features = append(features, prFeatureAzureDevOpsDefaults)
+	}
+	return features
+}
+
+// ensurePRFeaturesSupported returns an error wrapping ErrUnsupportedFeature if
+// the PR configuration for the request's target branch requests any feature
+// the specified Git provider does not support. If the configuration opts into
+// ignoring unsupported features, a warning is logged for each instead.
+func ensurePRFeaturesSupported(rc requestContext, provider GitProvider) error {
+	prCfg := rc.target.branchConfig.PRs
+	supported := prFeatureSupport[provider]
+	for _, feature := range requestedPRFeatures(prCfg) {
+		if _, ok := supported[feature]; ok {
+			continue
+		}
+		if !prCfg.IgnoreUnsupportedFeatures {
+			return fmt.Errorf(
+				"%w: %s is not supported by git provider %q",
+				ErrUnsupportedFeature,
+				feature,
+				provider,
+			)
+		}
+		rc.logger.WithField("feature", feature).
+			WithField("gitProvider", provider).
+			Warn("ignoring PR feature not supported by git provider")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package render
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsurePRFeaturesSupported(t *testing.T) {
+	testCases := []struct {
+		name       string
+		provider   GitProvider
+		cfg        pullRequestConfig
+		assertions func(*testing.T, []*log.Entry, error)
+	}{
+		{
+			name:     "supported features",
+			provider: GitProviderAzureDevOps,
+			cfg: pullRequestConfig{
+				DeleteBranchOnFailure: true,
+				Labels:                []string{"kargo-render"},
+				LabelRules:            []labelRuleConfig{{Path: "charts", Label: "helm"}},
+				AzureDevOpsDefaults:   &azureDevOpsPRDefaultsConfig{Draft: true},
+			},
+			assertions: func(t *testing.T, entries []*log.Entry, err error) {
+				require.NoError(t, err)
+				require.Empty(t, entries)
+			},
+		},
+		{
+			name:     "no optional features",
+			provider: GitProviderGitHub,
+			cfg:      pullRequestConfig{Footer: "footer"},
+			assertions: func(t *testing.T, entries []*log.Entry, err error) {
+				require.NoError(t, err)
+				require.Empty(t, entries)
+			},
+		},
+		{
+			name:     "unsupported feature",
+			provider: GitProviderGitHub,
+			cfg:      pullRequestConfig{Labels: []string{"kargo-render"}},
+			assertions: func(t *testing.T, entries []*log.Entry, err error) {
+				require.ErrorIs(t, err, ErrUnsupportedFeature)
+				require.ErrorContains(t, err, `labels is not supported by git provider "github"`)
+				require.Empty(t, entries)
+			},
+		},
+		{
+			name:     "unsupported features ignored",
+			provider: GitProviderGitHub,
+			cfg: pullRequestConfig{
+				Labels:                    []string{"kargo-render"},
+				DeleteBranchOnFailure:     true,
+				IgnoreUnsupportedFeatures: true,
+			},
+			assertions: func(t *testing.T, entries []*log.Entry, err error) {
+				require.NoError(t, err)
+				require.Len(t, entries, 2)
+				for _, entry := range entries {
+					require.Equal(t, log.WarnLevel, entry.Level)
+				}
+				require.Equal(t, prFeatureDeleteBranchOnFailure, entries[0].Data["feature"])
+				require.Equal(t, prFeatureLabels, entries[1].Data["feature"])
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			logger, hook := test.NewNullLogger()
+			err := ensurePRFeaturesSupported(
+				requestContext{
+					logger: log.NewEntry(logger),
+					target: targetContext{
+						branchConfig: branchConfig{PRs: testCase.cfg},
+					},
+				},
+				testCase.provider,
+			)
+			testCase.assertions(t, hook.AllEntries(), err)
+		})
+	}
+}
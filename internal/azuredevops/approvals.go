@@ -0,0 +1,307 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/policy"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// approvalPollInterval is the amount of time to wait between checks of a PR's
+// reviews while waiting for it to be approved. It is a package-level variable
+// so that it can be overridden in tests.
+var approvalPollInterval = 30 * time.Second
+
+// minimumReviewersPolicyTypeID is the ID of the built-in minimum number of
+// reviewers policy type.
+var minimumReviewersPolicyTypeID = uuid.MustParse("fa4e907d-c16b-4a4c-9dfa-4906e5d171dd")
+
+// reviewerPolicyTypeIDs are the IDs of the types of branch policy that require
+// PRs to be approved by reviewers.
+var reviewerPolicyTypeIDs = map[uuid.UUID]struct{}{
+	// Minimum number of reviewers
+	minimumReviewersPolicyTypeID: {},
+	// Required reviewers
+	uuid.MustParse("fd2167ab-b0be-447a-8ec8-39368250530e"): {},
+}
+
+// ErrPRNotActive is returned when an operation requires a PR to be active, but
+// it has been completed or abandoned.
+var ErrPRNotActive = errors.New("pull request is not active")
+
+// CompleteAfterApproval arranges for the specified PR to be completed, using
+// the specified settings, only once it has been approved. If a required
+// reviewer policy applies to the PR's target branch, Azure DevOps' own
+// auto-complete already waits for approval, so it is enabled and this returns
+// immediately. Otherwise, this polls the PR until it is approved and then
+// completes it, so that behavior is the same regardless of the target branch's
+// policies. A PR is approved once at least one reviewer has approved it, all of
+// its required reviewers have approved it, and no reviewer has rejected it or
+// is waiting for its author. Polling continues until the context is canceled;
+// if the PR is completed or abandoned meanwhile, an error wrapping
+// ErrPRNotActive is returned. A PR that no longer changes anything when it is
+// to be completed is handled according to opts.EmptyPRPolicy. If opts specifies
+// an iteration or area path for linked work items, this additionally waits for
+// the PR to be completed before moving them.
+func CompleteAfterApproval(
+	ctx context.Context,
+	repoURL string,
+	prID int,
+	creds gitutil.RepoCredentials,
+	opts AutoCompleteOptions,
+) (err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return err
+	}
+	pr, err := getActivePR(ctx, repo, prID)
+	if err != nil {
+		return err
+	}
+	if pr.TargetRefName == nil {
+		return fmt.Errorf(
+			"%w: pull request %d has no target branch",
+			ErrIncompleteResponse,
+			prID,
+		)
+	}
+	protected, err := hasPolicy(ctx, repo, *pr.TargetRefName, isReviewerPolicy)
+	if err != nil {
+		return err
+	}
+	var empty bool
+	if protected {
+		if empty, err = handleEmptyPR(ctx, repo, pr, opts.EmptyPRPolicy); empty || err != nil {
+			return err
+		}
+		var status AutoCompleteStatus
+		if status, err = enableAutoComplete(ctx, repo, prID, opts); err != nil ||
+			status != AutoCompleteEngaged {
+			return err
+		}
+		return moveLinkedWorkItemsOnCompletion(ctx, repo, prID, opts)
+	}
+	for !isApproved(pr) {
+		select {
+		case <-time.After(approvalPollInterval):
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"error waiting for approval of pull request %d: %w",
+				prID,
+				ctx.Err(),
+			)
+		}
+		if pr, err = getActivePR(ctx, repo, prID); err != nil {
+			return err
+		}
+	}
+	if empty, err = handleEmptyPR(ctx, repo, pr, opts.EmptyPRPolicy); empty || err != nil {
+		return err
+	}
+	if _, err = repo.client.UpdatePullRequest(ctx, git.UpdatePullRequestArgs{
+		Project:       &repo.project,
+		RepositoryId:  &repo.id,
+		PullRequestId: &prID,
+		GitPullRequestToUpdate: &git.GitPullRequest{
+			Status:                &git.PullRequestStatusValues.Completed,
+			LastMergeSourceCommit: pr.LastMergeSourceCommit,
+			CompletionOptions:     completionOptionsOf(opts),
+		},
+	}); err != nil {
+		return fmt.Errorf("error completing pull request %d: %w", prID, err)
+	}
+	return moveLinkedWorkItemsOnCompletion(ctx, repo, prID, opts)
+}
+
+// getActivePR returns the specified PR. If it is not active, an error wrapping
+// ErrPRNotActive is returned.
+func getActivePR(ctx context.Context, repo *repoClient, prID int) (*git.GitPullRequest, error) {
+	pr, err := repo.client.GetPullRequest(ctx, git.GetPullRequestArgs{
+		Project:       &repo.project,
+		RepositoryId:  &repo.id,
+		PullRequestId: &prID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting pull request %d: %w", prID, err)
+	}
+	if pr == nil {
+		return nil, fmt.Errorf(
+			"%w: pull request %d was not returned",
+			ErrIncompleteResponse,
+			prID,
+		)
+	}
+	if pr.Status != nil && *pr.Status != git.PullRequestStatusValues.Active {
+		return nil, fmt.Errorf("%w: pull request %d is %s", ErrPRNotActive, prID, *pr.Status)
+	}
+	return pr, nil
+}
+
+// isReviewerPolicy returns a bool indicating whether the specified policy is
+// a required policy that requires PRs to be approved by reviewers.
+func isReviewerPolicy(config policy.PolicyConfiguration) bool {
+	if !isRequired(config) || config.Type == nil || config.Type.Id == nil {
+		return false
+	}
+	_, ok := reviewerPolicyTypeIDs[*config.Type.Id]
+	return ok
+}
+
+// isApproved returns a bool indicating whether the specified PR has been
+// approved by at least one reviewer and by all of its required reviewers and
+// has not been rejected by, or left waiting for its author by, any reviewer.
+func isApproved(pr *git.GitPullRequest) bool {
+	if pr.Reviewers == nil {
+		return false
+	}
+	var approvals int
+	for _, reviewer := range *pr.Reviewers {
+		vote := VoteNone
+		if reviewer.Vote != nil {
+			vote = Vote(*reviewer.Vote)
+		}
+		switch {
+		case vote < VoteNone:
+			return false
+		case vote >= VoteApprovedWithSuggestions:
+			approvals++
+		case reviewer.IsRequired != nil && *reviewer.IsRequired:
+			return false
+		}
+	}
+	return approvals > 0
+}
+
+// ApprovalSummary summarizes how far a PR is from being approved, e.g. for
+// reporting that it has "3 of 4 required approvals".
+type ApprovalSummary struct {
+	// Approved is the number of approvals that count toward Required.
+	// Approvals by reviewers other than required ones only count toward the
+	// approvals that required reviewers do not account for, and an approval by
+	// the PR's author only counts if the target branch's policies allow it.
+	Approved int
+	// Required is the number of approvals the PR needs. This is the greater of
+	// the number of the PR's required reviewers and the minimum number of
+	// reviewers required by the target branch's policies, or one, if no policy
+	// requires a minimum.
+	Required int
+	// Blocked is the number of reviewers who have rejected the PR or are
+	// waiting for its author.
+	Blocked int
+	// Approvable indicates whether the PR has all the approvals it needs and is
+	// not blocked by any reviewer.
+	Approvable bool
+}
+
+// GetApprovalSummary summarizes the approval status of the specified PR, based
+// on its reviewers' votes and the reviewer policies that apply to its target
+// branch. If the PR is not active, an error wrapping ErrPRNotActive is
+// returned.
+func GetApprovalSummary(
+	ctx context.Context,
+	repoURL string,
+	prID int,
+	creds gitutil.RepoCredentials,
+) (_ ApprovalSummary, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return ApprovalSummary{}, err
+	}
+	pr, err := getActivePR(ctx, repo, prID)
+	if err != nil {
+		return ApprovalSummary{}, err
+	}
+	if pr.TargetRefName == nil {
+		return ApprovalSummary{}, fmt.Errorf(
+			"%w: pull request %d has no target branch",
+			ErrIncompleteResponse,
+			prID,
+		)
+	}
+	minimum := 1
+	creatorVoteCounts := true
+	if err = forEachPolicy(
+		ctx,
+		repo,
+		*pr.TargetRefName,
+		func(config policy.PolicyConfiguration) bool {
+			if !isRequired(config) || config.Type == nil || config.Type.Id == nil ||
+				*config.Type.Id != minimumReviewersPolicyTypeID {
+				return true
+			}
+			if count, ok := policySetting[float64](config, "minimumApproverCount"); ok &&
+				int(count) > minimum {
+				minimum = int(count)
+			}
+			if counts, ok := policySetting[bool](config, "creatorVoteCounts"); ok && !counts {
+				creatorVoteCounts = false
+			}
+			return true
+		},
+	); err != nil {
+		return ApprovalSummary{}, err
+	}
+	return summarizeApprovals(pr, minimum, creatorVoteCounts), nil
+}
+
+// summarizeApprovals summarizes the approval status of the specified PR, given
+// the minimum number of approvals it needs and whether its author's approval
+// counts toward them.
+func summarizeApprovals(
+	pr *git.GitPullRequest,
+	minimum int,
+	creatorVoteCounts bool,
+) ApprovalSummary {
+	var author string
+	if pr.CreatedBy != nil && pr.CreatedBy.Id != nil {
+		author = *pr.CreatedBy.Id
+	}
+	var summary ApprovalSummary
+	var required, requiredApproved, otherApproved int
+	if pr.Reviewers != nil {
+		for _, reviewer := range *pr.Reviewers {
+			vote := VoteNone
+			if reviewer.Vote != nil {
+				vote = Vote(*reviewer.Vote)
+			}
+			isRequired := reviewer.IsRequired != nil && *reviewer.IsRequired
+			if isRequired {
+				required++
+			}
+			switch {
+			case vote < VoteNone:
+				summary.Blocked++
+			case vote < VoteApprovedWithSuggestions:
+			case isRequired:
+				requiredApproved++
+			case creatorVoteCounts || reviewer.Id == nil || *reviewer.Id != author:
+				otherApproved++
+			}
+		}
+	}
+	summary.Required = max(minimum, required)
+	summary.Approved = requiredApproved + min(otherApproved, summary.Required-required)
+	summary.Approvable = summary.Approved >= summary.Required && summary.Blocked == 0
+	return summary
+}
+
+// policySetting returns the value of the specified setting of the specified
+// policy, if it is set and of the expected type. Settings are unmarshaled
+// from JSON, so numbers are float64s.
+func policySetting[T any](config policy.PolicyConfiguration, name string) (T, bool) {
+	var zero T
+	settings, ok := config.Settings.(map[string]any)
+	if !ok {
+		return zero, false
+	}
+	value, ok := settings[name].(T)
+	return value, ok
+}
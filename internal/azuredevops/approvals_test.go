@@ -0,0 +1,307 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/policy"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestCompleteAfterApproval(t *testing.T) {
+	requiredReviewers := policy.PolicyConfiguration{
+		IsEnabled:  ptr(true),
+		IsBlocking: ptr(true),
+		Type:       &policy.PolicyTypeRef{Id: ptr(uuid.MustParse("fd2167ab-b0be-447a-8ec8-39368250530e"))},
+	}
+	buildValidation := policy.PolicyConfiguration{
+		IsEnabled:  ptr(true),
+		IsBlocking: ptr(true),
+		Type:       &policy.PolicyTypeRef{Id: ptr(uuid.New())},
+	}
+	pending := []git.IdentityRefWithVote{
+		{Id: ptr("team"), IsRequired: ptr(true), Vote: ptr(0)},
+		{Id: ptr("dev"), Vote: ptr(10)},
+	}
+	approved := []git.IdentityRefWithVote{
+		{Id: ptr("team"), IsRequired: ptr(true), Vote: ptr(5)},
+		{Id: ptr("dev"), Vote: ptr(10)},
+	}
+	testCases := []struct {
+		name       string
+		policies   []policy.PolicyConfiguration
+		polls      []git.GitPullRequest
+		assertions func(t *testing.T, polls int, updates []*git.GitPullRequest, err error)
+	}{
+		{
+			name:     "reviewer policy present",
+			policies: []policy.PolicyConfiguration{buildValidation, requiredReviewers},
+			polls:    []git.GitPullRequest{{Reviewers: &pending}},
+			assertions: func(t *testing.T, polls int, updates []*git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, polls)
+				require.Len(t, updates, 1)
+				require.NotNil(t, updates[0].AutoCompleteSetBy)
+				require.Nil(t, updates[0].Status)
+			},
+		},
+		{
+			name:     "reviewer policy absent",
+			policies: []policy.PolicyConfiguration{buildValidation},
+			polls: []git.GitPullRequest{
+				{Reviewers: &pending},
+				{Reviewers: &pending},
+				{
+					Reviewers:             &approved,
+					LastMergeSourceCommit: &git.GitCommitRef{CommitId: ptr("abc123")},
+				},
+			},
+			assertions: func(t *testing.T, polls int, updates []*git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 3, polls)
+				require.Len(t, updates, 1)
+				require.Nil(t, updates[0].AutoCompleteSetBy)
+				require.Equal(t, git.PullRequestStatusValues.Completed, *updates[0].Status)
+				require.Equal(t, "abc123", *updates[0].LastMergeSourceCommit.CommitId)
+				require.True(t, *updates[0].CompletionOptions.DeleteSourceBranch)
+			},
+		},
+		{
+			name: "abandoned while waiting for approval",
+			polls: []git.GitPullRequest{
+				{Reviewers: &pending},
+				{Status: &git.PullRequestStatusValues.Abandoned},
+			},
+			assertions: func(t *testing.T, polls int, updates []*git.GitPullRequest, err error) {
+				require.ErrorIs(t, err, ErrPRNotActive)
+				require.ErrorContains(t, err, "pull request 42 is abandoned")
+				require.Equal(t, 2, polls)
+				require.Empty(t, updates)
+			},
+		},
+	}
+	origInterval := approvalPollInterval
+	approvalPollInterval = 0
+	t.Cleanup(func() { approvalPollInterval = origInterval })
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var polls int
+			var updates []*git.GitPullRequest
+			useFakeIdentity(t, uuid.New())
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPullRequestFn: func(
+					_ context.Context,
+					args git.GetPullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					pr := testCase.polls[polls]
+					polls++
+					pr.TargetRefName = ptr("refs/heads/env/prod")
+					return &pr, nil
+				},
+				getPolicyConfigurationsFn: func(
+					_ context.Context,
+					args git.GetPolicyConfigurationsArgs,
+				) (*git.GitPolicyConfigurationResponse, error) {
+					require.Equal(t, "refs/heads/env/prod", *args.RefName)
+					return &git.GitPolicyConfigurationResponse{
+						PolicyConfigurations: &testCase.policies,
+					}, nil
+				},
+				updatePullRequestFn: func(
+					_ context.Context,
+					args git.UpdatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					updates = append(updates, args.GitPullRequestToUpdate)
+					return args.GitPullRequestToUpdate, nil
+				},
+			})
+			err := CompleteAfterApproval(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				42,
+				gitutil.RepoCredentials{Password: "token"},
+				AutoCompleteOptions{DeleteSourceBranch: true},
+			)
+			testCase.assertions(t, polls, updates, err)
+		})
+	}
+}
+
+func TestIsApproved(t *testing.T) {
+	testCases := []struct {
+		name      string
+		reviewers []git.IdentityRefWithVote
+		approved  bool
+	}{
+		{
+			name: "no reviewers",
+		},
+		{
+			name:      "no votes",
+			reviewers: []git.IdentityRefWithVote{{Vote: ptr(0)}},
+		},
+		{
+			name:      "optional approval",
+			reviewers: []git.IdentityRefWithVote{{Vote: ptr(10)}, {Vote: ptr(0)}},
+			approved:  true,
+		},
+		{
+			name: "required reviewer has not voted",
+			reviewers: []git.IdentityRefWithVote{
+				{Vote: ptr(10)},
+				{IsRequired: ptr(true)},
+			},
+		},
+		{
+			name: "waiting for author",
+			reviewers: []git.IdentityRefWithVote{
+				{Vote: ptr(10), IsRequired: ptr(true)},
+				{Vote: ptr(-5)},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			pr := &git.GitPullRequest{}
+			if testCase.reviewers != nil {
+				pr.Reviewers = &testCase.reviewers
+			}
+			require.Equal(t, testCase.approved, isApproved(pr))
+		})
+	}
+}
+
+func TestGetApprovalSummary(t *testing.T) {
+	minimumReviewers := func(count int, creatorVoteCounts bool) policy.PolicyConfiguration {
+		return policy.PolicyConfiguration{
+			IsEnabled:  ptr(true),
+			IsBlocking: ptr(true),
+			Type:       &policy.PolicyTypeRef{Id: &minimumReviewersPolicyTypeID},
+			Settings: map[string]any{
+				"minimumApproverCount": float64(count),
+				"creatorVoteCounts":    creatorVoteCounts,
+			},
+		}
+	}
+	testCases := []struct {
+		name      string
+		policies  []policy.PolicyConfiguration
+		reviewers []git.IdentityRefWithVote
+		summary   ApprovalSummary
+	}{
+		{
+			name:    "no policies or reviewers",
+			summary: ApprovalSummary{Required: 1},
+		},
+		{
+			name: "no policies and an optional approval",
+			reviewers: []git.IdentityRefWithVote{
+				{Id: ptr("dev"), Vote: ptr(10)},
+				{Id: ptr("ops"), Vote: ptr(0)},
+			},
+			summary: ApprovalSummary{Approved: 1, Required: 1, Approvable: true},
+		},
+		{
+			name:     "3 of 4 required approvals",
+			policies: []policy.PolicyConfiguration{minimumReviewers(4, true)},
+			reviewers: []git.IdentityRefWithVote{
+				{Id: ptr("a"), Vote: ptr(10)},
+				{Id: ptr("b"), Vote: ptr(5)},
+				{Id: ptr("c"), Vote: ptr(10)},
+				{Id: ptr("d"), Vote: ptr(0)},
+			},
+			summary: ApprovalSummary{Approved: 3, Required: 4},
+		},
+		{
+			name:     "optional approvals do not stand in for required reviewers",
+			policies: []policy.PolicyConfiguration{minimumReviewers(2, true)},
+			reviewers: []git.IdentityRefWithVote{
+				{Id: ptr("team"), IsRequired: ptr(true), Vote: ptr(0)},
+				{Id: ptr("a"), Vote: ptr(10)},
+				{Id: ptr("b"), Vote: ptr(10)},
+			},
+			summary: ApprovalSummary{Approved: 1, Required: 2},
+		},
+		{
+			name: "more required reviewers than the policy minimum",
+			policies: []policy.PolicyConfiguration{
+				minimumReviewers(1, true),
+				{
+					IsEnabled:  ptr(false),
+					IsBlocking: ptr(true),
+					Type:       &policy.PolicyTypeRef{Id: &minimumReviewersPolicyTypeID},
+					Settings:   map[string]any{"minimumApproverCount": float64(5)},
+				},
+			},
+			reviewers: []git.IdentityRefWithVote{
+				{Id: ptr("team"), IsRequired: ptr(true), Vote: ptr(10)},
+				{Id: ptr("leads"), IsRequired: ptr(true), Vote: ptr(10)},
+			},
+			summary: ApprovalSummary{Approved: 2, Required: 2, Approvable: true},
+		},
+		{
+			name:     "author's approval does not count",
+			policies: []policy.PolicyConfiguration{minimumReviewers(2, false)},
+			reviewers: []git.IdentityRefWithVote{
+				{Id: ptr("author"), Vote: ptr(10)},
+				{Id: ptr("a"), Vote: ptr(10)},
+			},
+			summary: ApprovalSummary{Approved: 1, Required: 2},
+		},
+		{
+			name:     "approved but rejected by a reviewer",
+			policies: []policy.PolicyConfiguration{minimumReviewers(1, true)},
+			reviewers: []git.IdentityRefWithVote{
+				{Id: ptr("a"), Vote: ptr(10)},
+				{Id: ptr("b"), Vote: ptr(-10)},
+				{Id: ptr("c"), Vote: ptr(-5)},
+			},
+			summary: ApprovalSummary{Approved: 1, Required: 1, Blocked: 2},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPullRequestFn: func(
+					_ context.Context,
+					args git.GetPullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					pr := &git.GitPullRequest{
+						TargetRefName: ptr("refs/heads/env/prod"),
+						CreatedBy:     &webapi.IdentityRef{Id: ptr("author")},
+					}
+					if testCase.reviewers != nil {
+						pr.Reviewers = &testCase.reviewers
+					}
+					return pr, nil
+				},
+				getPolicyConfigurationsFn: func(
+					_ context.Context,
+					args git.GetPolicyConfigurationsArgs,
+				) (*git.GitPolicyConfigurationResponse, error) {
+					require.Equal(t, "refs/heads/env/prod", *args.RefName)
+					return &git.GitPolicyConfigurationResponse{
+						PolicyConfigurations: &testCase.policies,
+					}, nil
+				},
+			})
+			summary, err := GetApprovalSummary(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				42,
+				gitutil.RepoCredentials{Password: "token"},
+			)
+			require.NoError(t, err)
+			require.Equal(t, testCase.summary, summary)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package azuredevops
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AuditEvent is a record of an attempt to open a PR.
+type AuditEvent struct {
+	// Time is when the attempt began.
+	Time time.Time
+	// Identity is the ID of the Azure DevOps identity associated with the
+	// credentials used for the attempt. This is empty if it could not be
+	// determined, for instance, because Azure DevOps could not be reached.
+	Identity string
+	// Repo is the URL, without any credentials, of the repository the PR was to
+	// be opened in.
+	Repo string
+	// SourceBranch is the source branch of the PR, as specified.
+	SourceBranch string
+	// TargetBranch is the target branch of the PR, as specified.
+	TargetBranch string
+	// Title is the title of the PR.
+	Title string
+	// Outcome is the result of the attempt.
+	Outcome Outcome
+	// URL is the URL of the PR, if one was opened.
+	URL string
+	// AutoComplete is whether auto-complete was engaged for the PR, if one was
+	// opened.
+	AutoComplete AutoCompleteStatus
+	// Error is the message, with credentials masked, of the error the attempt
+	// failed with, if any.
+	Error string
+}
+
+// Auditor records AuditEvents, for instance, by persisting them to a store
+// that compliance tooling can query. Implementations must be safe for
+// concurrent use.
+type Auditor interface {
+	// Audit records the specified event. It cannot fail the attempt the event
+	// describes, so implementations must handle their own errors.
+	Audit(ctx context.Context, event AuditEvent)
+}
+
+// NopAuditor is an Auditor that discards all events. It is used when no
+// Auditor is specified.
+type NopAuditor struct{}
+
+// Audit implements Auditor.
+func (NopAuditor) Audit(context.Context, AuditEvent) {}
+
+// RecordingAuditor is an Auditor that retains all events in memory. It is
+// chiefly useful in tests.
+type RecordingAuditor struct {
+	mu     sync.Mutex
+	events []AuditEvent
+}
+
+// Audit implements Auditor.
+func (r *RecordingAuditor) Audit(_ context.Context, event AuditEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// Events returns the events recorded so far, in the order they were recorded.
+func (r *RecordingAuditor) Events() []AuditEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]AuditEvent(nil), r.events...)
+}
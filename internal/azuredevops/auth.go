@@ -0,0 +1,122 @@
+package azuredevops
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// AuthMode specifies how credentials are presented to Azure DevOps.
+type AuthMode string
+
+const (
+	// AuthModePAT presents the password of the credentials as a Personal Access
+	// Token (PAT). Any username is ignored. This is the default.
+	AuthModePAT AuthMode = "pat"
+	// AuthModeBasic presents the username and password of the credentials using
+	// basic authentication. This is useful for Azure DevOps Server instances
+	// that authenticate real user accounts rather than PATs. The Azure DevOps
+	// SDK cannot negotiate NTLM, so such servers must also accept basic
+	// authentication.
+	AuthModeBasic AuthMode = "basic"
+	// AuthModeOnBehalfOf presents the password of the credentials as a bearer
+	// token. The password must be a Microsoft Entra ID access token obtained
+	// using the on-behalf-of flow, so that pull requests and other changes are
+	// attributed to the user on whose behalf the token was issued rather than
+	// to the account of any PAT. Any username is ignored.
+	AuthModeOnBehalfOf AuthMode = "onBehalfOf"
+)
+
+// ErrInvalidOnBehalfOfToken is returned when the token presented using
+// AuthModeOnBehalfOf is not an unexpired access token delegated by a user.
+var ErrInvalidOnBehalfOfToken = errors.New("invalid on-behalf-of access token")
+
+// newConnection returns a connection to the specified Azure DevOps
+// organization, or server collection, URL that presents the specified
+// credentials as specified by the auth mode.
+func newConnection(
+	baseURL string,
+	creds gitutil.RepoCredentials,
+	mode AuthMode,
+) (*azuredevops.Connection, error) {
+	switch mode {
+	case "", AuthModePAT:
+		if creds.Password == "" {
+			return nil, errors.New(
+				"Azure DevOps requires a Personal Access Token (PAT) as password",
+			)
+		}
+		return azuredevops.NewPatConnection(baseURL, creds.Password), nil
+	case AuthModeBasic:
+		if creds.Username == "" || creds.Password == "" {
+			return nil, errors.New(
+				"basic authentication to Azure DevOps requires a username and password",
+			)
+		}
+		connection := azuredevops.NewAnonymousConnection(baseURL)
+		connection.AuthorizationString = azuredevops.CreateBasicAuthHeaderValue(
+			creds.Username,
+			creds.Password,
+		)
+		return connection, nil
+	case AuthModeOnBehalfOf:
+		if err := validateOnBehalfOfToken(creds.Password); err != nil {
+			return nil, err
+		}
+		connection := azuredevops.NewAnonymousConnection(baseURL)
+		connection.AuthorizationString = "Bearer " + creds.Password
+		return connection, nil
+	default:
+		return nil, fmt.Errorf("unknown Azure DevOps auth mode %q", mode)
+	}
+}
+
+// onBehalfOfClaims are the claims of an access token that are examined to
+// determine whether it was delegated by a user.
+type onBehalfOfClaims struct {
+	// Scope lists the delegated permissions of the token. Application tokens,
+	// which act on behalf of no user, have none.
+	Scope string `json:"scp"`
+	// Expiry is the time, in seconds since the Unix epoch, after which the
+	// token is no longer valid.
+	Expiry int64 `json:"exp"`
+}
+
+// validateOnBehalfOfToken returns an error wrapping ErrInvalidOnBehalfOfToken
+// if the specified token is not a JWT access token, was not delegated by a
+// user, or has expired. The token's signature is not verified; Azure DevOps
+// does that.
+func validateOnBehalfOfToken(token string) error {
+	if token == "" {
+		return fmt.Errorf("%w: a token is required as password", ErrInvalidOnBehalfOfToken)
+	}
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return fmt.Errorf("%w: token is not a JWT", ErrInvalidOnBehalfOfToken)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(segments[1])
+	if err != nil {
+		return fmt.Errorf("%w: token is not a JWT", ErrInvalidOnBehalfOfToken)
+	}
+	var claims onBehalfOfClaims
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return fmt.Errorf("%w: token claims could not be parsed", ErrInvalidOnBehalfOfToken)
+	}
+	if claims.Scope == "" {
+		return fmt.Errorf(
+			"%w: token is not delegated by a user; application tokens are not supported",
+			ErrInvalidOnBehalfOfToken,
+		)
+	}
+	if claims.Expiry != 0 && !now().Before(time.Unix(claims.Expiry, 0)) {
+		return fmt.Errorf("%w: token has expired", ErrInvalidOnBehalfOfToken)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package azuredevops
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestNewConnection(t *testing.T) {
+	testCases := []struct {
+		name       string
+		creds      gitutil.RepoCredentials
+		mode       AuthMode
+		assertions func(t *testing.T, connection *azuredevops.Connection, err error)
+	}{
+		{
+			name:  "PAT by default",
+			creds: gitutil.RepoCredentials{Username: "ignored", Password: "pat"},
+			assertions: func(t *testing.T, connection *azuredevops.Connection, err error) {
+				require.NoError(t, err)
+				require.Equal(t, basicAuth(":pat"), connection.AuthorizationString)
+			},
+		},
+		{
+			name:  "PAT without password",
+			creds: gitutil.RepoCredentials{Username: "user"},
+			mode:  AuthModePAT,
+			assertions: func(t *testing.T, _ *azuredevops.Connection, err error) {
+				require.ErrorContains(t, err, "requires a Personal Access Token")
+			},
+		},
+		{
+			name:  "basic",
+			creds: gitutil.RepoCredentials{Username: `CORP\user`, Password: "secret"},
+			mode:  AuthModeBasic,
+			assertions: func(t *testing.T, connection *azuredevops.Connection, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "https://tfs.example.com/tfs/defaultcollection", connection.BaseUrl)
+				require.Equal(t, basicAuth(`CORP\user:secret`), connection.AuthorizationString)
+			},
+		},
+		{
+			name:  "basic without username",
+			creds: gitutil.RepoCredentials{Password: "secret"},
+			mode:  AuthModeBasic,
+			assertions: func(t *testing.T, _ *azuredevops.Connection, err error) {
+				require.ErrorContains(t, err, "requires a username and password")
+			},
+		},
+		{
+			name:  "on behalf of",
+			creds: gitutil.RepoCredentials{Username: "ignored", Password: oboToken},
+			mode:  AuthModeOnBehalfOf,
+			assertions: func(t *testing.T, connection *azuredevops.Connection, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "Bearer "+oboToken, connection.AuthorizationString)
+			},
+		},
+		{
+			name:  "on behalf of without token",
+			creds: gitutil.RepoCredentials{Username: "user"},
+			mode:  AuthModeOnBehalfOf,
+			assertions: func(t *testing.T, _ *azuredevops.Connection, err error) {
+				require.ErrorIs(t, err, ErrInvalidOnBehalfOfToken)
+			},
+		},
+		{
+			name:  "on behalf of with PAT",
+			creds: gitutil.RepoCredentials{Password: "pat"},
+			mode:  AuthModeOnBehalfOf,
+			assertions: func(t *testing.T, _ *azuredevops.Connection, err error) {
+				require.ErrorIs(t, err, ErrInvalidOnBehalfOfToken)
+				require.ErrorContains(t, err, "not a JWT")
+			},
+		},
+		{
+			name: "on behalf of with application token",
+			creds: gitutil.RepoCredentials{
+				Password: jwt(`{"roles":["vso.code_write"],"exp":4102444800}`),
+			},
+			mode: AuthModeOnBehalfOf,
+			assertions: func(t *testing.T, _ *azuredevops.Connection, err error) {
+				require.ErrorIs(t, err, ErrInvalidOnBehalfOfToken)
+				require.ErrorContains(t, err, "not delegated by a user")
+			},
+		},
+		{
+			name: "on behalf of with expired token",
+			creds: gitutil.RepoCredentials{
+				Password: jwt(`{"scp":"user_impersonation","exp":946684800}`),
+			},
+			mode: AuthModeOnBehalfOf,
+			assertions: func(t *testing.T, _ *azuredevops.Connection, err error) {
+				require.ErrorIs(t, err, ErrInvalidOnBehalfOfToken)
+				require.ErrorContains(t, err, "expired")
+			},
+		},
+		{
+			name:  "on behalf of with malformed claims",
+			creds: gitutil.RepoCredentials{Password: jwt(`not json`)},
+			mode:  AuthModeOnBehalfOf,
+			assertions: func(t *testing.T, _ *azuredevops.Connection, err error) {
+				require.ErrorIs(t, err, ErrInvalidOnBehalfOfToken)
+			},
+		},
+		{
+			name:  "unknown mode",
+			creds: gitutil.RepoCredentials{Username: "user", Password: "secret"},
+			mode:  "ntlm",
+			assertions: func(t *testing.T, _ *azuredevops.Connection, err error) {
+				require.ErrorContains(t, err, `unknown Azure DevOps auth mode "ntlm"`)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			connection, err := newConnection(
+				"https://tfs.example.com/tfs/DefaultCollection/",
+				testCase.creds,
+				testCase.mode,
+			)
+			testCase.assertions(t, connection, err)
+		})
+	}
+}
+
+func TestNewRepoClientBasicAuth(t *testing.T) {
+	var authorization string
+	client := &fakeGitClient{getRepositoriesFn: fakeRepos("repo")}
+	useFakeGitClient(t, client)
+	newGitClient = func(_ context.Context, conn *azuredevops.Connection) (git.Client, error) {
+		authorization = conn.AuthorizationString
+		return client, nil
+	}
+	_, err := newRepoClient(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		gitutil.RepoCredentials{Username: "user", Password: "secret"},
+		&ConnectionOptions{AuthMode: AuthModeBasic},
+	)
+	require.NoError(t, err)
+	require.Equal(t, basicAuth("user:secret"), authorization)
+}
+
+func TestOpenPROnBehalfOf(t *testing.T) {
+	var authorization string
+	client := &fakeGitClient{
+		getRepositoriesFn:   fakeRepos("repo"),
+		createPullRequestFn: fakeCreatePullRequest(nil),
+	}
+	useFakeGitClient(t, client)
+	newGitClient = func(_ context.Context, conn *azuredevops.Connection) (git.Client, error) {
+		authorization = conn.AuthorizationString
+		return client, nil
+	}
+	_, err := OpenPR(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"title",
+		"description",
+		"env/dev",
+		"prs/kargo-render/env/dev",
+		gitutil.RepoCredentials{Username: "bot", Password: oboToken},
+		&OpenPROptions{
+			Connection: ConnectionOptions{AuthMode: AuthModeOnBehalfOf},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "Bearer "+oboToken, authorization)
+}
+
+// oboToken is an unsigned access token delegated by a user that expires in
+// 2100.
+var oboToken = jwt(`{"scp":"user_impersonation","exp":4102444800}`)
+
+// jwt returns an unsigned JWT with the specified claims.
+func jwt(claims string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`)) +
+		"." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + "."
+}
+
+// basicAuth returns the value of an Authorization header presenting the
+// specified username and password, separated by a colon, using basic
+// authentication.
+func basicAuth(userinfo string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(userinfo))
+}
@@ -0,0 +1,179 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+)
+
+// AutoCompleteOptions encapsulates settings for automatically completing a PR
+// once all of the target branch's policies are satisfied.
+type AutoCompleteOptions struct {
+	// MergeStrategy is the strategy used to merge the PR. When this is empty,
+	// the server's default strategy is used.
+	MergeStrategy git.GitPullRequestMergeStrategy
+	// DeleteSourceBranch specifies whether the source branch should be deleted
+	// after the PR is completed.
+	DeleteSourceBranch bool
+	// MergeCommitMessage optionally overrides the message of the merge commit.
+	MergeCommitMessage string
+	// SetByID is optionally the ID of the identity auto-complete is set by.
+	// Azure DevOps attributes the resulting merge to this identity. The API does
+	// not permit the merge commit's author or committer to be set arbitrarily,
+	// so this is the only means of controlling attribution. When this is empty,
+	// the identity associated with the credentials is used.
+	SetByID string
+	// RequireProtection specifies whether auto-complete should be refused, and
+	// no PR opened, unless at least one required policy applies to the target
+	// branch. This guards against accidentally merging automatically to an
+	// unprotected branch.
+	RequireProtection bool
+	// VerifyPermissions specifies whether auto-complete should be refused, and
+	// no PR opened, unless the identity associated with the credentials has the
+	// permissions required for Azure DevOps to complete PRs to the target branch
+	// on its behalf. This guards against PRs that are never completed. Only
+	// that identity's permissions are verified, even when SetByID is set.
+	VerifyPermissions bool
+	// TransitionWorkItems specifies whether work items linked to the PR should
+	// be transitioned to their next state when the PR is completed.
+	TransitionWorkItems bool
+	// WorkItemIterationPath optionally specifies the iteration path, e.g.
+	// `proj\Sprint 1`, that work items linked to the PR should be moved to as
+	// part of their transition. It must exist in the repository's project and
+	// may only be set when TransitionWorkItems is true. Work items are only
+	// moved once the PR's completion is observed, i.e. by CompleteAndWait or
+	// CompleteAfterApproval, and not when auto-complete is enabled by OpenPR.
+	WorkItemIterationPath string
+	// WorkItemAreaPath optionally specifies the area path, e.g. `proj\Team`,
+	// that work items linked to the PR should be moved to as part of their
+	// transition. It must exist in the repository's project and may only be set
+	// when TransitionWorkItems is true. It is honored as WorkItemIterationPath
+	// is.
+	WorkItemAreaPath string
+	// EmptyPRPolicy specifies how to proceed when a PR no longer changes
+	// anything by the time it is to be completed. This is only honored when
+	// completion is initiated by CompleteAfterApproval. When this is empty,
+	// EmptyPRPolicyComplete is used.
+	EmptyPRPolicy EmptyPRPolicy
+}
+
+// AutoCompleteStatus describes whether auto-complete was engaged for a PR.
+type AutoCompleteStatus string
+
+const (
+	// AutoCompleteNone indicates that auto-complete was not enabled for the PR,
+	// because it was not requested or because no PR was opened.
+	AutoCompleteNone AutoCompleteStatus = ""
+	// AutoCompleteEngaged indicates that Azure DevOps accepted auto-complete for
+	// the PR.
+	AutoCompleteEngaged AutoCompleteStatus = "engaged"
+	// AutoCompleteNotEngaged indicates that auto-complete was requested for the
+	// PR, but that Azure DevOps did not engage it, for instance, because the
+	// identity it was to be set by lacked permission.
+	AutoCompleteNotEngaged AutoCompleteStatus = "notEngaged"
+)
+
+// ErrAutoCompleteNotEngaged is reported when Azure DevOps accepts a request to
+// enable auto-complete for a PR without engaging it.
+var ErrAutoCompleteNotEngaged = errors.New("auto-complete was not engaged")
+
+// AutoCompletePolicy decides whether auto-complete may be enabled for a PR to
+// the specified target branch. The branch name is provided without any
+// refs/heads/ prefix.
+type AutoCompletePolicy func(targetBranch string) bool
+
+// ExceptBranches returns an AutoCompletePolicy that permits auto-complete for
+// PRs to any target branch except those matching any of the specified
+// patterns. Patterns use the syntax of path.Match. This is useful, for
+// instance, for ensuring that PRs to production environments are never
+// completed automatically.
+func ExceptBranches(patterns ...string) AutoCompletePolicy {
+	return func(targetBranch string) bool {
+		for _, pattern := range patterns {
+			if matched, err := path.Match(pattern, targetBranch); err == nil && matched {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// autoCompleteFor returns the settings with which auto-complete should be
+// enabled for a PR to the specified target branch or nil if it should not be.
+// The policy, if any, has the final say, so that it cannot be overridden by a
+// default enabling auto-complete.
+func autoCompleteFor(opts *OpenPROptions, targetBranch string) *AutoCompleteOptions {
+	if opts.AutoComplete == nil {
+		return nil
+	}
+	if opts.AutoCompletePolicy != nil &&
+		!opts.AutoCompletePolicy(strings.TrimPrefix(targetBranch, "refs/heads/")) {
+		return nil
+	}
+	return opts.AutoComplete
+}
+
+// enableAutoComplete enables auto-complete for the specified PR and returns
+// whether Azure DevOps engaged it, as indicated by the updated PR identifying
+// who it was set by. Azure DevOps may silently decline to engage it, for
+// instance, when the identity it is to be set by lacks permission.
+func enableAutoComplete(
+	ctx context.Context,
+	repo *repoClient,
+	prID int,
+	opts AutoCompleteOptions,
+) (AutoCompleteStatus, error) {
+	setByID := opts.SetByID
+	if setByID == "" {
+		var err error
+		if setByID, err = getAuthenticatedIdentityID(ctx, repo.connection, repo.cacheTTL); err != nil {
+			return AutoCompleteNone, fmt.Errorf("error resolving identity to set auto-complete by: %w", err)
+		}
+	}
+	pr, err := repo.client.UpdatePullRequest(ctx, git.UpdatePullRequestArgs{
+		Project:       &repo.project,
+		RepositoryId:  &repo.id,
+		PullRequestId: &prID,
+		GitPullRequestToUpdate: &git.GitPullRequest{
+			AutoCompleteSetBy: &webapi.IdentityRef{Id: &setByID},
+			CompletionOptions: completionOptionsOf(opts),
+		},
+	})
+	if err != nil {
+		return AutoCompleteNone, fmt.Errorf("error enabling auto-complete for pull request %d: %w", prID, err)
+	}
+	if !autoCompleteEngaged(pr) {
+		return AutoCompleteNotEngaged, nil
+	}
+	return AutoCompleteEngaged, nil
+}
+
+// autoCompleteEngaged returns a bool indicating whether auto-complete is
+// engaged for the specified PR.
+func autoCompleteEngaged(pr *git.GitPullRequest) bool {
+	return pr != nil && pr.AutoCompleteSetBy != nil &&
+		pr.AutoCompleteSetBy.Id != nil && *pr.AutoCompleteSetBy.Id != ""
+}
+
+// completionOptionsOf returns the options with which Azure DevOps should
+// complete a PR as specified by the specified auto-complete settings.
+func completionOptionsOf(opts AutoCompleteOptions) *git.GitPullRequestCompletionOptions {
+	completionOpts := &git.GitPullRequestCompletionOptions{
+		DeleteSourceBranch: &opts.DeleteSourceBranch,
+	}
+	if opts.TransitionWorkItems {
+		completionOpts.TransitionWorkItems = &opts.TransitionWorkItems
+	}
+	if opts.MergeStrategy != "" {
+		completionOpts.MergeStrategy = &opts.MergeStrategy
+	}
+	if opts.MergeCommitMessage != "" {
+		completionOpts.MergeCommitMessage = &opts.MergeCommitMessage
+	}
+	return completionOpts
+}
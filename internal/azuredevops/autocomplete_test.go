@@ -0,0 +1,305 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestOpenPRAutoComplete(t *testing.T) {
+	tokenIdentity := uuid.New()
+	testCases := []struct {
+		name       string
+		opts       AutoCompleteOptions
+		assertions func(t *testing.T, update *git.GitPullRequest)
+	}{
+		{
+			name: "configured merge metadata",
+			opts: AutoCompleteOptions{
+				MergeStrategy:      git.GitPullRequestMergeStrategyValues.Squash,
+				DeleteSourceBranch: true,
+				MergeCommitMessage: "Promote to env/dev",
+				SetByID:            "service-identity-id",
+			},
+			assertions: func(t *testing.T, update *git.GitPullRequest) {
+				require.Equal(t, "service-identity-id", *update.AutoCompleteSetBy.Id)
+				require.Equal(
+					t,
+					git.GitPullRequestMergeStrategyValues.Squash,
+					*update.CompletionOptions.MergeStrategy,
+				)
+				require.True(t, *update.CompletionOptions.DeleteSourceBranch)
+				require.Equal(
+					t,
+					"Promote to env/dev",
+					*update.CompletionOptions.MergeCommitMessage,
+				)
+			},
+		},
+		{
+			name: "falls back to token identity",
+			assertions: func(t *testing.T, update *git.GitPullRequest) {
+				require.Equal(t, tokenIdentity.String(), *update.AutoCompleteSetBy.Id)
+				require.Nil(t, update.CompletionOptions.MergeStrategy)
+				require.Nil(t, update.CompletionOptions.MergeCommitMessage)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var update *git.GitPullRequest
+			useFakeIdentity(t, tokenIdentity)
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn:   fakeRepos("repo"),
+				createPullRequestFn: fakeCreatePullRequest(nil),
+				updatePullRequestFn: func(
+					_ context.Context,
+					args git.UpdatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					update = args.GitPullRequestToUpdate
+					return update, nil
+				},
+			})
+			opts := testCase.opts
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				&OpenPROptions{AutoComplete: &opts},
+			)
+			require.NoError(t, err)
+			require.NotNil(t, update)
+			testCase.assertions(t, update)
+		})
+	}
+}
+
+func TestOpenPRAutoCompleteEngagement(t *testing.T) {
+	testCases := []struct {
+		name       string
+		ignored    bool
+		strict     bool
+		assertions func(t *testing.T, url string, event AuditEvent, warnings []error, err error)
+	}{
+		{
+			name: "engaged",
+			assertions: func(t *testing.T, url string, event AuditEvent, warnings []error, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, url)
+				require.Equal(t, AutoCompleteEngaged, event.AutoComplete)
+				require.Empty(t, warnings)
+			},
+		},
+		{
+			name:    "ignored by server",
+			ignored: true,
+			assertions: func(t *testing.T, url string, event AuditEvent, warnings []error, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, url)
+				require.Equal(t, AutoCompleteNotEngaged, event.AutoComplete)
+				require.Len(t, warnings, 1)
+				require.ErrorIs(t, warnings[0], ErrAutoCompleteNotEngaged)
+				require.ErrorContains(t, warnings[0], url)
+			},
+		},
+		{
+			name:    "ignored by server when strict",
+			ignored: true,
+			strict:  true,
+			assertions: func(t *testing.T, url string, event AuditEvent, warnings []error, err error) {
+				require.ErrorIs(t, err, ErrAutoCompleteNotEngaged)
+				require.NotEmpty(t, url)
+				require.Equal(t, AutoCompleteNotEngaged, event.AutoComplete)
+				require.Empty(t, warnings)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn:   fakeRepos("repo"),
+				createPullRequestFn: fakeCreatePullRequest(nil),
+				updatePullRequestFn: func(
+					_ context.Context,
+					args git.UpdatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					pr := *args.GitPullRequestToUpdate
+					if testCase.ignored {
+						// The server accepts the update, but does not engage
+						// auto-complete
+						pr.AutoCompleteSetBy = nil
+					}
+					return &pr, nil
+				},
+			})
+			var warnings []error
+			auditor := &RecordingAuditor{}
+			url, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "token"},
+				&OpenPROptions{
+					AutoComplete: &AutoCompleteOptions{SetByID: uuid.NewString()},
+					Strict:       testCase.strict,
+					OnWarning:    func(err error) { warnings = append(warnings, err) },
+					Auditor:      auditor,
+				},
+			)
+			require.Len(t, auditor.Events(), 1)
+			testCase.assertions(t, url, auditor.Events()[0], warnings, err)
+		})
+	}
+}
+
+func TestBatchOpenPRAutoCompleteIdentityLookup(t *testing.T) {
+	tokenIdentity := uuid.New()
+	lookups := useFakeIdentity(t, tokenIdentity)
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn:   fakeRepos("repo"),
+		createPullRequestFn: fakeCreatePullRequest(nil),
+		updatePullRequestFn: func(
+			_ context.Context,
+			args git.UpdatePullRequestArgs,
+		) (*git.GitPullRequest, error) {
+			require.Equal(
+				t,
+				tokenIdentity.String(),
+				*args.GitPullRequestToUpdate.AutoCompleteSetBy.Id,
+			)
+			return args.GitPullRequestToUpdate, nil
+		},
+	})
+	route := Route{
+		RepoURL:      "https://dev.azure.com/org/proj/_git/repo",
+		TargetBranch: "main",
+	}
+	var reqs []PRRequest
+	for _, env := range []string{"dev", "test", "stage", "prod", "dr"} {
+		reqs = append(reqs, PRRequest{
+			Env:          env,
+			Route:        route,
+			SourceBranch: "prs/" + env,
+			Title:        env,
+			Options:      &OpenPROptions{AutoComplete: &AutoCompleteOptions{}},
+		})
+	}
+	_, err := BatchOpenPR(
+		context.Background(),
+		reqs,
+		nil,
+		gitutil.RepoCredentials{Password: "pat"},
+		nil,
+	)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), lookups.Load())
+}
+
+func TestExceptBranches(t *testing.T) {
+	policy := ExceptBranches("env/prod", "env/prod-*")
+	require.True(t, policy("env/dev"))
+	require.True(t, policy("env/production"))
+	require.False(t, policy("env/prod"))
+	require.False(t, policy("env/prod-eu"))
+}
+
+func TestBatchOpenPRPerEnvironmentAutoComplete(t *testing.T) {
+	useFakeIdentity(t, uuid.New())
+	var mu sync.Mutex
+	prTargets := map[int]string{} // PR ID -> target branch
+	autoCompleted := map[string]bool{}
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: fakeRepos("repo"),
+		createPullRequestFn: func(
+			_ context.Context,
+			args git.CreatePullRequestArgs,
+		) (*git.GitPullRequest, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			id := len(prTargets) + 1
+			prTargets[id] = *args.GitPullRequestToCreate.TargetRefName
+			url := fmt.Sprintf("https://dev.azure.com/org/proj/_git/repo/pullrequest/%d", id)
+			return &git.GitPullRequest{PullRequestId: &id, Url: &url}, nil
+		},
+		updatePullRequestFn: func(
+			_ context.Context,
+			args git.UpdatePullRequestArgs,
+		) (*git.GitPullRequest, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			autoCompleted[prTargets[*args.PullRequestId]] = true
+			return args.GitPullRequestToUpdate, nil
+		},
+	})
+	// Auto-complete is enabled by default, but never for prod
+	defaults := &OpenPROptions{
+		AutoComplete:       &AutoCompleteOptions{},
+		AutoCompletePolicy: ExceptBranches("env/prod"),
+	}
+	var reqs []PRRequest
+	for env, override := range map[string]*bool{
+		"dev":     nil,
+		"staging": ptr(false),
+		"test":    nil,
+		"prod":    ptr(true),
+	} {
+		reqs = append(reqs, PRRequest{
+			Env: env,
+			Route: Route{
+				RepoURL:      "https://dev.azure.com/org/proj/_git/repo",
+				TargetBranch: "env/" + env,
+			},
+			SourceBranch: "prs/" + env,
+			Title:        env,
+			Options:      defaults,
+			AutoComplete: override,
+		})
+	}
+	results, err := BatchOpenPR(
+		context.Background(),
+		reqs,
+		nil,
+		gitutil.RepoCredentials{Password: "pat"},
+		nil,
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		map[string]bool{
+			"refs/heads/env/dev":  true,
+			"refs/heads/env/test": true,
+		},
+		autoCompleted,
+	)
+	statuses := map[string]AutoCompleteStatus{}
+	for _, res := range results {
+		statuses[res.Env] = res.AutoComplete
+	}
+	require.Equal(
+		t,
+		map[string]AutoCompleteStatus{
+			"dev":     AutoCompleteEngaged,
+			"staging": AutoCompleteNone,
+			"test":    AutoCompleteEngaged,
+			"prod":    AutoCompleteNone,
+		},
+		statuses,
+	)
+	// The shared defaults must not have been modified by per-request overrides
+	require.NotNil(t, defaults.AutoComplete)
+}
@@ -2,57 +2,303 @@ package azuredevops
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
 
-	"github.com/google/uuid"
-	"github.com/microsoft/azure-devops-go-api/azuredevops"
-	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/akuity/kargo-render/internal/repourl"
 	gitutil "github.com/akuity/kargo-render/pkg/git"
 )
 
-// parseAzureDevOpsURL parses an Azure DevOps repository URL and returns organization, project, and repository names
-func parseAzureDevOpsURL(repoURL string) (org, proj, repo string, err error) {
-	if strings.Contains(repoURL, "dev.azure.com") {
-		urlParts := strings.Split(strings.TrimPrefix(repoURL, "https://dev.azure.com/"), "/")
-		if len(urlParts) < 4 {
-			return "", "", "", fmt.Errorf("invalid Azure DevOps repository URL format")
-		}
-		org = urlParts[0]
-		proj = urlParts[1]
-		repo = strings.TrimSuffix(urlParts[3], ".git")
-	} else if strings.Contains(repoURL, ".visualstudio.com") {
-		urlParts := strings.Split(repoURL, "/")
-		if len(urlParts) < 5 {
-			return "", "", "", fmt.Errorf("invalid Azure DevOps repository URL format")
-		}
-		org = strings.Split(urlParts[2], ".")[0]
-		proj = urlParts[3]
-		repo = strings.TrimSuffix(urlParts[5], ".git")
-	} else {
-		return "", "", "", fmt.Errorf("unsupported Azure DevOps repository URL format")
-	}
-	return org, proj, repo, nil
+// OpenPROptions encapsulates optional settings for OpenPR.
+type OpenPROptions struct {
+	// IdempotencyKey, when non-empty, is embedded in the description of any PR
+	// that is opened. Before a new PR is opened, active PRs for the same source
+	// and target branches are checked for the same key and, if one is found, no
+	// new PR is opened. Use IdempotencyKey() to compute a suitable value.
+	IdempotencyKey string
+	// DuplicatePRPolicy specifies how to proceed when more than one active PR
+	// carries the IdempotencyKey. When this is empty, DuplicatePRPolicyError is
+	// used.
+	DuplicatePRPolicy DuplicatePRPolicy
+	// Marker optionally specifies a string, typically a hidden comment such as
+	// `<!-- kargo-render: team-a -->`, that is embedded in the description of
+	// any PR that is opened. When this is non-empty, only PRs whose
+	// descriptions contain it are considered to carry the IdempotencyKey. This
+	// permits multiple instances of Kargo Render that open PRs in the same
+	// repository to distinguish their own PRs from one another's.
+	Marker string
+	// OverflowToComment specifies whether, when the description exceeds the
+	// maximum length Azure DevOps permits and must be truncated, the full
+	// description should be posted as the first comment on the PR.
+	OverflowToComment bool
+	// Description, when non-nil, assembles the description of any PR that is
+	// opened from structured sections, in place of the description argument,
+	// which must then be empty. Whole sections are omitted, as necessary, to fit
+	// the description within the maximum length Azure DevOps permits alongside
+	// everything else OpenPR includes in it. When OverflowToComment is true, any
+	// omitted sections are posted as the first comment on the PR.
+	Description *DescriptionBuilder
+	// Footer, when non-empty, is appended to the description of any PR that is
+	// opened. The footer is always preserved intact, even when the description
+	// must be truncated to fit within the maximum length Azure DevOps permits.
+	Footer string
+	// AutoComplete, when non-nil, specifies that auto-complete should be enabled
+	// for any PR that is opened, using the specified settings.
+	AutoComplete *AutoCompleteOptions
+	// AutoCompletePolicy, when non-nil, is consulted before enabling
+	// auto-complete for any PR that is opened. If it does not permit
+	// auto-complete for the PR's target branch, auto-complete is not enabled,
+	// regardless of AutoComplete.
+	AutoCompletePolicy AutoCompletePolicy
+	// ParentPRID, when non-zero, records the specified PR as the parent of any
+	// PR that is opened. This permits tooling to reconstruct stacks of
+	// dependent PRs. Use GetParentPRID() to read the relationship back.
+	ParentPRID int
+	// SkipIfNoChanges specifies whether to refrain from opening a PR when the
+	// source branch contains no commits that are not already present in the
+	// target branch. When this is true and that is the case, an error wrapping
+	// ErrNoChanges is returned.
+	SkipIfNoChanges bool
+	// RequiredPath optionally specifies a path, relative to the root of the
+	// repository, that must contain at least one file on the source branch. When
+	// this is non-empty and the path is missing or empty, no PR is opened and an
+	// error wrapping ErrPathEmpty is returned. This catches broken renders before
+	// they become PRs.
+	RequiredPath string
+	// ArtifactURL optionally specifies the URL of a pre-computed artifact, such
+	// as one produced by CI, containing a diff of the rendered manifests. When
+	// this is non-empty, a link to it is included, under a standard heading, in
+	// the description of any PR that is opened. The link is always preserved,
+	// even when the description must be truncated.
+	ArtifactURL string
+	// RelatedPRURLs optionally specifies the URLs of PRs related to any PR that
+	// is opened, such as those promoting the same change to other environments.
+	// When this is non-empty, links to them are included, under a standard
+	// heading, in the description of the PR. The links are always preserved,
+	// even when the description must be truncated.
+	RelatedPRURLs []string
+	// EscapeControlCharacters specifies whether control characters, which Azure
+	// DevOps may reject, should be escaped rather than removed from the title,
+	// description, and footer of any PR that is opened. Newlines and tabs in the
+	// description and footer are always preserved.
+	EscapeControlCharacters bool
+	// NormalizeBranchNames specifies whether the names of the source and target
+	// branches should be converted to Unicode Normalization Form C before they
+	// are used. Azure DevOps compares ref names byte for byte, so this allows
+	// names containing accented characters that were decomposed, e.g. by
+	// macOS, to refer to the same branches as the composed names most systems
+	// produce.
+	NormalizeBranchNames bool
+	// Version optionally overrides the version of Kargo Render that is recorded,
+	// for reproducibility audits, in the description of any PR that is opened.
+	// When this is empty, the version of this build is recorded.
+	Version string
+	// OmitVersion specifies that no version of Kargo Render should be recorded
+	// in the description of any PR that is opened.
+	OmitVersion bool
+	// ConfigHash, when non-empty, is a hash of the configuration that produced
+	// the PR and is recorded, for reproducibility audits, in the description of
+	// any PR that is opened.
+	ConfigHash string
+	// Metadata optionally specifies build or run metadata, such as the ID of a
+	// CI run, the name of a pipeline, or a commit, that is written to the
+	// properties of any PR that is opened. The name of each property is the key
+	// prefixed with MetadataPropertyPrefix. Keys must not be empty or exceed 128
+	// characters, and values must not exceed 1024 characters.
+	Metadata map[string]string
+	// TargetResolver, when non-nil, is used to resolve the target branch from the
+	// source branch of any PR that is opened without an explicit target branch.
+	TargetResolver *TargetResolver
+	// IncludeChangelog specifies whether a section listing the commits in the
+	// source branch that are not already present in the target branch should be
+	// appended to the description of any PR that is opened.
+	IncludeChangelog bool
+	// MaxChangelogCommits is the maximum number of commits listed by the
+	// changelog section of any PR that is opened when IncludeChangelog is true.
+	// Any commits beyond this are summarized by a count. When this is 0, at
+	// most 20 commits are listed.
+	MaxChangelogCommits int
+	// Labels specifies labels to apply to any PR that is opened.
+	Labels []string
+	// LabelRules specifies rules for automatically applying additional labels
+	// to any PR that is opened, based on the files it changes. Labels already
+	// specified by Labels are not applied a second time.
+	LabelRules []LabelRule
+	// Reviewers specifies reviewers to request for any PR that is opened.
+	Reviewers []Reviewer
+	// ReviewerNames specifies additional, optional reviewers to request for any
+	// PR that is opened, by identity ID or by anything Azure DevOps can search
+	// identities by, such as an account name, email address, or display name.
+	// These are resolved as by ResolveReviewers.
+	ReviewerNames []string
+	// OnUnresolvedReviewers, when non-nil, is called with any of the
+	// ReviewerNames that could not be resolved so that callers can warn about
+	// them. Unresolved reviewers are otherwise ignored.
+	OnUnresolvedReviewers func(unresolved []string)
+	// AllowReviewerVotes must be true for any of the Reviewers to cast a vote
+	// when the PR is opened. This guards against accidental self-approval.
+	AllowReviewerVotes bool
+	// SourceRepoURL optionally specifies the URL of the repository containing
+	// the source branch, when it is known separately from that of the target
+	// branch. Azure DevOps PRs are scoped to a single repository, so when this is
+	// non-empty, it is verified to refer to the same repository as the target
+	// (or as the fork specified by ForkRepoURL) and an error wrapping
+	// ErrRepositoryMismatch is returned if it does not.
+	SourceRepoURL string
+	// ForkRepoURL optionally specifies the URL of a fork of the target
+	// repository that contains the source branch. The fork must exist and must
+	// belong to the same organization as the target repository. This cannot be
+	// combined with SkipIfNoChanges.
+	ForkRepoURL string
+	// RedactionPatterns optionally specifies patterns, in addition to the
+	// defaults, matching sensitive information that must be masked in any
+	// error that is returned. The credentials themselves are always masked.
+	RedactionPatterns []*regexp.Regexp
+	// DeleteSourceBranchOnFailure specifies whether the source branch should be
+	// deleted if no PR is opened because an error occurred, so that orphaned
+	// branches do not accumulate. This is only honored when
+	// SourceBranchCreated is also true, so that branches that predate the
+	// attempt are never deleted.
+	DeleteSourceBranchOnFailure bool
+	// SourceBranchCreated indicates that the source branch was created by the
+	// caller specifically for the PR being opened.
+	SourceBranchCreated bool
+	// SourceCommit optionally specifies the full ID of a commit from which to
+	// open a PR when there is no named source branch. When this is non-empty,
+	// no source branch may be specified. Instead, a temporary branch, named by
+	// TempBranchName, is created at the commit, if it does not already exist,
+	// and the PR is opened from it.
+	SourceCommit string
+	// DeleteTempBranch specifies whether a temporary branch created for
+	// SourceCommit should be deleted if no PR is opened because an error
+	// occurred, and, if auto-complete is enabled, once the PR is completed.
+	DeleteTempBranch bool
+	// CreateTargetBranch specifies whether the target branch should be created,
+	// from the head of the repository's default branch, if it does not exist.
+	// Otherwise, Azure DevOps refuses to open PRs to missing branches. Azure
+	// DevOps' API cannot create orphaned branches without content, so target
+	// branches that must be orphaned, like those kargo-render renders into,
+	// should instead be created by pushing an initial commit.
+	CreateTargetBranch bool
+	// GuardDefaultBranch specifies whether to refuse to open PRs that target
+	// the repository's default branch, so that changes are not accidentally
+	// merged into it, unless AllowDefaultBranchTarget is also true. When a PR is
+	// refused, an error wrapping ErrDefaultBranchTarget is returned.
+	GuardDefaultBranch bool
+	// AllowDefaultBranchTarget explicitly permits PRs that target the
+	// repository's default branch when GuardDefaultBranch is true.
+	AllowDefaultBranchTarget bool
+	// RepositoryID optionally specifies the ID, a UUID, of the repository the
+	// PR is to be opened in. When this is non-empty, the repository is not
+	// looked up by name, which spares listing every repository in its project,
+	// and the ID is not verified to be that of the repository the URL names.
+	// Repository URLs may also reference repositories by ID, in place of their
+	// names, to the same effect. Either way, a repository that is disabled is
+	// reported as such only when the PR is created.
+	RepositoryID string
+	// Connection encapsulates optional settings for connecting to Azure DevOps.
+	Connection ConnectionOptions
+	// SuppressNotifications specifies that reviewers should not be notified of
+	// any PR that is opened. Azure DevOps' API has no means of creating or
+	// updating a PR without notifying its reviewers, except that it does not
+	// notify them of draft PRs, so when this is true, PRs are opened as drafts.
+	// Drafts cannot be completed until they are published, so this cannot be
+	// combined with auto-complete. When this is nil, PRDefaults.Draft, if any,
+	// applies, so a pointer to false opens a PR that is not a draft regardless
+	// of the defaults.
+	SuppressNotifications *bool
+	// ConflictPolicy specifies how to proceed when Azure DevOps refuses to
+	// create a PR due to a conflict. When this is empty,
+	// ConflictPolicyReuseExisting is used.
+	ConflictPolicy ConflictPolicy
+	// TracerProvider optionally specifies the provider of the tracer used to
+	// record a span for each attempt to open a PR. When this is nil, the
+	// globally registered provider is used.
+	TracerProvider trace.TracerProvider
+	// WaitUntilVisible, when true, causes a newly created PR to be polled until
+	// Azure DevOps returns it when it is retrieved by ID, which can lag slightly
+	// behind its creation. This ensures the PR can be queried as soon as OpenPR
+	// returns.
+	WaitUntilVisible bool
+	// VisibilityTimeout is the maximum amount of time to wait for a newly
+	// created PR to become visible when WaitUntilVisible is true. When this is
+	// zero, a default of 10 seconds is used.
+	VisibilityTimeout time.Duration
+	// ResolveOwnThreads specifies whether, when an existing PR is found instead
+	// of a new one being opened, its active comment threads that were started by
+	// the identity associated with the credentials should be resolved, since
+	// they most likely concern changes that have since been superseded. Threads
+	// started by anyone else are never touched.
+	ResolveOwnThreads bool
+	// ResolvedThreadStatus is the status threads are resolved to when
+	// ResolveOwnThreads is true. When this is empty,
+	// git.CommentThreadStatusValues.Fixed is used.
+	ResolvedThreadStatus git.CommentThreadStatus
+	// Strict specifies whether a failure of any step that merely enriches a PR
+	// once it has been opened, or once an existing PR has been found, should
+	// cause OpenPR to fail. Those steps are applying label rules, writing
+	// Metadata, setting a commit status, posting an overflowing description as a
+	// comment, and, for existing PRs, adding Reviewers and resolving threads.
+	// When this is false, such failures are instead reported to OnWarning, if
+	// it is non-nil, and otherwise ignored. Failures to enable auto-complete are
+	// always fatal, but Azure DevOps declining to engage auto-complete, which is
+	// reported as ErrAutoCompleteNotEngaged, is treated like a failure to enrich
+	// the PR.
+	Strict bool
+	// OnWarning, when non-nil, is called with each failure of a step that
+	// enriches a PR when Strict is false.
+	OnWarning func(error)
+	// SetCommitStatus specifies whether a pending status, with context
+	// CommitStatusContext and linking to any PR that is opened, should be set
+	// on the PR's source commit, so that dashboards reading commit statuses
+	// reflect it.
+	SetCommitStatus bool
+	// Auditor, when non-nil, is called with an AuditEvent describing each
+	// attempt to open a PR, whatever its outcome. When this is nil, NopAuditor
+	// is used.
+	Auditor Auditor
+	// Defaults, when non-nil, specifies defaults, typically loaded from
+	// configuration, for settings that these options leave unspecified. Values
+	// specified by these options always take precedence.
+	Defaults *PRDefaults
 }
 
-// getRepositoryID gets the repository ID from Azure DevOps
-func getRepositoryID(ctx context.Context, client git.Client, project, repository string) (*uuid.UUID, error) {
-	repos, err := client.GetRepositories(ctx, git.GetRepositoriesArgs{
-		Project: &project,
-	})
+// ErrRepositoryMismatch is returned when the source and target branches of a
+// prospective PR belong to different repositories.
+var ErrRepositoryMismatch = errors.New("source and target repositories differ")
+
+// ensureSameRepository returns an error wrapping ErrRepositoryMismatch if the
+// specified source and target repository URLs do not refer to the same Azure
+// DevOps repository. Azure DevOps organization, project, and repository names
+// are case insensitive, so they are compared accordingly.
+func ensureSameRepository(sourceRepoURL, targetRepoURL string) error {
+	srcOrg, srcProj, srcRepo, err := parseAzureDevOpsURL(repourl.Normalize(sourceRepoURL))
 	if err != nil {
-		return nil, fmt.Errorf("error listing repositories: %w", err)
+		return fmt.Errorf("error parsing source repository URL: %w", err)
 	}
-
-	if repos != nil {
-		for _, repo := range *repos {
-			if *repo.Name == repository {
-				return repo.Id, nil
-			}
-		}
+	tgtOrg, tgtProj, tgtRepo, err := parseAzureDevOpsURL(repourl.Normalize(targetRepoURL))
+	if err != nil {
+		return fmt.Errorf("error parsing target repository URL: %w", err)
 	}
-
-	return nil, fmt.Errorf("repository '%s' not found in project '%s'", repository, project)
+	if !strings.EqualFold(srcOrg, tgtOrg) ||
+		!strings.EqualFold(srcProj, tgtProj) ||
+		!strings.EqualFold(srcRepo, tgtRepo) {
+		return fmt.Errorf(
+			"%w: source is %s/%s/%s, but target is %s/%s/%s",
+			ErrRepositoryMismatch,
+			srcOrg, srcProj, srcRepo,
+			tgtOrg, tgtProj, tgtRepo,
+		)
+	}
+	return nil
 }
 
 // OpenPR creates a pull request in Azure DevOps
@@ -64,63 +310,577 @@ func OpenPR(
 	targetBranch string,
 	sourceBranch string,
 	creds gitutil.RepoCredentials,
+	opts *OpenPROptions,
 ) (string, error) {
-	// Ensure we have a PAT token as password
-	if creds.Password == "" {
-		return "", fmt.Errorf("Azure DevOps requires a Personal Access Token (PAT) as password")
+	url, _, err := openPRAudited(
+		ctx,
+		repoURL,
+		title,
+		description,
+		targetBranch,
+		sourceBranch,
+		creds,
+		opts,
+	)
+	return url, err
+}
+
+// openPRAudited opens a PR as OpenPR does and returns, in addition to the PR's
+// URL, the AuditEvent recording the attempt, which is also passed to the
+// options' Auditor, if any.
+func openPRAudited(
+	ctx context.Context,
+	repoURL string,
+	title string,
+	description string,
+	targetBranch string,
+	sourceBranch string,
+	creds gitutil.RepoCredentials,
+	opts *OpenPROptions,
+) (string, AuditEvent, error) {
+	if opts == nil {
+		opts = &OpenPROptions{}
+	}
+	opts = opts.Defaults.apply(opts)
+
+	if onWarning := opts.OnWarning; onWarning != nil {
+		// Warnings are redacted just as errors are
+		o := *opts
+		o.OnWarning = func(err error) {
+			onWarning(redactError(err, []string{creds.Password}, opts.RedactionPatterns))
+		}
+		opts = &o
+	}
+
+	auditor := opts.Auditor
+	if auditor == nil {
+		auditor = NopAuditor{}
+	}
+	event := AuditEvent{
+		Time:         now(),
+		Repo:         withoutCredentials(repoURL),
+		SourceBranch: sourceBranch,
+		TargetBranch: targetBranch,
+		Title:        title,
 	}
 
-	// Parse Azure DevOps URL
-	organization, project, repository, err := parseAzureDevOpsURL(repoURL)
+	ctx, span := startSpan(ctx, opts.TracerProvider, "OpenPR", repoURL)
+	url, err := openPR(
+		ctx,
+		repoURL,
+		title,
+		description,
+		targetBranch,
+		sourceBranch,
+		creds,
+		opts,
+		&event,
+	)
+	err = redactError(err, []string{creds.Password}, opts.RedactionPatterns)
+	endSpan(span, url, err)
+
+	event.Outcome = outcomeOf(url, err)
+	event.URL = url
 	if err != nil {
+		event.Error = err.Error()
+	}
+	auditor.Audit(ctx, event)
+	return url, event, err
+}
+
+func openPR(
+	ctx context.Context,
+	repoURL string,
+	title string,
+	description string,
+	targetBranch string,
+	sourceBranch string,
+	creds gitutil.RepoCredentials,
+	opts *OpenPROptions,
+	event *AuditEvent,
+) (url string, err error) {
+	if opts.NormalizeBranchNames {
+		targetBranch = norm.NFC.String(targetBranch)
+		sourceBranch = norm.NFC.String(sourceBranch)
+	}
+	if opts.SourceCommit != "" {
+		if sourceBranch, err = tempBranchFor(sourceBranch, opts.SourceCommit); err != nil {
+			return "", err
+		}
+		if opts.DeleteTempBranch && opts.AutoComplete != nil {
+			// Have Azure DevOps delete the temporary branch upon completion
+			autoComplete := *opts.AutoComplete
+			autoComplete.DeleteSourceBranch = true
+			o := *opts
+			o.AutoComplete = &autoComplete
+			opts = &o
+		}
+	}
+	if err = ensureValidBranchNames(targetBranch, sourceBranch); err != nil {
 		return "", err
 	}
+	if targetBranch == "" {
+		if opts.TargetResolver == nil {
+			return "", fmt.Errorf("%w: no target branch was specified", ErrNoTarget)
+		}
+		if targetBranch, err = opts.TargetResolver.Resolve(sourceBranch); err != nil {
+			return "", err
+		}
+	}
 
-	// Create a connection to Azure DevOps
-	connection := azuredevops.NewPatConnection(
-		fmt.Sprintf("https://dev.azure.com/%s", organization),
-		creds.Password,
-	)
+	if err = ensureReviewerVotesAllowed(opts.Reviewers, opts.AllowReviewerVotes); err != nil {
+		return "", err
+	}
+	if err = validateMetadata(opts.Metadata); err != nil {
+		return "", err
+	}
+	if opts.Description != nil && description != "" {
+		return "", errors.New(
+			"a description and a description builder may not both be specified",
+		)
+	}
+	if suppressNotifications(opts) && autoCompleteFor(opts, targetBranch) != nil {
+		return "", errors.New(
+			"suppressing notifications is not supported for PRs with auto-complete " +
+				"enabled, because such PRs are opened as drafts",
+		)
+	}
 
-	// Create Git client
-	gitClient, err := git.NewClient(ctx, connection)
+	// Expand any short repository references, so that they can be compared
+	if repoURL, err = ExpandRepoRef(repoURL, opts.Connection.RepoDefaults); err != nil {
+		return "", err
+	}
+	forkRepoURL, err := ExpandRepoRef(opts.ForkRepoURL, opts.Connection.RepoDefaults)
 	if err != nil {
-		return "", fmt.Errorf("error creating Azure DevOps Git client: %w", err)
+		return "", err
+	}
+	sourceRepoURL := repoURL
+	if forkRepoURL != "" {
+		if opts.SkipIfNoChanges {
+			return "", errors.New(
+				"skipping PRs without changes is not supported for PRs from forks",
+			)
+		}
+		sourceRepoURL = forkRepoURL
+	}
+	if opts.SourceRepoURL != "" {
+		var declaredSourceRepoURL string
+		if declaredSourceRepoURL, err =
+			ExpandRepoRef(opts.SourceRepoURL, opts.Connection.RepoDefaults); err != nil {
+			return "", err
+		}
+		if err := ensureSameRepository(declaredSourceRepoURL, sourceRepoURL); err != nil {
+			return "", err
+		}
 	}
 
-	// Get repository ID
-	repoUUID, err := getRepositoryID(ctx, gitClient, project, repository)
+	conn := opts.Connection
+	conn.repositoryID = opts.RepositoryID
+	repo, err := newRepoClient(ctx, repoURL, creds, &conn)
 	if err != nil {
 		return "", err
 	}
+	// The source branch is cleaned up on any failure from here on. Until a
+	// fork's client has been created, the repository holding the source branch
+	// is unknown, so there is nothing that can be cleaned up.
+	deleteOnFailure := opts.DeleteSourceBranchOnFailure && opts.SourceBranchCreated
+	var cleanupRepo *repoClient
+	if forkRepoURL == "" {
+		cleanupRepo = repo
+	}
+	defer func() {
+		if !deleteOnFailure || cleanupRepo == nil || err == nil || url != "" {
+			return
+		}
+		if deleteErr := deleteBranch(
+			ctx,
+			cleanupRepo,
+			ensureRefFormat(sourceBranch),
+		); deleteErr != nil {
+			err = errors.Join(
+				err,
+				fmt.Errorf("error cleaning up source branch: %w", deleteErr),
+			)
+		}
+	}()
+	if opts.Auditor != nil {
+		// The identity is recorded on a best-effort basis
+		event.Identity, _ = getAuthenticatedIdentityID(ctx, repo.connection, repo.cacheTTL)
+	}
+	if opts.GuardDefaultBranch && !opts.AllowDefaultBranchTarget {
+		if err = ensureNotDefaultBranch(ctx, repo, ensureRefFormat(targetBranch)); err != nil {
+			return "", err
+		}
+	}
+	if autoComplete := autoCompleteFor(opts, targetBranch); autoComplete != nil {
+		if autoComplete.RequireProtection {
+			if err = ensureProtected(ctx, repo, ensureRefFormat(targetBranch)); err != nil {
+				return "", err
+			}
+		}
+		if autoComplete.VerifyPermissions {
+			if err = verifyAutoCompletePermissions(
+				ctx,
+				repo,
+				ensureRefFormat(targetBranch),
+			); err != nil {
+				return "", err
+			}
+		}
+		if err = validateWorkItemPaths(ctx, repo, *autoComplete); err != nil {
+			return "", err
+		}
+	}
+	sourceRepo := repo
+	if forkRepoURL != "" {
+		if sourceRepo, err = forkRepoClient(ctx, repo, forkRepoURL); err != nil {
+			return "", err
+		}
+		cleanupRepo = sourceRepo
+	}
 
 	// Ensure branch names are in the correct format
 	sourceBranch = ensureRefFormat(sourceBranch)
 	targetBranch = ensureRefFormat(targetBranch)
 
+	if opts.SourceCommit != "" {
+		var created bool
+		if created, err =
+			ensureBranchAt(ctx, sourceRepo, sourceBranch, opts.SourceCommit); err != nil {
+			return "", fmt.Errorf("error creating temporary source branch: %w", err)
+		}
+		deleteOnFailure = deleteOnFailure || (created && opts.DeleteTempBranch)
+	}
+
+	if opts.CreateTargetBranch {
+		if _, err = ensureBranch(ctx, repo, targetBranch); err != nil {
+			return "", fmt.Errorf("error ensuring target branch exists: %w", err)
+		}
+	}
+
+	if opts.SkipIfNoChanges {
+		var hasChanges bool
+		if hasChanges, err =
+			hasNewCommits(ctx, repo, targetBranch, sourceBranch); err != nil {
+			return "", err
+		}
+		if !hasChanges {
+			return "", fmt.Errorf(
+				"%w: %q is already contained in %q",
+				ErrNoChanges,
+				sourceBranch,
+				targetBranch,
+			)
+		}
+	}
+
+	if opts.RequiredPath != "" {
+		if err = ensurePathNotEmpty(
+			ctx,
+			sourceRepo,
+			sourceBranch,
+			opts.RequiredPath,
+		); err != nil {
+			return "", err
+		}
+	}
+
+	if opts.IdempotencyKey != "" {
+		// Serialize PR creation for the same key within this process and check
+		// whether a prior attempt already opened a PR for it
+		unlock := lockIdempotencyKey(opts.IdempotencyKey)
+		defer unlock()
+		var existing *git.GitPullRequest
+		if existing, err = findPRByIdempotencyKey(
+			ctx,
+			repo,
+			sourceBranch,
+			targetBranch,
+			opts.IdempotencyKey,
+			opts.Marker,
+			opts.DuplicatePRPolicy,
+		); err != nil {
+			return "", err
+		}
+		if existing != nil {
+			if err = degrade(opts, updateExistingPR(ctx, repo, existing, opts)); err != nil {
+				return "", err
+			}
+			// Consistent with other providers, an empty URL indicates that an
+			// existing PR was found
+			return "", nil
+		}
+	}
+
+	var overflow string
+	if opts.Description != nil {
+		description, overflow = opts.Description.Build(descriptionBudget(opts))
+	}
+
+	if opts.IncludeChangelog {
+		var changelog string
+		if changelog, err = changelogSection(
+			ctx,
+			repo,
+			targetBranch,
+			sourceBranch,
+			opts.MaxChangelogCommits,
+		); err != nil {
+			return "", fmt.Errorf("error computing changelog: %w", err)
+		}
+		switch {
+		case changelog == "":
+		case strings.TrimSpace(description) == "":
+			description = changelog
+		default:
+			description = strings.TrimRight(description, "\n") + "\n\n" + changelog
+		}
+	}
+
+	preview := PreviewPR(title, description, nil, opts)
+	labels := preview.Labels
+
+	requested, err := withNamedReviewers(ctx, repo, opts)
+	if err != nil {
+		return "", err
+	}
+	reviewers, err := toReviewers(ctx, repo, requested)
+	if err != nil {
+		return "", err
+	}
+
 	// Create pull request
-	repoIDStr := repoUUID.String()
 	createPRArgs := git.CreatePullRequestArgs{
-		Project: &project,
-		RepositoryId: &repoIDStr,
+		Project:      &repo.project,
+		RepositoryId: &repo.id,
 		GitPullRequestToCreate: &git.GitPullRequest{
-			Title:         &title,
-			Description:   &description,
+			Title:         &preview.Title,
+			Description:   &preview.Description,
 			SourceRefName: &sourceBranch,
 			TargetRefName: &targetBranch,
+			Labels:        toTagDefinitions(labels),
+			Reviewers:     reviewers,
 		},
 	}
+	if suppressNotifications(opts) {
+		createPRArgs.GitPullRequestToCreate.IsDraft = opts.SuppressNotifications
+	}
+	if sourceRepo != repo {
+		createPRArgs.GitPullRequestToCreate.ForkSource = forkSourceOf(sourceRepo, sourceBranch)
+	}
 
-	pr, err := gitClient.CreatePullRequest(ctx, createPRArgs)
+	pr, err := repo.client.CreatePullRequest(ctx, createPRArgs)
+	if isConflict(err) {
+		var existing *git.GitPullRequest
+		if existing, err = resolveConflict(
+			ctx,
+			repo,
+			sourceBranch,
+			targetBranch,
+			opts.ConflictPolicy,
+			err,
+		); err != nil {
+			return "", err
+		}
+		if err = degrade(opts, updateExistingPR(ctx, repo, existing, opts)); err != nil {
+			return "", err
+		}
+		// Consistent with other providers, an empty URL indicates that an
+		// existing PR was found
+		return "", nil
+	}
 	if err != nil {
 		return "", fmt.Errorf("error creating pull request: %w", err)
 	}
+	if pr == nil || pr.PullRequestId == nil || pr.Url == nil {
+		return "", fmt.Errorf(
+			"%w: pull request may have been created, but its ID or URL was not "+
+				"returned",
+			ErrIncompleteResponse,
+		)
+	}
+
+	if opts.WaitUntilVisible {
+		if err = waitUntilVisible(
+			ctx,
+			repo,
+			*pr.PullRequestId,
+			opts.VisibilityTimeout,
+		); err != nil {
+			return *pr.Url, fmt.Errorf(
+				"pull request %s was created, but an error occurred waiting for it "+
+					"to become visible: %w",
+				*pr.Url,
+				err,
+			)
+		}
+	}
+
+	if len(opts.LabelRules) > 0 {
+		if err = applyLabelRules(
+			ctx,
+			repo,
+			*pr.PullRequestId,
+			opts.LabelRules,
+			labels,
+		); err != nil {
+			if err = degrade(opts, fmt.Errorf(
+				"pull request %s was created, but an error occurred applying "+
+					"labels: %w",
+				*pr.Url,
+				err,
+			)); err != nil {
+				return *pr.Url, err
+			}
+		}
+	}
+
+	if err = setMetadataProperties(
+		ctx,
+		repo,
+		*pr.PullRequestId,
+		opts.Metadata,
+	); err != nil {
+		if err = degrade(opts, fmt.Errorf(
+			"pull request %s was created, but an error occurred writing its "+
+				"metadata: %w",
+			*pr.Url,
+			err,
+		)); err != nil {
+			return *pr.Url, err
+		}
+	}
+
+	if opts.SetCommitStatus {
+		if err = setPRCommitStatus(ctx, sourceRepo, sourceBranch, repoURL, pr); err != nil {
+			if err = degrade(opts, fmt.Errorf(
+				"pull request %s was created, but an error occurred setting the "+
+					"status of its source commit: %w",
+				*pr.Url,
+				err,
+			)); err != nil {
+				return *pr.Url, err
+			}
+		}
+	}
+
+	if autoComplete := autoCompleteFor(opts, targetBranch); autoComplete != nil {
+		if event.AutoComplete, err = enableAutoComplete(
+			ctx,
+			repo,
+			*pr.PullRequestId,
+			*autoComplete,
+		); err != nil {
+			return *pr.Url, fmt.Errorf(
+				"pull request %s was created, but an error occurred enabling "+
+					"auto-complete: %w",
+				*pr.Url,
+				err,
+			)
+		}
+		if event.AutoComplete == AutoCompleteNotEngaged {
+			if err = degrade(opts, fmt.Errorf(
+				"%w for pull request %s; the identity it was to be set by may lack "+
+					"permission to complete it",
+				ErrAutoCompleteNotEngaged,
+				*pr.Url,
+			)); err != nil {
+				return *pr.Url, err
+			}
+		}
+	}
+
+	if preview.Truncated {
+		// The comment carries everything that was omitted from the description
+		overflow = joinParagraphs(description, overflow)
+	}
+	if overflow != "" && opts.OverflowToComment {
+		overflow = sanitizeDescription(overflow, opts.EscapeControlCharacters)
+		if _, err = repo.client.CreateThread(ctx, git.CreateThreadArgs{
+			Project:       &repo.project,
+			RepositoryId:  &repo.id,
+			PullRequestId: pr.PullRequestId,
+			CommentThread: &git.GitPullRequestCommentThread{
+				Comments: &[]git.Comment{{Content: &overflow}},
+			},
+		}); err != nil {
+			if err = degrade(opts, fmt.Errorf(
+				"pull request %s was created, but an error occurred posting its full "+
+					"description as a comment: %w",
+				*pr.Url,
+				err,
+			)); err != nil {
+				return *pr.Url, err
+			}
+		}
+	}
 
 	return *pr.Url, nil
 }
 
+// degrade returns the specified error, from a step that enriches a PR, if the
+// specified options are strict. Otherwise, the error is reported to the
+// options' OnWarning, if any, and nil is returned.
+func degrade(opts *OpenPROptions, err error) error {
+	if err == nil || opts.Strict {
+		return err
+	}
+	if opts.OnWarning != nil {
+		opts.OnWarning(err)
+	}
+	return nil
+}
+
+// updateExistingPR brings the specified existing PR, which was found instead
+// of a new one being opened, up to date with the specified options. Reviewers
+// requested since it was opened are added to it, without duplicating those it
+// already has, and, if requested, its stale automated threads are resolved.
+func updateExistingPR(
+	ctx context.Context,
+	repo *repoClient,
+	pr *git.GitPullRequest,
+	opts *OpenPROptions,
+) error {
+	if err := addRequestedReviewers(ctx, repo, pr, opts); err != nil {
+		return err
+	}
+	if !opts.ResolveOwnThreads {
+		return nil
+	}
+	if pr.PullRequestId == nil {
+		return fmt.Errorf("%w: pull request has no ID", ErrIncompleteResponse)
+	}
+	if _, err := resolveOwnThreads(
+		ctx,
+		repo,
+		*pr.PullRequestId,
+		opts.ResolvedThreadStatus,
+	); err != nil {
+		return fmt.Errorf(
+			"error resolving threads on existing pull request %d: %w",
+			*pr.PullRequestId,
+			err,
+		)
+	}
+	return nil
+}
+
+// ensureValidBranchNames returns an error if any of the specified branch names
+// is not valid UTF-8. Request bodies are JSON, in which invalid UTF-8 would be
+// silently replaced, so such names would otherwise refer to the wrong refs.
+func ensureValidBranchNames(names ...string) error {
+	for _, name := range names {
+		if !utf8.ValidString(name) {
+			return fmt.Errorf("branch name %q is not valid UTF-8", name)
+		}
+	}
+	return nil
+}
+
 // ensureRefFormat ensures the branch name is in the correct format for Azure DevOps
-// Azure DevOps requires refs/heads/ prefix for branch names
+// Azure DevOps requires refs/heads/ prefix for branch names. Names are otherwise
+// left as they are, including any non-ASCII characters; the SDK escapes them
+// wherever they appear in URLs, so they must not be escaped beforehand.
 func ensureRefFormat(branchName string) string {
 	if !strings.HasPrefix(branchName, "refs/heads/") {
 		return "refs/heads/" + strings.TrimPrefix(branchName, "refs/heads/")
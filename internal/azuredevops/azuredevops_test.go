@@ -0,0 +1,802 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/identity"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/location"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// fakeGitClient is a fake implementation of the git.Client interface. Only
+// the methods whose corresponding function fields are set may be called.
+type fakeGitClient struct {
+	git.Client
+	getRepositoriesFn func(
+		context.Context,
+		git.GetRepositoriesArgs,
+	) (*[]git.GitRepository, error)
+	getRepositoryFn func(
+		context.Context,
+		git.GetRepositoryArgs,
+	) (*git.GitRepository, error)
+	getPullRequestsFn func(
+		context.Context,
+		git.GetPullRequestsArgs,
+	) (*[]git.GitPullRequest, error)
+	createPullRequestFn func(
+		context.Context,
+		git.CreatePullRequestArgs,
+	) (*git.GitPullRequest, error)
+	createThreadFn func(
+		context.Context,
+		git.CreateThreadArgs,
+	) (*git.GitPullRequestCommentThread, error)
+	getPullRequestFn func(
+		context.Context,
+		git.GetPullRequestArgs,
+	) (*git.GitPullRequest, error)
+	updatePullRequestFn func(
+		context.Context,
+		git.UpdatePullRequestArgs,
+	) (*git.GitPullRequest, error)
+	getCommitDiffsFn func(
+		context.Context,
+		git.GetCommitDiffsArgs,
+	) (*git.GitCommitDiffs, error)
+	getCommitFn func(
+		context.Context,
+		git.GetCommitArgs,
+	) (*git.GitCommit, error)
+	getCommitsFn func(
+		context.Context,
+		git.GetCommitsArgs,
+	) (*[]git.GitCommitRef, error)
+	getItemsFn func(
+		context.Context,
+		git.GetItemsArgs,
+	) (*[]git.GitItem, error)
+	getPullRequestWorkItemRefsFn func(
+		context.Context,
+		git.GetPullRequestWorkItemRefsArgs,
+	) (*[]webapi.ResourceRef, error)
+	getPolicyConfigurationsFn func(
+		context.Context,
+		git.GetPolicyConfigurationsArgs,
+	) (*git.GitPolicyConfigurationResponse, error)
+	getPullRequestIterationsFn func(
+		context.Context,
+		git.GetPullRequestIterationsArgs,
+	) (*[]git.GitPullRequestIteration, error)
+	getPullRequestIterationChangesFn func(
+		context.Context,
+		git.GetPullRequestIterationChangesArgs,
+	) (*git.GitPullRequestIterationChanges, error)
+	createPullRequestLabelFn func(
+		context.Context,
+		git.CreatePullRequestLabelArgs,
+	) (*core.WebApiTagDefinition, error)
+	getRefsFn func(
+		context.Context,
+		git.GetRefsArgs,
+	) (*git.GetRefsResponseValue, error)
+	updateRefsFn func(
+		context.Context,
+		git.UpdateRefsArgs,
+	) (*[]git.GitRefUpdateResult, error)
+	getPullRequestReviewersFn func(
+		context.Context,
+		git.GetPullRequestReviewersArgs,
+	) (*[]git.IdentityRefWithVote, error)
+	createPullRequestReviewerFn func(
+		context.Context,
+		git.CreatePullRequestReviewerArgs,
+	) (*git.IdentityRefWithVote, error)
+	getThreadsFn func(
+		context.Context,
+		git.GetThreadsArgs,
+	) (*[]git.GitPullRequestCommentThread, error)
+	updateThreadFn func(
+		context.Context,
+		git.UpdateThreadArgs,
+	) (*git.GitPullRequestCommentThread, error)
+	createAnnotatedTagFn func(
+		context.Context,
+		git.CreateAnnotatedTagArgs,
+	) (*git.GitAnnotatedTag, error)
+	updatePullRequestPropertiesFn func(
+		context.Context,
+		git.UpdatePullRequestPropertiesArgs,
+	) (interface{}, error)
+	createCommitStatusFn func(
+		context.Context,
+		git.CreateCommitStatusArgs,
+	) (*git.GitStatus, error)
+}
+
+func (f *fakeGitClient) GetRepositories(
+	ctx context.Context,
+	args git.GetRepositoriesArgs,
+) (*[]git.GitRepository, error) {
+	return f.getRepositoriesFn(ctx, args)
+}
+
+func (f *fakeGitClient) GetRepository(
+	ctx context.Context,
+	args git.GetRepositoryArgs,
+) (*git.GitRepository, error) {
+	return f.getRepositoryFn(ctx, args)
+}
+
+func (f *fakeGitClient) GetPullRequests(
+	ctx context.Context,
+	args git.GetPullRequestsArgs,
+) (*[]git.GitPullRequest, error) {
+	return f.getPullRequestsFn(ctx, args)
+}
+
+func (f *fakeGitClient) CreatePullRequest(
+	ctx context.Context,
+	args git.CreatePullRequestArgs,
+) (*git.GitPullRequest, error) {
+	return f.createPullRequestFn(ctx, args)
+}
+
+func (f *fakeGitClient) CreateThread(
+	ctx context.Context,
+	args git.CreateThreadArgs,
+) (*git.GitPullRequestCommentThread, error) {
+	return f.createThreadFn(ctx, args)
+}
+
+func (f *fakeGitClient) GetPullRequest(
+	ctx context.Context,
+	args git.GetPullRequestArgs,
+) (*git.GitPullRequest, error) {
+	return f.getPullRequestFn(ctx, args)
+}
+
+func (f *fakeGitClient) UpdatePullRequest(
+	ctx context.Context,
+	args git.UpdatePullRequestArgs,
+) (*git.GitPullRequest, error) {
+	return f.updatePullRequestFn(ctx, args)
+}
+
+func (f *fakeGitClient) GetCommitDiffs(
+	ctx context.Context,
+	args git.GetCommitDiffsArgs,
+) (*git.GitCommitDiffs, error) {
+	return f.getCommitDiffsFn(ctx, args)
+}
+
+func (f *fakeGitClient) GetCommit(
+	ctx context.Context,
+	args git.GetCommitArgs,
+) (*git.GitCommit, error) {
+	return f.getCommitFn(ctx, args)
+}
+
+func (f *fakeGitClient) GetItems(
+	ctx context.Context,
+	args git.GetItemsArgs,
+) (*[]git.GitItem, error) {
+	return f.getItemsFn(ctx, args)
+}
+
+func (f *fakeGitClient) GetCommits(
+	ctx context.Context,
+	args git.GetCommitsArgs,
+) (*[]git.GitCommitRef, error) {
+	return f.getCommitsFn(ctx, args)
+}
+
+func (f *fakeGitClient) GetPullRequestIterations(
+	ctx context.Context,
+	args git.GetPullRequestIterationsArgs,
+) (*[]git.GitPullRequestIteration, error) {
+	return f.getPullRequestIterationsFn(ctx, args)
+}
+
+func (f *fakeGitClient) GetPullRequestIterationChanges(
+	ctx context.Context,
+	args git.GetPullRequestIterationChangesArgs,
+) (*git.GitPullRequestIterationChanges, error) {
+	return f.getPullRequestIterationChangesFn(ctx, args)
+}
+
+func (f *fakeGitClient) CreatePullRequestLabel(
+	ctx context.Context,
+	args git.CreatePullRequestLabelArgs,
+) (*core.WebApiTagDefinition, error) {
+	return f.createPullRequestLabelFn(ctx, args)
+}
+
+func (f *fakeGitClient) GetRefs(
+	ctx context.Context,
+	args git.GetRefsArgs,
+) (*git.GetRefsResponseValue, error) {
+	return f.getRefsFn(ctx, args)
+}
+
+func (f *fakeGitClient) GetPullRequestWorkItemRefs(
+	ctx context.Context,
+	args git.GetPullRequestWorkItemRefsArgs,
+) (*[]webapi.ResourceRef, error) {
+	return f.getPullRequestWorkItemRefsFn(ctx, args)
+}
+
+func (f *fakeGitClient) GetPolicyConfigurations(
+	ctx context.Context,
+	args git.GetPolicyConfigurationsArgs,
+) (*git.GitPolicyConfigurationResponse, error) {
+	return f.getPolicyConfigurationsFn(ctx, args)
+}
+
+func (f *fakeGitClient) UpdateRefs(
+	ctx context.Context,
+	args git.UpdateRefsArgs,
+) (*[]git.GitRefUpdateResult, error) {
+	return f.updateRefsFn(ctx, args)
+}
+
+func (f *fakeGitClient) GetPullRequestReviewers(
+	ctx context.Context,
+	args git.GetPullRequestReviewersArgs,
+) (*[]git.IdentityRefWithVote, error) {
+	return f.getPullRequestReviewersFn(ctx, args)
+}
+
+func (f *fakeGitClient) CreatePullRequestReviewer(
+	ctx context.Context,
+	args git.CreatePullRequestReviewerArgs,
+) (*git.IdentityRefWithVote, error) {
+	return f.createPullRequestReviewerFn(ctx, args)
+}
+
+func (f *fakeGitClient) GetThreads(
+	ctx context.Context,
+	args git.GetThreadsArgs,
+) (*[]git.GitPullRequestCommentThread, error) {
+	return f.getThreadsFn(ctx, args)
+}
+
+func (f *fakeGitClient) UpdateThread(
+	ctx context.Context,
+	args git.UpdateThreadArgs,
+) (*git.GitPullRequestCommentThread, error) {
+	return f.updateThreadFn(ctx, args)
+}
+
+func (f *fakeGitClient) CreateAnnotatedTag(
+	ctx context.Context,
+	args git.CreateAnnotatedTagArgs,
+) (*git.GitAnnotatedTag, error) {
+	return f.createAnnotatedTagFn(ctx, args)
+}
+
+func (f *fakeGitClient) UpdatePullRequestProperties(
+	ctx context.Context,
+	args git.UpdatePullRequestPropertiesArgs,
+) (interface{}, error) {
+	return f.updatePullRequestPropertiesFn(ctx, args)
+}
+
+func (f *fakeGitClient) CreateCommitStatus(
+	ctx context.Context,
+	args git.CreateCommitStatusArgs,
+) (*git.GitStatus, error) {
+	return f.createCommitStatusFn(ctx, args)
+}
+
+// fakeLocationClient is a fake implementation of the location.Client
+// interface. Only the methods whose corresponding function fields are set may
+// be called.
+type fakeLocationClient struct {
+	location.Client
+	getConnectionDataFn func(
+		context.Context,
+		location.GetConnectionDataArgs,
+	) (*location.ConnectionData, error)
+}
+
+func (f *fakeLocationClient) GetConnectionData(
+	ctx context.Context,
+	args location.GetConnectionDataArgs,
+) (*location.ConnectionData, error) {
+	return f.getConnectionDataFn(ctx, args)
+}
+
+// useFakeIdentity overrides newLocationClient for the duration of the test
+// such that the authenticated identity has the specified ID. The identity
+// cache is also reset. A pointer to a count of identity lookups is returned.
+func useFakeIdentity(t *testing.T, id uuid.UUID) *atomic.Int32 {
+	lookups := &atomic.Int32{}
+	origClient, origCache := newLocationClient, identities
+	identities = newTTLCache[string]()
+	newLocationClient = func(context.Context, *azuredevops.Connection) location.Client {
+		return &fakeLocationClient{
+			getConnectionDataFn: func(
+				context.Context,
+				location.GetConnectionDataArgs,
+			) (*location.ConnectionData, error) {
+				lookups.Add(1)
+				return &location.ConnectionData{
+					AuthenticatedUser: &identity.Identity{Id: &id},
+				}, nil
+			},
+		}
+	}
+	t.Cleanup(func() {
+		newLocationClient, identities = origClient, origCache
+	})
+	return lookups
+}
+
+// ptr returns a pointer to the specified value.
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// useFakeGitClient overrides newGitClient for the duration of the test. The
+// repository cache is reset so that repositories reported by other tests'
+// fakes are not observed.
+func useFakeGitClient(t *testing.T, client git.Client) {
+	origClient, origCache := newGitClient, repositories
+	repositories = newTTLCache[*git.GitRepository]()
+	newGitClient = func(context.Context, *azuredevops.Connection) (git.Client, error) {
+		return client, nil
+	}
+	t.Cleanup(func() { newGitClient, repositories = origClient, origCache })
+}
+
+// fakeRepos returns a getRepositoriesFn that reports a single repository with
+// the specified name.
+func fakeRepos(name string) func(
+	context.Context,
+	git.GetRepositoriesArgs,
+) (*[]git.GitRepository, error) {
+	id := uuid.New()
+	return func(context.Context, git.GetRepositoriesArgs) (*[]git.GitRepository, error) {
+		return &[]git.GitRepository{{Id: &id, Name: &name}}, nil
+	}
+}
+
+// fakeCreatePullRequest returns a createPullRequestFn that echoes back the
+// requested PR with an ID and URL assigned. If captured is non-nil, the
+// requested PR is also stored there.
+func fakeCreatePullRequest(captured *git.GitPullRequest) func(
+	context.Context,
+	git.CreatePullRequestArgs,
+) (*git.GitPullRequest, error) {
+	return func(
+		_ context.Context,
+		args git.CreatePullRequestArgs,
+	) (*git.GitPullRequest, error) {
+		pr := *args.GitPullRequestToCreate
+		id := 42
+		url := "https://dev.azure.com/org/proj/_git/repo/pullrequest/42"
+		pr.PullRequestId = &id
+		pr.Url = &url
+		if captured != nil {
+			*captured = pr
+		}
+		return &pr, nil
+	}
+}
+
+func TestParseAzureDevOpsURL(t *testing.T) {
+	testCases := []struct {
+		name       string
+		url        string
+		assertions func(t *testing.T, org, proj, repo string, err error)
+	}{
+		{
+			name: "dev.azure.com",
+			url:  "https://dev.azure.com/org/proj/_git/repo",
+			assertions: func(t *testing.T, org, proj, repo string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "org", org)
+				require.Equal(t, "proj", proj)
+				require.Equal(t, "repo", repo)
+			},
+		},
+		{
+			name: "visualstudio.com",
+			url:  "https://org.visualstudio.com/proj/_git/repo.git",
+			assertions: func(t *testing.T, org, proj, repo string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "org", org)
+				require.Equal(t, "proj", proj)
+				require.Equal(t, "repo", repo)
+			},
+		},
+		{
+			name: "dev.azure.com with user info",
+			url:  "https://org@dev.azure.com/org/proj/_git/repo",
+			assertions: func(t *testing.T, org, proj, repo string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "org", org)
+				require.Equal(t, "proj", proj)
+				require.Equal(t, "repo", repo)
+			},
+		},
+		{
+			name: "query string",
+			url:  "https://dev.azure.com/org/proj/_git/repo?path=/charts&version=GBmain",
+			assertions: func(t *testing.T, org, proj, repo string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "org", org)
+				require.Equal(t, "proj", proj)
+				require.Equal(t, "repo", repo)
+			},
+		},
+		{
+			name: "fragment",
+			url:  "https://org.visualstudio.com/proj/_git/repo#readme",
+			assertions: func(t *testing.T, org, proj, repo string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "org", org)
+				require.Equal(t, "proj", proj)
+				require.Equal(t, "repo", repo)
+			},
+		},
+		{
+			name: ".git suffix with query string and fragment",
+			url:  "https://dev.azure.com/org/proj/_git/repo.git?path=/charts#L10",
+			assertions: func(t *testing.T, org, proj, repo string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "org", org)
+				require.Equal(t, "proj", proj)
+				require.Equal(t, "repo", repo)
+			},
+		},
+		{
+			name: "truncated dev.azure.com",
+			url:  "https://dev.azure.com/org/proj/_git",
+			assertions: func(t *testing.T, _, _, _ string, err error) {
+				require.ErrorIs(t, err, errInvalidURL)
+			},
+		},
+		{
+			name: "truncated visualstudio.com",
+			url:  "https://org.visualstudio.com/proj/_git",
+			assertions: func(t *testing.T, _, _, _ string, err error) {
+				require.ErrorIs(t, err, errInvalidURL)
+			},
+		},
+		{
+			name: "unsupported host",
+			url:  "https://example.com/org/proj/repo",
+			assertions: func(t *testing.T, _, _, _ string, err error) {
+				require.ErrorIs(t, err, errUnsupportedURL)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			org, proj, repo, err := parseAzureDevOpsURL(testCase.url)
+			testCase.assertions(t, org, proj, repo, err)
+		})
+	}
+}
+
+func TestOpenPRDisabledRepository(t *testing.T) {
+	id := uuid.New()
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: func(
+			context.Context,
+			git.GetRepositoriesArgs,
+		) (*[]git.GitRepository, error) {
+			return &[]git.GitRepository{{
+				Id:         &id,
+				Name:       ptr("repo"),
+				IsDisabled: ptr(true),
+			}}, nil
+		},
+		createPullRequestFn: func(
+			context.Context,
+			git.CreatePullRequestArgs,
+		) (*git.GitPullRequest, error) {
+			require.FailNow(t, "no attempt should be made to create a PR")
+			return nil, nil
+		},
+	})
+	_, err := OpenPR(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"title",
+		"description",
+		"env/dev",
+		"prs/kargo-render/env/dev",
+		gitutil.RepoCredentials{Password: "pat"},
+		nil,
+	)
+	require.ErrorIs(t, err, ErrRepositoryDisabled)
+	require.Contains(t, err.Error(), "repository is disabled")
+}
+
+func TestOpenPRSourceRepository(t *testing.T) {
+	testCases := []struct {
+		name          string
+		sourceRepoURL string
+		assertions    func(t *testing.T, created bool, err error)
+	}{
+		{
+			name:          "same repository",
+			sourceRepoURL: "git@ssh.dev.azure.com:v3/Org/Proj/Repo",
+			assertions: func(t *testing.T, created bool, err error) {
+				require.NoError(t, err)
+				require.True(t, created)
+			},
+		},
+		{
+			name:          "different repository",
+			sourceRepoURL: "https://dev.azure.com/org/proj/_git/other-repo",
+			assertions: func(t *testing.T, created bool, err error) {
+				require.ErrorIs(t, err, ErrRepositoryMismatch)
+				require.Contains(t, err.Error(), "org/proj/other-repo")
+				require.False(t, created)
+			},
+		},
+		{
+			name:          "different project",
+			sourceRepoURL: "https://dev.azure.com/org/other-proj/_git/repo",
+			assertions: func(t *testing.T, created bool, err error) {
+				require.ErrorIs(t, err, ErrRepositoryMismatch)
+				require.False(t, created)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var created bool
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				createPullRequestFn: func(
+					ctx context.Context,
+					args git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					created = true
+					return fakeCreatePullRequest(nil)(ctx, args)
+				},
+			})
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				&OpenPROptions{SourceRepoURL: testCase.sourceRepoURL},
+			)
+			testCase.assertions(t, created, err)
+		})
+	}
+}
+
+func TestOpenPRPartialResponses(t *testing.T) {
+	id := uuid.New()
+	testCases := []struct {
+		name         string
+		repos        []git.GitRepository
+		createdPR    *git.GitPullRequest
+		errSubstring string
+		incomplete   bool
+	}{
+		{
+			name:         "repository without name",
+			repos:        []git.GitRepository{{Id: &id}},
+			errSubstring: "repository 'repo' not found",
+		},
+		{
+			name:         "repository without ID",
+			repos:        []git.GitRepository{{Name: ptr("repo")}},
+			errSubstring: "has no ID",
+			incomplete:   true,
+		},
+		{
+			name:         "no pull request",
+			repos:        []git.GitRepository{{Id: &id, Name: ptr("repo")}},
+			errSubstring: "ID or URL was not returned",
+			incomplete:   true,
+		},
+		{
+			name:         "pull request without URL",
+			repos:        []git.GitRepository{{Id: &id, Name: ptr("repo")}},
+			createdPR:    &git.GitPullRequest{PullRequestId: ptr(42)},
+			errSubstring: "ID or URL was not returned",
+			incomplete:   true,
+		},
+		{
+			name:  "pull request without ID",
+			repos: []git.GitRepository{{Id: &id, Name: ptr("repo")}},
+			createdPR: &git.GitPullRequest{
+				Url: ptr("https://dev.azure.com/org/proj/_git/repo/pullrequest/42"),
+			},
+			errSubstring: "ID or URL was not returned",
+			incomplete:   true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: func(
+					context.Context,
+					git.GetRepositoriesArgs,
+				) (*[]git.GitRepository, error) {
+					return &testCase.repos, nil
+				},
+				createPullRequestFn: func(
+					context.Context,
+					git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					return testCase.createdPR, nil
+				},
+			})
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				nil,
+			)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), testCase.errSubstring)
+			if testCase.incomplete {
+				require.ErrorIs(t, err, ErrIncompleteResponse)
+			}
+		})
+	}
+}
+
+func TestOpenPRSuppressNotifications(t *testing.T) {
+	testCases := []struct {
+		name       string
+		opts       OpenPROptions
+		assertions func(t *testing.T, created *git.GitPullRequest, err error)
+	}{
+		{
+			name: "notifications not suppressed",
+			assertions: func(t *testing.T, created *git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.Nil(t, created.IsDraft)
+			},
+		},
+		{
+			name: "notifications suppressed",
+			opts: OpenPROptions{SuppressNotifications: ptr(true)},
+			assertions: func(t *testing.T, created *git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.True(t, *created.IsDraft)
+			},
+		},
+		{
+			name: "notifications explicitly not suppressed",
+			opts: OpenPROptions{SuppressNotifications: ptr(false)},
+			assertions: func(t *testing.T, created *git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.Nil(t, created.IsDraft)
+			},
+		},
+		{
+			name: "notifications suppressed with auto-complete",
+			opts: OpenPROptions{
+				SuppressNotifications: ptr(true),
+				AutoComplete:          &AutoCompleteOptions{},
+			},
+			assertions: func(t *testing.T, created *git.GitPullRequest, err error) {
+				require.ErrorContains(t, err, "not supported for PRs with auto-complete")
+				require.Nil(t, created.Title)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var created git.GitPullRequest
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn:   fakeRepos("repo"),
+				createPullRequestFn: fakeCreatePullRequest(&created),
+			})
+			opts := testCase.opts
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				&opts,
+			)
+			testCase.assertions(t, &created, err)
+		})
+	}
+}
+
+func TestOpenPREnrichmentFailures(t *testing.T) {
+	testCases := []struct {
+		name       string
+		strict     bool
+		assertions func(t *testing.T, url string, warnings []error, err error)
+	}{
+		{
+			name: "failures degrade into warnings by default",
+			assertions: func(t *testing.T, url string, warnings []error, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, url)
+				require.Len(t, warnings, 2)
+				require.ErrorContains(t, warnings[0], "error occurred writing its metadata")
+				require.ErrorContains(t, warnings[1], "error occurred setting the status")
+				for _, warning := range warnings {
+					// Warnings are redacted just as errors are
+					require.NotContains(t, warning.Error(), "s3cret-token")
+				}
+			},
+		},
+		{
+			name:   "failures are fatal in strict mode",
+			strict: true,
+			assertions: func(t *testing.T, url string, warnings []error, err error) {
+				require.ErrorContains(t, err, "error occurred writing its metadata")
+				require.NotContains(t, err.Error(), "s3cret-token")
+				// The PR was nevertheless created
+				require.NotEmpty(t, url)
+				require.Empty(t, warnings)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn:   fakeRepos("repo"),
+				createPullRequestFn: fakeCreatePullRequest(nil),
+				updatePullRequestPropertiesFn: func(
+					context.Context,
+					git.UpdatePullRequestPropertiesArgs,
+				) (interface{}, error) {
+					return nil, errors.New("properties rejected for s3cret-token")
+				},
+				getRefsFn: func(
+					context.Context,
+					git.GetRefsArgs,
+				) (*git.GetRefsResponseValue, error) {
+					return nil, errors.New("refs unavailable for s3cret-token")
+				},
+			})
+			var warnings []error
+			url, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "s3cret-token"},
+				&OpenPROptions{
+					Metadata:        map[string]string{"runID": "1234"},
+					SetCommitStatus: true,
+					Strict:          testCase.strict,
+					OnWarning: func(err error) {
+						warnings = append(warnings, err)
+					},
+				},
+			)
+			testCase.assertions(t, url, warnings, err)
+		})
+	}
+}
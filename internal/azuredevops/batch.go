@@ -0,0 +1,374 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	"github.com/akuity/kargo-render/internal/repourl"
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// maxBatchConcurrency is the maximum number of PRs BatchOpenPR opens
+// concurrently.
+const maxBatchConcurrency = 4
+
+// Route identifies the repository and branch a PR should target.
+type Route struct {
+	// RepoURL is the URL of the repository the PR should be opened in.
+	RepoURL string
+	// TargetBranch is the branch the PR should target.
+	TargetBranch string
+}
+
+// RouteFunc maps the name of an environment to a Route.
+type RouteFunc func(env string) (Route, error)
+
+// StaticRoutes returns a RouteFunc that maps environments to Routes using the
+// specified map. Environments absent from the map cannot be routed.
+func StaticRoutes(routes map[string]Route) RouteFunc {
+	return func(env string) (Route, error) {
+		route, ok := routes[env]
+		if !ok {
+			return Route{}, fmt.Errorf("no route for environment %q", env)
+		}
+		return route, nil
+	}
+}
+
+// PRRequest describes a single PR to be opened by BatchOpenPR.
+type PRRequest struct {
+	// Env is the name of the environment the PR is for. When BatchOpenPR is
+	// provided with a RouteFunc, this is used to determine the repository and
+	// target branch.
+	Env string
+	// Route is the repository and branch the PR should target. This is ignored
+	// when BatchOpenPR is provided with a RouteFunc.
+	Route Route
+	// SourceBranch is the branch the PR should be opened from.
+	SourceBranch string
+	// Title is the title of the PR.
+	Title string
+	// Description is the description of the PR.
+	Description string
+	// Options are optional settings for opening the PR.
+	Options *OpenPROptions
+	// AutoComplete, when non-nil, explicitly specifies whether auto-complete
+	// should be enabled for the PR, overriding Options.AutoComplete. When this
+	// is true and Options.AutoComplete is nil, default auto-complete settings
+	// are used. Options.AutoCompletePolicy, if any, is still honored.
+	AutoComplete *bool
+}
+
+// options returns the settings for opening the requested PR, with any
+// explicit auto-complete override applied.
+func (p PRRequest) options() *OpenPROptions {
+	if p.AutoComplete == nil {
+		return p.Options
+	}
+	opts := OpenPROptions{}
+	if p.Options != nil {
+		opts = *p.Options
+	}
+	switch {
+	case !*p.AutoComplete:
+		opts.AutoComplete = nil
+	case opts.AutoComplete == nil:
+		opts.AutoComplete = &AutoCompleteOptions{}
+	}
+	return &opts
+}
+
+// pooled returns a copy of the specified settings for opening a PR that shares
+// connections using the specified pool.
+func pooled(opts *OpenPROptions, pool *connectionPool) *OpenPROptions {
+	o := OpenPROptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.Connection.pool = pool
+	return &o
+}
+
+// PRResult is the outcome of opening a single PR requested of BatchOpenPR.
+type PRResult struct {
+	// Env is the name of the environment the PR is for.
+	Env string
+	// Route is the repository and branch the PR targeted.
+	Route Route
+	// URL is the URL of the PR. This is empty if the PR could not be opened or
+	// if an existing PR was found.
+	URL string
+	// Outcome summarizes the result of attempting to open the PR.
+	Outcome Outcome
+	// AutoComplete is whether auto-complete was engaged for the PR.
+	AutoComplete AutoCompleteStatus
+	// Err is any error that was encountered opening the PR.
+	Err error
+}
+
+// BatchFailure is the failure of a single PR requested of BatchOpenPR or Plan.
+type BatchFailure struct {
+	// Index is the index of the failed request within the batch.
+	Index int
+	// Env is the name of the environment the PR is for.
+	Env string
+	// Route is the repository and branch the PR targeted. This is empty if the
+	// environment could not be routed.
+	Route Route
+	// Err is the error that was encountered opening the PR.
+	Err error
+}
+
+func (b *BatchFailure) Error() string {
+	return fmt.Sprintf("error opening PR for environment %q: %v", b.Env, b.Err)
+}
+
+func (b *BatchFailure) Unwrap() error {
+	return b.Err
+}
+
+// BatchError is returned by BatchOpenPR when any of the requested PRs could
+// not be opened, and by Plan when any could not be planned. It unwraps to a
+// *BatchFailure for each of them, so errors.Is and errors.As consider every
+// failure.
+type BatchError struct {
+	// Failures are the failures of the requested PRs that could not be opened,
+	// in the same order as the requests.
+	Failures []*BatchFailure
+}
+
+// Error describes each failure on a line of its own, in order.
+func (b *BatchError) Error() string {
+	msgs := make([]string, len(b.Failures))
+	for i, failure := range b.Failures {
+		msgs[i] = failure.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (b *BatchError) Unwrap() []error {
+	errs := make([]error, len(b.Failures))
+	for i, failure := range b.Failures {
+		errs[i] = failure
+	}
+	return errs
+}
+
+// BatchOptions encapsulates optional settings for BatchOpenPR.
+type BatchOptions struct {
+	// LinkRelatedPRs specifies whether, once all requested PRs have been opened,
+	// the description of each PR that was newly created should be updated with
+	// links to the others that were, under a standard heading. At that point, the
+	// links are added to any specified by the request's Options.RelatedPRURLs.
+	// Existing PRs that were found instead of being created are neither updated
+	// nor linked to, since their URLs are not known.
+	LinkRelatedPRs bool
+}
+
+// BatchOpenPR concurrently opens the requested PRs, which may span multiple
+// repositories. If route is non-nil, it determines the repository and target
+// branch of each PR from its environment. Otherwise, each request's own Route
+// is used. Results are returned in the same order as the requests. If any PR
+// could not be opened, or, when requested, linked to its siblings, a
+// *BatchError describing each such failure is also returned. If opts is nil,
+// defaults are used.
+func BatchOpenPR(
+	ctx context.Context,
+	reqs []PRRequest,
+	route RouteFunc,
+	creds gitutil.RepoCredentials,
+	opts *BatchOptions,
+) ([]PRResult, error) {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+	results := make([]PRResult, len(reqs))
+	// All PRs in the batch share connections to each organization
+	pool := newConnectionPool()
+	sem := make(chan struct{}, maxBatchConcurrency)
+	wg := sync.WaitGroup{}
+	for i, req := range reqs {
+		results[i] = PRResult{Env: req.Env, Route: req.Route}
+		if route != nil {
+			var err error
+			if results[i].Route, err = route(req.Env); err != nil {
+				results[i].Err = fmt.Errorf(
+					"error routing environment %q: %w",
+					req.Env,
+					err,
+				)
+				results[i].Outcome = OutcomeError
+				continue
+			}
+		}
+		wg.Add(1)
+		go func(res *PRResult, req PRRequest) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			var event AuditEvent
+			res.URL, event, res.Err = openPRAudited(
+				ctx,
+				res.Route.RepoURL,
+				req.Title,
+				req.Description,
+				res.Route.TargetBranch,
+				req.SourceBranch,
+				creds,
+				pooled(req.options(), pool),
+			)
+			res.Outcome = event.Outcome
+			res.AutoComplete = event.AutoComplete
+		}(&results[i], req)
+	}
+	wg.Wait()
+	if opts.LinkRelatedPRs {
+		linkRelatedPRs(ctx, reqs, results, creds, pool)
+	}
+	var failures []*BatchFailure
+	for i, res := range results {
+		if res.Err != nil {
+			failures = append(failures, &BatchFailure{
+				Index: i,
+				Env:   res.Env,
+				Route: res.Route,
+				Err:   res.Err,
+			})
+		}
+	}
+	if len(failures) > 0 {
+		return results, &BatchError{Failures: failures}
+	}
+	return results, nil
+}
+
+// linkRelatedPRs updates the description of each PR in the specified results
+// that was newly created to link to every other PR in them that was. The
+// results are updated in place with any errors that are encountered.
+func linkRelatedPRs(
+	ctx context.Context,
+	reqs []PRRequest,
+	results []PRResult,
+	creds gitutil.RepoCredentials,
+	pool *connectionPool,
+) {
+	prIDs := make(map[int]int, len(results))
+	links := make([]string, len(results))
+	conns := make([]*ConnectionOptions, len(results))
+	for i, res := range results {
+		if res.Outcome != OutcomeCreated {
+			continue
+		}
+		id, ok := prIDFromURL(res.URL)
+		if !ok {
+			results[i].Err = fmt.Errorf(
+				"pull request %s was opened, but its ID could not be determined for "+
+					"linking related PRs",
+				res.URL,
+			)
+			continue
+		}
+		prIDs[i] = id
+		conns[i] = &pooled(reqs[i].options(), pool).Connection
+		repoURL := res.Route.RepoURL
+		if expanded, err := ExpandRepoRef(repoURL, conns[i].RepoDefaults); err == nil {
+			repoURL = expanded
+		}
+		links[i] = prWebURL(repoURL, id)
+	}
+	if len(prIDs) < 2 {
+		return
+	}
+	sem := make(chan struct{}, maxBatchConcurrency)
+	wg := sync.WaitGroup{}
+	for i, id := range prIDs {
+		urls := []string{}
+		if opts := reqs[i].Options; opts != nil {
+			urls = append(urls, opts.RelatedPRURLs...)
+		}
+		for j := range results {
+			if _, ok := prIDs[j]; ok && j != i {
+				urls = append(urls, links[j])
+			}
+		}
+		wg.Add(1)
+		go func(res *PRResult, id int, urls []string, conn *ConnectionOptions) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := updateRelatedPRs(ctx, res.Route.RepoURL, id, urls, creds, conn); err != nil {
+				res.Err = fmt.Errorf(
+					"pull request %d was opened, but an error occurred linking related PRs: %w",
+					id,
+					err,
+				)
+			}
+		}(&results[i], id, urls, conns[i])
+	}
+	wg.Wait()
+}
+
+// updateRelatedPRs replaces the links to related PRs in the description of the
+// specified PR with links to the specified URLs, connecting to Azure DevOps
+// using the specified options.
+func updateRelatedPRs(
+	ctx context.Context,
+	repoURL string,
+	prID int,
+	urls []string,
+	creds gitutil.RepoCredentials,
+	conn *ConnectionOptions,
+) (err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, conn)
+	if err != nil {
+		return err
+	}
+	pr, err := repo.client.GetPullRequest(ctx, git.GetPullRequestArgs{
+		Project:       &repo.project,
+		RepositoryId:  &repo.id,
+		PullRequestId: &prID,
+	})
+	if err != nil {
+		return fmt.Errorf("error getting pull request %d: %w", prID, err)
+	}
+	var description string
+	if pr.Description != nil {
+		description = *pr.Description
+	}
+	description = withRelatedPRs(description, urls)
+	if _, err = repo.client.UpdatePullRequest(ctx, git.UpdatePullRequestArgs{
+		Project:       &repo.project,
+		RepositoryId:  &repo.id,
+		PullRequestId: &prID,
+		GitPullRequestToUpdate: &git.GitPullRequest{
+			Description: &description,
+		},
+	}); err != nil {
+		return fmt.Errorf("error updating description of pull request %d: %w", prID, err)
+	}
+	return nil
+}
+
+// prIDFromURL returns the ID of the PR at the specified URL, as returned by
+// OpenPR, and a bool indicating whether it could be determined.
+func prIDFromURL(prURL string) (int, bool) {
+	prURL = strings.TrimRight(trimQueryAndFragment(prURL), "/")
+	id, err := strconv.Atoi(prURL[strings.LastIndex(prURL, "/")+1:])
+	return id, err == nil && id > 0
+}
+
+// prWebURL returns the URL at which the specified PR in the specified
+// repository can be viewed in the Azure DevOps web UI. Any credentials and
+// .git suffix are removed from the repository's URL, since the URL is
+// published in PR descriptions.
+func prWebURL(repoURL string, prID int) string {
+	repoURL = withoutCredentials(trimQueryAndFragment(repourl.Normalize(repoURL)))
+	repoURL = strings.TrimSuffix(strings.TrimSuffix(repoURL, "/"), ".git")
+	return fmt.Sprintf("%s/pullrequest/%d", repoURL, prID)
+}
@@ -0,0 +1,97 @@
+package azuredevops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+)
+
+// defaultCacheTTL is the default amount of time for which the results of
+// lookups are cached.
+const defaultCacheTTL = 5 * time.Minute
+
+// now returns the current time. It is a package-level variable so that it can
+// be overridden in tests.
+var now = time.Now
+
+// ttlCache memoizes the results of lookups for a limited time so that, for
+// instance, opening many PRs in a single run requires each lookup to be
+// performed only once, while long-running processes still eventually observe
+// changes, such as renamed repositories or rotated identities.
+type ttlCache[V any] struct {
+	mu      sync.Mutex
+	entries map[string]*ttlCacheEntry[V]
+}
+
+// ttlCacheEntry holds a single memoized value. Its mutex is held for the
+// duration of a lookup so that concurrent lookups for the same key result in
+// a single request.
+type ttlCacheEntry[V any] struct {
+	mu      sync.Mutex
+	value   V
+	expires time.Time
+}
+
+// newTTLCache returns an empty ttlCache.
+func newTTLCache[V any]() *ttlCache[V] {
+	return &ttlCache[V]{entries: map[string]*ttlCacheEntry[V]{}}
+}
+
+// get returns the value cached for the specified key if it has not expired.
+// Otherwise, it performs the specified lookup and, if it succeeds, caches the
+// result for the specified TTL. If the TTL is zero, defaultCacheTTL is used.
+// If it is negative, nothing is cached. Failed lookups are never cached.
+func (c *ttlCache[V]) get(
+	key string,
+	ttl time.Duration,
+	lookup func() (V, error),
+) (V, error) {
+	if ttl < 0 {
+		return lookup()
+	}
+	if ttl == 0 {
+		ttl = defaultCacheTTL
+	}
+	e := c.entry(key)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if now().Before(e.expires) {
+		return e.value, nil
+	}
+	value, err := lookup()
+	if err != nil {
+		return value, err
+	}
+	e.value, e.expires = value, now().Add(ttl)
+	return value, nil
+}
+
+// entry returns the cache entry for the specified key, creating it if
+// necessary.
+func (c *ttlCache[V]) entry(key string) *ttlCacheEntry[V] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = &ttlCacheEntry[V]{}
+		c.entries[key] = e
+	}
+	return e
+}
+
+// connectionKey returns a cache key for lookups made using the specified
+// connection, qualified by the specified parts. Connections are created per
+// operation, so they are keyed by their organization URL and credentials
+// rather than by pointer. The key is a digest so that credentials are not
+// retained in memory any longer than the connection itself retains them.
+func connectionKey(connection *azuredevops.Connection, parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(
+		append([]string{connection.BaseUrl, connection.AuthorizationString}, parts...),
+		"\x00",
+	)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,147 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// useFakeClock overrides now for the duration of the test and returns a
+// function that advances the fake clock by the specified duration.
+func useFakeClock(t *testing.T) func(time.Duration) {
+	current := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	orig := now
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = orig })
+	return func(d time.Duration) { current = current.Add(d) }
+}
+
+func TestTTLCache(t *testing.T) {
+	testCases := []struct {
+		name       string
+		ttl        time.Duration
+		advance    time.Duration
+		assertions func(t *testing.T, lookups int)
+	}{
+		{
+			name:    "default TTL not expired",
+			advance: defaultCacheTTL - time.Second,
+			assertions: func(t *testing.T, lookups int) {
+				require.Equal(t, 1, lookups)
+			},
+		},
+		{
+			name:    "default TTL expired",
+			advance: defaultCacheTTL,
+			assertions: func(t *testing.T, lookups int) {
+				require.Equal(t, 2, lookups)
+			},
+		},
+		{
+			name:    "custom TTL expired",
+			ttl:     time.Minute,
+			advance: time.Minute,
+			assertions: func(t *testing.T, lookups int) {
+				require.Equal(t, 2, lookups)
+			},
+		},
+		{
+			name: "caching disabled",
+			ttl:  -1,
+			assertions: func(t *testing.T, lookups int) {
+				require.Equal(t, 2, lookups)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			advance := useFakeClock(t)
+			cache := newTTLCache[int]()
+			var lookups int
+			lookup := func() (int, error) {
+				lookups++
+				return lookups, nil
+			}
+			_, err := cache.get("key", testCase.ttl, lookup)
+			require.NoError(t, err)
+			advance(testCase.advance)
+			value, err := cache.get("key", testCase.ttl, lookup)
+			require.NoError(t, err)
+			require.Equal(t, lookups, value)
+			testCase.assertions(t, lookups)
+		})
+	}
+}
+
+func TestTTLCacheFailedLookup(t *testing.T) {
+	cache := newTTLCache[int]()
+	_, err := cache.get("key", 0, func() (int, error) {
+		return 0, errors.New("something went wrong")
+	})
+	require.Error(t, err)
+	value, err := cache.get("key", 0, func() (int, error) { return 42, nil })
+	require.NoError(t, err)
+	require.Equal(t, 42, value)
+}
+
+func TestIdentityCacheExpiry(t *testing.T) {
+	advance := useFakeClock(t)
+	lookups := useFakeIdentity(t, uuid.New())
+	connection := azuredevops.NewPatConnection("https://dev.azure.com/org", "pat")
+	for range 2 {
+		_, err := getAuthenticatedIdentityID(context.Background(), connection, time.Minute)
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(1), lookups.Load())
+	advance(time.Minute)
+	_, err := getAuthenticatedIdentityID(context.Background(), connection, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), lookups.Load())
+}
+
+func TestRepositoryCacheExpiry(t *testing.T) {
+	advance := useFakeClock(t)
+	name := "repo"
+	var lookups int
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: func(
+			ctx context.Context,
+			args git.GetRepositoriesArgs,
+		) (*[]git.GitRepository, error) {
+			lookups++
+			return fakeRepos(name)(ctx, args)
+		},
+	})
+	newClient := func() (*repoClient, error) {
+		return newRepoClient(
+			context.Background(),
+			"https://dev.azure.com/org/proj/_git/repo",
+			gitutil.RepoCredentials{Password: "pat"},
+			&ConnectionOptions{CacheTTL: time.Minute},
+		)
+	}
+	for range 2 {
+		_, err := newClient()
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, lookups)
+
+	// The repository is renamed, but this is not observed until the cached
+	// lookup expires
+	name = "renamed"
+	_, err := newClient()
+	require.NoError(t, err)
+	require.Equal(t, 1, lookups)
+	advance(time.Minute)
+	_, err = newClient()
+	require.ErrorContains(t, err, "repository 'repo' not found")
+	require.Equal(t, 2, lookups)
+}
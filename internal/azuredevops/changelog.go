@@ -0,0 +1,107 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+)
+
+const (
+	// defaultMaxChangelogCommits is the default maximum number of commits listed
+	// in the changelog section of a PR description.
+	defaultMaxChangelogCommits = 20
+
+	// changelogHeading introduces the list of commits in a PR description.
+	changelogHeading = "### Changes"
+
+	// shortCommitIDLength is the number of characters of a commit ID that are
+	// shown in a changelog.
+	shortCommitIDLength = 7
+)
+
+// changelogSection returns the section of a PR description that lists the
+// commits in the head branch that are not already present in the base branch,
+// newest first. At most maxCommits commits are listed, with any remainder
+// summarized by a count. If maxCommits is 0, defaultMaxChangelogCommits is
+// used. If there are no such commits, an empty string is returned.
+func changelogSection(
+	ctx context.Context,
+	repo *repoClient,
+	base string,
+	head string,
+	maxCommits int,
+) (string, error) {
+	if maxCommits <= 0 {
+		maxCommits = defaultMaxChangelogCommits
+	}
+	// The comparison reveals how many commits there are in total, while the
+	// commits themselves must be listed separately
+	diffs, err := getCommitDiffs(ctx, repo, base, head, 1)
+	if err != nil {
+		return "", err
+	}
+	if diffs == nil || diffs.AheadCount == nil || *diffs.AheadCount == 0 {
+		return "", nil
+	}
+	base = strings.TrimPrefix(base, "refs/heads/")
+	head = strings.TrimPrefix(head, "refs/heads/")
+	commits, err := repo.client.GetCommits(ctx, git.GetCommitsArgs{
+		Project:      &repo.project,
+		RepositoryId: &repo.id,
+		SearchCriteria: &git.GitQueryCommitsCriteria{
+			Top: &maxCommits,
+			ItemVersion: &git.GitVersionDescriptor{
+				Version:     &head,
+				VersionType: &git.GitVersionTypeValues.Branch,
+			},
+			CompareVersion: &git.GitVersionDescriptor{
+				Version:     &base,
+				VersionType: &git.GitVersionTypeValues.Branch,
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf(
+			"error listing commits in branch %q that are not in branch %q: %w",
+			head,
+			base,
+			err,
+		)
+	}
+	var listed []git.GitCommitRef
+	if commits != nil {
+		listed = *commits
+	}
+	if len(listed) > maxCommits {
+		listed = listed[:maxCommits]
+	}
+	return renderChangelog(listed, *diffs.AheadCount), nil
+}
+
+// renderChangelog renders the specified commits, which are some or all of the
+// specified total number of commits, as a changelog section of a PR
+// description.
+func renderChangelog(commits []git.GitCommitRef, total int) string {
+	lines := make([]string, 0, len(commits)+3)
+	lines = append(lines, changelogHeading, "")
+	for _, commit := range commits {
+		var id, subject string
+		if commit.CommitId != nil {
+			id = *commit.CommitId
+			if len(id) > shortCommitIDLength {
+				id = id[:shortCommitIDLength]
+			}
+		}
+		if commit.Comment != nil {
+			subject, _, _ = strings.Cut(strings.TrimSpace(*commit.Comment), "\n")
+			subject = strings.TrimSpace(subject)
+		}
+		lines = append(lines, fmt.Sprintf("- `%s` %s", id, subject))
+	}
+	if remaining := total - len(commits); remaining > 0 {
+		lines = append(lines, fmt.Sprintf("- _...and %d more_", remaining))
+	}
+	return strings.Join(lines, "\n")
+}
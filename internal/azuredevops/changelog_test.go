@@ -0,0 +1,113 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestRenderChangelog(t *testing.T) {
+	testCases := []struct {
+		name     string
+		commits  []git.GitCommitRef
+		total    int
+		expected string
+	}{
+		{
+			name: "all commits listed",
+			commits: []git.GitCommitRef{
+				{
+					CommitId: ptr("0123456789abcdef"),
+					Comment:  ptr("Update dev image\n\nBumps the image tag."),
+				},
+				{CommitId: ptr("fedcba9876543210"), Comment: ptr("  Render dev  ")},
+			},
+			total: 2,
+			expected: "### Changes\n\n" +
+				"- `0123456` Update dev image\n" +
+				"- `fedcba9` Render dev",
+		},
+		{
+			name: "remaining commits summarized",
+			commits: []git.GitCommitRef{
+				{CommitId: ptr("0123456789abcdef"), Comment: ptr("Update dev image")},
+			},
+			total: 4,
+			expected: "### Changes\n\n" +
+				"- `0123456` Update dev image\n" +
+				"- _...and 3 more_",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(
+				t,
+				testCase.expected,
+				renderChangelog(testCase.commits, testCase.total),
+			)
+		})
+	}
+}
+
+func TestOpenPRIncludeChangelog(t *testing.T) {
+	var created git.GitPullRequest
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn:   fakeRepos("repo"),
+		createPullRequestFn: fakeCreatePullRequest(&created),
+		getCommitDiffsFn: func(
+			_ context.Context,
+			args git.GetCommitDiffsArgs,
+		) (*git.GitCommitDiffs, error) {
+			require.Equal(t, "env/dev", *args.BaseVersionDescriptor.BaseVersion)
+			require.Equal(
+				t,
+				"prs/kargo-render/env/dev",
+				*args.TargetVersionDescriptor.TargetVersion,
+			)
+			return &git.GitCommitDiffs{AheadCount: ptr(5)}, nil
+		},
+		getCommitsFn: func(
+			_ context.Context,
+			args git.GetCommitsArgs,
+		) (*[]git.GitCommitRef, error) {
+			require.Equal(t, "prs/kargo-render/env/dev", *args.SearchCriteria.ItemVersion.Version)
+			require.Equal(t, "env/dev", *args.SearchCriteria.CompareVersion.Version)
+			commits := make([]git.GitCommitRef, *args.SearchCriteria.Top)
+			for i := range commits {
+				commits[i] = git.GitCommitRef{
+					CommitId: ptr(fmt.Sprintf("%07d0000", i)),
+					Comment:  ptr(fmt.Sprintf("Commit %d", i)),
+				}
+			}
+			return &commits, nil
+		},
+	})
+	_, err := OpenPR(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"title",
+		"description\n",
+		"env/dev",
+		"prs/kargo-render/env/dev",
+		gitutil.RepoCredentials{Password: "pat"},
+		&OpenPROptions{
+			IncludeChangelog:    true,
+			MaxChangelogCommits: 2,
+			OmitVersion:         true,
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		"description\n\n### Changes\n\n"+
+			"- `0000000` Commit 0\n"+
+			"- `0000001` Commit 1\n"+
+			"- _...and 3 more_",
+		*created.Description,
+	)
+}
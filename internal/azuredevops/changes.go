@@ -0,0 +1,123 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// maxChangesPerPage is the maximum number of changes Azure DevOps will return
+// in a single page when listing the changes in a PR iteration.
+const maxChangesPerPage = 2000
+
+// GetPRChangedFiles returns the paths of all files changed by the specified
+// PR, as of its most recent iteration.
+func GetPRChangedFiles(
+	ctx context.Context,
+	repoURL string,
+	prID int,
+	creds gitutil.RepoCredentials,
+) (_ []string, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return nil, err
+	}
+	return getPRChangedFiles(ctx, repo, prID)
+}
+
+// getPRChangedFiles returns the paths of all files changed by the specified
+// PR, as of its most recent iteration.
+func getPRChangedFiles(
+	ctx context.Context,
+	repo *repoClient,
+	prID int,
+) ([]string, error) {
+	iterations, err := repo.client.GetPullRequestIterations(
+		ctx,
+		git.GetPullRequestIterationsArgs{
+			Project:       &repo.project,
+			RepositoryId:  &repo.id,
+			PullRequestId: &prID,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error listing iterations of pull request %d: %w",
+			prID,
+			err,
+		)
+	}
+	if iterations == nil || len(*iterations) == 0 {
+		return nil, nil
+	}
+	latest := (*iterations)[len(*iterations)-1].Id
+	if latest == nil {
+		return nil, fmt.Errorf("latest iteration of pull request %d has no ID", prID)
+	}
+
+	var files []string
+	top, skip := maxChangesPerPage, 0
+	for {
+		changes, err := repo.client.GetPullRequestIterationChanges(
+			ctx,
+			git.GetPullRequestIterationChangesArgs{
+				Project:       &repo.project,
+				RepositoryId:  &repo.id,
+				PullRequestId: &prID,
+				IterationId:   latest,
+				Top:           &top,
+				Skip:          &skip,
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error listing changes in pull request %d: %w",
+				prID,
+				err,
+			)
+		}
+		if changes == nil {
+			return nil, fmt.Errorf(
+				"%w: changes in pull request %d were not returned",
+				ErrIncompleteResponse,
+				prID,
+			)
+		}
+		if changes.ChangeEntries != nil {
+			for _, change := range *changes.ChangeEntries {
+				if path := changedPathOf(change); path != "" {
+					files = append(files, path)
+				}
+			}
+		}
+		if changes.NextSkip == nil || *changes.NextSkip == 0 {
+			return files, nil
+		}
+		skip = *changes.NextSkip
+	}
+}
+
+// changedPathOf returns the path of the item affected by the specified change
+// or an empty string if it cannot be determined. The item is untyped in the
+// Azure DevOps API, so it is inspected defensively.
+func changedPathOf(change git.GitPullRequestChange) string {
+	switch item := change.Item.(type) {
+	case map[string]any:
+		if path, ok := item["path"].(string); ok {
+			return path
+		}
+	case git.GitItem:
+		if item.Path != nil {
+			return *item.Path
+		}
+	case *git.GitItem:
+		if item != nil && item.Path != nil {
+			return *item.Path
+		}
+	}
+	return ""
+}
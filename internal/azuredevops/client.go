@@ -0,0 +1,406 @@
+package azuredevops
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	"github.com/akuity/kargo-render/internal/repourl"
+	"github.com/akuity/kargo-render/internal/transport"
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// newGitClient creates an Azure DevOps Git client. It is a package-level
+// variable so that it can be overridden in tests.
+var newGitClient = git.NewClient
+
+// defaultConnectTimeout is the default maximum amount of time permitted for
+// connecting to Azure DevOps and discovering its API locations.
+const defaultConnectTimeout = 30 * time.Second
+
+// ErrUnreachable is returned when Azure DevOps could not be reached within the
+// connection timeout.
+var ErrUnreachable = errors.New("could not reach Azure DevOps")
+
+// ConnectionOptions encapsulates optional settings for connecting to Azure
+// DevOps.
+type ConnectionOptions struct {
+	// AuthMode specifies how credentials are presented to Azure DevOps. When
+	// this is empty, AuthModePAT is used.
+	AuthMode AuthMode
+	// ConnectTimeout is the maximum amount of time permitted for connecting to
+	// Azure DevOps and discovering its API locations. When this is zero, a
+	// default of 30 seconds is used.
+	ConnectTimeout time.Duration
+	// Retry specifies how calls to the Azure DevOps API are bounded and
+	// retried. When this is nil, transport defaults are used. Calls that are not
+	// idempotent, including ones that timed out, are retried only when they were
+	// throttled and the policy's Retryable function, if any, also accepts them.
+	Retry *transport.Policy
+	// CacheTTL is the amount of time for which the results of repository and
+	// identity lookups are cached. When this is zero, a default of 5 minutes is
+	// used. When this is negative, results are not cached.
+	CacheTTL time.Duration
+	// OnRateLimit, when non-nil, is called with the rate limit information
+	// included in any response to a Git API request, so that callers can back
+	// off proactively before requests are throttled.
+	OnRateLimit func(RateLimit)
+	// RenameFallback, when non-nil, specifies how repositories that are not
+	// found by name, perhaps because they have been renamed, should be looked
+	// for instead. When this is nil, repositories must be referenced by their
+	// current names.
+	RenameFallback *RenameFallback
+	// TLS optionally specifies the TLS settings of connections to Azure DevOps.
+	// When this is nil, Go's defaults, which require at least TLS 1.2, are used.
+	// Note that the Azure DevOps SDK disregards proxy settings from the
+	// environment for API clients, other than the Git client, of connections
+	// with TLS settings.
+	TLS *TLSOptions
+	// BaseURL optionally specifies the base URL of the Azure DevOps API for the
+	// repository's organization, for instance, that of a gateway through which
+	// requests must be routed. When this is non-empty, it must be an absolute
+	// HTTPS URL and is used verbatim, regardless of the host in the repository's
+	// URL. When this is empty, https://dev.azure.com/<organization> is used or,
+	// for repositories hosted by Azure DevOps Server, the URL of the collection
+	// in the repository's URL, e.g. https://tfs.example.com/tfs/DefaultCollection
+	// for https://tfs.example.com/tfs/DefaultCollection/proj/_git/repo. Since
+	// credentials must not be sent in the clear, this must be specified for
+	// repositories whose URLs are HTTP URLs.
+	BaseURL string
+	// RepoDefaults are the organization and project against which short
+	// repository references, such as project/repo, are resolved. Repository URLs
+	// are unaffected by these.
+	RepoDefaults RepoDefaults
+	// RefreshStaleDiscovery specifies whether a connection should be created
+	// anew, rediscovering Azure DevOps' APIs, when a call fails because an API
+	// could not be found among those discovered earlier, as can happen in
+	// long-running processes when servers are upgraded or moved. The call is
+	// then made once more. Note that the Azure DevOps SDK caches the APIs
+	// available at each URL for the lifetime of the process, so this only
+	// helps if rediscovery yields a different URL.
+	RefreshStaleDiscovery bool
+	// pool, when non-nil, is used to share connections among operations.
+	pool *connectionPool
+	// repositoryID, when non-empty, is the already known ID of the repository,
+	// which therefore need not be looked up.
+	repositoryID string
+}
+
+// ErrInvalidBaseURL is returned when the base URL specified for the Azure
+// DevOps API, or that of the Azure DevOps Server collection in a repository's
+// URL, is not an absolute HTTPS URL.
+var ErrInvalidBaseURL = errors.New("invalid Azure DevOps base API URL")
+
+// organizationURL returns the base URL of the Azure DevOps API for the
+// specified organization. This is the specified base URL, if it is non-empty
+// and valid, or otherwise the specified Azure DevOps Server collection URL, if
+// it is non-empty. Collection URLs must be HTTPS URLs, so that credentials are
+// never sent in the clear.
+func organizationURL(organization string, collectionURL string, baseURL string) (string, error) {
+	if baseURL == "" {
+		if collectionURL != "" {
+			if !strings.HasPrefix(collectionURL, "https://") {
+				return "", fmt.Errorf(
+					"%w %q: Azure DevOps Server collections must be reached using HTTPS; "+
+						"specify an HTTPS base URL to reach the collection",
+					ErrInvalidBaseURL,
+					collectionURL,
+				)
+			}
+			return collectionURL, nil
+		}
+		return fmt.Sprintf("https://dev.azure.com/%s", organization), nil
+	}
+	u, err := url.Parse(baseURL)
+	switch {
+	case err != nil:
+		// The parse error is not wrapped, since it may include credentials
+		return "", fmt.Errorf("%w: could not be parsed", ErrInvalidBaseURL)
+	case u.Scheme != "https" || u.Host == "":
+		return "", fmt.Errorf(
+			"%w %q: must be an absolute HTTPS URL",
+			ErrInvalidBaseURL,
+			u.Redacted(),
+		)
+	case u.User != nil || u.RawQuery != "" || u.Fragment != "":
+		return "", fmt.Errorf(
+			"%w %q: must not include credentials, a query, or a fragment",
+			ErrInvalidBaseURL,
+			u.Redacted(),
+		)
+	}
+	return baseURL, nil
+}
+
+// ErrIncompleteResponse is returned when Azure DevOps responds to a request
+// without a field that is required to proceed.
+var ErrIncompleteResponse = errors.New("incomplete response from Azure DevOps")
+
+// ErrRepositoryDisabled is returned when the repository being interacted with
+// has been disabled in Azure DevOps.
+var ErrRepositoryDisabled = errors.New("repository is disabled")
+
+// repoClient bundles an Azure DevOps Git client with the coordinates of the
+// specific repository it is being used to interact with.
+type repoClient struct {
+	client     git.Client
+	connection *azuredevops.Connection
+	retry      *transport.Policy
+	cacheTTL   time.Duration
+	renames    *RenameFallback
+	org        string
+	project    string
+	name       string
+	id         string
+	repository *git.GitRepository
+	// idOnly indicates that the repository was not looked up because its ID
+	// was already known, so that repository holds nothing but the ID. Use
+	// details to get the rest.
+	idOnly bool
+}
+
+// newRepoClient connects to Azure DevOps and resolves the ID of the repository
+// referenced by the specified URL.
+func newRepoClient(
+	ctx context.Context,
+	repoURL string,
+	creds gitutil.RepoCredentials,
+	opts *ConnectionOptions,
+) (*repoClient, error) {
+	if opts == nil {
+		opts = &ConnectionOptions{}
+	}
+
+	// Parse Azure DevOps URL
+	repoURL, err := ExpandRepoRef(repoURL, opts.RepoDefaults)
+	if err != nil {
+		return nil, err
+	}
+	repoURL = repourl.Normalize(repoURL)
+	organization, project, repository, err := parseAzureDevOpsURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Connect to Azure DevOps, reusing a pooled connection if possible
+	orgURL, err := organizationURL(organization, serverCollectionURL(repoURL), opts.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	connection, err := newConnection(orgURL, creds, opts.AuthMode)
+	if err != nil {
+		return nil, err
+	}
+	if opts.TLS != nil {
+		if connection.TlsConfig, err = opts.TLS.config(); err != nil {
+			return nil, fmt.Errorf("error configuring TLS: %w", err)
+		}
+	}
+	connect := connect
+	if opts.pool != nil {
+		connect = opts.pool.get
+	}
+	connection, gitClient, err := connect(ctx, connection, opts.ConnectTimeout)
+	if err != nil {
+		return nil, err
+	}
+	var rediscover func(context.Context) (git.Client, error)
+	if opts.RefreshStaleDiscovery {
+		tlsConfig := connection.TlsConfig
+		rediscover = func(ctx context.Context) (git.Client, error) {
+			// A new connection discovers APIs afresh
+			fresh, err := newConnection(orgURL, creds, opts.AuthMode)
+			if err != nil {
+				return nil, err
+			}
+			fresh.TlsConfig = tlsConfig
+			client, err := connectGitClient(ctx, fresh, opts.ConnectTimeout)
+			if err != nil {
+				return nil, err
+			}
+			if opts.pool != nil {
+				opts.pool.replace(fresh, client)
+			}
+			return withTransport(client, tlsConfig, opts.OnRateLimit), nil
+		}
+	}
+	gitClient = withRetries(
+		withTransport(gitClient, connection.TlsConfig, opts.OnRateLimit),
+		opts.Retry,
+		rediscover,
+	)
+
+	// Get repository, unless its ID is already known, in which case the
+	// repository is not looked up at all
+	knownID, err := knownRepositoryID(repository, opts.repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	repo := &git.GitRepository{Id: knownID}
+	if knownID == nil {
+		if repo, err = cachedRepository(
+			ctx,
+			connection,
+			gitClient,
+			project,
+			repository,
+			opts.CacheTTL,
+			opts.RenameFallback,
+		); err != nil {
+			return nil, err
+		}
+		if repo.IsDisabled != nil && *repo.IsDisabled {
+			return nil, fmt.Errorf(
+				"%w: repository '%s' in project '%s'",
+				ErrRepositoryDisabled,
+				repository,
+				project,
+			)
+		}
+	}
+
+	return &repoClient{
+		client:     gitClient,
+		connection: connection,
+		retry:      opts.Retry,
+		cacheTTL:   opts.CacheTTL,
+		renames:    opts.RenameFallback,
+		org:        organization,
+		project:    project,
+		name:       repository,
+		id:         repo.Id.String(),
+		repository: repo,
+		idOnly:     knownID != nil,
+	}, nil
+}
+
+// withTransport arranges for the specified Git client to make requests using
+// its own transport, which uses the specified TLS configuration, if any,
+// reports expired credentials as ErrCredentialsExpired, and reports the rate
+// limit information included in every response to the specified observer, if
+// any. The specified client may be shared, so it is not modified; a copy is
+// returned instead. Clients other than those created by the Azure DevOps SDK
+// are returned unmodified.
+func withTransport(
+	client git.Client,
+	tlsConfig *tls.Config,
+	observe func(RateLimit),
+) git.Client {
+	impl, ok := client.(*git.ClientImpl)
+	if !ok {
+		return client
+	}
+	var rt http.RoundTripper = &expiredCredentialsTransport{
+		next: newTransport(tlsConfig),
+	}
+	if observe != nil {
+		rt = &rateLimitTransport{next: rt, observe: observe}
+	}
+	instrumented := *impl
+	azuredevops.WithHTTPClient(&http.Client{Transport: rt})(&instrumented.Client)
+	return &instrumented
+}
+
+// repositories is the process-wide cache of repositories looked up by name.
+var repositories = newTTLCache[*git.GitRepository]()
+
+// cachedRepository gets the specified repository from Azure DevOps. Successful
+// lookups are memoized for the specified TTL so that repeated operations
+// against the same repository need not list all of its project's repositories
+// each time. If the repository is not found, the specified rename fallback, if
+// any, is consulted.
+func cachedRepository(
+	ctx context.Context,
+	connection *azuredevops.Connection,
+	client git.Client,
+	project string,
+	repository string,
+	ttl time.Duration,
+	renames *RenameFallback,
+) (*git.GitRepository, error) {
+	return repositories.get(
+		connectionKey(connection, project, repository),
+		ttl,
+		func() (*git.GitRepository, error) {
+			return getRepository(ctx, client, project, repository, renames)
+		},
+	)
+}
+
+// getRepository gets the specified repository from Azure DevOps. If it is not
+// found, the specified rename fallback, if any, is consulted.
+func getRepository(
+	ctx context.Context,
+	client git.Client,
+	project string,
+	repository string,
+	renames *RenameFallback,
+) (*git.GitRepository, error) {
+	res, err := client.GetRepositories(ctx, git.GetRepositoriesArgs{
+		Project: &project,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing repositories: %w", err)
+	}
+	var repos []git.GitRepository
+	if res != nil {
+		repos = *res
+	}
+	repo := findRepository(repos, func(name string) bool { return name == repository })
+	if repo == nil {
+		repo = renames.find(repos, project, repository)
+	}
+	if repo == nil {
+		return nil, fmt.Errorf("repository '%s' not found in project '%s'", repository, project)
+	}
+	if repo.Id == nil {
+		return nil, fmt.Errorf(
+			"%w: repository '%s' in project '%s' has no ID",
+			ErrIncompleteResponse,
+			*repo.Name,
+			project,
+		)
+	}
+	return repo, nil
+}
+
+// connectGitClient creates a Git client using the specified connection. Client
+// creation involves API discovery, which can hang indefinitely against an
+// unreachable server, so it is bounded by the specified timeout. If the
+// timeout is zero, defaultConnectTimeout is used.
+func connectGitClient(
+	ctx context.Context,
+	connection *azuredevops.Connection,
+	timeout time.Duration,
+) (git.Client, error) {
+	if timeout == 0 {
+		timeout = defaultConnectTimeout
+	}
+	connectCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	gitClient, err := newGitClient(connectCtx, connection)
+	if err != nil {
+		if errors.Is(connectCtx.Err(), context.DeadlineExceeded) &&
+			ctx.Err() == nil {
+			return nil, fmt.Errorf(
+				"%w at %s within %s",
+				ErrUnreachable,
+				connection.BaseUrl,
+				timeout,
+			)
+		}
+		return nil, fmt.Errorf(
+			"error creating Azure DevOps Git client: %w",
+			asExpiredCredentials(err),
+		)
+	}
+	return gitClient, nil
+}
@@ -0,0 +1,191 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// CommentThread is a thread of comments on a PR.
+type CommentThread struct {
+	// ID is the ID of the thread.
+	ID int
+	// Status is the status of the thread, e.g. active or fixed. It is empty for
+	// threads, such as those recording system events, that have no status.
+	Status git.CommentThreadStatus
+	// FilePath is the path of the file the thread is about, if any.
+	FilePath string
+	// Comments are the thread's comments, excluding any that were deleted, in
+	// the order they were published.
+	Comments []Comment
+}
+
+// Comment is a single comment within a CommentThread.
+type Comment struct {
+	// ID is the ID of the comment. IDs are unique within a PR.
+	ID int
+	// ParentID is the ID of the comment this is a reply to, or zero if it is
+	// not a reply.
+	ParentID int
+	// AuthorID is the ID of the identity of the comment's author.
+	AuthorID string
+	// AuthorName is the display name of the comment's author.
+	AuthorName string
+	// Content is the comment's content, as markdown.
+	Content string
+	// Type is the type of the comment, e.g. text or system.
+	Type git.CommentType
+	// Published is when the comment was first published.
+	Published time.Time
+}
+
+// ListPRComments returns all comment threads on the specified PR, including
+// their authors and content. This permits, for instance, automation triggered
+// by reviewers leaving comments such as "/retry". Azure DevOps returns all of
+// a PR's threads at once, so no pagination is necessary.
+func ListPRComments(
+	ctx context.Context,
+	repoURL string,
+	prID int,
+	creds gitutil.RepoCredentials,
+) (_ []CommentThread, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return nil, err
+	}
+	threads, err := repo.client.GetThreads(ctx, git.GetThreadsArgs{
+		Project:       &repo.project,
+		RepositoryId:  &repo.id,
+		PullRequestId: &prID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing comments on pull request %d: %w", prID, err)
+	}
+	if threads == nil {
+		return nil, nil
+	}
+	res := make([]CommentThread, 0, len(*threads))
+	for _, thread := range *threads {
+		if thread.IsDeleted != nil && *thread.IsDeleted {
+			continue
+		}
+		res = append(res, commentThreadOf(thread))
+	}
+	return res, nil
+}
+
+// commentThreadOf converts the specified thread, as returned by Azure DevOps,
+// to a CommentThread.
+func commentThreadOf(thread git.GitPullRequestCommentThread) CommentThread {
+	t := CommentThread{}
+	if thread.Id != nil {
+		t.ID = *thread.Id
+	}
+	if thread.Status != nil {
+		t.Status = *thread.Status
+	}
+	if thread.ThreadContext != nil && thread.ThreadContext.FilePath != nil {
+		t.FilePath = *thread.ThreadContext.FilePath
+	}
+	if thread.Comments == nil {
+		return t
+	}
+	for _, comment := range *thread.Comments {
+		if comment.IsDeleted != nil && *comment.IsDeleted {
+			continue
+		}
+		c := Comment{}
+		if comment.Id != nil {
+			c.ID = *comment.Id
+		}
+		if comment.ParentCommentId != nil {
+			c.ParentID = *comment.ParentCommentId
+		}
+		if comment.Author != nil {
+			if comment.Author.Id != nil {
+				c.AuthorID = *comment.Author.Id
+			}
+			if comment.Author.DisplayName != nil {
+				c.AuthorName = *comment.Author.DisplayName
+			}
+		}
+		if comment.Content != nil {
+			c.Content = *comment.Content
+		}
+		if comment.CommentType != nil {
+			c.Type = *comment.CommentType
+		}
+		if comment.PublishedDate != nil {
+			c.Published = comment.PublishedDate.Time
+		}
+		t.Comments = append(t.Comments, c)
+	}
+	return t
+}
+
+// resolveOwnThreads sets the status of each of the specified PR's active or
+// pending comment threads that was started by the identity associated with the
+// repository's connection to the specified status, or to fixed if none is
+// specified. System threads, such as those recording votes, are left alone. The
+// IDs of the resolved threads are returned.
+func resolveOwnThreads(
+	ctx context.Context,
+	repo *repoClient,
+	prID int,
+	status git.CommentThreadStatus,
+) ([]int, error) {
+	if status == "" {
+		status = git.CommentThreadStatusValues.Fixed
+	}
+	self, err := getAuthenticatedIdentityID(ctx, repo.connection, repo.cacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving identity associated with credentials: %w", err)
+	}
+	threads, err := repo.client.GetThreads(ctx, git.GetThreadsArgs{
+		Project:       &repo.project,
+		RepositoryId:  &repo.id,
+		PullRequestId: &prID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing comments on pull request %d: %w", prID, err)
+	}
+	if threads == nil {
+		return nil, nil
+	}
+	var resolved []int
+	for _, thread := range *threads {
+		if thread.IsDeleted != nil && *thread.IsDeleted {
+			continue
+		}
+		t := commentThreadOf(thread)
+		if !isOpenThread(t) || len(t.Comments) == 0 ||
+			t.Comments[0].Type == git.CommentTypeValues.System ||
+			!strings.EqualFold(t.Comments[0].AuthorID, self) {
+			continue
+		}
+		if _, err = repo.client.UpdateThread(ctx, git.UpdateThreadArgs{
+			Project:       &repo.project,
+			RepositoryId:  &repo.id,
+			PullRequestId: &prID,
+			ThreadId:      &t.ID,
+			CommentThread: &git.GitPullRequestCommentThread{Status: &status},
+		}); err != nil {
+			return resolved, fmt.Errorf("error resolving thread %d: %w", t.ID, err)
+		}
+		resolved = append(resolved, t.ID)
+	}
+	return resolved, nil
+}
+
+// isOpenThread returns a bool indicating whether the specified thread awaits
+// resolution.
+func isOpenThread(thread CommentThread) bool {
+	return thread.Status == git.CommentThreadStatusValues.Active ||
+		thread.Status == git.CommentThreadStatusValues.Pending
+}
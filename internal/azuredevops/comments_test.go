@@ -0,0 +1,272 @@
+package azuredevops
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestListPRComments(t *testing.T) {
+	published := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	alice := &webapi.IdentityRef{Id: ptr("alice-id"), DisplayName: ptr("Alice")}
+	bob := &webapi.IdentityRef{Id: ptr("bob-id"), DisplayName: ptr("Bob")}
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: fakeRepos("repo"),
+		getThreadsFn: func(
+			_ context.Context,
+			args git.GetThreadsArgs,
+		) (*[]git.GitPullRequestCommentThread, error) {
+			require.Equal(t, 42, *args.PullRequestId)
+			return &[]git.GitPullRequestCommentThread{
+				{
+					Id:     ptr(1),
+					Status: &git.CommentThreadStatusValues.Active,
+					Comments: &[]git.Comment{
+						{
+							Id:            ptr(1),
+							Author:        alice,
+							Content:       ptr("/retry"),
+							CommentType:   &git.CommentTypeValues.Text,
+							PublishedDate: &azuredevops.Time{Time: published},
+						},
+						{
+							Id:              ptr(2),
+							ParentCommentId: ptr(1),
+							Author:          bob,
+							Content:         ptr("On it"),
+							CommentType:     &git.CommentTypeValues.Text,
+						},
+						{
+							Id:        ptr(3),
+							Author:    bob,
+							Content:   ptr("never mind"),
+							IsDeleted: ptr(true),
+						},
+					},
+				},
+				{
+					Id:            ptr(2),
+					Status:        &git.CommentThreadStatusValues.Fixed,
+					ThreadContext: &git.CommentThreadContext{FilePath: ptr("/env/dev/app.yaml")},
+					Comments: &[]git.Comment{{
+						Id:      ptr(4),
+						Author:  bob,
+						Content: ptr("Typo here"),
+					}},
+				},
+				{
+					Id:        ptr(3),
+					IsDeleted: ptr(true),
+					Comments:  &[]git.Comment{{Id: ptr(5), Content: ptr("deleted")}},
+				},
+				{
+					Id: ptr(4),
+					Comments: &[]git.Comment{{
+						Id:          ptr(6),
+						Content:     ptr("Alice voted 10"),
+						CommentType: &git.CommentTypeValues.System,
+					}},
+				},
+			}, nil
+		},
+	})
+	threads, err := ListPRComments(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		42,
+		gitutil.RepoCredentials{Password: "token"},
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		[]CommentThread{
+			{
+				ID:     1,
+				Status: git.CommentThreadStatusValues.Active,
+				Comments: []Comment{
+					{
+						ID:         1,
+						AuthorID:   "alice-id",
+						AuthorName: "Alice",
+						Content:    "/retry",
+						Type:       git.CommentTypeValues.Text,
+						Published:  published,
+					},
+					{
+						ID:         2,
+						ParentID:   1,
+						AuthorID:   "bob-id",
+						AuthorName: "Bob",
+						Content:    "On it",
+						Type:       git.CommentTypeValues.Text,
+					},
+				},
+			},
+			{
+				ID:       2,
+				Status:   git.CommentThreadStatusValues.Fixed,
+				FilePath: "/env/dev/app.yaml",
+				Comments: []Comment{{
+					ID:         4,
+					AuthorID:   "bob-id",
+					AuthorName: "Bob",
+					Content:    "Typo here",
+				}},
+			},
+			{
+				ID: 4,
+				Comments: []Comment{{
+					ID:      6,
+					Content: "Alice voted 10",
+					Type:    git.CommentTypeValues.System,
+				}},
+			},
+		},
+		threads,
+	)
+}
+
+func TestOpenPRResolveOwnThreads(t *testing.T) {
+	self := uuid.New()
+	own := &webapi.IdentityRef{Id: ptr(strings.ToUpper(self.String()))}
+	other := &webapi.IdentityRef{Id: ptr("reviewer-id")}
+	threads := []git.GitPullRequestCommentThread{
+		{
+			Id:       ptr(1),
+			Status:   &git.CommentThreadStatusValues.Active,
+			Comments: &[]git.Comment{{Author: own, Content: ptr("Drift detected")}},
+		},
+		{
+			Id:       ptr(2),
+			Status:   &git.CommentThreadStatusValues.Active,
+			Comments: &[]git.Comment{{Author: other, Content: ptr("Looks off")}},
+		},
+		{
+			Id:       ptr(3),
+			Status:   &git.CommentThreadStatusValues.Fixed,
+			Comments: &[]git.Comment{{Author: own, Content: ptr("Already resolved")}},
+		},
+		{
+			Id:     ptr(4),
+			Status: &git.CommentThreadStatusValues.Active,
+			Comments: &[]git.Comment{{
+				Author:      own,
+				Content:     ptr("Policy status updated"),
+				CommentType: &git.CommentTypeValues.System,
+			}},
+		},
+		{
+			Id:     ptr(5),
+			Status: &git.CommentThreadStatusValues.Pending,
+			Comments: &[]git.Comment{
+				{Author: own, Content: ptr("Full description")},
+				{Author: other, Content: ptr("Thanks")},
+			},
+		},
+	}
+	testCases := []struct {
+		name       string
+		opts       OpenPROptions
+		assertions func(t *testing.T, updated map[int]git.CommentThreadStatus, err error)
+	}{
+		{
+			name: "threads left alone by default",
+			assertions: func(t *testing.T, updated map[int]git.CommentThreadStatus, err error) {
+				require.NoError(t, err)
+				require.Empty(t, updated)
+			},
+		},
+		{
+			name: "own threads resolved as fixed",
+			opts: OpenPROptions{ResolveOwnThreads: true},
+			assertions: func(t *testing.T, updated map[int]git.CommentThreadStatus, err error) {
+				require.NoError(t, err)
+				require.Equal(
+					t,
+					map[int]git.CommentThreadStatus{
+						1: git.CommentThreadStatusValues.Fixed,
+						5: git.CommentThreadStatusValues.Fixed,
+					},
+					updated,
+				)
+			},
+		},
+		{
+			name: "own threads resolved with configured status",
+			opts: OpenPROptions{
+				ResolveOwnThreads:    true,
+				ResolvedThreadStatus: git.CommentThreadStatusValues.Closed,
+			},
+			assertions: func(t *testing.T, updated map[int]git.CommentThreadStatus, err error) {
+				require.NoError(t, err)
+				require.Equal(
+					t,
+					map[int]git.CommentThreadStatus{
+						1: git.CommentThreadStatusValues.Closed,
+						5: git.CommentThreadStatusValues.Closed,
+					},
+					updated,
+				)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			updated := map[int]git.CommentThreadStatus{}
+			useFakeIdentity(t, self)
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				createPullRequestFn: func(
+					context.Context,
+					git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					return nil, azuredevops.WrappedError{StatusCode: ptr(http.StatusConflict)}
+				},
+				getPullRequestsFn: func(
+					context.Context,
+					git.GetPullRequestsArgs,
+				) (*[]git.GitPullRequest, error) {
+					return &[]git.GitPullRequest{{PullRequestId: ptr(42)}}, nil
+				},
+				getThreadsFn: func(
+					_ context.Context,
+					args git.GetThreadsArgs,
+				) (*[]git.GitPullRequestCommentThread, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					return &threads, nil
+				},
+				updateThreadFn: func(
+					_ context.Context,
+					args git.UpdateThreadArgs,
+				) (*git.GitPullRequestCommentThread, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					updated[*args.ThreadId] = *args.CommentThread.Status
+					return args.CommentThread, nil
+				},
+			})
+			opts := testCase.opts
+			url, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "token"},
+				&opts,
+			)
+			require.Empty(t, url)
+			testCase.assertions(t, updated, err)
+		})
+	}
+}
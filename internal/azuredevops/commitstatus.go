@@ -0,0 +1,128 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// CommitStatusContext is the context of the status OpenPR sets on the source
+// commit of any PR it opens when OpenPROptions.SetCommitStatus is true.
+const CommitStatusContext = "kargo-render"
+
+// SetCommitStatus sets a status with the specified state and context on the
+// specified commit of the specified repository. The context identifies the
+// status, so that a later status with the same context supersedes it. It may
+// be qualified with a genre, as in genre/name, in which case the last segment
+// is used as the status' name and the remainder as its genre.
+func SetCommitStatus(
+	ctx context.Context,
+	repoURL string,
+	commitSHA string,
+	state git.GitStatusState,
+	statusContext string,
+	creds gitutil.RepoCredentials,
+) (err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return err
+	}
+	return setCommitStatus(ctx, repo, commitSHA, git.GitStatus{State: &state}, statusContext)
+}
+
+// setCommitStatus sets the specified status, with the specified context, on
+// the specified commit of the repository.
+func setCommitStatus(
+	ctx context.Context,
+	repo *repoClient,
+	commitSHA string,
+	status git.GitStatus,
+	statusContext string,
+) error {
+	if commitSHA == "" {
+		return errors.New("a commit is required")
+	}
+	var err error
+	if status.Context, err = commitStatusContextOf(statusContext); err != nil {
+		return err
+	}
+	if _, err = repo.client.CreateCommitStatus(ctx, git.CreateCommitStatusArgs{
+		Project:                 &repo.project,
+		RepositoryId:            &repo.id,
+		CommitId:                &commitSHA,
+		GitCommitStatusToCreate: &status,
+	}); err != nil {
+		return fmt.Errorf("error setting status %q on commit %s: %w", statusContext, commitSHA, err)
+	}
+	return nil
+}
+
+// commitStatusContextOf returns the Azure DevOps representation of the
+// specified status context, which may be qualified with a genre.
+func commitStatusContextOf(statusContext string) (*git.GitStatusContext, error) {
+	genre, name := "", statusContext
+	if i := strings.LastIndex(statusContext, "/"); i >= 0 {
+		genre, name = statusContext[:i], statusContext[i+1:]
+	}
+	if name == "" {
+		return nil, fmt.Errorf("status context %q has no name", statusContext)
+	}
+	statusCtx := &git.GitStatusContext{Name: &name}
+	if genre != "" {
+		statusCtx.Genre = &genre
+	}
+	return statusCtx, nil
+}
+
+// setPRCommitStatus sets a pending status, with context CommitStatusContext
+// and linking to the specified newly opened PR in the specified repository, on
+// the PR's source commit, which is found in the specified source repository
+// and branch.
+func setPRCommitStatus(
+	ctx context.Context,
+	sourceRepo *repoClient,
+	sourceBranch string,
+	repoURL string,
+	pr *git.GitPullRequest,
+) error {
+	var commitSHA string
+	if pr.LastMergeSourceCommit != nil && pr.LastMergeSourceCommit.CommitId != nil {
+		commitSHA = *pr.LastMergeSourceCommit.CommitId
+	} else {
+		// Azure DevOps may not have resolved the source commit of a PR it only
+		// just created
+		ref, err := getRef(ctx, sourceRepo, sourceBranch)
+		if err != nil {
+			return err
+		}
+		if ref != nil && ref.ObjectId != nil {
+			commitSHA = *ref.ObjectId
+		}
+	}
+	if commitSHA == "" {
+		return fmt.Errorf(
+			"%w: source commit of pull request %d could not be determined",
+			ErrIncompleteResponse,
+			*pr.PullRequestId,
+		)
+	}
+	description := fmt.Sprintf("Pull request %d is open", *pr.PullRequestId)
+	targetURL := prWebURL(repoURL, *pr.PullRequestId)
+	return setCommitStatus(
+		ctx,
+		sourceRepo,
+		commitSHA,
+		git.GitStatus{
+			State:       &git.GitStatusStateValues.Pending,
+			Description: &description,
+			TargetUrl:   &targetURL,
+		},
+		CommitStatusContext,
+	)
+}
@@ -0,0 +1,155 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestSetCommitStatus(t *testing.T) {
+	testCases := []struct {
+		name          string
+		commitSHA     string
+		statusContext string
+		assertions    func(t *testing.T, status *git.GitStatus, err error)
+	}{
+		{
+			name:          "context without genre",
+			commitSHA:     "abc123",
+			statusContext: "kargo-render",
+			assertions: func(t *testing.T, status *git.GitStatus, err error) {
+				require.NoError(t, err)
+				require.Equal(t, git.GitStatusStateValues.Succeeded, *status.State)
+				require.Equal(t, "kargo-render", *status.Context.Name)
+				require.Nil(t, status.Context.Genre)
+			},
+		},
+		{
+			name:          "context with genre",
+			commitSHA:     "abc123",
+			statusContext: "akuity/kargo-render",
+			assertions: func(t *testing.T, status *git.GitStatus, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "kargo-render", *status.Context.Name)
+				require.Equal(t, "akuity", *status.Context.Genre)
+			},
+		},
+		{
+			name:          "context without name",
+			commitSHA:     "abc123",
+			statusContext: "akuity/",
+			assertions: func(t *testing.T, status *git.GitStatus, err error) {
+				require.ErrorContains(t, err, "has no name")
+				require.Nil(t, status)
+			},
+		},
+		{
+			name:          "no commit",
+			statusContext: "kargo-render",
+			assertions: func(t *testing.T, status *git.GitStatus, err error) {
+				require.ErrorContains(t, err, "a commit is required")
+				require.Nil(t, status)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var status *git.GitStatus
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				createCommitStatusFn: func(
+					_ context.Context,
+					args git.CreateCommitStatusArgs,
+				) (*git.GitStatus, error) {
+					require.Equal(t, testCase.commitSHA, *args.CommitId)
+					status = args.GitCommitStatusToCreate
+					return status, nil
+				},
+			})
+			err := SetCommitStatus(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				testCase.commitSHA,
+				git.GitStatusStateValues.Succeeded,
+				testCase.statusContext,
+				gitutil.RepoCredentials{Password: "token"},
+			)
+			testCase.assertions(t, status, err)
+		})
+	}
+}
+
+func TestOpenPRSetCommitStatus(t *testing.T) {
+	testCases := []struct {
+		name         string
+		sourceCommit *git.GitCommitRef
+	}{
+		{
+			name:         "source commit returned",
+			sourceCommit: &git.GitCommitRef{CommitId: ptr("abc123")},
+		},
+		{
+			name: "source commit resolved from branch",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var status *git.GitStatus
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				createPullRequestFn: func(
+					ctx context.Context,
+					args git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					pr, err := fakeCreatePullRequest(nil)(ctx, args)
+					pr.LastMergeSourceCommit = testCase.sourceCommit
+					return pr, err
+				},
+				getRefsFn: func(
+					_ context.Context,
+					args git.GetRefsArgs,
+				) (*git.GetRefsResponseValue, error) {
+					require.Equal(t, "heads/prs/kargo-render/env/dev", *args.Filter)
+					return &git.GetRefsResponseValue{
+						Value: []git.GitRef{{
+							Name:     ptr("refs/heads/prs/kargo-render/env/dev"),
+							ObjectId: ptr("abc123"),
+						}},
+					}, nil
+				},
+				createCommitStatusFn: func(
+					_ context.Context,
+					args git.CreateCommitStatusArgs,
+				) (*git.GitStatus, error) {
+					require.Equal(t, "abc123", *args.CommitId)
+					status = args.GitCommitStatusToCreate
+					return status, nil
+				},
+			})
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "token"},
+				&OpenPROptions{SetCommitStatus: true},
+			)
+			require.NoError(t, err)
+			require.NotNil(t, status)
+			require.Equal(t, git.GitStatusStateValues.Pending, *status.State)
+			require.Equal(t, CommitStatusContext, *status.Context.Name)
+			require.Equal(t, "Pull request 42 is open", *status.Description)
+			require.Equal(
+				t,
+				"https://dev.azure.com/org/proj/_git/repo/pullrequest/42",
+				*status.TargetUrl,
+			)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// ErrMergeFailed is returned when Azure DevOps was unable to merge a PR that
+// was to be completed.
+var ErrMergeFailed = errors.New("pull request could not be merged")
+
+// completionPollInterval is the amount of time to wait between checks of
+// whether a PR has been completed. It is a package-level variable so that it
+// can be overridden in tests.
+var completionPollInterval = 2 * time.Second
+
+// MergeFailedError describes Azure DevOps' failure to merge a PR that was to
+// be completed. It unwraps to ErrMergeFailed.
+type MergeFailedError struct {
+	// PRID is the ID of the PR.
+	PRID int
+	// Check is the result of the failed merge attempt.
+	Check MergeCheck
+}
+
+func (m *MergeFailedError) Error() string {
+	msg := fmt.Sprintf(
+		"%v: merge status of pull request %d is %q",
+		ErrMergeFailed,
+		m.PRID,
+		m.Check.Status,
+	)
+	if m.Check.FailureMessage != "" {
+		msg += ": " + m.Check.FailureMessage
+	}
+	return msg
+}
+
+func (m *MergeFailedError) Unwrap() error {
+	return ErrMergeFailed
+}
+
+// mergeFailureStatuses are the merge statuses of a PR from which Azure DevOps
+// will not proceed to complete it.
+var mergeFailureStatuses = map[git.PullRequestAsyncStatus]struct{}{
+	git.PullRequestAsyncStatusValues.Conflicts:        {},
+	git.PullRequestAsyncStatusValues.Failure:          {},
+	git.PullRequestAsyncStatusValues.RejectedByPolicy: {},
+}
+
+// CompleteAndWait completes the specified PR, using the specified settings,
+// and waits for Azure DevOps to merge it, polling the PR for up to the
+// specified amount of time. If timeout is zero, polling continues until the
+// context is canceled. It returns the ID (sha) of the resulting merge commit.
+// If the merge fails, for instance, because of conflicts, a *MergeFailedError
+// is returned. If the PR is abandoned meanwhile, an error wrapping
+// ErrPRNotActive is returned. If the PR has not been merged in time, the
+// error wraps context.DeadlineExceeded, and the PR is left to be completed by
+// Azure DevOps. Linked work items are only moved to the settings' iteration
+// and area paths, if any, once the PR has been merged; if moving them fails,
+// the merge commit is returned along with the error. The settings'
+// EmptyPRPolicy is not honored.
+func CompleteAndWait(
+	ctx context.Context,
+	repoURL string,
+	prID int,
+	opts AutoCompleteOptions,
+	timeout time.Duration,
+	creds gitutil.RepoCredentials,
+) (_ string, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return "", err
+	}
+	pr, err := getActivePR(ctx, repo, prID)
+	if err != nil {
+		return "", err
+	}
+	if _, err = repo.client.UpdatePullRequest(ctx, git.UpdatePullRequestArgs{
+		Project:       &repo.project,
+		RepositoryId:  &repo.id,
+		PullRequestId: &prID,
+		GitPullRequestToUpdate: &git.GitPullRequest{
+			Status:                &git.PullRequestStatusValues.Completed,
+			LastMergeSourceCommit: pr.LastMergeSourceCommit,
+			CompletionOptions:     completionOptionsOf(opts),
+		},
+	}); err != nil {
+		return "", fmt.Errorf("error completing pull request %d: %w", prID, err)
+	}
+	if pr, err = awaitCompletion(ctx, repo, prID); err != nil {
+		return "", err
+	}
+	mergeCommit, err := mergeCommitOf(pr)
+	if err != nil {
+		return "", err
+	}
+	if err = moveLinkedWorkItems(ctx, repo, prID, opts); err != nil {
+		return mergeCommit, fmt.Errorf(
+			"pull request %d was merged, but an error occurred moving its linked work items: %w",
+			prID,
+			err,
+		)
+	}
+	return mergeCommit, nil
+}
+
+// awaitCompletion polls the specified PR until Azure DevOps has completed it
+// and returns the completed PR. If the merge fails, a *MergeFailedError is
+// returned. If the PR is abandoned meanwhile, an error wrapping ErrPRNotActive
+// is returned. Polling continues until the context is canceled.
+func awaitCompletion(ctx context.Context, repo *repoClient, prID int) (*git.GitPullRequest, error) {
+	for {
+		pr, err := repo.client.GetPullRequest(ctx, git.GetPullRequestArgs{
+			Project:       &repo.project,
+			RepositoryId:  &repo.id,
+			PullRequestId: &prID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error getting pull request %d: %w", prID, err)
+		}
+		if pr == nil {
+			return nil, fmt.Errorf(
+				"%w: pull request %d was not returned",
+				ErrIncompleteResponse,
+				prID,
+			)
+		}
+		if pr.Status != nil {
+			switch *pr.Status {
+			case git.PullRequestStatusValues.Completed:
+				return pr, nil
+			case git.PullRequestStatusValues.Abandoned:
+				return nil, fmt.Errorf("%w: pull request %d was abandoned", ErrPRNotActive, prID)
+			}
+		}
+		if check := mergeCheckOf(pr); isMergeFailure(check) {
+			return nil, &MergeFailedError{PRID: prID, Check: check}
+		}
+		select {
+		case <-time.After(completionPollInterval):
+		case <-ctx.Done():
+			return nil, fmt.Errorf(
+				"error waiting for completion of pull request %d: %w",
+				prID,
+				ctx.Err(),
+			)
+		}
+	}
+}
+
+// isMergeFailure returns a bool indicating whether the specified merge check
+// reports a failure from which Azure DevOps will not proceed to complete a PR.
+func isMergeFailure(check MergeCheck) bool {
+	_, ok := mergeFailureStatuses[check.Status]
+	return ok
+}
@@ -0,0 +1,168 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestCompleteAndWait(t *testing.T) {
+	origInterval := completionPollInterval
+	completionPollInterval = 0
+	t.Cleanup(func() { completionPollInterval = origInterval })
+
+	active := git.GitPullRequest{
+		PullRequestId:         ptr(42),
+		Status:                &git.PullRequestStatusValues.Active,
+		MergeStatus:           &git.PullRequestAsyncStatusValues.Succeeded,
+		LastMergeSourceCommit: &git.GitCommitRef{CommitId: ptr("source")},
+	}
+	queued := active
+	queued.MergeStatus = &git.PullRequestAsyncStatusValues.Queued
+	testCases := []struct {
+		name string
+		// polled are the states of the PR reported after it is completed, in
+		// order. The last is repeated indefinitely.
+		polled     []git.GitPullRequest
+		timeout    time.Duration
+		assertions func(t *testing.T, commit string, err error)
+	}{
+		{
+			name: "merged",
+			polled: []git.GitPullRequest{
+				queued,
+				{
+					PullRequestId:   ptr(42),
+					Status:          &git.PullRequestStatusValues.Completed,
+					MergeStatus:     &git.PullRequestAsyncStatusValues.Succeeded,
+					LastMergeCommit: &git.GitCommitRef{CommitId: ptr("merge")},
+				},
+			},
+			assertions: func(t *testing.T, commit string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "merge", commit)
+			},
+		},
+		{
+			name: "merge conflicts",
+			polled: []git.GitPullRequest{
+				queued,
+				{
+					PullRequestId:       ptr(42),
+					Status:              &git.PullRequestStatusValues.Active,
+					MergeStatus:         &git.PullRequestAsyncStatusValues.Conflicts,
+					MergeFailureMessage: ptr("conflicts in app.yaml"),
+				},
+			},
+			assertions: func(t *testing.T, commit string, err error) {
+				require.ErrorIs(t, err, ErrMergeFailed)
+				var mergeErr *MergeFailedError
+				require.ErrorAs(t, err, &mergeErr)
+				require.Equal(t, 42, mergeErr.PRID)
+				require.True(t, mergeErr.Check.HasConflicts())
+				require.Contains(t, err.Error(), "conflicts in app.yaml")
+				require.Empty(t, commit)
+			},
+		},
+		{
+			name: "abandoned",
+			polled: []git.GitPullRequest{
+				{
+					PullRequestId: ptr(42),
+					Status:        &git.PullRequestStatusValues.Abandoned,
+				},
+			},
+			assertions: func(t *testing.T, _ string, err error) {
+				require.ErrorIs(t, err, ErrPRNotActive)
+			},
+		},
+		{
+			name:    "timeout",
+			polled:  []git.GitPullRequest{queued},
+			timeout: 20 * time.Millisecond,
+			assertions: func(t *testing.T, _ string, err error) {
+				require.ErrorIs(t, err, context.DeadlineExceeded)
+				require.ErrorContains(t, err, "error waiting for completion")
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var updated *git.GitPullRequest
+			var polls int
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPullRequestFn: func(
+					context.Context,
+					git.GetPullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					if updated == nil {
+						pr := active
+						return &pr, nil
+					}
+					pr := testCase.polled[min(polls, len(testCase.polled)-1)]
+					polls++
+					return &pr, nil
+				},
+				updatePullRequestFn: func(
+					_ context.Context,
+					args git.UpdatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					updated = args.GitPullRequestToUpdate
+					return updated, nil
+				},
+			})
+			commit, err := CompleteAndWait(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				42,
+				AutoCompleteOptions{
+					MergeStrategy:      git.GitPullRequestMergeStrategyValues.Squash,
+					DeleteSourceBranch: true,
+				},
+				testCase.timeout,
+				gitutil.RepoCredentials{Password: "token"},
+			)
+			require.NotNil(t, updated)
+			require.Equal(t, git.PullRequestStatusValues.Completed, *updated.Status)
+			require.Equal(t, "source", *updated.LastMergeSourceCommit.CommitId)
+			require.Equal(
+				t,
+				git.GitPullRequestMergeStrategyValues.Squash,
+				*updated.CompletionOptions.MergeStrategy,
+			)
+			require.True(t, *updated.CompletionOptions.DeleteSourceBranch)
+			testCase.assertions(t, commit, err)
+		})
+	}
+}
+
+func TestCompleteAndWaitInactivePR(t *testing.T) {
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: fakeRepos("repo"),
+		getPullRequestFn: func(
+			context.Context,
+			git.GetPullRequestArgs,
+		) (*git.GitPullRequest, error) {
+			return &git.GitPullRequest{
+				PullRequestId: ptr(42),
+				Status:        &git.PullRequestStatusValues.Completed,
+			}, nil
+		},
+	})
+	_, err := CompleteAndWait(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		42,
+		AutoCompleteOptions{},
+		0,
+		gitutil.RepoCredentials{Password: "token"},
+	)
+	require.ErrorIs(t, err, ErrPRNotActive)
+}
@@ -0,0 +1,81 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+)
+
+// ConflictPolicy specifies how to proceed when Azure DevOps responds to a
+// request to create a PR with 409 Conflict.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyReuseExisting looks for an active PR from the source branch
+	// to the target branch, which is the usual cause of a conflict, and, if one
+	// is found, proceeds as if it had been found before attempting to create a
+	// PR. If none is found, an error wrapping ErrConflict is returned. This is
+	// the default.
+	ConflictPolicyReuseExisting ConflictPolicy = "reuseExisting"
+	// ConflictPolicyError returns an error wrapping ErrConflict without looking
+	// for an existing PR.
+	ConflictPolicyError ConflictPolicy = "error"
+)
+
+// ErrConflict is returned when Azure DevOps refuses to create a PR due to a
+// conflict that could not be attributed to an existing PR.
+var ErrConflict = errors.New("conflict creating pull request")
+
+// isConflict returns a bool indicating whether the specified error is a 409
+// Conflict response from Azure DevOps.
+func isConflict(err error) bool {
+	code, ok := statusCodeOf(err)
+	return ok && code == http.StatusConflict
+}
+
+// resolveConflict classifies the specified error, returned in response to a
+// request to create a PR from the source branch to the target branch, that
+// Azure DevOps reported as a conflict. It returns the existing PR responsible
+// for the conflict if the specified policy permits looking for one and one is
+// found. Otherwise, it returns an error wrapping ErrConflict as well as the
+// specified error.
+func resolveConflict(
+	ctx context.Context,
+	repo *repoClient,
+	sourceBranch string,
+	targetBranch string,
+	policy ConflictPolicy,
+	createErr error,
+) (*git.GitPullRequest, error) {
+	switch policy {
+	case "", ConflictPolicyReuseExisting:
+	case ConflictPolicyError:
+		return nil, fmt.Errorf("%w: %w", ErrConflict, createErr)
+	default:
+		return nil, fmt.Errorf("unknown conflict policy %q", policy)
+	}
+	prs, err := listActivePRs(ctx, repo, sourceBranch, targetBranch)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf(
+			"%w: %w; additionally, an error occurred looking for an existing "+
+				"pull request: %w",
+			ErrConflict,
+			createErr,
+			err,
+		)
+	case len(prs) == 0:
+		return nil, fmt.Errorf(
+			"%w: no active pull request from %q to %q exists, so either ref may "+
+				"have changed or be in an unexpected state: %w",
+			ErrConflict,
+			sourceBranch,
+			targetBranch,
+			createErr,
+		)
+	}
+	return &prs[0], nil
+}
@@ -0,0 +1,113 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestOpenPRConflict(t *testing.T) {
+	conflict := &azuredevops.WrappedError{
+		StatusCode: ptr(http.StatusConflict),
+		Message:    ptr("TF401179: An active pull request already exists"),
+	}
+	testCases := []struct {
+		name       string
+		policy     ConflictPolicy
+		createErr  error
+		existing   []git.GitPullRequest
+		assertions func(t *testing.T, url string, lookups int, err error)
+	}{
+		{
+			name:      "existing PR is reused",
+			createErr: conflict,
+			existing:  []git.GitPullRequest{{PullRequestId: ptr(7)}},
+			assertions: func(t *testing.T, url string, lookups int, err error) {
+				require.NoError(t, err)
+				require.Empty(t, url)
+				require.Equal(t, 1, lookups)
+			},
+		},
+		{
+			name: "ref conflict",
+			createErr: azuredevops.WrappedError{
+				StatusCode: ptr(http.StatusConflict),
+				Message:    ptr("TF401028: The reference has already been updated"),
+			},
+			assertions: func(t *testing.T, url string, lookups int, err error) {
+				require.ErrorIs(t, err, ErrConflict)
+				require.ErrorContains(t, err, "no active pull request from")
+				require.ErrorContains(t, err, "TF401028")
+				require.Empty(t, url)
+				require.Equal(t, 1, lookups)
+			},
+		},
+		{
+			name:      "lookup disabled by policy",
+			policy:    ConflictPolicyError,
+			createErr: conflict,
+			existing:  []git.GitPullRequest{{PullRequestId: ptr(7)}},
+			assertions: func(t *testing.T, _ string, lookups int, err error) {
+				require.ErrorIs(t, err, ErrConflict)
+				var wrapped *azuredevops.WrappedError
+				require.ErrorAs(t, err, &wrapped)
+				require.Zero(t, lookups)
+			},
+		},
+		{
+			name:      "other errors are not conflicts",
+			createErr: errors.New("something went wrong"),
+			existing:  []git.GitPullRequest{{PullRequestId: ptr(7)}},
+			assertions: func(t *testing.T, _ string, lookups int, err error) {
+				require.ErrorContains(t, err, "error creating pull request")
+				require.NotErrorIs(t, err, ErrConflict)
+				require.Zero(t, lookups)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var lookups int
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				createPullRequestFn: func(
+					context.Context,
+					git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					return nil, testCase.createErr
+				},
+				getPullRequestsFn: func(
+					_ context.Context,
+					args git.GetPullRequestsArgs,
+				) (*[]git.GitPullRequest, error) {
+					lookups++
+					require.Equal(
+						t,
+						"refs/heads/prs/kargo-render/env/dev",
+						*args.SearchCriteria.SourceRefName,
+					)
+					require.Equal(t, "refs/heads/env/dev", *args.SearchCriteria.TargetRefName)
+					return &testCase.existing, nil
+				},
+			})
+			url, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				&OpenPROptions{ConflictPolicy: testCase.policy},
+			)
+			testCase.assertions(t, url, lookups, err)
+		})
+	}
+}
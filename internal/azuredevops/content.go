@@ -0,0 +1,73 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// ErrPathEmpty is returned when a path that is expected to contain rendered
+// files is missing or contains no files.
+var ErrPathEmpty = errors.New("path is missing or contains no files")
+
+// EnsurePathNotEmpty returns an error wrapping ErrPathEmpty if the specified
+// path, relative to the root of the repository, is missing from the specified
+// branch or contains no files there. This permits callers to confirm that a
+// render actually produced manifests before pushing or opening a PR. The
+// branch name may be specified with or without a refs/heads/ prefix.
+func EnsurePathNotEmpty(
+	ctx context.Context,
+	repoURL string,
+	branch string,
+	path string,
+	creds gitutil.RepoCredentials,
+) (err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return err
+	}
+	return ensurePathNotEmpty(ctx, repo, branch, path)
+}
+
+// ensurePathNotEmpty returns an error wrapping ErrPathEmpty if the specified
+// path is missing from the specified branch or contains no files there.
+func ensurePathNotEmpty(
+	ctx context.Context,
+	repo *repoClient,
+	branch string,
+	path string,
+) error {
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+	scopePath := "/" + strings.Trim(path, "/")
+	items, err := repo.client.GetItems(ctx, git.GetItemsArgs{
+		Project:        &repo.project,
+		RepositoryId:   &repo.id,
+		ScopePath:      &scopePath,
+		RecursionLevel: &git.VersionControlRecursionTypeValues.Full,
+		VersionDescriptor: &git.GitVersionDescriptor{
+			Version:     &branch,
+			VersionType: &git.GitVersionTypeValues.Branch,
+		},
+	})
+	if code, ok := statusCodeOf(err); ok && code == http.StatusNotFound {
+		return fmt.Errorf("%w: %q does not exist on branch %q", ErrPathEmpty, scopePath, branch)
+	}
+	if err != nil {
+		return fmt.Errorf("error listing %q on branch %q: %w", scopePath, branch, err)
+	}
+	if items != nil {
+		for _, item := range *items {
+			if item.IsFolder == nil || !*item.IsFolder {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: %q contains no files on branch %q", ErrPathEmpty, scopePath, branch)
+}
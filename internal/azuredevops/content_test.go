@@ -0,0 +1,126 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestEnsurePathNotEmpty(t *testing.T) {
+	testCases := []struct {
+		name       string
+		items      *[]git.GitItem
+		itemsErr   error
+		assertions func(t *testing.T, err error)
+	}{
+		{
+			name: "path contains files",
+			items: &[]git.GitItem{
+				{Path: ptr("/envs/dev"), IsFolder: ptr(true)},
+				{Path: ptr("/envs/dev/app"), IsFolder: ptr(true)},
+				{Path: ptr("/envs/dev/app/all.yaml")},
+			},
+			assertions: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "path contains only folders",
+			items: &[]git.GitItem{
+				{Path: ptr("/envs/dev"), IsFolder: ptr(true)},
+				{Path: ptr("/envs/dev/app"), IsFolder: ptr(true)},
+			},
+			assertions: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrPathEmpty)
+				require.ErrorContains(t, err, "contains no files")
+			},
+		},
+		{
+			name:  "no items",
+			items: &[]git.GitItem{},
+			assertions: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrPathEmpty)
+			},
+		},
+		{
+			name:     "path does not exist",
+			itemsErr: azuredevops.WrappedError{StatusCode: ptr(http.StatusNotFound)},
+			assertions: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrPathEmpty)
+				require.ErrorContains(t, err, "does not exist")
+			},
+		},
+		{
+			name:     "error listing path",
+			itemsErr: errors.New("something went wrong"),
+			assertions: func(t *testing.T, err error) {
+				require.NotErrorIs(t, err, ErrPathEmpty)
+				require.ErrorContains(t, err, "error listing")
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getItemsFn: func(
+					_ context.Context,
+					args git.GetItemsArgs,
+				) (*[]git.GitItem, error) {
+					require.Equal(t, "/envs/dev", *args.ScopePath)
+					require.Equal(t, "env/dev", *args.VersionDescriptor.Version)
+					return testCase.items, testCase.itemsErr
+				},
+			})
+			err := EnsurePathNotEmpty(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"refs/heads/env/dev",
+				"envs/dev/",
+				gitutil.RepoCredentials{Password: "token"},
+			)
+			testCase.assertions(t, err)
+		})
+	}
+}
+
+func TestOpenPRRequiredPath(t *testing.T) {
+	var created bool
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: fakeRepos("repo"),
+		getItemsFn: func(
+			_ context.Context,
+			args git.GetItemsArgs,
+		) (*[]git.GitItem, error) {
+			require.Equal(t, "prs/kargo-render/env/dev", *args.VersionDescriptor.Version)
+			return &[]git.GitItem{{Path: ptr("/envs/dev"), IsFolder: ptr(true)}}, nil
+		},
+		createPullRequestFn: func(
+			ctx context.Context,
+			args git.CreatePullRequestArgs,
+		) (*git.GitPullRequest, error) {
+			created = true
+			return fakeCreatePullRequest(nil)(ctx, args)
+		},
+	})
+	url, err := OpenPR(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"title",
+		"description",
+		"env/dev",
+		"prs/kargo-render/env/dev",
+		gitutil.RepoCredentials{Password: "token"},
+		&OpenPROptions{RequiredPath: "envs/dev"},
+	)
+	require.ErrorIs(t, err, ErrPathEmpty)
+	require.Empty(t, url)
+	require.False(t, created)
+}
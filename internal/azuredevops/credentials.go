@@ -0,0 +1,120 @@
+package azuredevops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+)
+
+// ErrCredentialsExpired is returned when Azure DevOps rejects a request
+// because the credentials used, typically a PAT, have expired. Automation can
+// treat this as a signal to rotate them; retrying will not help.
+var ErrCredentialsExpired = errors.New("credentials for Azure DevOps have expired")
+
+// serviceErrorHeader is the header in which Azure DevOps describes why it
+// rejected a request.
+const serviceErrorHeader = "X-TFS-ServiceError"
+
+// maxCredentialsErrorBody is the number of bytes of the body of a 401 response
+// that are inspected for an indication that credentials have expired.
+const maxCredentialsErrorBody = 4096
+
+// expiredCredentialsTransport is an http.RoundTripper that replaces responses
+// indicating that credentials have expired with errors wrapping
+// ErrCredentialsExpired. Left to the SDK, such responses surface as opaque
+// errors: a 401 whose HTML body fails to unmarshal, or a 203 sign-in page that
+// is mistaken for success.
+type expiredCredentialsTransport struct {
+	next http.RoundTripper
+}
+
+func (e *expiredCredentialsTransport) RoundTrip(
+	req *http.Request,
+) (*http.Response, error) {
+	res, err := e.next.RoundTrip(req)
+	if err != nil || res == nil {
+		return res, err
+	}
+	if detail, expired := credentialsExpired(res); expired {
+		res.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrCredentialsExpired, detail)
+	}
+	return res, nil
+}
+
+// credentialsExpired returns a bool indicating whether the specified response
+// reports that the credentials used to make the request have expired and, if
+// so, a description of the condition. Azure DevOps responds to requests made
+// with an expired PAT either with a 203 redirecting to its sign-in page or,
+// since the SDK suppresses that redirect, with a 401 whose service error
+// mentions the expiry. Other 401s, e.g. for a revoked or mistyped PAT, are left
+// alone. The body of an inspected 401 is restored for subsequent readers.
+func credentialsExpired(res *http.Response) (string, bool) {
+	switch res.StatusCode {
+	case http.StatusNonAuthoritativeInfo:
+		return "Azure DevOps responded with its sign-in page", true
+	case http.StatusUnauthorized:
+	default:
+		return "", false
+	}
+	if msg, err := url.QueryUnescape(res.Header.Get(serviceErrorHeader)); err == nil &&
+		mentionsExpiry(msg) {
+		return msg, true
+	}
+	if res.Body == nil {
+		return "", false
+	}
+	prefix, _ := io.ReadAll(io.LimitReader(res.Body, maxCredentialsErrorBody))
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(prefix), res.Body), res.Body}
+	if mentionsExpiry(string(prefix)) {
+		return "Azure DevOps reported that the credentials used have expired", true
+	}
+	return "", false
+}
+
+// mentionsExpiry returns a bool indicating whether the specified message from
+// Azure DevOps reports that credentials have expired.
+func mentionsExpiry(msg string) bool {
+	return strings.Contains(strings.ToLower(msg), "expired")
+}
+
+// asExpiredCredentials returns the specified error wrapped with
+// ErrCredentialsExpired if it is an Azure DevOps API error reporting that
+// credentials have expired. Otherwise, it is returned unmodified. This catches
+// such errors that the SDK receives without going through
+// expiredCredentialsTransport, e.g. while discovering resource areas.
+func asExpiredCredentials(err error) error {
+	code, ok := statusCodeOf(err)
+	if !ok || errors.Is(err, ErrCredentialsExpired) {
+		return err
+	}
+	if code == http.StatusNonAuthoritativeInfo ||
+		(code == http.StatusUnauthorized && mentionsExpiry(wrappedMessageOf(err))) {
+		return fmt.Errorf("%w: %w", ErrCredentialsExpired, err)
+	}
+	return err
+}
+
+// wrappedMessageOf returns the message of the Azure DevOps API error wrapped by
+// the specified error, if any.
+func wrappedMessageOf(err error) string {
+	var wrappedPtr *azuredevops.WrappedError
+	if errors.As(err, &wrappedPtr) && wrappedPtr != nil &&
+		wrappedPtr.Message != nil {
+		return *wrappedPtr.Message
+	}
+	var wrapped azuredevops.WrappedError
+	if errors.As(err, &wrapped) && wrapped.Message != nil {
+		return *wrapped.Message
+	}
+	return ""
+}
@@ -0,0 +1,148 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+)
+
+// signInPage resembles the HTML with which Azure DevOps responds to requests
+// made with credentials it no longer accepts.
+const signInPage = `<!DOCTYPE html><html><head><title>Azure DevOps Services | Sign In</title>` +
+	`</head><body></body></html>`
+
+func TestExpiredCredentialsTransport(t *testing.T) {
+	testCases := []struct {
+		name       string
+		handler    http.HandlerFunc
+		assertions func(t *testing.T, err error)
+	}{
+		{
+			name: "401 for an expired PAT",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set(
+					serviceErrorHeader,
+					"The%20Personal%20Access%20Token%20used%20has%20expired.",
+				)
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(signInPage))
+			},
+			assertions: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrCredentialsExpired)
+				require.ErrorContains(t, err, "The Personal Access Token used has expired.")
+			},
+		},
+		{
+			name: "401 whose body reports expiry",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte("Access denied: the personal access token has expired"))
+			},
+			assertions: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrCredentialsExpired)
+			},
+		},
+		{
+			name: "203 redirect to the sign-in page",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.WriteHeader(http.StatusNonAuthoritativeInfo)
+				_, _ = w.Write([]byte(signInPage))
+			},
+			assertions: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrCredentialsExpired)
+			},
+		},
+		{
+			name: "401 for other reasons",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte("Access denied"))
+			},
+			assertions: func(t *testing.T, err error) {
+				require.Error(t, err)
+				require.NotErrorIs(t, err, ErrCredentialsExpired)
+				code, ok := statusCodeOf(err)
+				require.True(t, ok)
+				require.Equal(t, http.StatusUnauthorized, code)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := httptest.NewServer(testCase.handler)
+			t.Cleanup(server.Close)
+			connection := azuredevops.NewPatConnection(server.URL, "token")
+			client := withTransport(
+				&git.ClientImpl{Client: *azuredevops.NewClient(connection, server.URL)},
+				nil,
+				nil,
+			)
+			_, err := client.GetRepositories(context.Background(), git.GetRepositoriesArgs{
+				Project: ptr("proj"),
+			})
+			testCase.assertions(t, err)
+		})
+	}
+}
+
+func TestAsExpiredCredentials(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		assertions func(t *testing.T, err error)
+	}{
+		{
+			name: "401 reporting expiry",
+			err: azuredevops.WrappedError{
+				StatusCode: ptr(http.StatusUnauthorized),
+				Message:    ptr("The Personal Access Token used has expired."),
+			},
+			assertions: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrCredentialsExpired)
+			},
+		},
+		{
+			name: "203",
+			err: fmt.Errorf("error: %w", &azuredevops.WrappedError{
+				StatusCode: ptr(http.StatusNonAuthoritativeInfo),
+			}),
+			assertions: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrCredentialsExpired)
+			},
+		},
+		{
+			name: "other 401",
+			err: azuredevops.WrappedError{
+				StatusCode: ptr(http.StatusUnauthorized),
+				Message:    ptr("Access denied"),
+			},
+			assertions: func(t *testing.T, err error) {
+				require.NotErrorIs(t, err, ErrCredentialsExpired)
+			},
+		},
+		{
+			name: "not an API error",
+			err:  errors.New("something went wrong"),
+			assertions: func(t *testing.T, err error) {
+				require.NotErrorIs(t, err, ErrCredentialsExpired)
+				require.EqualError(t, err, "something went wrong")
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.assertions(t, asExpiredCredentials(testCase.err))
+		})
+	}
+}
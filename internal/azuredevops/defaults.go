@@ -0,0 +1,58 @@
+package azuredevops
+
+// PRDefaults encapsulates default settings for PRs opened in Azure DevOps.
+// These are typically loaded from configuration, so that settings such as
+// reviewers need not be specified for every PR, and are merged with any
+// OpenPROptions, whose values take precedence over them.
+type PRDefaults struct {
+	// Draft specifies whether PRs should be opened as drafts, as by
+	// OpenPROptions.SuppressNotifications, for PRs for which
+	// OpenPROptions.SuppressNotifications is nil. This default is disregarded
+	// for PRs with auto-complete enabled, whether by OpenPROptions or by these
+	// defaults, since drafts cannot be completed.
+	Draft bool
+	// AutoComplete, when non-nil, specifies that auto-complete should be
+	// enabled, using the specified settings, for PRs for which
+	// OpenPROptions.AutoComplete is nil. This default is disregarded for PRs
+	// for which OpenPROptions.SuppressNotifications is a pointer to true.
+	AutoComplete *AutoCompleteOptions
+	// Reviewers specifies reviewers to request for PRs for which
+	// OpenPROptions.Reviewers is nil. A non-nil, empty
+	// OpenPROptions.Reviewers overrides these with none.
+	Reviewers []Reviewer
+	// ReviewerNames specifies optional reviewers to request, as by
+	// OpenPROptions.ReviewerNames, for PRs for which
+	// OpenPROptions.ReviewerNames is nil. A non-nil, empty
+	// OpenPROptions.ReviewerNames overrides these with none.
+	ReviewerNames []string
+}
+
+// apply returns a copy of the specified options with any settings they leave
+// unspecified taken from the defaults. If the defaults are nil, the options are
+// returned as they are.
+func (d *PRDefaults) apply(opts *OpenPROptions) *OpenPROptions {
+	if d == nil {
+		return opts
+	}
+	o := *opts
+	if o.AutoComplete == nil && !suppressNotifications(&o) {
+		o.AutoComplete = d.AutoComplete
+	}
+	if o.SuppressNotifications == nil && o.AutoComplete == nil && d.Draft {
+		draft := true
+		o.SuppressNotifications = &draft
+	}
+	if o.Reviewers == nil {
+		o.Reviewers = d.Reviewers
+	}
+	if o.ReviewerNames == nil {
+		o.ReviewerNames = d.ReviewerNames
+	}
+	return &o
+}
+
+// suppressNotifications returns a bool indicating whether the specified options
+// specify that notifications should be suppressed.
+func suppressNotifications(opts *OpenPROptions) bool {
+	return opts.SuppressNotifications != nil && *opts.SuppressNotifications
+}
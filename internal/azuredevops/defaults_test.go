@@ -0,0 +1,177 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestPRDefaultsApply(t *testing.T) {
+	defaults := &PRDefaults{
+		AutoComplete:  &AutoCompleteOptions{DeleteSourceBranch: true},
+		Reviewers:     []Reviewer{{ID: "default-reviewer"}},
+		ReviewerNames: []string{"team-a"},
+	}
+	testCases := []struct {
+		name       string
+		defaults   *PRDefaults
+		opts       *OpenPROptions
+		assertions func(t *testing.T, opts *OpenPROptions)
+	}{
+		{
+			name:     "no defaults",
+			defaults: nil,
+			opts:     &OpenPROptions{Labels: []string{"a"}},
+			assertions: func(t *testing.T, opts *OpenPROptions) {
+				require.Equal(t, &OpenPROptions{Labels: []string{"a"}}, opts)
+			},
+		},
+		{
+			name:     "unspecified values taken from defaults",
+			defaults: defaults,
+			opts:     &OpenPROptions{},
+			assertions: func(t *testing.T, opts *OpenPROptions) {
+				require.Equal(t, defaults.AutoComplete, opts.AutoComplete)
+				require.Equal(t, defaults.Reviewers, opts.Reviewers)
+				require.Equal(t, defaults.ReviewerNames, opts.ReviewerNames)
+				require.Nil(t, opts.SuppressNotifications)
+			},
+		},
+		{
+			name:     "specified values take precedence",
+			defaults: defaults,
+			opts: &OpenPROptions{
+				AutoComplete:  &AutoCompleteOptions{MergeCommitMessage: "merged"},
+				Reviewers:     []Reviewer{{ID: "reviewer"}},
+				ReviewerNames: []string{"team-b"},
+			},
+			assertions: func(t *testing.T, opts *OpenPROptions) {
+				require.Equal(t, &AutoCompleteOptions{MergeCommitMessage: "merged"}, opts.AutoComplete)
+				require.Equal(t, []Reviewer{{ID: "reviewer"}}, opts.Reviewers)
+				require.Equal(t, []string{"team-b"}, opts.ReviewerNames)
+			},
+		},
+		{
+			name:     "empty values override defaults with none",
+			defaults: defaults,
+			opts: &OpenPROptions{
+				Reviewers:     []Reviewer{},
+				ReviewerNames: []string{},
+			},
+			assertions: func(t *testing.T, opts *OpenPROptions) {
+				require.Empty(t, opts.Reviewers)
+				require.Empty(t, opts.ReviewerNames)
+			},
+		},
+		{
+			name:     "draft by default",
+			defaults: &PRDefaults{Draft: true},
+			opts:     &OpenPROptions{},
+			assertions: func(t *testing.T, opts *OpenPROptions) {
+				require.True(t, suppressNotifications(opts))
+			},
+		},
+		{
+			name:     "request opts out of draft by default",
+			defaults: &PRDefaults{Draft: true},
+			opts:     &OpenPROptions{SuppressNotifications: ptr(false)},
+			assertions: func(t *testing.T, opts *OpenPROptions) {
+				require.NotNil(t, opts.SuppressNotifications)
+				require.False(t, *opts.SuppressNotifications)
+			},
+		},
+		{
+			name:     "draft by default disregarded for auto-complete",
+			defaults: &PRDefaults{Draft: true},
+			opts:     &OpenPROptions{AutoComplete: &AutoCompleteOptions{}},
+			assertions: func(t *testing.T, opts *OpenPROptions) {
+				require.Nil(t, opts.SuppressNotifications)
+				require.NotNil(t, opts.AutoComplete)
+			},
+		},
+		{
+			name: "draft by default disregarded for default auto-complete",
+			defaults: &PRDefaults{
+				Draft:        true,
+				AutoComplete: &AutoCompleteOptions{},
+			},
+			opts: &OpenPROptions{},
+			assertions: func(t *testing.T, opts *OpenPROptions) {
+				require.Nil(t, opts.SuppressNotifications)
+				require.NotNil(t, opts.AutoComplete)
+			},
+		},
+		{
+			name:     "default auto-complete disregarded for drafts",
+			defaults: defaults,
+			opts:     &OpenPROptions{SuppressNotifications: ptr(true)},
+			assertions: func(t *testing.T, opts *OpenPROptions) {
+				require.True(t, suppressNotifications(opts))
+				require.Nil(t, opts.AutoComplete)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			orig := *testCase.opts
+			opts := testCase.defaults.apply(testCase.opts)
+			require.Equal(t, orig, *testCase.opts, "options must not be modified")
+			testCase.assertions(t, opts)
+		})
+	}
+}
+
+func TestOpenPRDefaults(t *testing.T) {
+	var created git.GitPullRequest
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn:   fakeRepos("repo"),
+		createPullRequestFn: fakeCreatePullRequest(&created),
+	})
+	_, err := OpenPR(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"title",
+		"description",
+		"env/dev",
+		"prs/kargo-render/env/dev",
+		gitutil.RepoCredentials{Password: "token"},
+		&OpenPROptions{
+			Defaults: &PRDefaults{
+				Draft:     true,
+				Reviewers: []Reviewer{{ID: "d3b07384-d9a0-4c9b-8f1e-000000000001"}},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.NotNil(t, created.IsDraft)
+	require.True(t, *created.IsDraft)
+	require.Len(t, *created.Reviewers, 1)
+	require.Equal(t, "d3b07384-d9a0-4c9b-8f1e-000000000001", *(*created.Reviewers)[0].Id)
+}
+
+func TestOpenPRDefaultsDraftOptOut(t *testing.T) {
+	var created git.GitPullRequest
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn:   fakeRepos("repo"),
+		createPullRequestFn: fakeCreatePullRequest(&created),
+	})
+	_, err := OpenPR(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"title",
+		"description",
+		"env/dev",
+		"prs/kargo-render/env/dev",
+		gitutil.RepoCredentials{Password: "token"},
+		&OpenPROptions{
+			SuppressNotifications: ptr(false),
+			Defaults:              &PRDefaults{Draft: true},
+		},
+	)
+	require.NoError(t, err)
+	require.Nil(t, created.IsDraft)
+}
@@ -0,0 +1,110 @@
+package azuredevops
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// maxDescriptionLength is the maximum length, in characters, that Azure
+	// DevOps permits for a PR description.
+	maxDescriptionLength = 4000
+
+	// truncationNotice is appended to a PR description that had to be truncated
+	// to fit within maxDescriptionLength.
+	truncationNotice = "\n\n_(Description truncated.)_"
+
+	// artifactHeading introduces the link to a rendered manifest diff in a PR
+	// description.
+	artifactHeading = "### Rendered Manifest Diff"
+
+	// relatedPRsHeading introduces links to related PRs in a PR description.
+	relatedPRsHeading = "### Related PRs"
+)
+
+// artifactSection returns the section of a PR description that links to the
+// rendered manifest diff at the specified URL or an empty string if the URL is
+// empty.
+func artifactSection(artifactURL string) string {
+	if artifactURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s\n\n[View the rendered diff](%s)", artifactHeading, artifactURL)
+}
+
+// relatedPRsSection returns the section of a PR description that links to the
+// related PRs at the specified URLs or an empty string if there are none. Empty
+// and duplicate URLs are ignored.
+func relatedPRsSection(urls []string) string {
+	var b strings.Builder
+	seen := make(map[string]struct{}, len(urls))
+	for _, url := range urls {
+		if url == "" {
+			continue
+		}
+		if _, ok := seen[url]; ok {
+			continue
+		}
+		seen[url] = struct{}{}
+		fmt.Fprintf(&b, "\n- %s", url)
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return relatedPRsHeading + "\n" + b.String()
+}
+
+// withRelatedPRs returns the specified PR description, as previously assembled
+// by PreviewPR, with its related PRs section, if any, replaced by one linking
+// to the specified URLs. Everything following an existing section, such as
+// hidden markers, is preserved intact. If there is no existing section, one is
+// inserted ahead of any hidden Kargo Render markers.
+func withRelatedPRs(description string, urls []string) string {
+	body, rest := description, ""
+	if i := strings.LastIndex(description, "\n\n"+relatedPRsHeading+"\n"); i >= 0 {
+		body = description[:i]
+		// The section ends at the first blank line following its links
+		section := description[i+len("\n\n"+relatedPRsHeading+"\n"):]
+		if j := strings.Index(section, "\n\n"); j >= 0 {
+			rest = section[j+2:]
+		}
+	} else if i := strings.Index(description, "\n\n"+kargoRenderMarkerPrefix); i >= 0 {
+		body, rest = description[:i], description[i+2:]
+	}
+	// Any truncation notice is dropped so that it is not repeated if the body
+	// must be truncated again
+	body = strings.TrimSuffix(body, truncationNotice)
+	description, _ = fitDescription(body, relatedPRsSection(urls), rest)
+	return description
+}
+
+// fitDescription assembles a PR description from the specified body followed
+// by any non-empty trailers, each separated by a blank line. If the result
+// would exceed maxDescriptionLength, the body is truncated, with a notice, so
+// that the trailers are always preserved intact. It returns the assembled
+// description and a bool indicating whether the body was truncated.
+func fitDescription(body string, trailers ...string) (string, bool) {
+	return fitDescriptionTo(maxDescriptionLength, body, trailers...)
+}
+
+// fitDescriptionTo is like fitDescription, but fits the description to the
+// specified maximum length instead of maxDescriptionLength.
+func fitDescriptionTo(limit int, body string, trailers ...string) (string, bool) {
+	var trailer string
+	for _, t := range trailers {
+		if t != "" {
+			trailer += "\n\n" + t
+		}
+	}
+	bodyRunes := []rune(body)
+	trailerLen := len([]rune(trailer))
+	if len(bodyRunes)+trailerLen <= limit {
+		return body + trailer, false
+	}
+	keep := limit - trailerLen - len([]rune(truncationNotice))
+	if keep < 0 {
+		keep = 0
+	}
+	return strings.TrimRight(string(bodyRunes[:keep]), " \t\n") +
+		truncationNotice + trailer, true
+}
@@ -0,0 +1,331 @@
+package azuredevops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestFitDescription(t *testing.T) {
+	testCases := []struct {
+		name       string
+		body       string
+		trailers   []string
+		assertions func(t *testing.T, desc string, truncated bool)
+	}{
+		{
+			name: "within limit",
+			body: "hello",
+			assertions: func(t *testing.T, desc string, truncated bool) {
+				require.False(t, truncated)
+				require.Equal(t, "hello", desc)
+			},
+		},
+		{
+			name:     "within limit with trailers",
+			body:     "hello",
+			trailers: []string{"", "marker"},
+			assertions: func(t *testing.T, desc string, truncated bool) {
+				require.False(t, truncated)
+				require.Equal(t, "hello\n\nmarker", desc)
+			},
+		},
+		{
+			name:     "over limit",
+			body:     strings.Repeat("x", maxDescriptionLength+100),
+			trailers: []string{"marker"},
+			assertions: func(t *testing.T, desc string, truncated bool) {
+				require.True(t, truncated)
+				require.Len(t, []rune(desc), maxDescriptionLength)
+				require.True(t, strings.HasSuffix(desc, truncationNotice+"\n\nmarker"))
+			},
+		},
+		{
+			name: "over limit with multi-byte characters",
+			body: strings.Repeat("é", maxDescriptionLength+1),
+			assertions: func(t *testing.T, desc string, truncated bool) {
+				require.True(t, truncated)
+				require.Len(t, []rune(desc), maxDescriptionLength)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			desc, truncated := fitDescription(testCase.body, testCase.trailers...)
+			testCase.assertions(t, desc, truncated)
+		})
+	}
+}
+
+func TestOpenPROverLimitDescription(t *testing.T) {
+	longDescription := strings.Repeat("line of change summary\n", 500)
+	testCases := []struct {
+		name              string
+		overflowToComment bool
+		assertions        func(t *testing.T, pr git.GitPullRequest, comments []string)
+	}{
+		{
+			name: "truncated without overflow comment",
+			assertions: func(t *testing.T, pr git.GitPullRequest, comments []string) {
+				require.LessOrEqual(t, len([]rune(*pr.Description)), maxDescriptionLength)
+				require.Empty(t, comments)
+			},
+		},
+		{
+			name:              "truncated with overflow comment",
+			overflowToComment: true,
+			assertions: func(t *testing.T, pr git.GitPullRequest, comments []string) {
+				require.LessOrEqual(t, len([]rune(*pr.Description)), maxDescriptionLength)
+				require.Equal(t, []string{longDescription}, comments)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var pr git.GitPullRequest
+			var comments []string
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn:   fakeRepos("repo"),
+				createPullRequestFn: fakeCreatePullRequest(&pr),
+				createThreadFn: func(
+					_ context.Context,
+					args git.CreateThreadArgs,
+				) (*git.GitPullRequestCommentThread, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					for _, c := range *args.CommentThread.Comments {
+						comments = append(comments, *c.Content)
+					}
+					return args.CommentThread, nil
+				},
+			})
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				longDescription,
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				&OpenPROptions{OverflowToComment: testCase.overflowToComment},
+			)
+			require.NoError(t, err)
+			testCase.assertions(t, pr, comments)
+		})
+	}
+}
+
+func TestOpenPRFooter(t *testing.T) {
+	const footer = "Generated automatically; do not edit the target branch directly."
+	testCases := []struct {
+		name        string
+		description string
+		assertions  func(t *testing.T, desc string)
+	}{
+		{
+			name:        "footer appended",
+			description: "See individual commit messages for details.",
+			assertions: func(t *testing.T, desc string) {
+				require.Equal(
+					t,
+					"See individual commit messages for details.\n\n"+footer,
+					desc,
+				)
+			},
+		},
+		{
+			name:        "footer preserved when description is truncated",
+			description: strings.Repeat("x", maxDescriptionLength),
+			assertions: func(t *testing.T, desc string) {
+				require.Len(t, []rune(desc), maxDescriptionLength)
+				require.True(t, strings.HasSuffix(desc, truncationNotice+"\n\n"+footer))
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var pr git.GitPullRequest
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn:   fakeRepos("repo"),
+				createPullRequestFn: fakeCreatePullRequest(&pr),
+			})
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				testCase.description,
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				&OpenPROptions{Footer: footer, OmitVersion: true},
+			)
+			require.NoError(t, err)
+			testCase.assertions(t, *pr.Description)
+		})
+	}
+}
+
+func TestOpenPRArtifactURL(t *testing.T) {
+	const artifactURL = "https://ci.example.com/builds/123/artifacts/diff.html"
+	var pr git.GitPullRequest
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn:   fakeRepos("repo"),
+		createPullRequestFn: fakeCreatePullRequest(&pr),
+	})
+	_, err := OpenPR(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"title",
+		"See individual commit messages for details.",
+		"env/dev",
+		"prs/kargo-render/env/dev",
+		gitutil.RepoCredentials{Password: "pat"},
+		&OpenPROptions{
+			ArtifactURL: artifactURL,
+			Footer:      "footer",
+			OmitVersion: true,
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		"See individual commit messages for details.\n\n"+
+			artifactHeading+"\n\n[View the rendered diff]("+artifactURL+")\n\n"+
+			"footer",
+		*pr.Description,
+	)
+}
+
+func TestRelatedPRsSection(t *testing.T) {
+	testCases := []struct {
+		name     string
+		urls     []string
+		expected string
+	}{
+		{
+			name: "no URLs",
+		},
+		{
+			name: "only empty URLs",
+			urls: []string{""},
+		},
+		{
+			name: "URLs",
+			urls: []string{
+				"https://dev.azure.com/org/proj/_git/repo/pullrequest/1",
+				"",
+				"https://dev.azure.com/org/proj/_git/repo/pullrequest/2",
+				"https://dev.azure.com/org/proj/_git/repo/pullrequest/1",
+			},
+			expected: relatedPRsHeading + "\n\n" +
+				"- https://dev.azure.com/org/proj/_git/repo/pullrequest/1\n" +
+				"- https://dev.azure.com/org/proj/_git/repo/pullrequest/2",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.expected, relatedPRsSection(testCase.urls))
+		})
+	}
+}
+
+func TestWithRelatedPRs(t *testing.T) {
+	const marker = "<!-- kargo-render-version: v1.0.0 -->"
+	testCases := []struct {
+		name        string
+		description string
+		urls        []string
+		expected    string
+	}{
+		{
+			name:        "no existing section",
+			description: "body\n\nfooter\n\n" + marker,
+			urls:        []string{"https://example.com/1"},
+			expected: "body\n\nfooter\n\n" + relatedPRsHeading +
+				"\n\n- https://example.com/1\n\n" + marker,
+		},
+		{
+			name: "existing section",
+			description: "body\n\n" + relatedPRsHeading +
+				"\n\n- https://example.com/1\n\n" + marker,
+			urls: []string{"https://example.com/2"},
+			expected: "body\n\n" + relatedPRsHeading +
+				"\n\n- https://example.com/2\n\n" + marker,
+		},
+		{
+			name: "existing section followed by custom marker",
+			description: "body\n\n" + relatedPRsHeading +
+				"\n\n- https://example.com/1\n- https://example.com/2" +
+				"\n\n<!-- kargo-render: team-a -->\n\n" + marker,
+			urls: []string{"https://example.com/3"},
+			expected: "body\n\n" + relatedPRsHeading +
+				"\n\n- https://example.com/3" +
+				"\n\n<!-- kargo-render: team-a -->\n\n" + marker,
+		},
+		{
+			name: "existing section at end",
+			description: "body\n\n" + relatedPRsHeading +
+				"\n\n- https://example.com/1",
+			urls: []string{"https://example.com/2"},
+			expected: "body\n\n" + relatedPRsHeading +
+				"\n\n- https://example.com/2",
+		},
+		{
+			name:        "no markers",
+			description: "body",
+			urls:        []string{"https://example.com/1"},
+			expected:    "body\n\n" + relatedPRsHeading + "\n\n- https://example.com/1",
+		},
+		{
+			name: "no URLs",
+			description: "body\n\n" + relatedPRsHeading +
+				"\n\n- https://example.com/1\n\n" + marker,
+			expected: "body\n\n" + marker,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(
+				t,
+				testCase.expected,
+				withRelatedPRs(testCase.description, testCase.urls),
+			)
+		})
+	}
+}
+
+func TestOpenPRRelatedPRURLs(t *testing.T) {
+	const relatedURL = "https://dev.azure.com/org/proj/_git/other/pullrequest/7"
+	var pr git.GitPullRequest
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn:   fakeRepos("repo"),
+		createPullRequestFn: fakeCreatePullRequest(&pr),
+	})
+	_, err := OpenPR(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"title",
+		strings.Repeat("x", maxDescriptionLength),
+		"env/dev",
+		"prs/kargo-render/env/dev",
+		gitutil.RepoCredentials{Password: "pat"},
+		&OpenPROptions{
+			RelatedPRURLs: []string{relatedURL},
+			Footer:        "footer",
+			OmitVersion:   true,
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, []rune(*pr.Description), maxDescriptionLength)
+	require.True(
+		t,
+		strings.HasSuffix(
+			*pr.Description,
+			truncationNotice+"\n\nfooter\n\n"+relatedPRsHeading+"\n\n- "+relatedURL,
+		),
+	)
+}
@@ -0,0 +1,182 @@
+package azuredevops
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DescriptionLink is a link listed in the Links section of a PR description.
+type DescriptionLink struct {
+	// Text is the link's text. When this is empty, the URL is used.
+	Text string
+	// URL is the link's target.
+	URL string
+}
+
+// descriptionSection is one section of a PR description assembled by a
+// DescriptionBuilder.
+type descriptionSection struct {
+	heading string
+	body    string
+}
+
+// markdown returns the section as markdown.
+func (s descriptionSection) markdown() string {
+	if s.heading == "" {
+		return s.body
+	}
+	return fmt.Sprintf("### %s\n\n%s", s.heading, s.body)
+}
+
+// DescriptionBuilder assembles a PR description from named sections, such as
+// Summary, Changes, Images, and Links, which appear in the order in which they
+// were added, followed by any footer. Sections with empty bodies are omitted.
+// The zero value is an empty builder ready for use.
+type DescriptionBuilder struct {
+	sections []descriptionSection
+	footer   string
+}
+
+// NewDescriptionBuilder returns an empty DescriptionBuilder.
+func NewDescriptionBuilder() *DescriptionBuilder {
+	return &DescriptionBuilder{}
+}
+
+// AddSection adds a section with the specified heading and markdown body. It
+// returns the builder so that calls may be chained.
+func (b *DescriptionBuilder) AddSection(heading, body string) *DescriptionBuilder {
+	if body = strings.TrimSpace(body); body != "" {
+		b.sections = append(b.sections, descriptionSection{heading: heading, body: body})
+	}
+	return b
+}
+
+// Summary adds a Summary section with the specified markdown body.
+func (b *DescriptionBuilder) Summary(body string) *DescriptionBuilder {
+	return b.AddSection("Summary", body)
+}
+
+// Changes adds a Changes section listing the specified changes.
+func (b *DescriptionBuilder) Changes(changes ...string) *DescriptionBuilder {
+	return b.AddSection("Changes", bulletList(changes))
+}
+
+// Images adds an Images section listing the specified image references.
+func (b *DescriptionBuilder) Images(images ...string) *DescriptionBuilder {
+	items := make([]string, 0, len(images))
+	for _, image := range images {
+		if image != "" {
+			items = append(items, "`"+image+"`")
+		}
+	}
+	return b.AddSection("Images", bulletList(items))
+}
+
+// Links adds a Links section listing the specified links. Links with empty
+// URLs are ignored.
+func (b *DescriptionBuilder) Links(links ...DescriptionLink) *DescriptionBuilder {
+	items := make([]string, 0, len(links))
+	for _, link := range links {
+		if link.URL == "" {
+			continue
+		}
+		text := link.Text
+		if text == "" {
+			text = link.URL
+		}
+		items = append(items, fmt.Sprintf("[%s](%s)", text, link.URL))
+	}
+	return b.AddSection("Links", bulletList(items))
+}
+
+// Footer sets the markdown that follows all sections. Unlike sections, the
+// footer has no heading and is always preserved intact by Build, even when
+// sections must be omitted to fit within a limit.
+func (b *DescriptionBuilder) Footer(footer string) *DescriptionBuilder {
+	b.footer = strings.TrimSpace(footer)
+	return b
+}
+
+// String returns the complete description as markdown.
+func (b *DescriptionBuilder) String() string {
+	return joinParagraphs(sectionsMarkdown(b.sections), b.footer)
+}
+
+// Build returns the description as markdown, fitted to the specified maximum
+// length, in characters, along with the markdown of any sections that had to be
+// omitted to fit, which is suitable for posting as a comment. Sections are kept
+// in order for as long as they fit, and those that follow are replaced by a
+// notice naming them. If not even the first section fits, it is truncated
+// instead. A limit of zero or less means no limit.
+func (b *DescriptionBuilder) Build(limit int) (description, overflow string) {
+	full := b.String()
+	if limit <= 0 || len([]rune(full)) <= limit || len(b.sections) == 0 {
+		return full, ""
+	}
+	for kept := len(b.sections) - 1; kept > 0; kept-- {
+		description = joinParagraphs(
+			sectionsMarkdown(b.sections[:kept]),
+			omissionNotice(b.sections[kept:]),
+			b.footer,
+		)
+		if len([]rune(description)) <= limit {
+			return description, sectionsMarkdown(b.sections[kept:])
+		}
+	}
+	description, _ = fitDescriptionTo(limit, b.sections[0].markdown(), b.footer)
+	return description, sectionsMarkdown(b.sections)
+}
+
+// omissionNotice returns a notice naming the specified sections, which were
+// omitted from a PR description for length.
+func omissionNotice(sections []descriptionSection) string {
+	headings := make([]string, len(sections))
+	for i, section := range sections {
+		headings[i] = section.heading
+		if headings[i] == "" {
+			headings[i] = "untitled section"
+		}
+	}
+	return fmt.Sprintf("_(Omitted for length: %s.)_", strings.Join(headings, ", "))
+}
+
+// sectionsMarkdown returns the specified sections as markdown, separated by
+// blank lines.
+func sectionsMarkdown(sections []descriptionSection) string {
+	parts := make([]string, len(sections))
+	for i, section := range sections {
+		parts[i] = section.markdown()
+	}
+	return joinParagraphs(parts...)
+}
+
+// joinParagraphs joins the specified non-empty strings with blank lines.
+func joinParagraphs(parts ...string) string {
+	nonEmpty := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "\n\n")
+}
+
+// bulletList returns the specified non-empty items as a markdown bulleted
+// list.
+func bulletList(items []string) string {
+	var b strings.Builder
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			fmt.Fprintf(&b, "- %s\n", item)
+		}
+	}
+	return b.String()
+}
+
+// descriptionBudget returns the number of characters that remain for the body
+// of the description of a PR opened with the specified options once everything
+// else OpenPR includes in the description is accounted for.
+func descriptionBudget(opts *OpenPROptions) int {
+	trailers := PreviewPR("", "", nil, opts).Description
+	return max(maxDescriptionLength-len([]rune(trailers)), 1)
+}
@@ -0,0 +1,246 @@
+package azuredevops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestDescriptionBuilderString(t *testing.T) {
+	testCases := []struct {
+		name       string
+		builder    *DescriptionBuilder
+		assertions func(t *testing.T, desc string)
+	}{
+		{
+			name:    "empty",
+			builder: NewDescriptionBuilder(),
+			assertions: func(t *testing.T, desc string) {
+				require.Empty(t, desc)
+			},
+		},
+		{
+			name: "all sections",
+			builder: NewDescriptionBuilder().
+				Summary("Promotes the latest changes to dev.").
+				Changes("bump replicas", "", "add probe").
+				Images("nginx:1.27.0").
+				Links(
+					DescriptionLink{Text: "Build", URL: "https://ci.example.com/1"},
+					DescriptionLink{URL: "https://example.com/docs"},
+					DescriptionLink{Text: "Nowhere"},
+				).
+				AddSection("Notes", "\n  Handle with care.\n").
+				Footer("Generated by Kargo Render."),
+			assertions: func(t *testing.T, desc string) {
+				require.Equal(
+					t,
+					"### Summary\n\nPromotes the latest changes to dev.\n\n"+
+						"### Changes\n\n- bump replicas\n- add probe\n\n"+
+						"### Images\n\n- `nginx:1.27.0`\n\n"+
+						"### Links\n\n- [Build](https://ci.example.com/1)\n"+
+						"- [https://example.com/docs](https://example.com/docs)\n\n"+
+						"### Notes\n\nHandle with care.\n\n"+
+						"Generated by Kargo Render.",
+					desc,
+				)
+			},
+		},
+		{
+			name: "empty sections omitted",
+			builder: NewDescriptionBuilder().
+				Summary("Summary.").
+				Changes().
+				Images("").
+				Links(DescriptionLink{Text: "Nowhere"}),
+			assertions: func(t *testing.T, desc string) {
+				require.Equal(t, "### Summary\n\nSummary.", desc)
+			},
+		},
+		{
+			name:    "footer only",
+			builder: (&DescriptionBuilder{}).Footer("footer"),
+			assertions: func(t *testing.T, desc string) {
+				require.Equal(t, "footer", desc)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.assertions(t, testCase.builder.String())
+		})
+	}
+}
+
+func TestDescriptionBuilderBuild(t *testing.T) {
+	builder := NewDescriptionBuilder().
+		Summary("Short summary.").
+		Changes(strings.Repeat("c", 200)).
+		Links(DescriptionLink{URL: "https://example.com"}).
+		Footer("footer")
+	full := builder.String()
+	testCases := []struct {
+		name       string
+		builder    *DescriptionBuilder
+		limit      int
+		assertions func(t *testing.T, desc, overflow string)
+	}{
+		{
+			name:    "within limit",
+			builder: builder,
+			limit:   len(full),
+			assertions: func(t *testing.T, desc, overflow string) {
+				require.Equal(t, full, desc)
+				require.Empty(t, overflow)
+			},
+		},
+		{
+			name:    "no limit",
+			builder: builder,
+			assertions: func(t *testing.T, desc, overflow string) {
+				require.Equal(t, full, desc)
+				require.Empty(t, overflow)
+			},
+		},
+		{
+			name:    "trailing sections overflow",
+			builder: builder,
+			limit:   100,
+			assertions: func(t *testing.T, desc, overflow string) {
+				require.Equal(
+					t,
+					"### Summary\n\nShort summary.\n\n"+
+						"_(Omitted for length: Changes, Links.)_\n\n"+
+						"footer",
+					desc,
+				)
+				require.Equal(
+					t,
+					"### Changes\n\n- "+strings.Repeat("c", 200)+"\n\n"+
+						"### Links\n\n- [https://example.com](https://example.com)",
+					overflow,
+				)
+			},
+		},
+		{
+			name: "first section truncated",
+			builder: NewDescriptionBuilder().
+				Summary(strings.Repeat("s", 200)).
+				Links(DescriptionLink{URL: "https://example.com"}).
+				Footer("footer"),
+			limit: 80,
+			assertions: func(t *testing.T, desc, overflow string) {
+				require.Len(t, []rune(desc), 80)
+				require.True(t, strings.HasPrefix(desc, "### Summary\n\nsss"))
+				require.True(t, strings.HasSuffix(desc, truncationNotice+"\n\nfooter"))
+				require.Equal(
+					t,
+					"### Summary\n\n"+strings.Repeat("s", 200)+"\n\n"+
+						"### Links\n\n- [https://example.com](https://example.com)",
+					overflow,
+				)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			desc, overflow := testCase.builder.Build(testCase.limit)
+			if testCase.limit > 0 {
+				require.LessOrEqual(t, len([]rune(desc)), testCase.limit)
+			}
+			testCase.assertions(t, desc, overflow)
+		})
+	}
+}
+
+func TestOpenPRDescriptionBuilder(t *testing.T) {
+	changes := make([]string, 300)
+	for i := range changes {
+		changes[i] = "a change that is described at some length"
+	}
+	testCases := []struct {
+		name        string
+		description string
+		builder     *DescriptionBuilder
+		assertions  func(t *testing.T, pr git.GitPullRequest, comments []string, err error)
+	}{
+		{
+			name:    "within limit",
+			builder: NewDescriptionBuilder().Summary("Summary.").Footer("footer"),
+			assertions: func(t *testing.T, pr git.GitPullRequest, comments []string, err error) {
+				require.NoError(t, err)
+				require.True(t, strings.HasPrefix(
+					*pr.Description,
+					"### Summary\n\nSummary.\n\nfooter",
+				))
+				require.Empty(t, comments)
+			},
+		},
+		{
+			name:    "overflowing sections posted as a comment",
+			builder: NewDescriptionBuilder().Summary("Summary.").Changes(changes...),
+			assertions: func(t *testing.T, pr git.GitPullRequest, comments []string, err error) {
+				require.NoError(t, err)
+				require.LessOrEqual(t, len([]rune(*pr.Description)), maxDescriptionLength)
+				require.Contains(t, *pr.Description, "_(Omitted for length: Changes.)_")
+				require.Contains(t, *pr.Description, idempotencyKeyMarker("key"))
+				require.NotContains(t, *pr.Description, truncationNotice)
+				require.Len(t, comments, 1)
+				require.True(t, strings.HasPrefix(comments[0], "### Changes\n\n"))
+			},
+		},
+		{
+			name:        "description and builder",
+			description: "description",
+			builder:     NewDescriptionBuilder().Summary("Summary."),
+			assertions: func(t *testing.T, _ git.GitPullRequest, _ []string, err error) {
+				require.ErrorContains(t, err, "may not both be specified")
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var pr git.GitPullRequest
+			var comments []string
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPullRequestsFn: func(
+					context.Context,
+					git.GetPullRequestsArgs,
+				) (*[]git.GitPullRequest, error) {
+					return &[]git.GitPullRequest{}, nil
+				},
+				createPullRequestFn: fakeCreatePullRequest(&pr),
+				createThreadFn: func(
+					_ context.Context,
+					args git.CreateThreadArgs,
+				) (*git.GitPullRequestCommentThread, error) {
+					for _, c := range *args.CommentThread.Comments {
+						comments = append(comments, *c.Content)
+					}
+					return args.CommentThread, nil
+				},
+			})
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				testCase.description,
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "token"},
+				&OpenPROptions{
+					IdempotencyKey:    "key",
+					OverflowToComment: true,
+					Description:       testCase.builder,
+				},
+			)
+			testCase.assertions(t, pr, comments, err)
+		})
+	}
+}
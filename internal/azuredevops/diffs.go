@@ -0,0 +1,208 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// ErrNoChanges is returned when the source branch of a prospective PR contains
+// no commits that are not already present in the target branch.
+var ErrNoChanges = errors.New("source branch has no changes that are not already in the target branch")
+
+// ErrUnrelatedHistories is returned when two branches that are being compared
+// have no common ancestor, so neither can be said to be ahead of or behind the
+// other.
+var ErrUnrelatedHistories = errors.New("branches have unrelated histories")
+
+// IsBranchMerged returns a bool indicating whether all commits in the source
+// branch are already present in the target branch of the specified
+// repository, i.e. whether a PR from the former to the latter would have no
+// changes. Unlike OpenPROptions.SkipIfNoChanges, this can be used to avoid
+// rendering changes that have already been merged in the first place.
+func IsBranchMerged(
+	ctx context.Context,
+	repoURL string,
+	sourceBranch string,
+	targetBranch string,
+	creds gitutil.RepoCredentials,
+) (_ bool, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return false, err
+	}
+	hasChanges, err := hasNewCommits(ctx, repo, targetBranch, sourceBranch)
+	if err != nil {
+		return false, err
+	}
+	return !hasChanges, nil
+}
+
+// AheadBehind returns the number of commits in the head branch that are not in
+// the base branch and the number of commits in the base branch that are not in
+// the head branch of the specified repository. Branch names may be specified
+// with or without a refs/heads/ prefix. If the branches have no common
+// ancestor, an error wrapping ErrUnrelatedHistories is returned.
+func AheadBehind(
+	ctx context.Context,
+	repoURL string,
+	base string,
+	head string,
+	creds gitutil.RepoCredentials,
+) (ahead, behind int, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	diffs, err := getCommitDiffs(ctx, repo, base, head, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	return aheadBehindOf(diffs, base, head)
+}
+
+// aheadBehindOf returns the ahead and behind counts of the specified comparison
+// of the head branch to the base branch.
+func aheadBehindOf(
+	diffs *git.GitCommitDiffs,
+	base string,
+	head string,
+) (int, int, error) {
+	if diffs == nil {
+		return 0, 0, fmt.Errorf(
+			"%w: no comparison of branch %q to branch %q",
+			ErrIncompleteResponse,
+			head,
+			base,
+		)
+	}
+	if diffs.CommonCommit == nil || *diffs.CommonCommit == "" {
+		return 0, 0, fmt.Errorf(
+			"%w: branch %q has no common ancestor with branch %q",
+			ErrUnrelatedHistories,
+			head,
+			base,
+		)
+	}
+	if diffs.AheadCount == nil || diffs.BehindCount == nil {
+		return 0, 0, fmt.Errorf(
+			"%w: comparison of branch %q to branch %q has no ahead or behind count",
+			ErrIncompleteResponse,
+			head,
+			base,
+		)
+	}
+	return *diffs.AheadCount, *diffs.BehindCount, nil
+}
+
+// EnsureContentChanged returns an error wrapping ErrNoChanges if the head
+// commit of the specified branch already has the tree with the specified ID,
+// i.e. if pushing a commit with that tree to the branch would change nothing.
+// Since a tree's ID is a hash of its content, callers can compute the ID of the
+// tree they are about to push, for instance with git write-tree, and use this
+// to skip both pushing and opening a PR when rendering is idempotent. The
+// branch name may be specified with or without a refs/heads/ prefix. It is not
+// an error if the branch does not exist.
+func EnsureContentChanged(
+	ctx context.Context,
+	repoURL string,
+	branch string,
+	treeID string,
+	creds gitutil.RepoCredentials,
+) (err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return err
+	}
+	branch = ensureRefFormat(branch)
+	ref, err := getRef(ctx, repo, branch)
+	if err != nil {
+		return err
+	}
+	if ref == nil || ref.ObjectId == nil {
+		return nil
+	}
+	commit, err := repo.client.GetCommit(ctx, git.GetCommitArgs{
+		Project:      &repo.project,
+		RepositoryId: &repo.id,
+		CommitId:     ref.ObjectId,
+	})
+	if err != nil {
+		return fmt.Errorf("error getting head commit of branch %q: %w", branch, err)
+	}
+	if commit == nil || commit.TreeId == nil {
+		return fmt.Errorf(
+			"%w: head commit of branch %q has no tree",
+			ErrIncompleteResponse,
+			branch,
+		)
+	}
+	if strings.EqualFold(*commit.TreeId, treeID) {
+		return fmt.Errorf(
+			"%w: head commit %s of branch %q already has tree %s",
+			ErrNoChanges,
+			*ref.ObjectId,
+			branch,
+			treeID,
+		)
+	}
+	return nil
+}
+
+// getCommitDiffs compares the head branch to the base branch, returning at
+// most top changes.
+func getCommitDiffs(
+	ctx context.Context,
+	repo *repoClient,
+	base string,
+	head string,
+	top int,
+) (*git.GitCommitDiffs, error) {
+	base = strings.TrimPrefix(base, "refs/heads/")
+	head = strings.TrimPrefix(head, "refs/heads/")
+	diffs, err := repo.client.GetCommitDiffs(ctx, git.GetCommitDiffsArgs{
+		Project:      &repo.project,
+		RepositoryId: &repo.id,
+		Top:          &top,
+		BaseVersionDescriptor: &git.GitBaseVersionDescriptor{
+			BaseVersion:     &base,
+			BaseVersionType: &git.GitVersionTypeValues.Branch,
+		},
+		TargetVersionDescriptor: &git.GitTargetVersionDescriptor{
+			TargetVersion:     &head,
+			TargetVersionType: &git.GitVersionTypeValues.Branch,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error comparing branch %q to branch %q: %w",
+			head,
+			base,
+			err,
+		)
+	}
+	return diffs, nil
+}
+
+// hasNewCommits returns a bool indicating whether the head branch contains any
+// commits that are not already present in the base branch.
+func hasNewCommits(
+	ctx context.Context,
+	repo *repoClient,
+	base string,
+	head string,
+) (bool, error) {
+	diffs, err := getCommitDiffs(ctx, repo, base, head, 1)
+	if err != nil {
+		return false, err
+	}
+	return diffs != nil && diffs.AheadCount != nil && *diffs.AheadCount > 0, nil
+}
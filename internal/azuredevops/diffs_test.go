@@ -0,0 +1,304 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestOpenPRSkipIfNoChanges(t *testing.T) {
+	testCases := []struct {
+		name       string
+		ahead      int
+		assertions func(t *testing.T, url string, created bool, err error)
+	}{
+		{
+			name:  "target already contains source",
+			ahead: 0,
+			assertions: func(t *testing.T, _ string, created bool, err error) {
+				require.ErrorIs(t, err, ErrNoChanges)
+				require.False(t, created)
+			},
+		},
+		{
+			name:  "source has new commits",
+			ahead: 2,
+			assertions: func(t *testing.T, url string, created bool, err error) {
+				require.NoError(t, err)
+				require.True(t, created)
+				require.NotEmpty(t, url)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var created bool
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getCommitDiffsFn: func(
+					_ context.Context,
+					args git.GetCommitDiffsArgs,
+				) (*git.GitCommitDiffs, error) {
+					require.Equal(t, "env/dev", *args.BaseVersionDescriptor.BaseVersion)
+					require.Equal(
+						t,
+						"prs/kargo-render/env/dev",
+						*args.TargetVersionDescriptor.TargetVersion,
+					)
+					behind := 3
+					return &git.GitCommitDiffs{
+						AheadCount:  &testCase.ahead,
+						BehindCount: &behind,
+					}, nil
+				},
+				createPullRequestFn: func(
+					ctx context.Context,
+					args git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					created = true
+					return fakeCreatePullRequest(nil)(ctx, args)
+				},
+			})
+			url, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				&OpenPROptions{SkipIfNoChanges: true},
+			)
+			testCase.assertions(t, url, created, err)
+		})
+	}
+}
+
+func TestIsBranchMerged(t *testing.T) {
+	testCases := []struct {
+		name       string
+		diffs      *git.GitCommitDiffs
+		diffsErr   error
+		assertions func(t *testing.T, merged bool, err error)
+	}{
+		{
+			name:  "merged",
+			diffs: &git.GitCommitDiffs{AheadCount: ptr(0), BehindCount: ptr(4)},
+			assertions: func(t *testing.T, merged bool, err error) {
+				require.NoError(t, err)
+				require.True(t, merged)
+			},
+		},
+		{
+			name:  "not merged",
+			diffs: &git.GitCommitDiffs{AheadCount: ptr(1), BehindCount: ptr(0)},
+			assertions: func(t *testing.T, merged bool, err error) {
+				require.NoError(t, err)
+				require.False(t, merged)
+			},
+		},
+		{
+			name:     "error comparing branches",
+			diffsErr: errors.New("something went wrong"),
+			assertions: func(t *testing.T, merged bool, err error) {
+				require.ErrorContains(t, err, "error comparing branch")
+				require.False(t, merged)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getCommitDiffsFn: func(
+					_ context.Context,
+					args git.GetCommitDiffsArgs,
+				) (*git.GitCommitDiffs, error) {
+					require.Equal(t, "env/dev", *args.BaseVersionDescriptor.BaseVersion)
+					require.Equal(
+						t,
+						"prs/kargo-render/env/dev",
+						*args.TargetVersionDescriptor.TargetVersion,
+					)
+					return testCase.diffs, testCase.diffsErr
+				},
+			})
+			merged, err := IsBranchMerged(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"refs/heads/prs/kargo-render/env/dev",
+				"env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+			)
+			testCase.assertions(t, merged, err)
+		})
+	}
+}
+
+func TestAheadBehind(t *testing.T) {
+	testCases := []struct {
+		name       string
+		diffs      *git.GitCommitDiffs
+		diffsErr   error
+		assertions func(t *testing.T, ahead, behind int, err error)
+	}{
+		{
+			name: "ahead and behind",
+			diffs: &git.GitCommitDiffs{
+				AheadCount:   ptr(2),
+				BehindCount:  ptr(5),
+				CommonCommit: ptr("abc123"),
+			},
+			assertions: func(t *testing.T, ahead, behind int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 2, ahead)
+				require.Equal(t, 5, behind)
+			},
+		},
+		{
+			name: "up to date",
+			diffs: &git.GitCommitDiffs{
+				AheadCount:   ptr(0),
+				BehindCount:  ptr(0),
+				CommonCommit: ptr("abc123"),
+			},
+			assertions: func(t *testing.T, ahead, behind int, err error) {
+				require.NoError(t, err)
+				require.Zero(t, ahead)
+				require.Zero(t, behind)
+			},
+		},
+		{
+			name:  "unrelated histories",
+			diffs: &git.GitCommitDiffs{AheadCount: ptr(3), BehindCount: ptr(7)},
+			assertions: func(t *testing.T, _, _ int, err error) {
+				require.ErrorIs(t, err, ErrUnrelatedHistories)
+				require.ErrorContains(t, err, "no common ancestor")
+			},
+		},
+		{
+			name:  "missing counts",
+			diffs: &git.GitCommitDiffs{CommonCommit: ptr("abc123")},
+			assertions: func(t *testing.T, _, _ int, err error) {
+				require.ErrorIs(t, err, ErrIncompleteResponse)
+			},
+		},
+		{
+			name: "no comparison",
+			assertions: func(t *testing.T, _, _ int, err error) {
+				require.ErrorIs(t, err, ErrIncompleteResponse)
+			},
+		},
+		{
+			name:     "error comparing branches",
+			diffsErr: errors.New("something went wrong"),
+			assertions: func(t *testing.T, _, _ int, err error) {
+				require.ErrorContains(t, err, "error comparing branch")
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getCommitDiffsFn: func(
+					_ context.Context,
+					args git.GetCommitDiffsArgs,
+				) (*git.GitCommitDiffs, error) {
+					require.Equal(t, "main", *args.BaseVersionDescriptor.BaseVersion)
+					require.Equal(t, "env/dev", *args.TargetVersionDescriptor.TargetVersion)
+					return testCase.diffs, testCase.diffsErr
+				},
+			})
+			ahead, behind, err := AheadBehind(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"refs/heads/main",
+				"env/dev",
+				gitutil.RepoCredentials{Password: "token"},
+			)
+			testCase.assertions(t, ahead, behind, err)
+		})
+	}
+}
+
+func TestEnsureContentChanged(t *testing.T) {
+	const (
+		headRef  = "refs/heads/prs/kargo-render/env/dev"
+		headTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+	)
+	testCases := []struct {
+		name       string
+		refs       []git.GitRef
+		treeID     string
+		assertions func(t *testing.T, err error)
+	}{
+		{
+			name:   "identical content",
+			refs:   []git.GitRef{{Name: ptr(headRef), ObjectId: ptr("abc123")}},
+			treeID: headTree,
+			assertions: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrNoChanges)
+				require.ErrorContains(t, err, "abc123")
+			},
+		},
+		{
+			name:   "identical content with different case",
+			refs:   []git.GitRef{{Name: ptr(headRef), ObjectId: ptr("abc123")}},
+			treeID: "4B825DC642CB6EB9A060E54BF8D69288FBEE4904",
+			assertions: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrNoChanges)
+			},
+		},
+		{
+			name:   "changed content",
+			refs:   []git.GitRef{{Name: ptr(headRef), ObjectId: ptr("abc123")}},
+			treeID: "d8329fc1cc938780ffdd9f94e0d364e0ea74f579",
+			assertions: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "branch does not exist",
+			refs:   []git.GitRef{{Name: ptr(headRef + "-other"), ObjectId: ptr("abc123")}},
+			treeID: headTree,
+			assertions: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getRefsFn: func(
+					_ context.Context,
+					args git.GetRefsArgs,
+				) (*git.GetRefsResponseValue, error) {
+					require.Equal(t, "heads/prs/kargo-render/env/dev", *args.Filter)
+					return &git.GetRefsResponseValue{Value: testCase.refs}, nil
+				},
+				getCommitFn: func(
+					_ context.Context,
+					args git.GetCommitArgs,
+				) (*git.GitCommit, error) {
+					require.Equal(t, "abc123", *args.CommitId)
+					return &git.GitCommit{CommitId: args.CommitId, TreeId: ptr(headTree)}, nil
+				},
+			})
+			err := EnsureContentChanged(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"prs/kargo-render/env/dev",
+				testCase.treeID,
+				gitutil.RepoCredentials{Password: "token"},
+			)
+			testCase.assertions(t, err)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+)
+
+// isStaleDiscovery returns a bool indicating whether the specified error was
+// returned by the Azure DevOps SDK because an API it had discovered earlier
+// could not be found among those it had discovered, as happens when the
+// server has since been upgraded or moved. Such errors occur before any
+// request is sent, so even non-idempotent calls are safe to make again. The
+// SDK returns these errors both by value and by pointer, so both are handled.
+func isStaleDiscovery(err error) bool {
+	var locationErr azuredevops.LocationIdNotRegisteredError
+	var locationErrPtr *azuredevops.LocationIdNotRegisteredError
+	var areaErr azuredevops.ResourceAreaIdNotRegisteredError
+	var areaErrPtr *azuredevops.ResourceAreaIdNotRegisteredError
+	return errors.As(err, &locationErr) || errors.As(err, &locationErrPtr) ||
+		errors.As(err, &areaErr) || errors.As(err, &areaErrPtr)
+}
+
+// current returns the client calls should be made through: the replacement
+// for the embedded client, if it has been replaced, or the embedded client
+// otherwise.
+func (r *retryingGitClient) current() git.Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.refreshed != nil {
+		return r.refreshed
+	}
+	return r.Client
+}
+
+// refresh replaces the specified client, which a call failed with stale API
+// discovery through, by rediscovering Azure DevOps' APIs. If the client has
+// already been replaced by a concurrent call, it is not replaced again.
+func (r *retryingGitClient) refresh(ctx context.Context, stale git.Client) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if current := r.refreshed; current != nil && current != stale {
+		return nil
+	}
+	client, err := r.rediscover(ctx)
+	if err != nil {
+		return fmt.Errorf("error rediscovering Azure DevOps APIs: %w", err)
+	}
+	r.refreshed = client
+	return nil
+}
+
+// rediscovering returns a function that invokes the specified Azure DevOps API
+// call, which must be made through r.current(), and, if the call fails because
+// of stale API discovery and r can rediscover APIs, makes it once more after
+// doing so.
+func rediscovering[T any](
+	r *retryingGitClient,
+	fn func(context.Context) (T, error),
+) func(context.Context) (T, error) {
+	return func(ctx context.Context) (T, error) {
+		stale := r.current()
+		res, err := fn(ctx)
+		if err == nil || r.rediscover == nil || !isStaleDiscovery(err) {
+			return res, err
+		}
+		if refreshErr := r.refresh(ctx, stale); refreshErr != nil {
+			return res, errors.Join(err, refreshErr)
+		}
+		return fn(ctx)
+	}
+}
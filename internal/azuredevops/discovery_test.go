@@ -0,0 +1,134 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestIsStaleDiscovery(t *testing.T) {
+	testCases := []struct {
+		name  string
+		err   error
+		stale bool
+	}{
+		{
+			name:  "location not registered",
+			err:   azuredevops.LocationIdNotRegisteredError{LocationId: uuid.New()},
+			stale: true,
+		},
+		{
+			name:  "location not registered by pointer",
+			err:   &azuredevops.LocationIdNotRegisteredError{LocationId: uuid.New()},
+			stale: true,
+		},
+		{
+			name: "wrapped resource area not registered",
+			err: fmt.Errorf(
+				"error: %w",
+				&azuredevops.ResourceAreaIdNotRegisteredError{ResourceAreaId: uuid.New()},
+			),
+			stale: true,
+		},
+		{
+			name: "other error",
+			err:  errors.New("something went wrong"),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.stale, isStaleDiscovery(testCase.err))
+		})
+	}
+}
+
+func TestOpenPRRefreshStaleDiscovery(t *testing.T) {
+	testCases := []struct {
+		name       string
+		refresh    bool
+		assertions func(t *testing.T, clients int, err error)
+	}{
+		{
+			name:    "connection rebuilt",
+			refresh: true,
+			assertions: func(t *testing.T, clients int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 2, clients)
+			},
+		},
+		{
+			name: "connection not rebuilt",
+			assertions: func(t *testing.T, clients int, err error) {
+				require.True(t, isStaleDiscovery(err))
+				require.Equal(t, 1, clients)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			stale := &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				createPullRequestFn: func(
+					context.Context,
+					git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					return nil, &azuredevops.LocationIdNotRegisteredError{
+						LocationId: uuid.New(),
+						Url:        "https://dev.azure.com/org",
+					}
+				},
+			}
+			fresh := &fakeGitClient{
+				getRepositoriesFn:   fakeRepos("repo"),
+				createPullRequestFn: fakeCreatePullRequest(nil),
+			}
+			useFakeGitClient(t, stale)
+			var clients int
+			newGitClient = func(context.Context, *azuredevops.Connection) (git.Client, error) {
+				if clients++; clients == 1 {
+					return stale, nil
+				}
+				return fresh, nil
+			}
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "token"},
+				&OpenPROptions{
+					Connection: ConnectionOptions{RefreshStaleDiscovery: testCase.refresh},
+				},
+			)
+			testCase.assertions(t, clients, err)
+		})
+	}
+}
+
+func TestRetryingGitClientRefreshFailure(t *testing.T) {
+	staleErr := azuredevops.LocationIdNotRegisteredError{LocationId: uuid.New()}
+	client := withRetries(
+		&fakeGitClient{
+			getPullRequestFn: func(context.Context, git.GetPullRequestArgs) (*git.GitPullRequest, error) {
+				return nil, staleErr
+			},
+		},
+		nil,
+		func(context.Context) (git.Client, error) {
+			return nil, errors.New("server unreachable")
+		},
+	)
+	_, err := client.GetPullRequest(context.Background(), git.GetPullRequestArgs{})
+	require.ErrorIs(t, err, staleErr)
+	require.ErrorContains(t, err, "error rediscovering Azure DevOps APIs: server unreachable")
+}
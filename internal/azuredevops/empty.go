@@ -0,0 +1,89 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+)
+
+// EmptyPRPolicy specifies how to proceed when a PR that is about to be
+// completed no longer changes anything, e.g. because its changes have since
+// been merged to the target branch by other means. Azure DevOps fails to
+// complete such PRs.
+type EmptyPRPolicy string
+
+const (
+	// EmptyPRPolicyComplete attempts to complete PRs regardless of whether they
+	// are empty, so completing an empty PR fails. This is the default.
+	EmptyPRPolicyComplete EmptyPRPolicy = "complete"
+	// EmptyPRPolicyAbandon abandons empty PRs instead of completing them.
+	EmptyPRPolicyAbandon EmptyPRPolicy = "abandon"
+	// EmptyPRPolicySkip leaves empty PRs active, without completing them.
+	EmptyPRPolicySkip EmptyPRPolicy = "skip"
+)
+
+// handleEmptyPR applies the specified policy to the specified PR if it is
+// empty. It returns a bool indicating whether the PR was empty and, therefore,
+// should not be completed.
+func handleEmptyPR(
+	ctx context.Context,
+	repo *repoClient,
+	pr *git.GitPullRequest,
+	policy EmptyPRPolicy,
+) (bool, error) {
+	switch policy {
+	case "", EmptyPRPolicyComplete:
+		return false, nil
+	case EmptyPRPolicyAbandon, EmptyPRPolicySkip:
+	default:
+		return false, fmt.Errorf("unknown empty PR policy %q", policy)
+	}
+	empty, err := isEmptyPR(ctx, repo, pr)
+	if err != nil || !empty {
+		return false, err
+	}
+	if policy == EmptyPRPolicyAbandon {
+		if err = abandonPR(ctx, repo, *pr); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// isEmptyPR returns a bool indicating whether merging the specified PR would
+// leave its target branch unchanged. This is determined by comparing the
+// result of Azure DevOps' most recent merge attempt to the target commit it
+// merged into, so a PR whose merge has not yet been attempted is never
+// considered empty.
+func isEmptyPR(ctx context.Context, repo *repoClient, pr *git.GitPullRequest) (bool, error) {
+	if pr.LastMergeCommit == nil || pr.LastMergeCommit.CommitId == nil ||
+		pr.LastMergeTargetCommit == nil || pr.LastMergeTargetCommit.CommitId == nil {
+		return false, nil
+	}
+	top := 1
+	diffCommonCommit := false
+	diffs, err := repo.client.GetCommitDiffs(ctx, git.GetCommitDiffsArgs{
+		Project:          &repo.project,
+		RepositoryId:     &repo.id,
+		Top:              &top,
+		DiffCommonCommit: &diffCommonCommit,
+		BaseVersionDescriptor: &git.GitBaseVersionDescriptor{
+			BaseVersion:     pr.LastMergeTargetCommit.CommitId,
+			BaseVersionType: &git.GitVersionTypeValues.Commit,
+		},
+		TargetVersionDescriptor: &git.GitTargetVersionDescriptor{
+			TargetVersion:     pr.LastMergeCommit.CommitId,
+			TargetVersionType: &git.GitVersionTypeValues.Commit,
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf(
+			"error comparing merge commit of pull request %s to its target: %w",
+			prIDString(*pr),
+			err,
+		)
+	}
+	// An absent list of changes is not taken to mean that there are none
+	return diffs != nil && diffs.Changes != nil && len(*diffs.Changes) == 0, nil
+}
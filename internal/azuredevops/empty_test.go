@@ -0,0 +1,130 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestCompleteAfterApprovalEmptyPR(t *testing.T) {
+	testCases := []struct {
+		name       string
+		policy     EmptyPRPolicy
+		changes    []any
+		assertions func(t *testing.T, compared bool, updates []*git.GitPullRequest, err error)
+	}{
+		{
+			name:   "empty PR is abandoned",
+			policy: EmptyPRPolicyAbandon,
+			assertions: func(t *testing.T, compared bool, updates []*git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.True(t, compared)
+				require.Len(t, updates, 1)
+				require.Equal(t, git.PullRequestStatusValues.Abandoned, *updates[0].Status)
+			},
+		},
+		{
+			name:   "empty PR is skipped",
+			policy: EmptyPRPolicySkip,
+			assertions: func(t *testing.T, compared bool, updates []*git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.True(t, compared)
+				require.Empty(t, updates)
+			},
+		},
+		{
+			name: "empty PR is completed by default",
+			assertions: func(t *testing.T, compared bool, updates []*git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.False(t, compared)
+				require.Len(t, updates, 1)
+				require.Equal(t, git.PullRequestStatusValues.Completed, *updates[0].Status)
+			},
+		},
+		{
+			name:    "non-empty PR is completed",
+			policy:  EmptyPRPolicyAbandon,
+			changes: []any{map[string]any{"changeType": "edit"}},
+			assertions: func(t *testing.T, compared bool, updates []*git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.True(t, compared)
+				require.Len(t, updates, 1)
+				require.Equal(t, git.PullRequestStatusValues.Completed, *updates[0].Status)
+			},
+		},
+		{
+			name:   "unknown policy",
+			policy: "bogus",
+			assertions: func(t *testing.T, compared bool, updates []*git.GitPullRequest, err error) {
+				require.ErrorContains(t, err, `unknown empty PR policy "bogus"`)
+				require.False(t, compared)
+				require.Empty(t, updates)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var compared bool
+			var updates []*git.GitPullRequest
+			useFakeIdentity(t, uuid.New())
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPullRequestFn: func(
+					_ context.Context,
+					args git.GetPullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					return &git.GitPullRequest{
+						PullRequestId: args.PullRequestId,
+						TargetRefName: ptr("refs/heads/env/prod"),
+						Reviewers: &[]git.IdentityRefWithVote{
+							{Vote: ptr(int(VoteApproved))},
+						},
+						LastMergeSourceCommit: &git.GitCommitRef{CommitId: ptr("abc123")},
+						LastMergeTargetCommit: &git.GitCommitRef{CommitId: ptr("def456")},
+						LastMergeCommit:       &git.GitCommitRef{CommitId: ptr("789abc")},
+					}, nil
+				},
+				getPolicyConfigurationsFn: func(
+					context.Context,
+					git.GetPolicyConfigurationsArgs,
+				) (*git.GitPolicyConfigurationResponse, error) {
+					return &git.GitPolicyConfigurationResponse{}, nil
+				},
+				getCommitDiffsFn: func(
+					_ context.Context,
+					args git.GetCommitDiffsArgs,
+				) (*git.GitCommitDiffs, error) {
+					compared = true
+					require.False(t, *args.DiffCommonCommit)
+					require.Equal(t, "def456", *args.BaseVersionDescriptor.BaseVersion)
+					require.Equal(t, "789abc", *args.TargetVersionDescriptor.TargetVersion)
+					changes := testCase.changes
+					if changes == nil {
+						changes = []any{}
+					}
+					return &git.GitCommitDiffs{Changes: &changes}, nil
+				},
+				updatePullRequestFn: func(
+					_ context.Context,
+					args git.UpdatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					updates = append(updates, args.GitPullRequestToUpdate)
+					return args.GitPullRequestToUpdate, nil
+				},
+			})
+			err := CompleteAfterApproval(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				42,
+				gitutil.RepoCredentials{Password: "token"},
+				AutoCompleteOptions{EmptyPRPolicy: testCase.policy},
+			)
+			testCase.assertions(t, compared, updates, err)
+		})
+	}
+}
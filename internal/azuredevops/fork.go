@@ -0,0 +1,70 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	"github.com/akuity/kargo-render/internal/repourl"
+)
+
+// forkRepoClient returns a repoClient for the fork, referenced by the
+// specified URL, of the specified target repository. Azure DevOps only
+// supports PRs from forks within the same organization, so an error is
+// returned if the fork belongs to a different organization or if it does not
+// exist.
+func forkRepoClient(
+	ctx context.Context,
+	target *repoClient,
+	forkURL string,
+) (*repoClient, error) {
+	org, project, name, err := parseAzureDevOpsURL(repourl.Normalize(forkURL))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing fork repository URL: %w", err)
+	}
+	if !strings.EqualFold(org, target.org) {
+		return nil, fmt.Errorf(
+			"fork %s/%s/%s must belong to the same organization as the target "+
+				"repository, %q",
+			org,
+			project,
+			name,
+			target.org,
+		)
+	}
+	fork, err := cachedRepository(
+		ctx,
+		target.connection,
+		target.client,
+		project,
+		name,
+		target.cacheTTL,
+		target.renames,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving fork repository: %w", err)
+	}
+	return &repoClient{
+		client:     target.client,
+		connection: target.connection,
+		retry:      target.retry,
+		cacheTTL:   target.cacheTTL,
+		renames:    target.renames,
+		org:        org,
+		project:    project,
+		name:       name,
+		id:         fork.Id.String(),
+		repository: fork,
+	}, nil
+}
+
+// forkSourceOf returns a reference to the specified branch of the specified
+// fork, suitable for use as the source of a PR.
+func forkSourceOf(fork *repoClient, sourceBranch string) *git.GitForkRef {
+	return &git.GitForkRef{
+		Name:       &sourceBranch,
+		Repository: &git.GitRepository{Id: fork.repository.Id},
+	}
+}
@@ -0,0 +1,112 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestOpenPRFromFork(t *testing.T) {
+	upstreamID := uuid.New()
+	forkID := uuid.New()
+	// Each project contains a single repository
+	repos := map[string]git.GitRepository{
+		"proj": {Id: &upstreamID, Name: ptr("repo")},
+		"fork": {Id: &forkID, Name: ptr("repo-fork")},
+	}
+	testCases := []struct {
+		name       string
+		forkURL    string
+		opts       OpenPROptions
+		assertions func(t *testing.T, pr *git.GitPullRequest, err error)
+	}{
+		{
+			name:    "fork exists",
+			forkURL: "https://dev.azure.com/org/fork/_git/repo-fork",
+			assertions: func(t *testing.T, pr *git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, pr)
+				require.NotNil(t, pr.ForkSource)
+				require.Equal(t, "refs/heads/prs/kargo-render/env/dev", *pr.ForkSource.Name)
+				require.Equal(t, forkID, *pr.ForkSource.Repository.Id)
+				require.Equal(t, "refs/heads/prs/kargo-render/env/dev", *pr.SourceRefName)
+			},
+		},
+		{
+			name:    "fork does not exist",
+			forkURL: "https://dev.azure.com/org/fork/_git/no-such-repo",
+			assertions: func(t *testing.T, pr *git.GitPullRequest, err error) {
+				require.ErrorContains(t, err, "error resolving fork repository")
+				require.ErrorContains(t, err, "repository 'no-such-repo' not found")
+				require.Nil(t, pr)
+			},
+		},
+		{
+			name:    "fork in another organization",
+			forkURL: "https://dev.azure.com/other-org/fork/_git/repo-fork",
+			assertions: func(t *testing.T, pr *git.GitPullRequest, err error) {
+				require.ErrorContains(t, err, "must belong to the same organization")
+				require.Nil(t, pr)
+			},
+		},
+		{
+			name:    "skip if no changes",
+			forkURL: "https://dev.azure.com/org/fork/_git/repo-fork",
+			opts:    OpenPROptions{SkipIfNoChanges: true},
+			assertions: func(t *testing.T, pr *git.GitPullRequest, err error) {
+				require.ErrorContains(t, err, "not supported for PRs from forks")
+				require.Nil(t, pr)
+			},
+		},
+		{
+			name:    "source repository matches fork",
+			forkURL: "https://dev.azure.com/org/fork/_git/repo-fork",
+			opts: OpenPROptions{
+				SourceRepoURL: "https://dev.azure.com/org/proj/_git/repo",
+			},
+			assertions: func(t *testing.T, pr *git.GitPullRequest, err error) {
+				require.ErrorIs(t, err, ErrRepositoryMismatch)
+				require.Nil(t, pr)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var pr *git.GitPullRequest
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: func(
+					_ context.Context,
+					args git.GetRepositoriesArgs,
+				) (*[]git.GitRepository, error) {
+					return &[]git.GitRepository{repos[*args.Project]}, nil
+				},
+				createPullRequestFn: func(
+					ctx context.Context,
+					args git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					require.Equal(t, upstreamID.String(), *args.RepositoryId)
+					pr = args.GitPullRequestToCreate
+					return fakeCreatePullRequest(nil)(ctx, args)
+				},
+			})
+			opts := testCase.opts
+			opts.ForkRepoURL = testCase.forkURL
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				&opts,
+			)
+			testCase.assertions(t, pr, err)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package azuredevops
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+)
+
+// idempotencyKeyMarkerPrefix prefixes the hidden comment that embeds an
+// idempotency key in a PR description.
+const idempotencyKeyMarkerPrefix = "<!-- kargo-render-idempotency-key: "
+
+// idempotencyLock is an in-process lock for a single idempotency key.
+type idempotencyLock struct {
+	mu sync.Mutex
+	// holders is the number of callers holding or waiting for mu. It is
+	// guarded by idempotencyLocksMu.
+	holders int
+}
+
+var (
+	idempotencyLocksMu sync.Mutex
+	// idempotencyLocks holds locks for only those keys that are held or waited
+	// for, so that it does not grow with every distinct key.
+	idempotencyLocks = map[string]*idempotencyLock{}
+)
+
+// IdempotencyKey returns a key that uniquely identifies a PR for the specified
+// source branch, target branch, and commit.
+func IdempotencyKey(sourceBranch, targetBranch, commit string) string {
+	sum := sha256.Sum256(
+		[]byte(strings.Join([]string{sourceBranch, targetBranch, commit}, "\n")),
+	)
+	return hex.EncodeToString(sum[:])
+}
+
+// idempotencyKeyMarker returns the hidden comment used to embed the specified
+// idempotency key in a PR description.
+func idempotencyKeyMarker(key string) string {
+	return fmt.Sprintf("%s%s -->", idempotencyKeyMarkerPrefix, key)
+}
+
+// lockIdempotencyKey acquires an in-process lock for the specified idempotency
+// key and returns a function that releases it. This prevents concurrent
+// OpenPR calls within the same process from racing between looking up and
+// creating a PR.
+func lockIdempotencyKey(key string) func() {
+	idempotencyLocksMu.Lock()
+	lock, ok := idempotencyLocks[key]
+	if !ok {
+		lock = &idempotencyLock{}
+		idempotencyLocks[key] = lock
+	}
+	lock.holders++
+	idempotencyLocksMu.Unlock()
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+		idempotencyLocksMu.Lock()
+		defer idempotencyLocksMu.Unlock()
+		if lock.holders--; lock.holders == 0 {
+			delete(idempotencyLocks, key)
+		}
+	}
+}
+
+// DuplicatePRPolicy specifies how to proceed when more than one active PR
+// matches an idempotency key.
+type DuplicatePRPolicy string
+
+const (
+	// DuplicatePRPolicyError refuses to proceed and returns an error wrapping
+	// ErrDuplicatePRs. This is the default, as duplicates are an anomaly that
+	// warrants attention.
+	DuplicatePRPolicyError DuplicatePRPolicy = "error"
+	// DuplicatePRPolicyFirstMatch proceeds with the oldest matching PR and
+	// leaves the others untouched.
+	DuplicatePRPolicyFirstMatch DuplicatePRPolicy = "firstMatch"
+	// DuplicatePRPolicyAbandonExtras proceeds with the oldest matching PR and
+	// abandons the others.
+	DuplicatePRPolicyAbandonExtras DuplicatePRPolicy = "abandonExtras"
+)
+
+// ErrDuplicatePRs is returned when more than one active PR matches an
+// idempotency key and DuplicatePRPolicyError is in effect.
+var ErrDuplicatePRs = errors.New("multiple active pull requests match")
+
+// findPRByIdempotencyKey returns the active PR from the source branch to the
+// target branch whose description carries the specified idempotency key and,
+// if it is non-empty, the specified marker. If no such PR exists, nil is
+// returned. If more than one such PR exists, the specified policy determines
+// the outcome.
+func findPRByIdempotencyKey(
+	ctx context.Context,
+	repo *repoClient,
+	sourceBranch string,
+	targetBranch string,
+	key string,
+	marker string,
+	policy DuplicatePRPolicy,
+) (*git.GitPullRequest, error) {
+	prs, err := listActivePRs(ctx, repo, sourceBranch, targetBranch)
+	if err != nil {
+		return nil, err
+	}
+	keyMarker := idempotencyKeyMarker(key)
+	var matches []git.GitPullRequest
+	for _, pr := range prs {
+		if hasMarkers(pr, keyMarker, marker) {
+			matches = append(matches, pr)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	sortOldestFirst(matches)
+	if len(matches) == 1 {
+		return &matches[0], nil
+	}
+	switch policy {
+	case "", DuplicatePRPolicyError:
+		ids := make([]string, len(matches))
+		for i, pr := range matches {
+			ids[i] = prIDString(pr)
+		}
+		return nil, fmt.Errorf(
+			"%w idempotency key %s: pull requests %s",
+			ErrDuplicatePRs,
+			key,
+			strings.Join(ids, ", "),
+		)
+	case DuplicatePRPolicyFirstMatch:
+	case DuplicatePRPolicyAbandonExtras:
+		for _, extra := range matches[1:] {
+			if err = abandonPR(ctx, repo, extra); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown duplicate PR policy %q", policy)
+	}
+	return &matches[0], nil
+}
+
+// hasMarkers returns a bool indicating whether the description of the
+// specified PR contains all of the specified markers. Empty markers are
+// ignored.
+func hasMarkers(pr git.GitPullRequest, markers ...string) bool {
+	if pr.Description == nil {
+		return false
+	}
+	for _, marker := range markers {
+		if marker != "" && !strings.Contains(*pr.Description, marker) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortOldestFirst sorts the specified PRs by creation date, oldest first. PRs
+// without a creation date are ordered by ID.
+func sortOldestFirst(prs []git.GitPullRequest) {
+	sort.SliceStable(prs, func(i, j int) bool {
+		a, b := prs[i], prs[j]
+		if a.CreationDate != nil && b.CreationDate != nil &&
+			!a.CreationDate.Time.Equal(b.CreationDate.Time) {
+			return a.CreationDate.Time.Before(b.CreationDate.Time)
+		}
+		if a.PullRequestId != nil && b.PullRequestId != nil {
+			return *a.PullRequestId < *b.PullRequestId
+		}
+		return false
+	})
+}
+
+// prIDString returns the ID of the specified PR as a string, or "unknown" if it
+// has none.
+func prIDString(pr git.GitPullRequest) string {
+	if pr.PullRequestId == nil {
+		return "unknown"
+	}
+	return strconv.Itoa(*pr.PullRequestId)
+}
+
+// abandonPR abandons the specified PR.
+func abandonPR(ctx context.Context, repo *repoClient, pr git.GitPullRequest) error {
+	if pr.PullRequestId == nil {
+		return fmt.Errorf("%w: pull request has no ID", ErrIncompleteResponse)
+	}
+	if _, err := repo.client.UpdatePullRequest(ctx, git.UpdatePullRequestArgs{
+		Project:       &repo.project,
+		RepositoryId:  &repo.id,
+		PullRequestId: pr.PullRequestId,
+		GitPullRequestToUpdate: &git.GitPullRequest{
+			Status: &git.PullRequestStatusValues.Abandoned,
+		},
+	}); err != nil {
+		return fmt.Errorf("error abandoning pull request %d: %w", *pr.PullRequestId, err)
+	}
+	return nil
+}
@@ -0,0 +1,351 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	key := IdempotencyKey("src", "dst", "abc")
+	require.Equal(t, key, IdempotencyKey("src", "dst", "abc"))
+	require.NotEqual(t, key, IdempotencyKey("src", "dst", "def"))
+	require.NotEqual(t, key, IdempotencyKey("src", "other", "abc"))
+}
+
+func TestLockIdempotencyKey(t *testing.T) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		held    = map[string]int{}
+		overlap bool
+	)
+	for i := range 20 {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			unlock := lockIdempotencyKey(key)
+			defer unlock()
+			mu.Lock()
+			if held[key]++; held[key] > 1 {
+				overlap = true
+			}
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			held[key]--
+			mu.Unlock()
+		}(fmt.Sprintf("key-%d", i%2))
+	}
+	wg.Wait()
+	require.False(t, overlap, "a key's lock must not be held concurrently")
+	idempotencyLocksMu.Lock()
+	defer idempotencyLocksMu.Unlock()
+	require.Empty(t, idempotencyLocks, "released locks must not be retained")
+}
+
+func TestOpenPRIdempotency(t *testing.T) {
+	// A minimal, concurrency-safe, in-memory PR store
+	var mu sync.Mutex
+	var prs []git.GitPullRequest
+	var creates int
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: fakeRepos("repo"),
+		getPullRequestsFn: func(
+			context.Context,
+			git.GetPullRequestsArgs,
+		) (*[]git.GitPullRequest, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			res := make([]git.GitPullRequest, len(prs))
+			copy(res, prs)
+			return &res, nil
+		},
+		createPullRequestFn: func(
+			_ context.Context,
+			args git.CreatePullRequestArgs,
+		) (*git.GitPullRequest, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			creates++
+			id := creates
+			url := fmt.Sprintf("https://dev.azure.com/org/proj/_git/repo/pullrequest/%d", id)
+			pr := *args.GitPullRequestToCreate
+			pr.PullRequestId = &id
+			pr.Url = &url
+			prs = append(prs, pr)
+			return &pr, nil
+		},
+	})
+
+	key := IdempotencyKey("prs/kargo-render/env/dev", "env/dev", "abc")
+	const callers = 2
+	urls := make([]string, callers)
+	errs := make([]error, callers)
+	wg := sync.WaitGroup{}
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			urls[i], errs[i] = OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				&OpenPROptions{IdempotencyKey: key},
+			)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, creates)
+	require.Len(t, prs, 1)
+	require.Contains(t, *prs[0].Description, idempotencyKeyMarker(key))
+	// Exactly one caller should have opened the PR; the other should have found
+	// it already open
+	require.ElementsMatch(t, []string{"", *prs[0].Url}, urls)
+}
+
+func TestOpenPRDuplicatePRPolicy(t *testing.T) {
+	key := IdempotencyKey("prs/kargo-render/env/dev", "env/dev", "abc")
+	desc := "description\n\n" + idempotencyKeyMarker(key)
+	now := time.Now()
+	// Deliberately listed newest first
+	duplicates := []git.GitPullRequest{
+		{
+			PullRequestId: ptr(8),
+			Description:   &desc,
+			CreationDate:  &azuredevops.Time{Time: now},
+		},
+		{
+			PullRequestId: ptr(7),
+			Description:   &desc,
+			CreationDate:  &azuredevops.Time{Time: now.Add(-time.Hour)},
+		},
+	}
+	testCases := []struct {
+		name       string
+		policy     DuplicatePRPolicy
+		assertions func(t *testing.T, url string, abandoned []int, err error)
+	}{
+		{
+			name: "default",
+			assertions: func(t *testing.T, _ string, abandoned []int, err error) {
+				require.ErrorIs(t, err, ErrDuplicatePRs)
+				require.ErrorContains(t, err, "pull requests 7, 8")
+				require.Empty(t, abandoned)
+			},
+		},
+		{
+			name:   "error",
+			policy: DuplicatePRPolicyError,
+			assertions: func(t *testing.T, _ string, abandoned []int, err error) {
+				require.ErrorIs(t, err, ErrDuplicatePRs)
+				require.Empty(t, abandoned)
+			},
+		},
+		{
+			name:   "first match",
+			policy: DuplicatePRPolicyFirstMatch,
+			assertions: func(t *testing.T, url string, abandoned []int, err error) {
+				require.NoError(t, err)
+				require.Empty(t, url)
+				require.Empty(t, abandoned)
+			},
+		},
+		{
+			name:   "abandon extras",
+			policy: DuplicatePRPolicyAbandonExtras,
+			assertions: func(t *testing.T, url string, abandoned []int, err error) {
+				require.NoError(t, err)
+				require.Empty(t, url)
+				require.Equal(t, []int{8}, abandoned)
+			},
+		},
+		{
+			name:   "unknown policy",
+			policy: "bogus",
+			assertions: func(t *testing.T, _ string, _ []int, err error) {
+				require.ErrorContains(t, err, `unknown duplicate PR policy "bogus"`)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var abandoned []int
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPullRequestsFn: func(
+					context.Context,
+					git.GetPullRequestsArgs,
+				) (*[]git.GitPullRequest, error) {
+					res := make([]git.GitPullRequest, len(duplicates))
+					copy(res, duplicates)
+					return &res, nil
+				},
+				updatePullRequestFn: func(
+					_ context.Context,
+					args git.UpdatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					require.Equal(
+						t,
+						git.PullRequestStatusValues.Abandoned,
+						*args.GitPullRequestToUpdate.Status,
+					)
+					abandoned = append(abandoned, *args.PullRequestId)
+					return args.GitPullRequestToUpdate, nil
+				},
+				createPullRequestFn: func(
+					context.Context,
+					git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					require.FailNow(t, "no attempt should be made to create a PR")
+					return nil, nil
+				},
+			})
+			url, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				&OpenPROptions{
+					IdempotencyKey:    key,
+					DuplicatePRPolicy: testCase.policy,
+				},
+			)
+			testCase.assertions(t, url, abandoned, err)
+		})
+	}
+}
+
+func TestOpenPRIdempotencyAddsReviewers(t *testing.T) {
+	key := IdempotencyKey("prs/kargo-render/env/dev", "env/dev", "abc")
+	var created []string
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: fakeRepos("repo"),
+		getPullRequestsFn: func(
+			context.Context,
+			git.GetPullRequestsArgs,
+		) (*[]git.GitPullRequest, error) {
+			return &[]git.GitPullRequest{{
+				PullRequestId: ptr(42),
+				Description:   ptr(idempotencyKeyMarker(key)),
+			}}, nil
+		},
+		getPullRequestReviewersFn: func(
+			context.Context,
+			git.GetPullRequestReviewersArgs,
+		) (*[]git.IdentityRefWithVote, error) {
+			return &[]git.IdentityRefWithVote{{Id: ptr("alice-id")}}, nil
+		},
+		createPullRequestReviewerFn: func(
+			_ context.Context,
+			args git.CreatePullRequestReviewerArgs,
+		) (*git.IdentityRefWithVote, error) {
+			created = append(created, *args.ReviewerId)
+			return args.Reviewer, nil
+		},
+	})
+	url, err := OpenPR(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"title",
+		"description",
+		"env/dev",
+		"prs/kargo-render/env/dev",
+		gitutil.RepoCredentials{Password: "token"},
+		&OpenPROptions{
+			IdempotencyKey: key,
+			Reviewers:      []Reviewer{{ID: "alice-id"}, {ID: "bob-id"}},
+		},
+	)
+	require.NoError(t, err)
+	require.Empty(t, url)
+	require.Equal(t, []string{"bob-id"}, created)
+}
+
+func TestOpenPRIdempotencyMarker(t *testing.T) {
+	key := IdempotencyKey("prs/kargo-render/env/dev", "env/dev", "abc")
+	const marker = "<!-- kargo-render: team-a -->"
+	testCases := []struct {
+		name       string
+		existing   string
+		assertions func(t *testing.T, url string, created *git.GitPullRequest, err error)
+	}{
+		{
+			name:     "PR with same marker is reused",
+			existing: "description\n\n" + marker + "\n\n" + idempotencyKeyMarker(key),
+			assertions: func(t *testing.T, url string, created *git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.Empty(t, url)
+				require.Nil(t, created.Description)
+			},
+		},
+		{
+			name: "PR with other marker is ignored",
+			existing: "description\n\n<!-- kargo-render: team-b -->\n\n" +
+				idempotencyKeyMarker(key),
+			assertions: func(t *testing.T, url string, created *git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, url)
+				require.Contains(t, *created.Description, marker)
+				require.Contains(t, *created.Description, idempotencyKeyMarker(key))
+			},
+		},
+		{
+			name:     "PR without marker is ignored",
+			existing: "description\n\n" + idempotencyKeyMarker(key),
+			assertions: func(t *testing.T, url string, created *git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, url)
+				require.Contains(t, *created.Description, marker)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var created git.GitPullRequest
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPullRequestsFn: func(
+					context.Context,
+					git.GetPullRequestsArgs,
+				) (*[]git.GitPullRequest, error) {
+					return &[]git.GitPullRequest{{
+						PullRequestId: ptr(7),
+						Description:   &testCase.existing,
+					}}, nil
+				},
+				createPullRequestFn: fakeCreatePullRequest(&created),
+			})
+			url, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "token"},
+				&OpenPROptions{IdempotencyKey: key, Marker: marker},
+			)
+			testCase.assertions(t, url, &created, err)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/location"
+)
+
+// newLocationClient creates an Azure DevOps Location client. It is a
+// package-level variable so that it can be overridden in tests.
+var newLocationClient = location.NewClient
+
+// identities is the process-wide cache of the IDs of the identities associated
+// with connections.
+var identities = newTTLCache[string]()
+
+// getAuthenticatedIdentityID returns the ID of the identity associated with the
+// credentials used by the specified connection. Successful lookups are
+// memoized for the specified TTL; failed ones are not.
+func getAuthenticatedIdentityID(
+	ctx context.Context,
+	connection *azuredevops.Connection,
+	ttl time.Duration,
+) (string, error) {
+	return identities.get(connectionKey(connection), ttl, func() (string, error) {
+		data, err := newLocationClient(ctx, connection).GetConnectionData(
+			ctx,
+			location.GetConnectionDataArgs{},
+		)
+		if err != nil {
+			return "", fmt.Errorf("error getting connection data: %w", err)
+		}
+		if data == nil || data.AuthenticatedUser == nil ||
+			data.AuthenticatedUser.Id == nil {
+			return "", errors.New("connection data did not identify the authenticated user")
+		}
+		return data.AuthenticatedUser.Id.String(), nil
+	})
+}
@@ -0,0 +1,120 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+)
+
+// LabelRule specifies a label that should be applied to a PR if it changes any
+// file within a specified path.
+type LabelRule struct {
+	// Path is a path, relative to the root of the repository, to a file or
+	// directory. A PR that changes the file, or any file within the
+	// directory, matches the rule.
+	Path string
+	// Label is the label to apply to a PR that matches the rule.
+	Label string
+}
+
+// matches returns a bool indicating whether the specified changed file path
+// falls within the rule's path.
+func (l LabelRule) matches(file string) bool {
+	rulePath := strings.Trim(l.Path, "/")
+	file = strings.TrimPrefix(file, "/")
+	if rulePath == "" {
+		return true
+	}
+	return file == rulePath || strings.HasPrefix(file, rulePath+"/")
+}
+
+// matchLabels returns the labels, in rule order and without duplicates, of all
+// rules matched by any of the specified changed file paths.
+func matchLabels(rules []LabelRule, files []string) []string {
+	var labels []string
+	for _, rule := range rules {
+		for _, file := range files {
+			if rule.matches(file) {
+				labels = appendLabels(labels, rule.Label)
+				break
+			}
+		}
+	}
+	return labels
+}
+
+// appendLabels appends the specified labels to the existing ones, omitting
+// any that are empty or already present. Azure DevOps treats labels as case
+// insensitive, so comparisons are as well.
+func appendLabels(existing []string, labels ...string) []string {
+	for _, label := range labels {
+		if label == "" {
+			continue
+		}
+		var found bool
+		for _, e := range existing {
+			if strings.EqualFold(e, label) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, label)
+		}
+	}
+	return existing
+}
+
+// toTagDefinitions converts the specified labels to the form Azure DevOps
+// expects when creating a PR.
+func toTagDefinitions(labels []string) *[]core.WebApiTagDefinition {
+	if len(labels) == 0 {
+		return nil
+	}
+	defs := make([]core.WebApiTagDefinition, len(labels))
+	for i := range labels {
+		defs[i] = core.WebApiTagDefinition{Name: &labels[i]}
+	}
+	return &defs
+}
+
+// applyLabelRules applies to the specified PR the labels of all specified
+// rules matched by the files it changes, except for those already applied.
+func applyLabelRules(
+	ctx context.Context,
+	repo *repoClient,
+	prID int,
+	rules []LabelRule,
+	applied []string,
+) error {
+	files, err := getPRChangedFiles(ctx, repo, prID)
+	if err != nil {
+		return err
+	}
+	for _, label := range matchLabels(rules, files) {
+		if len(appendLabels(applied, label)) == len(applied) {
+			continue // Already applied
+		}
+		if _, err = repo.client.CreatePullRequestLabel(
+			ctx,
+			git.CreatePullRequestLabelArgs{
+				Project:       &repo.project,
+				RepositoryId:  &repo.id,
+				PullRequestId: &prID,
+				Label:         &core.WebApiCreateTagRequestData{Name: &label},
+			},
+		); err != nil {
+			return fmt.Errorf(
+				"error applying label %q to pull request %d: %w",
+				label,
+				prID,
+				err,
+			)
+		}
+		applied = append(applied, label)
+	}
+	return nil
+}
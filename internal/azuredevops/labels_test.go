@@ -0,0 +1,115 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestMatchLabels(t *testing.T) {
+	rules := []LabelRule{
+		{Path: "charts/", Label: "helm"},
+		{Path: "/overlays", Label: "kustomize"},
+		{Path: "charts/foo/values.yaml", Label: "helm"},
+	}
+	testCases := []struct {
+		name     string
+		files    []string
+		expected []string
+	}{
+		{
+			name:  "no changed files",
+			files: nil,
+		},
+		{
+			name:  "no matching files",
+			files: []string{"/README.md", "/chartsfoo/values.yaml"},
+		},
+		{
+			name:     "single match",
+			files:    []string{"/charts/foo/Chart.yaml"},
+			expected: []string{"helm"},
+		},
+		{
+			name:     "file matched by multiple rules with the same label",
+			files:    []string{"/charts/foo/values.yaml"},
+			expected: []string{"helm"},
+		},
+		{
+			name: "multiple matches",
+			files: []string{
+				"/overlays/dev/kustomization.yaml",
+				"/charts/foo/Chart.yaml",
+			},
+			expected: []string{"helm", "kustomize"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.expected, matchLabels(rules, testCase.files))
+		})
+	}
+}
+
+func TestOpenPRLabels(t *testing.T) {
+	var created git.GitPullRequest
+	var applied []string
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn:   fakeRepos("repo"),
+		createPullRequestFn: fakeCreatePullRequest(&created),
+		getPullRequestIterationsFn: func(
+			context.Context,
+			git.GetPullRequestIterationsArgs,
+		) (*[]git.GitPullRequestIteration, error) {
+			return &[]git.GitPullRequestIteration{{Id: ptr(1)}}, nil
+		},
+		getPullRequestIterationChangesFn: func(
+			context.Context,
+			git.GetPullRequestIterationChangesArgs,
+		) (*git.GitPullRequestIterationChanges, error) {
+			return &git.GitPullRequestIterationChanges{
+				ChangeEntries: &[]git.GitPullRequestChange{
+					{Item: map[string]any{"path": "/charts/foo/values.yaml"}},
+					{Item: map[string]any{"path": "/overlays/dev/kustomization.yaml"}},
+				},
+			}, nil
+		},
+		createPullRequestLabelFn: func(
+			_ context.Context,
+			args git.CreatePullRequestLabelArgs,
+		) (*core.WebApiTagDefinition, error) {
+			applied = append(applied, *args.Label.Name)
+			return &core.WebApiTagDefinition{Name: args.Label.Name}, nil
+		},
+	})
+	_, err := OpenPR(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"title",
+		"description",
+		"env/dev",
+		"prs/kargo-render/env/dev",
+		gitutil.RepoCredentials{Password: "pat"},
+		&OpenPROptions{
+			Labels: []string{"kustomize", "render"},
+			LabelRules: []LabelRule{
+				{Path: "charts", Label: "helm"},
+				{Path: "overlays", Label: "kustomize"},
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.NotNil(t, created.Labels)
+	var manual []string
+	for _, label := range *created.Labels {
+		manual = append(manual, *label.Name)
+	}
+	require.Equal(t, []string{"kustomize", "render"}, manual)
+	// kustomize was already applied manually, so only helm should be added
+	require.Equal(t, []string{"helm"}, applied)
+}
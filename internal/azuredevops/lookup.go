@@ -0,0 +1,62 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// GetPRByBranch returns the active PR from the specified source branch to the
+// specified target branch or nil if there is none.
+func GetPRByBranch(
+	ctx context.Context,
+	repoURL string,
+	sourceBranch string,
+	targetBranch string,
+	creds gitutil.RepoCredentials,
+) (_ *git.GitPullRequest, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return nil, err
+	}
+	prs, err := listActivePRs(
+		ctx,
+		repo,
+		ensureRefFormat(sourceBranch),
+		ensureRefFormat(targetBranch),
+	)
+	if err != nil || len(prs) == 0 {
+		return nil, err
+	}
+	return &prs[0], nil
+}
+
+// listActivePRs returns all active PRs from the specified source ref to the
+// specified target ref.
+func listActivePRs(
+	ctx context.Context,
+	repo *repoClient,
+	sourceRef string,
+	targetRef string,
+) ([]git.GitPullRequest, error) {
+	prs, err := repo.client.GetPullRequests(ctx, git.GetPullRequestsArgs{
+		Project:      &repo.project,
+		RepositoryId: &repo.id,
+		SearchCriteria: &git.GitPullRequestSearchCriteria{
+			SourceRefName: &sourceRef,
+			TargetRefName: &targetRef,
+			Status:        &git.PullRequestStatusValues.Active,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pull requests: %w", err)
+	}
+	if prs == nil {
+		return nil, nil
+	}
+	return *prs, nil
+}
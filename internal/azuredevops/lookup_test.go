@@ -0,0 +1,61 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestGetPRByBranch(t *testing.T) {
+	testCases := []struct {
+		name       string
+		prs        []git.GitPullRequest
+		assertions func(t *testing.T, pr *git.GitPullRequest, err error)
+	}{
+		{
+			name: "not found",
+			assertions: func(t *testing.T, pr *git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.Nil(t, pr)
+			},
+		},
+		{
+			name: "found",
+			prs:  []git.GitPullRequest{{PullRequestId: ptr(42)}},
+			assertions: func(t *testing.T, pr *git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, pr)
+				require.Equal(t, 42, *pr.PullRequestId)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPullRequestsFn: func(
+					_ context.Context,
+					args git.GetPullRequestsArgs,
+				) (*[]git.GitPullRequest, error) {
+					criteria := args.SearchCriteria
+					require.Equal(t, "refs/heads/prs/kargo-render/env/dev", *criteria.SourceRefName)
+					require.Equal(t, "refs/heads/env/dev", *criteria.TargetRefName)
+					require.Equal(t, git.PullRequestStatusValues.Active, *criteria.Status)
+					return &testCase.prs, nil
+				},
+			})
+			pr, err := GetPRByBranch(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"prs/kargo-render/env/dev",
+				"env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+			)
+			testCase.assertions(t, pr, err)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// ErrPRNotMerged is returned when an operation requires a PR to have been
+// merged, but it has not been.
+var ErrPRNotMerged = errors.New("pull request has not been merged")
+
+// mergeStatusPollInterval is the amount of time to wait between checks of a
+// PR's merge status while Azure DevOps is still computing it. It is a
+// package-level variable so that it can be overridden in tests.
+var mergeStatusPollInterval = 2 * time.Second
+
+// MergeCheck is the result of Azure DevOps' attempt to merge the source branch
+// of a PR into its target branch.
+type MergeCheck struct {
+	// Status is the outcome of the merge attempt. It is never queued.
+	Status git.PullRequestAsyncStatus
+	// FailureMessage is Azure DevOps' explanation of a failed merge attempt, if
+	// any.
+	FailureMessage string
+}
+
+// HasConflicts returns a bool indicating whether the PR has merge conflicts
+// that require manual resolution.
+func (m MergeCheck) HasConflicts() bool {
+	return m.Status == git.PullRequestAsyncStatusValues.Conflicts
+}
+
+// Mergeable returns a bool indicating whether the PR's source branch merges
+// cleanly into its target branch.
+func (m MergeCheck) Mergeable() bool {
+	return m.Status == git.PullRequestAsyncStatusValues.Succeeded
+}
+
+// CheckMergeStatus returns the result of Azure DevOps' attempt to merge the
+// source branch of the specified PR into its target branch. This permits
+// callers to detect merge conflicts early, instead of waiting on a completion
+// that will never occur. While the merge attempt is still queued, the PR is
+// polled until it is resolved or the context is canceled.
+func CheckMergeStatus(
+	ctx context.Context,
+	repoURL string,
+	prID int,
+	creds gitutil.RepoCredentials,
+) (_ MergeCheck, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return MergeCheck{}, err
+	}
+	for {
+		pr, err := repo.client.GetPullRequest(ctx, git.GetPullRequestArgs{
+			Project:       &repo.project,
+			RepositoryId:  &repo.id,
+			PullRequestId: &prID,
+		})
+		if err != nil {
+			return MergeCheck{}, fmt.Errorf("error getting pull request %d: %w", prID, err)
+		}
+		if pr == nil {
+			return MergeCheck{}, fmt.Errorf(
+				"%w: pull request %d was not returned",
+				ErrIncompleteResponse,
+				prID,
+			)
+		}
+		if pr.MergeStatus == nil ||
+			*pr.MergeStatus != git.PullRequestAsyncStatusValues.Queued {
+			return mergeCheckOf(pr), nil
+		}
+		select {
+		case <-time.After(mergeStatusPollInterval):
+		case <-ctx.Done():
+			return MergeCheck{}, fmt.Errorf(
+				"error waiting for merge status of pull request %d: %w",
+				prID,
+				ctx.Err(),
+			)
+		}
+	}
+}
+
+// mergeCheckOf returns the result of the specified PR's most recent merge
+// attempt.
+func mergeCheckOf(pr *git.GitPullRequest) MergeCheck {
+	check := MergeCheck{Status: git.PullRequestAsyncStatusValues.NotSet}
+	if pr.MergeStatus != nil {
+		check.Status = *pr.MergeStatus
+	}
+	if pr.MergeFailureMessage != nil {
+		check.FailureMessage = *pr.MergeFailureMessage
+	}
+	return check
+}
+
+// GetMergeCommit returns the ID (sha) of the merge commit that resulted from
+// completing the specified PR. If the PR has not been completed, an error
+// wrapping ErrPRNotMerged is returned.
+func GetMergeCommit(
+	ctx context.Context,
+	repoURL string,
+	prID int,
+	creds gitutil.RepoCredentials,
+) (_ string, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return "", err
+	}
+	pr, err := repo.client.GetPullRequest(ctx, git.GetPullRequestArgs{
+		Project:       &repo.project,
+		RepositoryId:  &repo.id,
+		PullRequestId: &prID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error getting pull request %d: %w", prID, err)
+	}
+	return mergeCommitOf(pr)
+}
+
+// mergeCommitOf returns the ID (sha) of the merge commit that resulted from
+// completing the specified PR. If the PR has not been completed, an error
+// wrapping ErrPRNotMerged is returned.
+func mergeCommitOf(pr *git.GitPullRequest) (string, error) {
+	if pr == nil {
+		return "", fmt.Errorf("%w: pull request was not returned", ErrIncompleteResponse)
+	}
+	if pr.Status == nil || *pr.Status != git.PullRequestStatusValues.Completed {
+		status := git.PullRequestStatusValues.NotSet
+		if pr.Status != nil {
+			status = *pr.Status
+		}
+		return "", fmt.Errorf("%w: status is %q", ErrPRNotMerged, status)
+	}
+	if pr.LastMergeCommit == nil || pr.LastMergeCommit.CommitId == nil {
+		return "", errors.New("completed pull request has no merge commit")
+	}
+	return *pr.LastMergeCommit.CommitId, nil
+}
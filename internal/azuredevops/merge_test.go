@@ -0,0 +1,191 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestGetMergeCommit(t *testing.T) {
+	const mergeCommit = "0123456789abcdef0123456789abcdef01234567"
+	testCases := []struct {
+		name       string
+		pr         *git.GitPullRequest
+		assertions func(t *testing.T, commit string, err error)
+	}{
+		{
+			name: "completed PR",
+			pr: &git.GitPullRequest{
+				Status: &git.PullRequestStatusValues.Completed,
+				LastMergeCommit: &git.GitCommitRef{
+					CommitId: ptr(mergeCommit),
+				},
+			},
+			assertions: func(t *testing.T, commit string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, mergeCommit, commit)
+			},
+		},
+		{
+			name: "active PR",
+			pr: &git.GitPullRequest{
+				Status: &git.PullRequestStatusValues.Active,
+				LastMergeCommit: &git.GitCommitRef{
+					CommitId: ptr(mergeCommit),
+				},
+			},
+			assertions: func(t *testing.T, _ string, err error) {
+				require.ErrorIs(t, err, ErrPRNotMerged)
+				require.Contains(t, err.Error(), "active")
+			},
+		},
+		{
+			name: "completed PR without merge commit",
+			pr: &git.GitPullRequest{
+				Status: &git.PullRequestStatusValues.Completed,
+			},
+			assertions: func(t *testing.T, _ string, err error) {
+				require.Error(t, err)
+				require.NotErrorIs(t, err, ErrPRNotMerged)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPullRequestFn: func(
+					_ context.Context,
+					args git.GetPullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					return testCase.pr, nil
+				},
+			})
+			commit, err := GetMergeCommit(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				42,
+				gitutil.RepoCredentials{Password: "pat"},
+			)
+			testCase.assertions(t, commit, err)
+		})
+	}
+}
+
+func TestMergeCommitOfNilPR(t *testing.T) {
+	_, err := mergeCommitOf(nil)
+	require.ErrorIs(t, err, ErrIncompleteResponse)
+}
+
+func TestCheckMergeStatus(t *testing.T) {
+	queued := &git.GitPullRequest{MergeStatus: &git.PullRequestAsyncStatusValues.Queued}
+	testCases := []struct {
+		name       string
+		prs        []*git.GitPullRequest
+		assertions func(t *testing.T, check MergeCheck, polls int, err error)
+	}{
+		{
+			name: "clean",
+			prs: []*git.GitPullRequest{
+				{MergeStatus: &git.PullRequestAsyncStatusValues.Succeeded},
+			},
+			assertions: func(t *testing.T, check MergeCheck, polls int, err error) {
+				require.NoError(t, err)
+				require.True(t, check.Mergeable())
+				require.False(t, check.HasConflicts())
+				require.Equal(t, 1, polls)
+			},
+		},
+		{
+			name: "conflicts after queued",
+			prs: []*git.GitPullRequest{
+				queued,
+				queued,
+				{
+					MergeStatus:         &git.PullRequestAsyncStatusValues.Conflicts,
+					MergeFailureMessage: ptr("conflicts in env/prod/app.yaml"),
+				},
+			},
+			assertions: func(t *testing.T, check MergeCheck, polls int, err error) {
+				require.NoError(t, err)
+				require.True(t, check.HasConflicts())
+				require.False(t, check.Mergeable())
+				require.Equal(t, "conflicts in env/prod/app.yaml", check.FailureMessage)
+				require.Equal(t, 3, polls)
+			},
+		},
+		{
+			name: "not set",
+			prs:  []*git.GitPullRequest{{}},
+			assertions: func(t *testing.T, check MergeCheck, _ int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, git.PullRequestAsyncStatusValues.NotSet, check.Status)
+				require.False(t, check.Mergeable())
+				require.False(t, check.HasConflicts())
+			},
+		},
+		{
+			name: "PR not returned",
+			prs:  []*git.GitPullRequest{nil},
+			assertions: func(t *testing.T, _ MergeCheck, _ int, err error) {
+				require.ErrorIs(t, err, ErrIncompleteResponse)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			orig := mergeStatusPollInterval
+			mergeStatusPollInterval = time.Millisecond
+			t.Cleanup(func() { mergeStatusPollInterval = orig })
+			var polls int
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPullRequestFn: func(
+					_ context.Context,
+					args git.GetPullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					pr := testCase.prs[polls]
+					polls++
+					return pr, nil
+				},
+			})
+			check, err := CheckMergeStatus(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				42,
+				gitutil.RepoCredentials{Password: "pat"},
+			)
+			testCase.assertions(t, check, polls, err)
+		})
+	}
+}
+
+func TestCheckMergeStatusCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: fakeRepos("repo"),
+		getPullRequestFn: func(
+			context.Context,
+			git.GetPullRequestArgs,
+		) (*git.GitPullRequest, error) {
+			cancel()
+			return &git.GitPullRequest{
+				MergeStatus: &git.PullRequestAsyncStatusValues.Queued,
+			}, nil
+		},
+	})
+	_, err := CheckMergeStatus(
+		ctx,
+		"https://dev.azure.com/org/proj/_git/repo",
+		42,
+		gitutil.RepoCredentials{Password: "pat"},
+	)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,115 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+)
+
+// MetadataPropertyPrefix prefixes the names of the PR properties to which
+// OpenPROptions.Metadata is written, distinguishing them from properties set
+// by Azure DevOps and other tools.
+const MetadataPropertyPrefix = "KargoRender.Metadata."
+
+const (
+	// maxMetadataKeyLength is the maximum length, in characters, of a key of
+	// OpenPROptions.Metadata, excluding MetadataPropertyPrefix.
+	maxMetadataKeyLength = 128
+	// maxMetadataValueLength is the maximum length, in characters, of a value of
+	// OpenPROptions.Metadata.
+	maxMetadataValueLength = 1024
+)
+
+// ErrInvalidMetadata is returned when metadata to be written to the properties
+// of a PR is invalid.
+var ErrInvalidMetadata = errors.New("invalid pull request metadata")
+
+// validateMetadata returns an error wrapping ErrInvalidMetadata if any of the
+// specified metadata's keys is empty or too long or any of its values is too
+// long.
+func validateMetadata(metadata map[string]string) error {
+	var errs []error
+	for _, key := range sortedKeys(metadata) {
+		switch n := utf8.RuneCountInString(key); {
+		case strings.TrimSpace(key) == "":
+			errs = append(errs, fmt.Errorf("%w: keys must not be empty", ErrInvalidMetadata))
+		case n > maxMetadataKeyLength:
+			errs = append(errs, fmt.Errorf(
+				"%w: key %q is %d characters long, exceeding the maximum of %d",
+				ErrInvalidMetadata,
+				key,
+				n,
+				maxMetadataKeyLength,
+			))
+		}
+		if n := utf8.RuneCountInString(metadata[key]); n > maxMetadataValueLength {
+			errs = append(errs, fmt.Errorf(
+				"%w: value of key %q is %d characters long, exceeding the maximum of %d",
+				ErrInvalidMetadata,
+				key,
+				n,
+				maxMetadataValueLength,
+			))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// setMetadataProperties writes the specified metadata to the properties of the
+// specified PR, prefixing each key with MetadataPropertyPrefix. Existing
+// properties with the same names are replaced.
+func setMetadataProperties(
+	ctx context.Context,
+	repo *repoClient,
+	prID int,
+	metadata map[string]string,
+) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+	keys := sortedKeys(metadata)
+	patch := make([]webapi.JsonPatchOperation, len(keys))
+	for i, key := range keys {
+		path := "/" + metadataPropertyName(key)
+		patch[i] = webapi.JsonPatchOperation{
+			Op:    &webapi.OperationValues.Add,
+			Path:  &path,
+			Value: metadata[key],
+		}
+	}
+	if _, err := repo.client.UpdatePullRequestProperties(
+		ctx,
+		git.UpdatePullRequestPropertiesArgs{
+			Project:       &repo.project,
+			RepositoryId:  &repo.id,
+			PullRequestId: &prID,
+			PatchDocument: &patch,
+		},
+	); err != nil {
+		return fmt.Errorf("error updating properties of pull request %d: %w", prID, err)
+	}
+	return nil
+}
+
+// metadataPropertyName returns the name of the PR property to which the
+// metadata with the specified key is written, escaped for use as a JSON
+// pointer reference token.
+func metadataPropertyName(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(MetadataPropertyPrefix + key)
+}
+
+// sortedKeys returns the keys of the specified map in lexical order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
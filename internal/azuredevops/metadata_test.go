@@ -0,0 +1,169 @@
+package azuredevops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	testCases := []struct {
+		name       string
+		metadata   map[string]string
+		assertions func(t *testing.T, err error)
+	}{
+		{
+			name: "no metadata",
+			assertions: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "valid",
+			metadata: map[string]string{
+				"runID":    "1234",
+				"pipeline": strings.Repeat("é", maxMetadataValueLength),
+				strings.Repeat("k", maxMetadataKeyLength): "",
+			},
+			assertions: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:     "empty key",
+			metadata: map[string]string{" ": "value"},
+			assertions: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidMetadata)
+				require.ErrorContains(t, err, "must not be empty")
+			},
+		},
+		{
+			name: "key and value too long",
+			metadata: map[string]string{
+				strings.Repeat("k", maxMetadataKeyLength+1): "value",
+				"runID": strings.Repeat("v", maxMetadataValueLength+1),
+			},
+			assertions: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidMetadata)
+				require.ErrorContains(t, err, "129 characters long")
+				require.ErrorContains(t, err, `value of key "runID" is 1025 characters long`)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.assertions(t, validateMetadata(testCase.metadata))
+		})
+	}
+}
+
+func TestOpenPRMetadata(t *testing.T) {
+	testCases := []struct {
+		name       string
+		metadata   map[string]string
+		assertions func(t *testing.T, created bool, patch []webapi.JsonPatchOperation, err error)
+	}{
+		{
+			name: "properties are written",
+			metadata: map[string]string{
+				"runID":       "1234",
+				"pipeline":    "render",
+				"commit/sha":  "abc123",
+				"approx~time": "1h",
+			},
+			assertions: func(
+				t *testing.T,
+				created bool,
+				patch []webapi.JsonPatchOperation,
+				err error,
+			) {
+				require.NoError(t, err)
+				require.True(t, created)
+				paths := make([]string, len(patch))
+				values := make([]any, len(patch))
+				for i, op := range patch {
+					require.Equal(t, webapi.OperationValues.Add, *op.Op)
+					paths[i], values[i] = *op.Path, op.Value
+				}
+				require.Equal(
+					t,
+					[]string{
+						"/KargoRender.Metadata.approx~0time",
+						"/KargoRender.Metadata.commit~1sha",
+						"/KargoRender.Metadata.pipeline",
+						"/KargoRender.Metadata.runID",
+					},
+					paths,
+				)
+				require.Equal(t, []any{"1h", "abc123", "render", "1234"}, values)
+			},
+		},
+		{
+			name: "no metadata",
+			assertions: func(
+				t *testing.T,
+				created bool,
+				patch []webapi.JsonPatchOperation,
+				err error,
+			) {
+				require.NoError(t, err)
+				require.True(t, created)
+				require.Nil(t, patch)
+			},
+		},
+		{
+			name:     "invalid metadata",
+			metadata: map[string]string{"": "value"},
+			assertions: func(
+				t *testing.T,
+				created bool,
+				_ []webapi.JsonPatchOperation,
+				err error,
+			) {
+				require.ErrorIs(t, err, ErrInvalidMetadata)
+				require.False(t, created)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var created bool
+			var patch []webapi.JsonPatchOperation
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				createPullRequestFn: func(
+					ctx context.Context,
+					args git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					created = true
+					return fakeCreatePullRequest(nil)(ctx, args)
+				},
+				updatePullRequestPropertiesFn: func(
+					_ context.Context,
+					args git.UpdatePullRequestPropertiesArgs,
+				) (interface{}, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					patch = *args.PatchDocument
+					return nil, nil
+				},
+			})
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "token"},
+				&OpenPROptions{Metadata: testCase.metadata},
+			)
+			testCase.assertions(t, created, patch, err)
+		})
+	}
+}
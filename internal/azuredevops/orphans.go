@@ -0,0 +1,146 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// CleanupOrphanedSourceBranches deletes all branches of the specified
+// repository whose names begin with the specified prefix, e.g.
+// prs/kargo-render/, and that are not the source branch of any active PR, for
+// instance, because their PRs were abandoned or deleted. The prefix may be
+// specified with or without a refs/heads/ prefix, but must not be empty. It
+// always denotes a directory of branches, so a trailing slash is added if it
+// lacks one; prs/kargo-render and prs/kargo-render/ are equivalent, and
+// neither matches prs/kargo-render-old/. Protected branches are never deleted.
+// These are the repository's default branch, locked branches, and branches to
+// which any required policy applies. Branches whose head commits were
+// committed less than the specified grace period ago are never deleted either,
+// since their PRs may be in the midst of being opened, e.g. by a concurrent
+// call to OpenPR; a commit without a commit date is considered recent. A zero
+// grace period disables this safeguard, in which case cleanup must not run
+// concurrently with the opening of PRs in the same repository. The names of the
+// deleted branches are returned, without any refs/heads/ prefix. If an error is
+// encountered, the branches deleted until then are returned along with it.
+func CleanupOrphanedSourceBranches(
+	ctx context.Context,
+	repoURL string,
+	prefix string,
+	gracePeriod time.Duration,
+	creds gitutil.RepoCredentials,
+) (_ []string, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	prefix = strings.TrimPrefix(prefix, "refs/heads/")
+	if strings.Trim(prefix, "/") == "" {
+		return nil, errors.New("a branch prefix is required")
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := listRefs(ctx, repo, "heads/"+prefix)
+	if err != nil {
+		return nil, err
+	}
+	prs, err := listAllActivePRs(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	details, err := repo.details(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var defaultBranch string
+	if details.DefaultBranch != nil {
+		defaultBranch = *details.DefaultBranch
+	}
+	cutoff := now().Add(-gracePeriod)
+	var deleted []string
+	for _, ref := range orphanedBranches(refs, prs, defaultBranch) {
+		if gracePeriod > 0 {
+			recent, err := committedAfter(ctx, repo, ref, cutoff)
+			if err != nil {
+				return deleted, err
+			}
+			if recent {
+				continue
+			}
+		}
+		protected, err := hasPolicy(ctx, repo, *ref.Name, isRequired)
+		if err != nil {
+			return deleted, err
+		}
+		if protected {
+			continue
+		}
+		if err = updateRef(ctx, repo, *ref.Name, *ref.ObjectId, nullObjectID); err != nil {
+			return deleted, fmt.Errorf("error deleting branch %q: %w", *ref.Name, err)
+		}
+		deleted = append(deleted, strings.TrimPrefix(*ref.Name, "refs/heads/"))
+	}
+	return deleted, nil
+}
+
+// committedAfter returns a bool indicating whether the head commit of the
+// specified branch ref was committed after the specified time. A commit
+// without a commit date is considered to have been.
+func committedAfter(
+	ctx context.Context,
+	repo *repoClient,
+	ref git.GitRef,
+	cutoff time.Time,
+) (bool, error) {
+	commit, err := repo.client.GetCommit(ctx, git.GetCommitArgs{
+		Project:      &repo.project,
+		RepositoryId: &repo.id,
+		CommitId:     ref.ObjectId,
+	})
+	if err != nil {
+		return false, fmt.Errorf("error getting head commit of branch %q: %w", *ref.Name, err)
+	}
+	if commit == nil || commit.Committer == nil || commit.Committer.Date == nil {
+		return true, nil
+	}
+	return commit.Committer.Date.Time.After(cutoff), nil
+}
+
+// orphanedBranches returns those of the specified branch refs that are not the
+// source branch of any of the specified active PRs, excluding the specified
+// default branch and any branches that are locked. Policies are not
+// considered.
+func orphanedBranches(
+	refs []git.GitRef,
+	activePRs []git.GitPullRequest,
+	defaultBranch string,
+) []git.GitRef {
+	sources := make(map[string]struct{}, len(activePRs))
+	for _, pr := range activePRs {
+		if pr.SourceRefName != nil {
+			sources[*pr.SourceRefName] = struct{}{}
+		}
+	}
+	var orphans []git.GitRef
+	for _, ref := range refs {
+		if ref.Name == nil || ref.ObjectId == nil ||
+			!strings.HasPrefix(*ref.Name, "refs/heads/") ||
+			*ref.Name == defaultBranch ||
+			(ref.IsLocked != nil && *ref.IsLocked) {
+			continue
+		}
+		if _, ok := sources[*ref.Name]; ok {
+			continue
+		}
+		orphans = append(orphans, ref)
+	}
+	return orphans
+}
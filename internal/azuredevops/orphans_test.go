@@ -0,0 +1,241 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/policy"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestOrphanedBranches(t *testing.T) {
+	branch := func(name string) git.GitRef {
+		return git.GitRef{Name: ptr("refs/heads/" + name), ObjectId: ptr("abc123")}
+	}
+	activePR := func(source string) git.GitPullRequest {
+		return git.GitPullRequest{SourceRefName: ptr("refs/heads/" + source)}
+	}
+	locked := branch("prs/kargo-render/env/locked")
+	locked.IsLocked = ptr(true)
+	testCases := []struct {
+		name     string
+		refs     []git.GitRef
+		prs      []git.GitPullRequest
+		expected []string
+	}{
+		{
+			name: "no branches",
+			prs:  []git.GitPullRequest{activePR("prs/kargo-render/env/dev")},
+		},
+		{
+			name: "all branches have active PRs",
+			refs: []git.GitRef{
+				branch("prs/kargo-render/env/dev"),
+				branch("prs/kargo-render/env/prod"),
+			},
+			prs: []git.GitPullRequest{
+				activePR("prs/kargo-render/env/prod"),
+				activePR("prs/kargo-render/env/dev"),
+			},
+		},
+		{
+			name: "some branches have no active PR",
+			refs: []git.GitRef{
+				branch("prs/kargo-render/env/dev"),
+				branch("prs/kargo-render/env/test"),
+				branch("prs/kargo-render/env/prod"),
+			},
+			prs: []git.GitPullRequest{
+				activePR("prs/kargo-render/env/test"),
+				{},
+			},
+			expected: []string{
+				"refs/heads/prs/kargo-render/env/dev",
+				"refs/heads/prs/kargo-render/env/prod",
+			},
+		},
+		{
+			name: "default, locked, and incomplete branches are never orphaned",
+			refs: []git.GitRef{
+				branch("main"),
+				locked,
+				{Name: ptr("refs/heads/prs/kargo-render/env/no-object")},
+				{Name: ptr("refs/tags/prs/kargo-render/env/dev"), ObjectId: ptr("abc123")},
+				branch("prs/kargo-render/env/dev"),
+			},
+			expected: []string{"refs/heads/prs/kargo-render/env/dev"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var names []string
+			for _, ref := range orphanedBranches(testCase.refs, testCase.prs, "refs/heads/main") {
+				names = append(names, *ref.Name)
+			}
+			require.Equal(t, testCase.expected, names)
+		})
+	}
+}
+
+func TestCleanupOrphanedSourceBranches(t *testing.T) {
+	repoID := uuid.New()
+	var deletedRefs []string
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: func(
+			context.Context,
+			git.GetRepositoriesArgs,
+		) (*[]git.GitRepository, error) {
+			return &[]git.GitRepository{{
+				Id:            &repoID,
+				Name:          ptr("repo"),
+				DefaultBranch: ptr("refs/heads/main"),
+			}}, nil
+		},
+		getRefsFn: func(
+			_ context.Context,
+			args git.GetRefsArgs,
+		) (*git.GetRefsResponseValue, error) {
+			require.Equal(t, "heads/prs/kargo-render/", *args.Filter)
+			return &git.GetRefsResponseValue{
+				Value: []git.GitRef{
+					{Name: ptr("refs/heads/prs/kargo-render/env/dev"), ObjectId: ptr("a")},
+					{Name: ptr("refs/heads/prs/kargo-render/env/test"), ObjectId: ptr("b")},
+					{Name: ptr("refs/heads/prs/kargo-render/env/prod"), ObjectId: ptr("c")},
+				},
+			}, nil
+		},
+		getPullRequestsFn: func(
+			context.Context,
+			git.GetPullRequestsArgs,
+		) (*[]git.GitPullRequest, error) {
+			return &[]git.GitPullRequest{
+				{SourceRefName: ptr("refs/heads/prs/kargo-render/env/test")},
+			}, nil
+		},
+		getPolicyConfigurationsFn: func(
+			_ context.Context,
+			args git.GetPolicyConfigurationsArgs,
+		) (*git.GitPolicyConfigurationResponse, error) {
+			var configs []policy.PolicyConfiguration
+			if *args.RefName == "refs/heads/prs/kargo-render/env/prod" {
+				configs = append(configs, policy.PolicyConfiguration{
+					IsEnabled:  ptr(true),
+					IsBlocking: ptr(true),
+				})
+			}
+			return &git.GitPolicyConfigurationResponse{PolicyConfigurations: &configs}, nil
+		},
+		updateRefsFn: func(
+			_ context.Context,
+			args git.UpdateRefsArgs,
+		) (*[]git.GitRefUpdateResult, error) {
+			for _, update := range *args.RefUpdates {
+				require.Equal(t, nullObjectID, *update.NewObjectId)
+				deletedRefs = append(deletedRefs, *update.Name)
+			}
+			return &[]git.GitRefUpdateResult{{Success: ptr(true)}}, nil
+		},
+	})
+	deleted, err := CleanupOrphanedSourceBranches(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		// A trailing slash is added, so that branches of other directories, e.g.
+		// prs/kargo-render-old/, are not matched
+		"refs/heads/prs/kargo-render",
+		0,
+		gitutil.RepoCredentials{Password: "token"},
+	)
+	require.NoError(t, err)
+	// The test branch has an active PR and the prod branch is protected
+	require.Equal(t, []string{"prs/kargo-render/env/dev"}, deleted)
+	require.Equal(t, []string{"refs/heads/prs/kargo-render/env/dev"}, deletedRefs)
+}
+
+func TestCleanupOrphanedSourceBranchesRequiresPrefix(t *testing.T) {
+	_, err := CleanupOrphanedSourceBranches(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"refs/heads/",
+		0,
+		gitutil.RepoCredentials{Password: "token"},
+	)
+	require.ErrorContains(t, err, "prefix is required")
+}
+
+func TestCleanupOrphanedSourceBranchesGracePeriod(t *testing.T) {
+	current := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	orig := now
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = orig })
+
+	commitDates := map[string]*azuredevops.Time{
+		"old":     {Time: current.Add(-2 * time.Hour)},
+		"recent":  {Time: current.Add(-time.Minute)},
+		"undated": nil,
+	}
+	var deletedRefs []string
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: fakeRepos("repo"),
+		getRefsFn: func(
+			context.Context,
+			git.GetRefsArgs,
+		) (*git.GetRefsResponseValue, error) {
+			return &git.GetRefsResponseValue{
+				Value: []git.GitRef{
+					{Name: ptr("refs/heads/prs/kargo-render/env/dev"), ObjectId: ptr("old")},
+					{Name: ptr("refs/heads/prs/kargo-render/env/test"), ObjectId: ptr("recent")},
+					{Name: ptr("refs/heads/prs/kargo-render/env/prod"), ObjectId: ptr("undated")},
+				},
+			}, nil
+		},
+		getPullRequestsFn: func(
+			context.Context,
+			git.GetPullRequestsArgs,
+		) (*[]git.GitPullRequest, error) {
+			return &[]git.GitPullRequest{}, nil
+		},
+		getCommitFn: func(
+			_ context.Context,
+			args git.GetCommitArgs,
+		) (*git.GitCommit, error) {
+			return &git.GitCommit{
+				Committer: &git.GitUserDate{Date: commitDates[*args.CommitId]},
+			}, nil
+		},
+		getPolicyConfigurationsFn: func(
+			context.Context,
+			git.GetPolicyConfigurationsArgs,
+		) (*git.GitPolicyConfigurationResponse, error) {
+			return &git.GitPolicyConfigurationResponse{
+				PolicyConfigurations: &[]policy.PolicyConfiguration{},
+			}, nil
+		},
+		updateRefsFn: func(
+			_ context.Context,
+			args git.UpdateRefsArgs,
+		) (*[]git.GitRefUpdateResult, error) {
+			for _, update := range *args.RefUpdates {
+				deletedRefs = append(deletedRefs, *update.Name)
+			}
+			return &[]git.GitRefUpdateResult{{Success: ptr(true)}}, nil
+		},
+	})
+	deleted, err := CleanupOrphanedSourceBranches(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"prs/kargo-render/",
+		time.Hour,
+		gitutil.RepoCredentials{Password: "token"},
+	)
+	require.NoError(t, err)
+	// The test branch's head commit is too recent and the prod branch's head
+	// commit has no date
+	require.Equal(t, []string{"prs/kargo-render/env/dev"}, deleted)
+	require.Equal(t, []string{"refs/heads/prs/kargo-render/env/dev"}, deletedRefs)
+}
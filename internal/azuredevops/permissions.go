@@ -0,0 +1,137 @@
+package azuredevops
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/security"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// newSecurityClient creates an Azure DevOps Security client. It is a
+// package-level variable so that it can be overridden in tests.
+var newSecurityClient = security.NewClient
+
+// gitRepositoriesNamespaceID is the ID of the security namespace that governs
+// permissions on Git repositories and their branches.
+var gitRepositoriesNamespaceID = uuid.MustParse("2e9eb7ed-3c0a-47d4-87c1-0ffdd275fd87")
+
+// ErrInsufficientPermissions is returned when the identity associated with the
+// credentials used lacks permissions required for an operation.
+var ErrInsufficientPermissions = errors.New("insufficient permissions")
+
+// gitPermission is a permission in the Git repositories security namespace.
+type gitPermission struct {
+	name string
+	bit  int
+}
+
+// autoCompletePermissions are the permissions, on the target branch, that the
+// identity auto-complete is set by requires for Azure DevOps to complete a PR
+// on its behalf.
+var autoCompletePermissions = []gitPermission{
+	{name: "Contribute", bit: 4},
+	{name: "Contribute to pull requests", bit: 16384},
+}
+
+// VerifyAutoCompletePermissions verifies that the identity associated with the
+// specified credentials has the permissions required for Azure DevOps to
+// complete PRs to the specified target branch of the specified repository on
+// its behalf. Without these, PRs for which that identity enables auto-complete
+// are never completed. If any permission is lacking, an error wrapping
+// ErrInsufficientPermissions and naming the missing permissions is returned.
+func VerifyAutoCompletePermissions(
+	ctx context.Context,
+	repoURL string,
+	targetBranch string,
+	creds gitutil.RepoCredentials,
+) (err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return err
+	}
+	return verifyAutoCompletePermissions(ctx, repo, ensureRefFormat(targetBranch))
+}
+
+// verifyAutoCompletePermissions verifies that the identity associated with the
+// specified repository's connection has the permissions required for Azure
+// DevOps to complete PRs to the specified fully-qualified target branch on its
+// behalf.
+func verifyAutoCompletePermissions(
+	ctx context.Context,
+	repo *repoClient,
+	targetBranch string,
+) error {
+	details, err := repo.details(ctx)
+	if err != nil {
+		return err
+	}
+	if details.Project == nil || details.Project.Id == nil {
+		return fmt.Errorf(
+			"%w: repository %q does not identify its project",
+			ErrIncompleteResponse,
+			repo.name,
+		)
+	}
+	token := branchSecurityToken(
+		details.Project.Id.String(),
+		repo.id,
+		targetBranch,
+	)
+	client := newSecurityClient(ctx, repo.connection)
+	var missing []string
+	for _, perm := range autoCompletePermissions {
+		bit := perm.bit
+		granted, err := client.HasPermissions(ctx, security.HasPermissionsArgs{
+			SecurityNamespaceId: &gitRepositoriesNamespaceID,
+			Permissions:         &bit,
+			Tokens:              &token,
+		})
+		if err != nil {
+			return fmt.Errorf(
+				"error checking %q permission on %q: %w",
+				perm.name,
+				targetBranch,
+				err,
+			)
+		}
+		if granted == nil || len(*granted) != 1 || !(*granted)[0] {
+			missing = append(missing, perm.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf(
+			"%w to complete pull requests to %q; missing %s",
+			ErrInsufficientPermissions,
+			targetBranch,
+			strings.Join(missing, ", "),
+		)
+	}
+	return nil
+}
+
+// branchSecurityToken returns the token that identifies the specified
+// fully-qualified branch ref of the specified repository in the Git
+// repositories security namespace. Each segment of the branch name is encoded
+// as the hexadecimal representation of its UTF-16LE encoding.
+func branchSecurityToken(projectID, repoID, ref string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "repoV2/%s/%s/refs/heads/", projectID, repoID)
+	for _, segment := range strings.Split(strings.TrimPrefix(ref, "refs/heads/"), "/") {
+		units := utf16.Encode([]rune(segment))
+		encoded := make([]byte, 0, 2*len(units))
+		for _, unit := range units {
+			encoded = append(encoded, byte(unit), byte(unit>>8))
+		}
+		b.WriteString(hex.EncodeToString(encoded))
+		b.WriteString("/")
+	}
+	return b.String()
+}
@@ -0,0 +1,131 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/security"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// fakeSecurityClient is a fake security.Client. Only HasPermissions is
+// implemented; calling any other method panics.
+type fakeSecurityClient struct {
+	security.Client
+	hasPermissionsFn func(context.Context, security.HasPermissionsArgs) (*[]bool, error)
+}
+
+func (f *fakeSecurityClient) HasPermissions(
+	ctx context.Context,
+	args security.HasPermissionsArgs,
+) (*[]bool, error) {
+	return f.hasPermissionsFn(ctx, args)
+}
+
+func TestBranchSecurityToken(t *testing.T) {
+	require.Equal(
+		t,
+		"repoV2/proj-id/repo-id/refs/heads/65006e007600/640065007600/",
+		branchSecurityToken("proj-id", "repo-id", "refs/heads/env/dev"),
+	)
+	require.Equal(
+		t,
+		"repoV2/proj-id/repo-id/refs/heads/65006e007600/630061006600e900/3dd880de/",
+		branchSecurityToken("proj-id", "repo-id", "refs/heads/env/caf\u00e9/\U0001F680"),
+	)
+}
+
+func TestOpenPRVerifyAutoCompletePermissions(t *testing.T) {
+	projectID, repoID := uuid.New(), uuid.New()
+	testCases := []struct {
+		name       string
+		granted    map[int]bool
+		assertions func(t *testing.T, created bool, err error)
+	}{
+		{
+			name:    "all permissions granted",
+			granted: map[int]bool{4: true, 16384: true},
+			assertions: func(t *testing.T, created bool, err error) {
+				require.NoError(t, err)
+				require.True(t, created)
+			},
+		},
+		{
+			name:    "cannot contribute to pull requests",
+			granted: map[int]bool{4: true},
+			assertions: func(t *testing.T, created bool, err error) {
+				require.ErrorIs(t, err, ErrInsufficientPermissions)
+				require.ErrorContains(t, err, "missing Contribute to pull requests")
+				require.False(t, created)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var created bool
+			useFakeIdentity(t, uuid.New())
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: func(
+					context.Context,
+					git.GetRepositoriesArgs,
+				) (*[]git.GitRepository, error) {
+					return &[]git.GitRepository{{
+						Id:      &repoID,
+						Name:    ptr("repo"),
+						Project: &core.TeamProjectReference{Id: &projectID},
+					}}, nil
+				},
+				createPullRequestFn: func(
+					ctx context.Context,
+					args git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					created = true
+					return fakeCreatePullRequest(nil)(ctx, args)
+				},
+				updatePullRequestFn: func(
+					_ context.Context,
+					args git.UpdatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					return args.GitPullRequestToUpdate, nil
+				},
+			})
+			orig := newSecurityClient
+			newSecurityClient = func(context.Context, *azuredevops.Connection) security.Client {
+				return &fakeSecurityClient{
+					hasPermissionsFn: func(
+						_ context.Context,
+						args security.HasPermissionsArgs,
+					) (*[]bool, error) {
+						require.Equal(t, gitRepositoriesNamespaceID, *args.SecurityNamespaceId)
+						require.Equal(
+							t,
+							branchSecurityToken(projectID.String(), repoID.String(), "refs/heads/env/dev"),
+							*args.Tokens,
+						)
+						return &[]bool{testCase.granted[*args.Permissions]}, nil
+					},
+				}
+			}
+			t.Cleanup(func() { newSecurityClient = orig })
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "token"},
+				&OpenPROptions{
+					AutoComplete: &AutoCompleteOptions{VerifyPermissions: true},
+				},
+			)
+			testCase.assertions(t, created, err)
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"golang.org/x/text/unicode/norm"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// ProviderName is the name by which Plan identifies Azure DevOps as the Git
+// provider of the PRs it plans.
+const ProviderName = "azuredevops"
+
+// PlanAction is what BatchOpenPR would do for a requested PR.
+type PlanAction string
+
+const (
+	// PlanActionCreate indicates that a new PR would be opened.
+	PlanActionCreate PlanAction = "create"
+	// PlanActionUpdate indicates that an existing PR would be found and brought
+	// up to date instead of a new one being opened.
+	PlanActionUpdate PlanAction = "update"
+	// PlanActionSkip indicates that no PR would be opened because there are no
+	// changes to propose.
+	PlanActionSkip PlanAction = "skip"
+)
+
+// PlannedPR describes what BatchOpenPR would do for a single requested PR.
+type PlannedPR struct {
+	// Env is the name of the environment the PR is for.
+	Env string
+	// Provider is the name of the Git provider the PR is for, which is always
+	// ProviderName.
+	Provider string
+	// Route is the repository and branch the PR would target.
+	Route Route
+	// SourceBranch is the fully-qualified branch the PR would be opened from.
+	SourceBranch string
+	// ExistingPRID is the ID of the existing PR that would be updated, if any.
+	ExistingPRID int
+	// ExistingPRURL is the URL of the existing PR that would be updated, if
+	// any.
+	ExistingPRURL string
+	// HasChanges indicates whether the source branch contains commits that the
+	// target branch does not. This is always true for PRs from forks, which
+	// are not compared.
+	HasChanges bool
+	// Action is what would be done for the PR. This is empty if it could not
+	// be determined.
+	Action PlanAction
+	// Err is any error that was encountered planning the PR.
+	Err error
+}
+
+// Plan is the read-only counterpart to BatchOpenPR. It concurrently determines,
+// for each of the requested PRs, which may span multiple repositories, whether
+// a PR already exists and whether there are changes to propose, and hence what
+// BatchOpenPR would do, without changing anything. Each request's own Route is
+// used. An existing PR is found as OpenPR would find it, using the request's
+// IdempotencyKey, if any, except that duplicates are never abandoned. When a
+// request has no IdempotencyKey, any active PR from its source branch to its
+// target branch is considered to be the existing PR, since Azure DevOps
+// permits only one. Requests that open PRs from a SourceCommit are planned
+// from the temporary branch, which must therefore already exist. Results are
+// returned in the same order as the requests. If any PR could not be planned,
+// a *BatchError describing each such failure is also returned.
+func Plan(
+	ctx context.Context,
+	reqs []PRRequest,
+	creds gitutil.RepoCredentials,
+) ([]PlannedPR, error) {
+	plans := make([]PlannedPR, len(reqs))
+	// All plans share connections to each organization
+	pool := newConnectionPool()
+	sem := make(chan struct{}, maxBatchConcurrency)
+	wg := sync.WaitGroup{}
+	for i, req := range reqs {
+		plans[i] = PlannedPR{Env: req.Env, Provider: ProviderName, Route: req.Route}
+		wg.Add(1)
+		go func(plan *PlannedPR, req PRRequest) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			plan.Err = redactError(
+				planPR(ctx, plan, req.SourceBranch, creds, pooled(req.options(), pool)),
+				[]string{creds.Password},
+				nil,
+			)
+		}(&plans[i], req)
+	}
+	wg.Wait()
+	var failures []*BatchFailure
+	for i, plan := range plans {
+		if plan.Err != nil {
+			failures = append(failures, &BatchFailure{
+				Index: i,
+				Env:   plan.Env,
+				Route: plan.Route,
+				Err:   plan.Err,
+			})
+		}
+	}
+	if len(failures) > 0 {
+		return plans, &BatchError{Failures: failures}
+	}
+	return plans, nil
+}
+
+// planPR completes the specified plan for a PR from the specified source
+// branch, opened with the specified options, to the plan's route.
+func planPR(
+	ctx context.Context,
+	plan *PlannedPR,
+	sourceBranch string,
+	creds gitutil.RepoCredentials,
+	opts *OpenPROptions,
+) (err error) {
+	opts = opts.Defaults.apply(opts)
+	targetBranch := plan.Route.TargetBranch
+	if opts.NormalizeBranchNames {
+		targetBranch = norm.NFC.String(targetBranch)
+		sourceBranch = norm.NFC.String(sourceBranch)
+	}
+	if opts.SourceCommit != "" {
+		if sourceBranch, err = tempBranchFor(sourceBranch, opts.SourceCommit); err != nil {
+			return err
+		}
+	}
+	if err = ensureValidBranchNames(targetBranch, sourceBranch); err != nil {
+		return err
+	}
+	if targetBranch == "" {
+		if opts.TargetResolver == nil {
+			return fmt.Errorf("%w: no target branch was specified", ErrNoTarget)
+		}
+		if targetBranch, err = opts.TargetResolver.Resolve(sourceBranch); err != nil {
+			return err
+		}
+	}
+	sourceBranch = ensureRefFormat(sourceBranch)
+	targetBranch = ensureRefFormat(targetBranch)
+	plan.SourceBranch = sourceBranch
+
+	conn := opts.Connection
+	conn.repositoryID = opts.RepositoryID
+	repo, err := newRepoClient(ctx, plan.Route.RepoURL, creds, &conn)
+	if err != nil {
+		return err
+	}
+
+	plan.HasChanges = true
+	if opts.ForkRepoURL == "" {
+		if plan.HasChanges, err = hasNewCommits(ctx, repo, targetBranch, sourceBranch); err != nil {
+			return err
+		}
+	}
+	if !plan.HasChanges && opts.SkipIfNoChanges {
+		plan.Action = PlanActionSkip
+		return nil
+	}
+
+	existing, err := findExistingPR(ctx, repo, sourceBranch, targetBranch, opts)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		plan.Action = PlanActionCreate
+		return nil
+	}
+	plan.Action = PlanActionUpdate
+	if existing.PullRequestId != nil {
+		plan.ExistingPRID = *existing.PullRequestId
+	}
+	if existing.Url != nil {
+		plan.ExistingPRURL = *existing.Url
+	}
+	return nil
+}
+
+// findExistingPR returns the active PR from the specified source branch to the
+// specified target branch that OpenPR, given the specified options, would find
+// instead of opening a new one, without abandoning any duplicates. If the
+// options specify no idempotency key, any active PR between the branches is
+// returned. If there is no such PR, nil is returned.
+func findExistingPR(
+	ctx context.Context,
+	repo *repoClient,
+	sourceBranch string,
+	targetBranch string,
+	opts *OpenPROptions,
+) (*git.GitPullRequest, error) {
+	if opts.IdempotencyKey == "" {
+		prs, err := listActivePRs(ctx, repo, sourceBranch, targetBranch)
+		if err != nil || len(prs) == 0 {
+			return nil, err
+		}
+		sortOldestFirst(prs)
+		return &prs[0], nil
+	}
+	policy := opts.DuplicatePRPolicy
+	if policy == DuplicatePRPolicyAbandonExtras {
+		// The oldest PR, which OpenPR would keep, is found without abandoning
+		// the others
+		policy = DuplicatePRPolicyFirstMatch
+	}
+	return findPRByIdempotencyKey(
+		ctx,
+		repo,
+		sourceBranch,
+		targetBranch,
+		opts.IdempotencyKey,
+		opts.Marker,
+		policy,
+	)
+}
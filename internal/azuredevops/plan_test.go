@@ -0,0 +1,121 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestPlan(t *testing.T) {
+	const repoURL = "https://dev.azure.com/org/proj/_git/repo"
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: fakeRepos("repo"),
+		getCommitDiffsFn: func(
+			_ context.Context,
+			args git.GetCommitDiffsArgs,
+		) (*git.GitCommitDiffs, error) {
+			ahead := 1
+			switch source := *args.TargetVersionDescriptor.TargetVersion; {
+			case strings.HasPrefix(source, "prs/unchanged"):
+				ahead = 0
+			case source == "prs/broken":
+				return nil, errors.New("something went wrong")
+			}
+			return &git.GitCommitDiffs{AheadCount: &ahead, BehindCount: ptr(0)}, nil
+		},
+		getPullRequestsFn: func(
+			_ context.Context,
+			args git.GetPullRequestsArgs,
+		) (*[]git.GitPullRequest, error) {
+			switch *args.SearchCriteria.SourceRefName {
+			case "refs/heads/prs/existing":
+				return &[]git.GitPullRequest{{
+					PullRequestId: ptr(7),
+					Url:           ptr(repoURL + "/pullrequest/7"),
+					Description:   ptr("description\n\n" + idempotencyKeyMarker("existing")),
+				}}, nil
+			case "refs/heads/prs/unkeyed":
+				return &[]git.GitPullRequest{{
+					PullRequestId: ptr(8),
+					Url:           ptr(repoURL + "/pullrequest/8"),
+				}}, nil
+			case "refs/heads/prs/other-key":
+				return &[]git.GitPullRequest{{
+					PullRequestId: ptr(9),
+					Description:   ptr(idempotencyKeyMarker("someone-else")),
+				}}, nil
+			}
+			return &[]git.GitPullRequest{}, nil
+		},
+		// Planning must not change anything, so no functions that would are set
+	})
+	request := func(env string, opts *OpenPROptions) PRRequest {
+		return PRRequest{
+			Env:          env,
+			Route:        Route{RepoURL: repoURL, TargetBranch: "env/" + env},
+			SourceBranch: "prs/" + env,
+			Options:      opts,
+		}
+	}
+	plans, err := Plan(
+		context.Background(),
+		[]PRRequest{
+			request("new", &OpenPROptions{IdempotencyKey: "new"}),
+			request("existing", &OpenPROptions{IdempotencyKey: "existing"}),
+			request("unkeyed", nil),
+			request("other-key", &OpenPROptions{IdempotencyKey: "other-key"}),
+			request("unchanged", &OpenPROptions{SkipIfNoChanges: true}),
+			request("unchanged-anyway", nil),
+			request("broken", nil),
+		},
+		gitutil.RepoCredentials{Password: "token"},
+	)
+
+	batchErr := &BatchError{}
+	require.ErrorAs(t, err, &batchErr)
+	require.Len(t, batchErr.Failures, 1)
+	require.Equal(t, 6, batchErr.Failures[0].Index)
+	require.Equal(t, "broken", batchErr.Failures[0].Env)
+	require.ErrorContains(t, err, "error comparing branch")
+	require.NotContains(t, err.Error(), "token")
+
+	require.Len(t, plans, 7)
+	actions := map[string]PlanAction{}
+	for _, plan := range plans {
+		require.Equal(t, ProviderName, plan.Provider)
+		require.Equal(t, "refs/heads/prs/"+plan.Env, plan.SourceBranch)
+		require.Equal(t, "env/"+plan.Env, plan.Route.TargetBranch)
+		actions[plan.Env] = plan.Action
+	}
+	require.Equal(
+		t,
+		map[string]PlanAction{
+			"new":       PlanActionCreate,
+			"existing":  PlanActionUpdate,
+			"unkeyed":   PlanActionUpdate,
+			"other-key": PlanActionCreate,
+			"unchanged": PlanActionSkip,
+			// Without SkipIfNoChanges, a PR is opened regardless
+			"unchanged-anyway": PlanActionCreate,
+			"broken":           "",
+		},
+		actions,
+	)
+
+	require.True(t, plans[0].HasChanges)
+	require.Zero(t, plans[0].ExistingPRID)
+	require.Equal(t, 7, plans[1].ExistingPRID)
+	require.True(t, strings.HasSuffix(plans[1].ExistingPRURL, "/pullrequest/7"))
+	require.Equal(t, 8, plans[2].ExistingPRID)
+	require.Zero(t, plans[3].ExistingPRID)
+	require.False(t, plans[4].HasChanges)
+	require.Zero(t, plans[4].ExistingPRID)
+	require.False(t, plans[5].HasChanges)
+	require.Error(t, plans[6].Err)
+}
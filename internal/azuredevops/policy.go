@@ -0,0 +1,114 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/policy"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// newPolicyClient creates an Azure DevOps Policy client. It is a package-level
+// variable so that it can be overridden in tests.
+var newPolicyClient = policy.NewClient
+
+// buildPolicyTypeID is the ID of the built-in build validation policy type.
+// Evaluations of this policy type are the only ones Azure DevOps is able to
+// requeue.
+var buildPolicyTypeID = uuid.MustParse("0609b952-1397-4640-95ec-e00a01b2c241")
+
+// ErrPolicyNotRequeueable is returned when an attempt is made to requeue the
+// evaluation of a policy whose type does not support it.
+var ErrPolicyNotRequeueable = errors.New("policy evaluation cannot be requeued")
+
+// RequeuePolicyEvaluation re-triggers the specified evaluation of a policy on
+// the specified PR. This is useful, for instance, for re-running a flaky build
+// validation. If the evaluation is of a policy type that does not support
+// requeuing, an error wrapping ErrPolicyNotRequeueable is returned.
+func RequeuePolicyEvaluation(
+	ctx context.Context,
+	repoURL string,
+	prID int,
+	evaluationID string,
+	creds gitutil.RepoCredentials,
+) (err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	id, err := uuid.Parse(evaluationID)
+	if err != nil {
+		return fmt.Errorf("error parsing policy evaluation ID %q: %w", evaluationID, err)
+	}
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return err
+	}
+	client, err := newPolicyClient(ctx, repo.connection)
+	if err != nil {
+		return fmt.Errorf("error creating Azure DevOps Policy client: %w", err)
+	}
+	evaluation, err := read(ctx, repo.retry, func(ctx context.Context) (*policy.PolicyEvaluationRecord, error) {
+		return client.GetPolicyEvaluation(ctx, policy.GetPolicyEvaluationArgs{
+			Project:      &repo.project,
+			EvaluationId: &id,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("error getting policy evaluation %s: %w", id, err)
+	}
+	if evaluation == nil {
+		return fmt.Errorf(
+			"%w: policy evaluation %s was not returned",
+			ErrIncompleteResponse,
+			id,
+		)
+	}
+	// Artifact IDs of PR policy evaluations end with the PR's ID
+	if evaluation.ArtifactId == nil ||
+		!strings.HasSuffix(*evaluation.ArtifactId, fmt.Sprintf("/%d", prID)) {
+		return fmt.Errorf(
+			"policy evaluation %s does not belong to pull request %d",
+			id,
+			prID,
+		)
+	}
+	if err = ensureRequeueable(evaluation); err != nil {
+		return err
+	}
+	if _, err = client.RequeuePolicyEvaluation(
+		ctx,
+		policy.RequeuePolicyEvaluationArgs{
+			Project:      &repo.project,
+			EvaluationId: &id,
+		},
+	); err != nil {
+		return fmt.Errorf("error requeuing policy evaluation %s: %w", id, err)
+	}
+	return nil
+}
+
+// ensureRequeueable returns an error wrapping ErrPolicyNotRequeueable if the
+// specified evaluation is not of a policy type that supports requeuing.
+func ensureRequeueable(evaluation *policy.PolicyEvaluationRecord) error {
+	cfg := evaluation.Configuration
+	if cfg == nil || cfg.Type == nil || cfg.Type.Id == nil {
+		return fmt.Errorf(
+			"%w: the type of the evaluated policy could not be determined",
+			ErrPolicyNotRequeueable,
+		)
+	}
+	if *cfg.Type.Id != buildPolicyTypeID {
+		policyType := cfg.Type.Id.String()
+		if cfg.Type.DisplayName != nil {
+			policyType = *cfg.Type.DisplayName
+		}
+		return fmt.Errorf(
+			"%w: only build policies can be requeued, but this is a %q policy",
+			ErrPolicyNotRequeueable,
+			policyType,
+		)
+	}
+	return nil
+}
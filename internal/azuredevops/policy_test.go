@@ -0,0 +1,145 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/policy"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// fakePolicyClient is a fake implementation of the policy.Client interface.
+// Only the methods whose corresponding function fields are set may be called.
+type fakePolicyClient struct {
+	policy.Client
+	getPolicyEvaluationFn func(
+		context.Context,
+		policy.GetPolicyEvaluationArgs,
+	) (*policy.PolicyEvaluationRecord, error)
+	requeuePolicyEvaluationFn func(
+		context.Context,
+		policy.RequeuePolicyEvaluationArgs,
+	) (*policy.PolicyEvaluationRecord, error)
+}
+
+func (f *fakePolicyClient) GetPolicyEvaluation(
+	ctx context.Context,
+	args policy.GetPolicyEvaluationArgs,
+) (*policy.PolicyEvaluationRecord, error) {
+	return f.getPolicyEvaluationFn(ctx, args)
+}
+
+func (f *fakePolicyClient) RequeuePolicyEvaluation(
+	ctx context.Context,
+	args policy.RequeuePolicyEvaluationArgs,
+) (*policy.PolicyEvaluationRecord, error) {
+	return f.requeuePolicyEvaluationFn(ctx, args)
+}
+
+func TestRequeuePolicyEvaluation(t *testing.T) {
+	evaluationID := uuid.New()
+	statusPolicyTypeID := uuid.New()
+	testCases := []struct {
+		name         string
+		evaluationID string
+		evaluation   *policy.PolicyEvaluationRecord
+		assertions   func(t *testing.T, requeued bool, err error)
+	}{
+		{
+			name:         "invalid evaluation ID",
+			evaluationID: "not-a-uuid",
+			assertions: func(t *testing.T, requeued bool, err error) {
+				require.ErrorContains(t, err, "error parsing policy evaluation ID")
+				require.False(t, requeued)
+			},
+		},
+		{
+			name:         "evaluation of another PR",
+			evaluationID: evaluationID.String(),
+			evaluation: &policy.PolicyEvaluationRecord{
+				ArtifactId: ptr("vstfs:///CodeReview/CodeReviewId/proj-id/7"),
+				Configuration: &policy.PolicyConfiguration{
+					Type: &policy.PolicyTypeRef{Id: &buildPolicyTypeID},
+				},
+			},
+			assertions: func(t *testing.T, requeued bool, err error) {
+				require.ErrorContains(t, err, "does not belong to pull request 42")
+				require.False(t, requeued)
+			},
+		},
+		{
+			name:         "non-requeueable policy type",
+			evaluationID: evaluationID.String(),
+			evaluation: &policy.PolicyEvaluationRecord{
+				ArtifactId: ptr("vstfs:///CodeReview/CodeReviewId/proj-id/42"),
+				Configuration: &policy.PolicyConfiguration{
+					Type: &policy.PolicyTypeRef{
+						Id:          &statusPolicyTypeID,
+						DisplayName: ptr("Minimum number of reviewers"),
+					},
+				},
+			},
+			assertions: func(t *testing.T, requeued bool, err error) {
+				require.ErrorIs(t, err, ErrPolicyNotRequeueable)
+				require.ErrorContains(t, err, "Minimum number of reviewers")
+				require.False(t, requeued)
+			},
+		},
+		{
+			name:         "build policy",
+			evaluationID: evaluationID.String(),
+			evaluation: &policy.PolicyEvaluationRecord{
+				ArtifactId: ptr("vstfs:///CodeReview/CodeReviewId/proj-id/42"),
+				Configuration: &policy.PolicyConfiguration{
+					Type: &policy.PolicyTypeRef{Id: &buildPolicyTypeID},
+				},
+			},
+			assertions: func(t *testing.T, requeued bool, err error) {
+				require.NoError(t, err)
+				require.True(t, requeued)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var requeued bool
+			useFakeGitClient(t, &fakeGitClient{getRepositoriesFn: fakeRepos("repo")})
+			orig := newPolicyClient
+			newPolicyClient = func(
+				context.Context,
+				*azuredevops.Connection,
+			) (policy.Client, error) {
+				return &fakePolicyClient{
+					getPolicyEvaluationFn: func(
+						_ context.Context,
+						args policy.GetPolicyEvaluationArgs,
+					) (*policy.PolicyEvaluationRecord, error) {
+						require.Equal(t, evaluationID, *args.EvaluationId)
+						return testCase.evaluation, nil
+					},
+					requeuePolicyEvaluationFn: func(
+						_ context.Context,
+						args policy.RequeuePolicyEvaluationArgs,
+					) (*policy.PolicyEvaluationRecord, error) {
+						require.Equal(t, "proj", *args.Project)
+						requeued = true
+						return testCase.evaluation, nil
+					},
+				}, nil
+			}
+			t.Cleanup(func() { newPolicyClient = orig })
+			err := RequeuePolicyEvaluation(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				42,
+				testCase.evaluationID,
+				gitutil.RepoCredentials{Password: "pat"},
+			)
+			testCase.assertions(t, requeued, err)
+		})
+	}
+}
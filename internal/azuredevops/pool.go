@@ -0,0 +1,122 @@
+package azuredevops
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+)
+
+// connectionPool shares connections to Azure DevOps, and the Git clients
+// created using them, among concurrent operations against the same
+// organization. This avoids repeating API discovery for every operation and
+// keeps the number of underlying HTTP connections bounded.
+type connectionPool struct {
+	mu      sync.Mutex
+	entries map[string]*pooledConnection
+}
+
+// pooledConnection holds a single shared connection and Git client. Its mutex
+// is held while connecting so that concurrent operations against the same
+// organization result in a single connection.
+type pooledConnection struct {
+	mu         sync.Mutex
+	connection *azuredevops.Connection
+	client     git.Client
+}
+
+// newConnectionPool returns an empty connectionPool.
+func newConnectionPool() *connectionPool {
+	return &connectionPool{entries: map[string]*pooledConnection{}}
+}
+
+// get returns the pool's connection and Git client for the organization,
+// credentials, and TLS settings of the specified connection, connecting using
+// it if necessary. Failed connection attempts are not retained, so a later
+// operation may try again.
+func (p *connectionPool) get(
+	ctx context.Context,
+	connection *azuredevops.Connection,
+	timeout time.Duration,
+) (*azuredevops.Connection, git.Client, error) {
+	e := p.entry(poolKey(connection))
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.client == nil {
+		connected, client, err := connect(ctx, connection, timeout)
+		if err != nil {
+			return nil, nil, err
+		}
+		e.connection, e.client = connected, client
+	}
+	return e.connection, e.client, nil
+}
+
+// replace replaces the pool's connection and Git client for the organization,
+// credentials, and TLS settings of the specified connection with the specified
+// ones, so that later operations use them, too.
+func (p *connectionPool) replace(connection *azuredevops.Connection, client git.Client) {
+	e := p.entry(poolKey(connection))
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.connection, e.client = connection, client
+}
+
+// poolKey returns the key of the pool entry for the specified connection. This
+// reflects the connection's TLS settings, as well as its organization and
+// credentials, so that connections with different TLS settings are never
+// shared.
+func poolKey(connection *azuredevops.Connection) string {
+	return connectionKey(connection, tlsConfigKey(connection.TlsConfig))
+}
+
+// tlsConfigKey returns a string that identifies the specified TLS
+// configuration, which may be nil. Certificate pools are identified by
+// address, so configurations with distinct, but equivalent, pools are
+// considered different.
+func tlsConfigKey(cfg *tls.Config) string {
+	if cfg == nil {
+		return ""
+	}
+	return fmt.Sprintf(
+		"min=%d max=%d suites=%v insecure=%t server=%s roots=%p",
+		cfg.MinVersion,
+		cfg.MaxVersion,
+		cfg.CipherSuites,
+		cfg.InsecureSkipVerify,
+		cfg.ServerName,
+		cfg.RootCAs,
+	)
+}
+
+// entry returns the pool entry with the specified key, creating it if
+// necessary.
+func (p *connectionPool) entry(key string) *pooledConnection {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.entries[key]
+	if !ok {
+		e = &pooledConnection{}
+		p.entries[key] = e
+	}
+	return e
+}
+
+// connect creates a Git client that uses the specified connection. It returns
+// the connection as well, so that its signature matches that of
+// connectionPool.get.
+func connect(
+	ctx context.Context,
+	connection *azuredevops.Connection,
+	timeout time.Duration,
+) (*azuredevops.Connection, git.Client, error) {
+	client, err := connectGitClient(ctx, connection, timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	return connection, client, nil
+}
@@ -0,0 +1,39 @@
+package azuredevops
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionPoolTLS(t *testing.T) {
+	client := &fakeGitClient{}
+	useFakeGitClient(t, client)
+	var connections int
+	newGitClient = func(context.Context, *azuredevops.Connection) (git.Client, error) {
+		connections++
+		return client, nil
+	}
+	connectionWith := func(cfg *tls.Config) *azuredevops.Connection {
+		connection := azuredevops.NewPatConnection("https://dev.azure.com/org", "token")
+		connection.TlsConfig = cfg
+		return connection
+	}
+	pool := newConnectionPool()
+	for _, cfg := range []*tls.Config{
+		nil,
+		nil,
+		{MinVersion: tls.VersionTLS13},
+		{MinVersion: tls.VersionTLS13},
+		{MinVersion: tls.VersionTLS13, ServerName: "gateway.example.com"},
+	} {
+		connection, _, err := pool.get(context.Background(), connectionWith(cfg), 0)
+		require.NoError(t, err)
+		require.Equal(t, cfg, connection.TlsConfig)
+	}
+	require.Equal(t, 3, connections)
+}
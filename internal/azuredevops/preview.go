@@ -0,0 +1,60 @@
+package azuredevops
+
+// PRPreview is the title, description, and labels of a PR exactly as OpenPR
+// would submit them to Azure DevOps.
+type PRPreview struct {
+	// Title is the PR's sanitized title.
+	Title string
+	// Description is the PR's final description, including any artifact
+	// section, footer, related PRs, and hidden markers, truncated if necessary.
+	Description string
+	// Labels are the labels applied to the PR, including those of any label
+	// rules matched by the changed files provided.
+	Labels []string
+	// Truncated indicates whether the description had to be truncated to fit
+	// within Azure DevOps' limit.
+	Truncated bool
+}
+
+// PreviewPR returns the title, description, and labels that OpenPR would use
+// for a PR opened with the specified title, description, and options, without
+// making any API calls. Label rules are matched against the specified changed
+// file paths, since OpenPR determines those only after the PR is created. If
+// opts is nil, defaults are used.
+func PreviewPR(
+	title string,
+	description string,
+	changedFiles []string,
+	opts *OpenPROptions,
+) PRPreview {
+	if opts == nil {
+		opts = &OpenPROptions{}
+	}
+	var idempotencyMarker string
+	if opts.IdempotencyKey != "" {
+		idempotencyMarker = idempotencyKeyMarker(opts.IdempotencyKey)
+	}
+	var parentMarker string
+	if opts.ParentPRID != 0 {
+		parentMarker = parentPRMarker(opts.ParentPRID)
+	}
+	prDescription, truncated := fitDescription(
+		sanitizeDescription(description, opts.EscapeControlCharacters),
+		artifactSection(opts.ArtifactURL),
+		sanitizeDescription(opts.Footer, opts.EscapeControlCharacters),
+		relatedPRsSection(opts.RelatedPRURLs),
+		provenanceMarkers(provenanceVersion(opts), opts.ConfigHash),
+		parentMarker,
+		opts.Marker,
+		idempotencyMarker,
+	)
+	return PRPreview{
+		Title:       sanitizeTitle(title, opts.EscapeControlCharacters),
+		Description: prDescription,
+		Labels: appendLabels(
+			appendLabels(nil, opts.Labels...),
+			matchLabels(opts.LabelRules, changedFiles)...,
+		),
+		Truncated: truncated,
+	}
+}
@@ -0,0 +1,105 @@
+package azuredevops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestPreviewPRMatchesOpenPR(t *testing.T) {
+	testCases := []struct {
+		name        string
+		title       string
+		description string
+		opts        OpenPROptions
+	}{
+		{
+			name:        "defaults",
+			title:       "title",
+			description: "description",
+		},
+		{
+			name:        "footer, artifact, markers, and labels",
+			title:       "title\x1b[31m",
+			description: "description\x00",
+			opts: OpenPROptions{
+				Footer:         "footer",
+				ArtifactURL:    "https://example.com/diff",
+				IdempotencyKey: "key",
+				ParentPRID:     7,
+				ConfigHash:     "sha256:abc",
+				Labels:         []string{"kargo-render", "Kargo-Render", "env/dev"},
+			},
+		},
+		{
+			name:        "truncated",
+			title:       "title",
+			description: strings.Repeat("x", 2*maxDescriptionLength),
+			opts:        OpenPROptions{Footer: "footer"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var created git.GitPullRequest
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPullRequestsFn: func(
+					context.Context,
+					git.GetPullRequestsArgs,
+				) (*[]git.GitPullRequest, error) {
+					return &[]git.GitPullRequest{}, nil
+				},
+				createPullRequestFn: fakeCreatePullRequest(&created),
+			})
+			opts := testCase.opts
+			preview := PreviewPR(testCase.title, testCase.description, nil, &opts)
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				testCase.title,
+				testCase.description,
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				&opts,
+			)
+			require.NoError(t, err)
+			require.Equal(t, *created.Title, preview.Title)
+			require.Equal(t, *created.Description, preview.Description)
+			var labels []string
+			if created.Labels != nil {
+				for _, label := range *created.Labels {
+					labels = append(labels, *label.Name)
+				}
+			}
+			require.Equal(t, labels, preview.Labels)
+			require.Equal(
+				t,
+				strings.Contains(preview.Description, truncationNotice),
+				preview.Truncated,
+			)
+		})
+	}
+}
+
+func TestPreviewPRLabelRules(t *testing.T) {
+	preview := PreviewPR(
+		"title",
+		"description",
+		[]string{"env/prod/app.yaml"},
+		&OpenPROptions{
+			Labels: []string{"kargo-render"},
+			LabelRules: []LabelRule{
+				{Path: "env/dev", Label: "dev"},
+				{Path: "env/prod", Label: "prod"},
+				{Path: "", Label: "Kargo-Render"},
+			},
+		},
+	)
+	require.Equal(t, []string{"kargo-render", "prod"}, preview.Labels)
+}
@@ -0,0 +1,99 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+
+	"github.com/akuity/kargo-render/internal/repourl"
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// newCoreClient creates an Azure DevOps Core client. It is a package-level
+// variable so that it can be overridden in tests.
+var newCoreClient = core.NewClient
+
+// projectsPageSize is the number of projects requested from Azure DevOps per
+// page.
+const projectsPageSize = 100
+
+// Project describes an Azure DevOps project.
+type Project struct {
+	// ID is the ID of the project.
+	ID string
+	// Name is the name of the project.
+	Name string
+	// Description is the description of the project, if any.
+	Description string
+}
+
+// ListProjects returns all projects in the Azure DevOps organization
+// referenced by the specified URL, following continuation tokens until all
+// pages have been retrieved. The URL may be that of the organization itself or
+// of anything within it, such as a repository.
+func ListProjects(
+	ctx context.Context,
+	orgURL string,
+	creds gitutil.RepoCredentials,
+) (_ []Project, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	org, err := parseOrganizationURL(repourl.Normalize(orgURL))
+	if err != nil {
+		return nil, err
+	}
+	connection, err := newConnection(fmt.Sprintf("https://dev.azure.com/%s", org), creds, "")
+	if err != nil {
+		return nil, err
+	}
+	client, err := newCoreClient(ctx, connection)
+	if err != nil {
+		return nil, fmt.Errorf("error creating core client: %w", err)
+	}
+	var projects []Project
+	top := projectsPageSize
+	var continuationToken *int
+	for {
+		res, err := client.GetProjects(ctx, core.GetProjectsArgs{
+			Top:               &top,
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing projects in organization %q: %w", org, err)
+		}
+		if res == nil {
+			return projects, nil
+		}
+		for _, ref := range res.Value {
+			projects = append(projects, projectOf(ref))
+		}
+		if res.ContinuationToken == "" {
+			return projects, nil
+		}
+		token, err := strconv.Atoi(res.ContinuationToken)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error parsing continuation token %q: %w",
+				res.ContinuationToken,
+				err,
+			)
+		}
+		continuationToken = &token
+	}
+}
+
+// projectOf converts the specified project reference to a Project.
+func projectOf(ref core.TeamProjectReference) Project {
+	var project Project
+	if ref.Id != nil {
+		project.ID = ref.Id.String()
+	}
+	if ref.Name != nil {
+		project.Name = *ref.Name
+	}
+	if ref.Description != nil {
+		project.Description = *ref.Description
+	}
+	return project
+}
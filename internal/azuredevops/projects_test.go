@@ -0,0 +1,85 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// fakeCoreClient is a fake core.Client. Only GetProjects is implemented;
+// calling any other method panics.
+type fakeCoreClient struct {
+	core.Client
+	getProjectsFn func(context.Context, core.GetProjectsArgs) (*core.GetProjectsResponseValue, error)
+}
+
+func (f *fakeCoreClient) GetProjects(
+	ctx context.Context,
+	args core.GetProjectsArgs,
+) (*core.GetProjectsResponseValue, error) {
+	return f.getProjectsFn(ctx, args)
+}
+
+func TestListProjects(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	pages := map[int]*core.GetProjectsResponseValue{
+		0: {
+			Value: []core.TeamProjectReference{
+				{Id: &ids[0], Name: ptr("platform"), Description: ptr("Platform team")},
+				{Id: &ids[1], Name: ptr("payments")},
+			},
+			ContinuationToken: "2",
+		},
+		2: {
+			Value: []core.TeamProjectReference{
+				{Id: &ids[2], Name: ptr("search"), Description: ptr("")},
+			},
+		},
+	}
+	var baseURL string
+	var requests int
+	orig := newCoreClient
+	newCoreClient = func(_ context.Context, conn *azuredevops.Connection) (core.Client, error) {
+		baseURL = conn.BaseUrl
+		return &fakeCoreClient{
+			getProjectsFn: func(
+				_ context.Context,
+				args core.GetProjectsArgs,
+			) (*core.GetProjectsResponseValue, error) {
+				requests++
+				require.Equal(t, projectsPageSize, *args.Top)
+				var token int
+				if args.ContinuationToken != nil {
+					token = *args.ContinuationToken
+				}
+				page, ok := pages[token]
+				require.True(t, ok, "unexpected continuation token %d", token)
+				return page, nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() { newCoreClient = orig })
+	projects, err := ListProjects(
+		context.Background(),
+		"https://org.visualstudio.com",
+		gitutil.RepoCredentials{Password: "pat"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "https://dev.azure.com/org", baseURL)
+	require.Equal(t, 2, requests)
+	require.Equal(
+		t,
+		[]Project{
+			{ID: ids[0].String(), Name: "platform", Description: "Platform team"},
+			{ID: ids[1].String(), Name: "payments"},
+			{ID: ids[2].String(), Name: "search"},
+		},
+		projects,
+	)
+}
@@ -0,0 +1,112 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/policy"
+)
+
+// ErrUnprotectedBranch is returned when auto-complete is requested for a PR
+// whose target branch is required to be protected, but has no required
+// policies configured.
+var ErrUnprotectedBranch = errors.New("target branch has no required policies")
+
+// ErrDefaultBranchTarget is returned when a PR would target the repository's
+// default branch, but OpenPROptions.GuardDefaultBranch forbids that.
+var ErrDefaultBranchTarget = errors.New("target branch is the repository's default branch")
+
+// ensureNotDefaultBranch returns an error wrapping ErrDefaultBranchTarget if
+// the specified fully-qualified branch ref is the repository's default branch.
+// Azure DevOps branch names are case insensitive, so they are compared
+// accordingly.
+func ensureNotDefaultBranch(ctx context.Context, repo *repoClient, branch string) error {
+	details, err := repo.details(ctx)
+	if err != nil {
+		return err
+	}
+	if details.DefaultBranch == nil || !strings.EqualFold(*details.DefaultBranch, branch) {
+		return nil
+	}
+	return fmt.Errorf(
+		"%w: refusing to open a pull request to %q without explicit permission",
+		ErrDefaultBranchTarget,
+		strings.TrimPrefix(branch, "refs/heads/"),
+	)
+}
+
+// ensureProtected returns an error wrapping ErrUnprotectedBranch if no
+// enabled, blocking policies apply to the specified branch of the repository.
+func ensureProtected(ctx context.Context, repo *repoClient, branch string) error {
+	found, err := hasPolicy(ctx, repo, branch, isRequired)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %q", ErrUnprotectedBranch, branch)
+	}
+	return nil
+}
+
+// hasPolicy returns a bool indicating whether any policy that applies to the
+// specified branch of the repository satisfies the specified predicate.
+func hasPolicy(
+	ctx context.Context,
+	repo *repoClient,
+	branch string,
+	match func(policy.PolicyConfiguration) bool,
+) (bool, error) {
+	var found bool
+	err := forEachPolicy(ctx, repo, branch, func(config policy.PolicyConfiguration) bool {
+		found = match(config)
+		return !found
+	})
+	return found, err
+}
+
+// forEachPolicy invokes the specified function with each policy that applies
+// to the specified branch of the repository, for as long as it returns true.
+func forEachPolicy(
+	ctx context.Context,
+	repo *repoClient,
+	branch string,
+	fn func(policy.PolicyConfiguration) bool,
+) error {
+	var continuationToken *string
+	for {
+		res, err := repo.client.GetPolicyConfigurations(ctx, git.GetPolicyConfigurationsArgs{
+			Project:           &repo.project,
+			RepositoryId:      repo.repository.Id,
+			RefName:           &branch,
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			return fmt.Errorf("error listing policies for branch %q: %w", branch, err)
+		}
+		if res == nil {
+			return nil
+		}
+		if res.PolicyConfigurations != nil {
+			for _, config := range *res.PolicyConfigurations {
+				if !fn(config) {
+					return nil
+				}
+			}
+		}
+		if res.ContinuationToken == nil || *res.ContinuationToken == "" {
+			return nil
+		}
+		continuationToken = res.ContinuationToken
+	}
+}
+
+// isRequired returns a bool indicating whether the specified policy must be
+// satisfied before a PR can be completed.
+func isRequired(config policy.PolicyConfiguration) bool {
+	return config.IsEnabled != nil && *config.IsEnabled &&
+		config.IsBlocking != nil && *config.IsBlocking &&
+		(config.IsDeleted == nil || !*config.IsDeleted)
+}
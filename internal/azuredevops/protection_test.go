@@ -0,0 +1,217 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/policy"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestOpenPRRequireProtection(t *testing.T) {
+	required := policy.PolicyConfiguration{IsEnabled: ptr(true), IsBlocking: ptr(true)}
+	optional := policy.PolicyConfiguration{IsEnabled: ptr(true), IsBlocking: ptr(false)}
+	disabled := policy.PolicyConfiguration{IsEnabled: ptr(false), IsBlocking: ptr(true)}
+	deleted := policy.PolicyConfiguration{
+		IsEnabled:  ptr(true),
+		IsBlocking: ptr(true),
+		IsDeleted:  ptr(true),
+	}
+	testCases := []struct {
+		name       string
+		pages      [][]policy.PolicyConfiguration
+		assertions func(t *testing.T, created, autoCompleted bool, err error)
+	}{
+		{
+			name:  "protected target",
+			pages: [][]policy.PolicyConfiguration{{optional, required}},
+			assertions: func(t *testing.T, created, autoCompleted bool, err error) {
+				require.NoError(t, err)
+				require.True(t, created)
+				require.True(t, autoCompleted)
+			},
+		},
+		{
+			name:  "required policy on a later page",
+			pages: [][]policy.PolicyConfiguration{{optional}, {required}},
+			assertions: func(t *testing.T, created, autoCompleted bool, err error) {
+				require.NoError(t, err)
+				require.True(t, created)
+				require.True(t, autoCompleted)
+			},
+		},
+		{
+			name:  "only optional, disabled, or deleted policies",
+			pages: [][]policy.PolicyConfiguration{{optional, disabled, deleted}},
+			assertions: func(t *testing.T, created, autoCompleted bool, err error) {
+				require.ErrorIs(t, err, ErrUnprotectedBranch)
+				require.ErrorContains(t, err, "refs/heads/env/prod")
+				require.False(t, created)
+				require.False(t, autoCompleted)
+			},
+		},
+		{
+			name:  "no policies",
+			pages: [][]policy.PolicyConfiguration{{}},
+			assertions: func(t *testing.T, created, autoCompleted bool, err error) {
+				require.ErrorIs(t, err, ErrUnprotectedBranch)
+				require.False(t, created)
+				require.False(t, autoCompleted)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var created, autoCompleted bool
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPolicyConfigurationsFn: func(
+					_ context.Context,
+					args git.GetPolicyConfigurationsArgs,
+				) (*git.GitPolicyConfigurationResponse, error) {
+					require.Equal(t, "refs/heads/env/prod", *args.RefName)
+					page := 0
+					if args.ContinuationToken != nil {
+						page = 1
+					}
+					res := &git.GitPolicyConfigurationResponse{
+						PolicyConfigurations: &testCase.pages[page],
+					}
+					if page < len(testCase.pages)-1 {
+						res.ContinuationToken = ptr("next")
+					}
+					return res, nil
+				},
+				createPullRequestFn: func(
+					ctx context.Context,
+					args git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					created = true
+					return fakeCreatePullRequest(nil)(ctx, args)
+				},
+				updatePullRequestFn: func(
+					_ context.Context,
+					args git.UpdatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					autoCompleted = true
+					return args.GitPullRequestToUpdate, nil
+				},
+			})
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/prod",
+				"prs/kargo-render/env/prod",
+				gitutil.RepoCredentials{Password: "pat"},
+				&OpenPROptions{
+					AutoComplete: &AutoCompleteOptions{
+						SetByID:           "service-identity-id",
+						RequireProtection: true,
+					},
+				},
+			)
+			testCase.assertions(t, created, autoCompleted, err)
+		})
+	}
+}
+
+func TestOpenPRGuardDefaultBranch(t *testing.T) {
+	testCases := []struct {
+		name       string
+		target     string
+		opts       OpenPROptions
+		assertions func(t *testing.T, url string, created bool, err error)
+	}{
+		{
+			name:   "default branch refused",
+			target: "main",
+			opts:   OpenPROptions{GuardDefaultBranch: true},
+			assertions: func(t *testing.T, url string, created bool, err error) {
+				require.ErrorIs(t, err, ErrDefaultBranchTarget)
+				require.ErrorContains(t, err, `"main"`)
+				require.Empty(t, url)
+				require.False(t, created)
+			},
+		},
+		{
+			name:   "default branch refused regardless of case",
+			target: "refs/heads/Main",
+			opts:   OpenPROptions{GuardDefaultBranch: true},
+			assertions: func(t *testing.T, _ string, created bool, err error) {
+				require.ErrorIs(t, err, ErrDefaultBranchTarget)
+				require.False(t, created)
+			},
+		},
+		{
+			name:   "default branch explicitly allowed",
+			target: "main",
+			opts: OpenPROptions{
+				GuardDefaultBranch:       true,
+				AllowDefaultBranchTarget: true,
+			},
+			assertions: func(t *testing.T, url string, created bool, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, url)
+				require.True(t, created)
+			},
+		},
+		{
+			name:   "other branch",
+			target: "env/prod",
+			opts:   OpenPROptions{GuardDefaultBranch: true},
+			assertions: func(t *testing.T, _ string, created bool, err error) {
+				require.NoError(t, err)
+				require.True(t, created)
+			},
+		},
+		{
+			name:   "guard disabled",
+			target: "main",
+			assertions: func(t *testing.T, _ string, created bool, err error) {
+				require.NoError(t, err)
+				require.True(t, created)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var created bool
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: func(
+					context.Context,
+					git.GetRepositoriesArgs,
+				) (*[]git.GitRepository, error) {
+					return &[]git.GitRepository{{
+						Id:            ptr(uuid.New()),
+						Name:          ptr("repo"),
+						DefaultBranch: ptr("refs/heads/main"),
+					}}, nil
+				},
+				createPullRequestFn: func(
+					ctx context.Context,
+					args git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					created = true
+					return fakeCreatePullRequest(nil)(ctx, args)
+				},
+			})
+			url, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				testCase.target,
+				"prs/kargo-render/main",
+				gitutil.RepoCredentials{Password: "token"},
+				&testCase.opts,
+			)
+			testCase.assertions(t, url, created, err)
+		})
+	}
+}
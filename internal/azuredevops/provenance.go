@@ -0,0 +1,38 @@
+package azuredevops
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/akuity/kargo-render/internal/version"
+)
+
+// provenanceMarkers returns hidden comments, for inclusion in a PR
+// description, recording the version of Kargo Render and the hash of the
+// configuration that produced the PR. Empty values are omitted.
+func provenanceMarkers(ver string, configHash string) string {
+	var markers []string
+	if ver != "" {
+		markers = append(markers, fmt.Sprintf("<!-- kargo-render-version: %s -->", ver))
+	}
+	if configHash != "" {
+		markers = append(
+			markers,
+			fmt.Sprintf("<!-- kargo-render-config-hash: %s -->", configHash),
+		)
+	}
+	return strings.Join(markers, "\n")
+}
+
+// provenanceVersion returns the version of Kargo Render that should be
+// recorded in a PR description according to the specified options.
+func provenanceVersion(opts *OpenPROptions) string {
+	switch {
+	case opts.OmitVersion:
+		return ""
+	case opts.Version != "":
+		return opts.Version
+	default:
+		return version.GetVersion().Version
+	}
+}
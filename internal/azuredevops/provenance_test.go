@@ -0,0 +1,76 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akuity/kargo-render/internal/version"
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestOpenPRProvenance(t *testing.T) {
+	testCases := []struct {
+		name       string
+		opts       *OpenPROptions
+		assertions func(t *testing.T, desc string)
+	}{
+		{
+			name: "version included by default",
+			assertions: func(t *testing.T, desc string) {
+				require.Contains(
+					t,
+					desc,
+					"<!-- kargo-render-version: "+version.GetVersion().Version+" -->",
+				)
+				require.NotContains(t, desc, "kargo-render-config-hash")
+			},
+		},
+		{
+			name: "explicit version and config hash",
+			opts: &OpenPROptions{
+				Version:    "v1.2.3",
+				ConfigHash: "sha256:abc123",
+			},
+			assertions: func(t *testing.T, desc string) {
+				require.Equal(
+					t,
+					"description\n\n"+
+						"<!-- kargo-render-version: v1.2.3 -->\n"+
+						"<!-- kargo-render-config-hash: sha256:abc123 -->",
+					desc,
+				)
+			},
+		},
+		{
+			name: "version omitted",
+			opts: &OpenPROptions{OmitVersion: true},
+			assertions: func(t *testing.T, desc string) {
+				require.Equal(t, "description", desc)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var pr git.GitPullRequest
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn:   fakeRepos("repo"),
+				createPullRequestFn: fakeCreatePullRequest(&pr),
+			})
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				testCase.opts,
+			)
+			require.NoError(t, err)
+			testCase.assertions(t, *pr.Description)
+		})
+	}
+}
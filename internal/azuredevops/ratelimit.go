@@ -0,0 +1,85 @@
+package azuredevops
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit describes how close a caller is to Azure DevOps' rate limits, as
+// reported by the headers of a response. Azure DevOps only reports this when a
+// caller is approaching or exceeding a limit.
+type RateLimit struct {
+	// Resource is the name of the resource whose limit is being approached,
+	// e.g. "Core".
+	Resource string
+	// Limit is the total number of units permitted within the current window.
+	// When this is zero, Limit and Remaining were not reported.
+	Limit int
+	// Remaining is the number of units remaining within the current window.
+	Remaining int
+	// Reset is the time at which the current window ends, if reported.
+	Reset time.Time
+	// Delay is the amount of time by which the request was delayed, if any.
+	Delay time.Duration
+	// RetryAfter is the amount of time the caller should wait before making
+	// another request, if reported.
+	RetryAfter time.Duration
+}
+
+// parseRateLimit parses rate limit information from the specified response
+// headers. It returns false if the headers include none.
+func parseRateLimit(header http.Header) (RateLimit, bool) {
+	var rl RateLimit
+	var found bool
+	if v := header.Get("X-RateLimit-Resource"); v != "" {
+		rl.Resource, found = v, true
+	}
+	if v, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil {
+		rl.Limit, found = v, true
+	}
+	if v, err := strconv.Atoi(header.Get("X-RateLimit-Remaining")); err == nil {
+		rl.Remaining, found = v, true
+	}
+	if v, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.Reset, found = time.Unix(v, 0), true
+	}
+	if v, ok := parseSeconds(header.Get("X-RateLimit-Delay")); ok {
+		rl.Delay, found = v, true
+	}
+	if v := strings.TrimSpace(header.Get("Retry-After")); v != "" {
+		if d, ok := parseSeconds(v); ok {
+			rl.RetryAfter, found = d, true
+		} else if t, err := http.ParseTime(v); err == nil {
+			rl.RetryAfter, found = max(t.Sub(now()), 0), true
+		}
+	}
+	return rl, found
+}
+
+// parseSeconds parses a possibly fractional, non-negative number of seconds.
+func parseSeconds(v string) (time.Duration, bool) {
+	secs, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || secs < 0 {
+		return 0, false
+	}
+	return time.Duration(secs * float64(time.Second)), true
+}
+
+// rateLimitTransport is an http.RoundTripper that reports the rate limit
+// information included in every response to an observer.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	observe func(RateLimit)
+}
+
+func (r *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := r.next.RoundTrip(req)
+	if err == nil && res != nil {
+		if rl, ok := parseRateLimit(res.Header); ok {
+			r.observe(rl)
+		}
+	}
+	return res, err
+}
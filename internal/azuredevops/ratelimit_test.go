@@ -0,0 +1,118 @@
+package azuredevops
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	testCases := []struct {
+		name       string
+		header     http.Header
+		assertions func(t *testing.T, rl RateLimit, found bool)
+	}{
+		{
+			name:   "no rate limit headers",
+			header: http.Header{"Content-Type": []string{"application/json"}},
+			assertions: func(t *testing.T, _ RateLimit, found bool) {
+				require.False(t, found)
+			},
+		},
+		{
+			name: "approaching limit",
+			header: http.Header{
+				"X-Ratelimit-Resource":  []string{"Core"},
+				"X-Ratelimit-Limit":     []string{"200"},
+				"X-Ratelimit-Remaining": []string{"12"},
+				"X-Ratelimit-Reset":     []string{"1704067200"},
+				"X-Ratelimit-Delay":     []string{"0.5"},
+			},
+			assertions: func(t *testing.T, rl RateLimit, found bool) {
+				require.True(t, found)
+				require.Equal(
+					t,
+					RateLimit{
+						Resource:  "Core",
+						Limit:     200,
+						Remaining: 12,
+						Reset:     time.Unix(1704067200, 0),
+						Delay:     500 * time.Millisecond,
+					},
+					rl,
+				)
+			},
+		},
+		{
+			name:   "retry after seconds",
+			header: http.Header{"Retry-After": []string{"30"}},
+			assertions: func(t *testing.T, rl RateLimit, found bool) {
+				require.True(t, found)
+				require.Equal(t, 30*time.Second, rl.RetryAfter)
+			},
+		},
+		{
+			name: "retry after date",
+			header: http.Header{
+				"Retry-After": []string{"Mon, 01 Jan 2024 00:01:00 GMT"},
+			},
+			assertions: func(t *testing.T, rl RateLimit, found bool) {
+				require.True(t, found)
+				require.Equal(t, time.Minute, rl.RetryAfter)
+			},
+		},
+		{
+			name:   "malformed values",
+			header: http.Header{"X-Ratelimit-Remaining": []string{"lots"}},
+			assertions: func(t *testing.T, _ RateLimit, found bool) {
+				require.False(t, found)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			useFakeClock(t)
+			rl, found := parseRateLimit(testCase.header)
+			testCase.assertions(t, rl, found)
+		})
+	}
+}
+
+func TestWithTransportRateLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Resource", "Core")
+		w.Header().Set("X-RateLimit-Limit", "200")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+	connection := azuredevops.NewPatConnection(server.URL, "pat")
+	var observed []RateLimit
+	client := withTransport(
+		&git.ClientImpl{Client: *azuredevops.NewClient(connection, server.URL)},
+		nil,
+		func(rl RateLimit) { observed = append(observed, rl) },
+	)
+	_, err := client.GetRepositories(context.Background(), git.GetRepositoriesArgs{
+		Project: ptr("proj"),
+	})
+	require.Error(t, err)
+	require.NotEmpty(t, observed)
+	require.Equal(
+		t,
+		RateLimit{
+			Resource:   "Core",
+			Limit:      200,
+			Remaining:  0,
+			RetryAfter: 10 * time.Second,
+		},
+		observed[0],
+	)
+}
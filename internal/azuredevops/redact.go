@@ -0,0 +1,101 @@
+package azuredevops
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// redactedPlaceholder replaces any redacted portion of an error message.
+const redactedPlaceholder = "[REDACTED]"
+
+// redaction pairs a pattern matching sensitive information within an error
+// message with the replacement for each match. Replacements may refer to
+// capture groups so that non-sensitive context can be preserved.
+type redaction struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// defaultRedactions match credentials that the Azure DevOps client library is
+// known to, or may plausibly, echo in the errors it returns.
+var defaultRedactions = []redaction{
+	{
+		// Authorization header values
+		pattern:     regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/-]+=*`),
+		replacement: "${1} " + redactedPlaceholder,
+	},
+	{
+		// Credentials embedded in URLs
+		pattern:     regexp.MustCompile(`(://[^/\s:@]+:)[^@\s/]+@`),
+		replacement: "${1}" + redactedPlaceholder + "@",
+	},
+	{
+		// Credentials passed as parameters
+		pattern: regexp.MustCompile(
+			`(?i)\b((?:access_?token|password|pat|token)\s*[=:]\s*)[^\s&"',]+`,
+		),
+		replacement: "${1}" + redactedPlaceholder,
+	},
+	{
+		// Legacy (52 character, base32) and current (84 character) PATs
+		pattern:     regexp.MustCompile(`\b(?:[a-z2-7]{52}|[A-Za-z0-9]{84})\b`),
+		replacement: redactedPlaceholder,
+	},
+}
+
+// redactedError is an error whose message has had sensitive information
+// masked. It deliberately does not expose the original error via Unwrap, since
+// that would expose the original message as well, for instance, to anything
+// that formats the chain of wrapped errors. It does, however, preserve the
+// ability to use errors.Is to identify the original error and errors.As to
+// find typed errors, such as those carrying the status codes of Azure DevOps
+// API responses, within it. The fields of typed errors found that way are not
+// masked.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (r *redactedError) Error() string {
+	return r.msg
+}
+
+func (r *redactedError) Is(target error) bool {
+	return errors.Is(r.err, target)
+}
+
+func (r *redactedError) As(target any) bool {
+	return errors.As(r.err, target)
+}
+
+// redactError masks, within the message of the specified error, any of the
+// specified secrets and anything matching a pattern in defaultRedactions or
+// the specified additional patterns. If nothing requires masking, the error is
+// returned unmodified.
+func redactError(
+	err error,
+	secrets []string,
+	patterns []*regexp.Regexp,
+) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	redacted := msg
+	for _, secret := range secrets {
+		if secret != "" {
+			redacted = strings.ReplaceAll(redacted, secret, redactedPlaceholder)
+		}
+	}
+	for _, r := range defaultRedactions {
+		redacted = r.pattern.ReplaceAllString(redacted, r.replacement)
+	}
+	for _, pattern := range patterns {
+		redacted = pattern.ReplaceAllString(redacted, redactedPlaceholder)
+	}
+	if redacted == msg {
+		return err
+	}
+	return &redactedError{msg: redacted, err: err}
+}
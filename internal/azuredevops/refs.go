@@ -0,0 +1,189 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// nullObjectID is the object ID that, when specified as the new object ID of a
+// ref update, deletes the ref.
+const nullObjectID = "0000000000000000000000000000000000000000"
+
+// refsPageSize is the maximum number of refs Azure DevOps will return in a
+// single page.
+const refsPageSize = 1000
+
+// ListBranches returns the names of all branches in the specified repository,
+// without any refs/heads/ prefix.
+func ListBranches(
+	ctx context.Context,
+	repoURL string,
+	creds gitutil.RepoCredentials,
+) (_ []string, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := listRefs(ctx, repo, "heads/")
+	if err != nil {
+		return nil, err
+	}
+	branches := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Name != nil && strings.HasPrefix(*ref.Name, "refs/heads/") {
+			branches = append(branches, strings.TrimPrefix(*ref.Name, "refs/heads/"))
+		}
+	}
+	return branches, nil
+}
+
+// listRefs returns all refs whose names, relative to refs/, begin with the
+// specified prefix, following continuation tokens until all pages have been
+// retrieved.
+func listRefs(
+	ctx context.Context,
+	repo *repoClient,
+	filter string,
+) ([]git.GitRef, error) {
+	var refs []git.GitRef
+	top := refsPageSize
+	var continuationToken *string
+	for {
+		res, err := repo.client.GetRefs(ctx, git.GetRefsArgs{
+			Project:           &repo.project,
+			RepositoryId:      &repo.id,
+			Filter:            &filter,
+			Top:               &top,
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing refs matching %q: %w", filter, err)
+		}
+		if res == nil {
+			return refs, nil
+		}
+		refs = append(refs, res.Value...)
+		if res.ContinuationToken == "" {
+			return refs, nil
+		}
+		continuationToken = &res.ContinuationToken
+	}
+}
+
+// getRef returns the specified fully-qualified ref or nil if it does not
+// exist.
+func getRef(ctx context.Context, repo *repoClient, ref string) (*git.GitRef, error) {
+	// Ref filters are prefixes relative to refs/
+	refs, err := listRefs(ctx, repo, strings.TrimPrefix(ref, "refs/"))
+	if err != nil {
+		return nil, err
+	}
+	for i := range refs {
+		if refs[i].Name != nil && *refs[i].Name == ref {
+			return &refs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// deleteBranch deletes the specified fully-qualified branch ref. It is not an
+// error if the branch does not exist.
+func deleteBranch(ctx context.Context, repo *repoClient, ref string) error {
+	existing, err := getRef(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+	if existing == nil || existing.ObjectId == nil {
+		return nil
+	}
+	if err = updateRef(ctx, repo, ref, *existing.ObjectId, nullObjectID); err != nil {
+		return fmt.Errorf("error deleting branch %q: %w", ref, err)
+	}
+	return nil
+}
+
+// ensureBranch creates the specified fully-qualified branch ref, pointing at
+// the head of the repository's default branch, if it does not already exist.
+// It returns a bool indicating whether the branch was created.
+func ensureBranch(ctx context.Context, repo *repoClient, ref string) (bool, error) {
+	existing, err := getRef(ctx, repo, ref)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		return false, nil
+	}
+	details, err := repo.details(ctx)
+	if err != nil {
+		return false, err
+	}
+	if details.DefaultBranch == nil || *details.DefaultBranch == "" {
+		return false, fmt.Errorf(
+			"error creating branch %q: repository has no default branch",
+			ref,
+		)
+	}
+	defaultBranch := *details.DefaultBranch
+	head, err := getRef(ctx, repo, defaultBranch)
+	if err != nil {
+		return false, err
+	}
+	if head == nil || head.ObjectId == nil {
+		return false, fmt.Errorf(
+			"error creating branch %q: default branch %q not found",
+			ref,
+			defaultBranch,
+		)
+	}
+	if err = updateRef(ctx, repo, ref, nullObjectID, *head.ObjectId); err != nil {
+		return false, fmt.Errorf(
+			"error creating branch %q from %q: %w",
+			ref,
+			defaultBranch,
+			err,
+		)
+	}
+	return true, nil
+}
+
+// updateRef atomically updates the specified fully-qualified ref from the
+// specified old object ID to the specified new one. A null old object ID
+// creates the ref; a null new object ID deletes it.
+func updateRef(
+	ctx context.Context,
+	repo *repoClient,
+	ref string,
+	oldObjectID string,
+	newObjectID string,
+) error {
+	results, err := repo.client.UpdateRefs(ctx, git.UpdateRefsArgs{
+		Project:      &repo.project,
+		RepositoryId: &repo.id,
+		RefUpdates: &[]git.GitRefUpdate{{
+			Name:        &ref,
+			OldObjectId: &oldObjectID,
+			NewObjectId: &newObjectID,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	if results != nil {
+		for _, res := range *results {
+			if res.Success != nil && !*res.Success {
+				status := git.GitRefUpdateStatus("unknown")
+				if res.UpdateStatus != nil {
+					status = *res.UpdateStatus
+				}
+				return fmt.Errorf("status was %q", status)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,436 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestOpenPRDeleteSourceBranchOnFailure(t *testing.T) {
+	const sourceRef = "refs/heads/prs/kargo-render/env/dev"
+	testCases := []struct {
+		name          string
+		branchCreated bool
+		assertions    func(t *testing.T, deleted []git.GitRefUpdate, err error)
+	}{
+		{
+			name:          "branch created by caller",
+			branchCreated: true,
+			assertions: func(t *testing.T, deleted []git.GitRefUpdate, err error) {
+				require.ErrorContains(t, err, "error creating pull request")
+				require.Len(t, deleted, 1)
+				require.Equal(t, sourceRef, *deleted[0].Name)
+				require.Equal(t, "abc123", *deleted[0].OldObjectId)
+				require.Equal(t, nullObjectID, *deleted[0].NewObjectId)
+			},
+		},
+		{
+			name: "pre-existing branch",
+			assertions: func(t *testing.T, deleted []git.GitRefUpdate, err error) {
+				require.ErrorContains(t, err, "error creating pull request")
+				require.Empty(t, deleted)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var deleted []git.GitRefUpdate
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				createPullRequestFn: func(
+					context.Context,
+					git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					return nil, errors.New("something went wrong")
+				},
+				getRefsFn: func(
+					_ context.Context,
+					args git.GetRefsArgs,
+				) (*git.GetRefsResponseValue, error) {
+					require.Equal(t, "heads/prs/kargo-render/env/dev", *args.Filter)
+					return &git.GetRefsResponseValue{
+						Value: []git.GitRef{
+							{Name: ptr(sourceRef + "-other"), ObjectId: ptr("def456")},
+							{Name: ptr(sourceRef), ObjectId: ptr("abc123")},
+						},
+					}, nil
+				},
+				updateRefsFn: func(
+					_ context.Context,
+					args git.UpdateRefsArgs,
+				) (*[]git.GitRefUpdateResult, error) {
+					deleted = append(deleted, *args.RefUpdates...)
+					return &[]git.GitRefUpdateResult{{Success: ptr(true)}}, nil
+				},
+			})
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				&OpenPROptions{
+					DeleteSourceBranchOnFailure: true,
+					SourceBranchCreated:         testCase.branchCreated,
+				},
+			)
+			testCase.assertions(t, deleted, err)
+		})
+	}
+}
+
+func TestOpenPRDeleteSourceBranchOnPreflightFailure(t *testing.T) {
+	const sourceRef = "refs/heads/prs/kargo-render/main"
+	testCases := []struct {
+		name        string
+		opts        OpenPROptions
+		expectedErr error
+	}{
+		{
+			name:        "default branch refused",
+			opts:        OpenPROptions{GuardDefaultBranch: true},
+			expectedErr: ErrDefaultBranchTarget,
+		},
+		{
+			name: "unprotected target branch",
+			opts: OpenPROptions{
+				AutoComplete: &AutoCompleteOptions{
+					SetByID:           "service-identity-id",
+					RequireProtection: true,
+				},
+			},
+			expectedErr: ErrUnprotectedBranch,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var deleted []git.GitRefUpdate
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: func(
+					context.Context,
+					git.GetRepositoriesArgs,
+				) (*[]git.GitRepository, error) {
+					return &[]git.GitRepository{{
+						Id:            ptr(uuid.New()),
+						Name:          ptr("repo"),
+						DefaultBranch: ptr("refs/heads/main"),
+					}}, nil
+				},
+				getPolicyConfigurationsFn: func(
+					context.Context,
+					git.GetPolicyConfigurationsArgs,
+				) (*git.GitPolicyConfigurationResponse, error) {
+					return &git.GitPolicyConfigurationResponse{}, nil
+				},
+				getRefsFn: func(
+					context.Context,
+					git.GetRefsArgs,
+				) (*git.GetRefsResponseValue, error) {
+					return &git.GetRefsResponseValue{
+						Value: []git.GitRef{{Name: ptr(sourceRef), ObjectId: ptr("abc123")}},
+					}, nil
+				},
+				updateRefsFn: func(
+					_ context.Context,
+					args git.UpdateRefsArgs,
+				) (*[]git.GitRefUpdateResult, error) {
+					deleted = append(deleted, *args.RefUpdates...)
+					return &[]git.GitRefUpdateResult{{Success: ptr(true)}}, nil
+				},
+			})
+			opts := testCase.opts
+			opts.DeleteSourceBranchOnFailure = true
+			opts.SourceBranchCreated = true
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"main",
+				"prs/kargo-render/main",
+				gitutil.RepoCredentials{Password: "token"},
+				&opts,
+			)
+			require.ErrorIs(t, err, testCase.expectedErr)
+			require.Len(t, deleted, 1)
+			require.Equal(t, sourceRef, *deleted[0].Name)
+			require.Equal(t, nullObjectID, *deleted[0].NewObjectId)
+		})
+	}
+}
+
+func TestListBranches(t *testing.T) {
+	pages := map[string]*git.GetRefsResponseValue{
+		"": {
+			Value: []git.GitRef{
+				{Name: ptr("refs/heads/main")},
+				{Name: ptr("refs/heads/env/dev")},
+			},
+			ContinuationToken: "page-2",
+		},
+		"page-2": {
+			Value: []git.GitRef{
+				{Name: ptr("refs/heads/env/prod")},
+			},
+			ContinuationToken: "page-3",
+		},
+		"page-3": {
+			Value: []git.GitRef{
+				{Name: ptr("refs/heads/prs/kargo-render/env/prod")},
+			},
+		},
+	}
+	var requests int
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: fakeRepos("repo"),
+		getRefsFn: func(
+			_ context.Context,
+			args git.GetRefsArgs,
+		) (*git.GetRefsResponseValue, error) {
+			requests++
+			require.Equal(t, "heads/", *args.Filter)
+			var token string
+			if args.ContinuationToken != nil {
+				token = *args.ContinuationToken
+			}
+			page, ok := pages[token]
+			require.True(t, ok, "unexpected continuation token %q", token)
+			return page, nil
+		},
+	})
+	branches, err := ListBranches(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		gitutil.RepoCredentials{Password: "pat"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, 3, requests)
+	require.Equal(
+		t,
+		[]string{"main", "env/dev", "env/prod", "prs/kargo-render/env/prod"},
+		branches,
+	)
+}
+
+func TestOpenPRCreateTargetBranch(t *testing.T) {
+	const targetRef = "refs/heads/env/new"
+	testCases := []struct {
+		name          string
+		refs          map[string]string
+		defaultBranch *string
+		assertions    func(t *testing.T, updates []git.GitRefUpdate, created bool, err error)
+	}{
+		{
+			name:          "missing target branch is created from default",
+			refs:          map[string]string{"refs/heads/main": "abc123"},
+			defaultBranch: ptr("refs/heads/main"),
+			assertions: func(t *testing.T, updates []git.GitRefUpdate, created bool, err error) {
+				require.NoError(t, err)
+				require.True(t, created)
+				require.Equal(
+					t,
+					[]git.GitRefUpdate{{
+						Name:        ptr(targetRef),
+						OldObjectId: ptr(nullObjectID),
+						NewObjectId: ptr("abc123"),
+					}},
+					updates,
+				)
+			},
+		},
+		{
+			name: "existing target branch is left alone",
+			refs: map[string]string{
+				"refs/heads/main": "abc123",
+				targetRef:         "def456",
+			},
+			defaultBranch: ptr("refs/heads/main"),
+			assertions: func(t *testing.T, updates []git.GitRefUpdate, created bool, err error) {
+				require.NoError(t, err)
+				require.True(t, created)
+				require.Empty(t, updates)
+			},
+		},
+		{
+			name: "repository without default branch",
+			assertions: func(t *testing.T, updates []git.GitRefUpdate, created bool, err error) {
+				require.ErrorContains(t, err, "repository has no default branch")
+				require.False(t, created)
+				require.Empty(t, updates)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var updates []git.GitRefUpdate
+			var created bool
+			id := uuid.New()
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: func(
+					context.Context,
+					git.GetRepositoriesArgs,
+				) (*[]git.GitRepository, error) {
+					return &[]git.GitRepository{{
+						Id:            &id,
+						Name:          ptr("repo"),
+						DefaultBranch: testCase.defaultBranch,
+					}}, nil
+				},
+				getRefsFn: func(
+					_ context.Context,
+					args git.GetRefsArgs,
+				) (*git.GetRefsResponseValue, error) {
+					res := &git.GetRefsResponseValue{}
+					if objectID, ok := testCase.refs["refs/"+*args.Filter]; ok {
+						res.Value = []git.GitRef{{
+							Name:     ptr("refs/" + *args.Filter),
+							ObjectId: &objectID,
+						}}
+					}
+					return res, nil
+				},
+				updateRefsFn: func(
+					_ context.Context,
+					args git.UpdateRefsArgs,
+				) (*[]git.GitRefUpdateResult, error) {
+					updates = append(updates, *args.RefUpdates...)
+					return &[]git.GitRefUpdateResult{{Success: ptr(true)}}, nil
+				},
+				createPullRequestFn: func(
+					ctx context.Context,
+					args git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					created = true
+					return fakeCreatePullRequest(nil)(ctx, args)
+				},
+			})
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/new",
+				"prs/kargo-render/env/new",
+				gitutil.RepoCredentials{Password: "pat"},
+				&OpenPROptions{CreateTargetBranch: true},
+			)
+			testCase.assertions(t, updates, created, err)
+		})
+	}
+}
+
+func TestOpenPRUnicodeBranchNames(t *testing.T) {
+	testCases := []struct {
+		name         string
+		targetBranch string
+		sourceBranch string
+		normalize    bool
+		assertions   func(t *testing.T, pr git.GitPullRequest, err error)
+	}{
+		{
+			name:         "names are used verbatim",
+			targetBranch: "env/cafe\u0301",
+			sourceBranch: "prs/kargo-render/env/cafe\u0301",
+			assertions: func(t *testing.T, pr git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "refs/heads/env/cafe\u0301", *pr.TargetRefName)
+				require.Equal(t, "refs/heads/prs/kargo-render/env/cafe\u0301", *pr.SourceRefName)
+			},
+		},
+		{
+			name:         "names are normalized",
+			targetBranch: "env/cafe\u0301",
+			sourceBranch: "refs/heads/prs/kargo-render/env/cafe\u0301",
+			normalize:    true,
+			assertions: func(t *testing.T, pr git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "refs/heads/env/caf\u00e9", *pr.TargetRefName)
+				require.Equal(t, "refs/heads/prs/kargo-render/env/caf\u00e9", *pr.SourceRefName)
+			},
+		},
+		{
+			name:         "non-Latin names",
+			targetBranch: "环境/生产",
+			sourceBranch: "prs/kargo-render/环境/生产",
+			normalize:    true,
+			assertions: func(t *testing.T, pr git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "refs/heads/环境/生产", *pr.TargetRefName)
+			},
+		},
+		{
+			name:         "invalid UTF-8",
+			targetBranch: "env/caf\xe9",
+			sourceBranch: "prs/kargo-render/env/caf\xe9",
+			assertions: func(t *testing.T, pr git.GitPullRequest, err error) {
+				require.ErrorContains(t, err, "is not valid UTF-8")
+				require.Nil(t, pr.TargetRefName)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var pr git.GitPullRequest
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn:   fakeRepos("repo"),
+				createPullRequestFn: fakeCreatePullRequest(&pr),
+			})
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				testCase.targetBranch,
+				testCase.sourceBranch,
+				gitutil.RepoCredentials{Password: "token"},
+				&OpenPROptions{NormalizeBranchNames: testCase.normalize},
+			)
+			testCase.assertions(t, pr, err)
+		})
+	}
+}
+
+func TestListRefsEscapesUnicodeFilter(t *testing.T) {
+	var rawQuery, filter string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodOptions {
+			// Advertise the location of the refs resource
+			_, _ = w.Write([]byte(`{"count":1,"value":[{` +
+				`"id":"2d874a60-a811-4f62-9c9f-963a6ea0a55b",` +
+				`"area":"git","resourceName":"refs",` +
+				`"routeTemplate":"{project}/_apis/{area}/repositories/{repositoryId}/{resource}",` +
+				`"resourceVersion":1,"minVersion":"1.0","maxVersion":"7.1","releasedVersion":"7.0"}]}`))
+			return
+		}
+		rawQuery, filter = r.URL.RawQuery, r.URL.Query().Get("filter")
+		_, _ = w.Write([]byte(`{"count":1,"value":[{"name":"refs/heads/env/café"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	connection := azuredevops.NewPatConnection(server.URL, "token")
+	refs, err := listRefs(
+		context.Background(),
+		&repoClient{
+			client:  &git.ClientImpl{Client: *azuredevops.NewClient(connection, server.URL)},
+			project: "proj",
+			id:      "repo-id",
+		},
+		"heads/env/café",
+	)
+	require.NoError(t, err)
+	require.Contains(t, rawQuery, "filter=heads%2Fenv%2Fcaf%C3%A9")
+	require.Equal(t, "heads/env/café", filter)
+	require.Len(t, refs, 1)
+	require.Equal(t, "refs/heads/env/café", *refs[0].Name)
+}
@@ -0,0 +1,71 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// parentPRRegex matches the hidden comment that records a PR's parent in its
+// description.
+var parentPRRegex = regexp.MustCompile(`<!-- kargo-render-parent-pr: (\d+) -->`)
+
+// parentPRMarker returns the hidden comment used to record the specified
+// parent PR ID in a PR description.
+func parentPRMarker(parentID int) string {
+	return fmt.Sprintf("<!-- kargo-render-parent-pr: %d -->", parentID)
+}
+
+// parseParentPRID returns the parent PR ID recorded in the specified PR
+// description and a bool indicating whether one was found.
+func parseParentPRID(description string) (int, bool) {
+	parts := parentPRRegex.FindStringSubmatch(description)
+	if parts == nil {
+		return 0, false
+	}
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// GetParentPRID returns the ID of the parent of the specified PR, as recorded
+// when the PR was opened, and a bool indicating whether the PR has a parent.
+func GetParentPRID(
+	ctx context.Context,
+	repoURL string,
+	prID int,
+	creds gitutil.RepoCredentials,
+) (_ int, _ bool, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	pr, err := repo.client.GetPullRequest(ctx, git.GetPullRequestArgs{
+		Project:       &repo.project,
+		RepositoryId:  &repo.id,
+		PullRequestId: &prID,
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("error getting pull request %d: %w", prID, err)
+	}
+	if pr == nil {
+		return 0, false, fmt.Errorf(
+			"%w: pull request %d was not returned",
+			ErrIncompleteResponse,
+			prID,
+		)
+	}
+	if pr.Description == nil {
+		return 0, false, nil
+	}
+	parentID, ok := parseParentPRID(*pr.Description)
+	return parentID, ok, nil
+}
@@ -0,0 +1,71 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestParseParentPRID(t *testing.T) {
+	testCases := []struct {
+		name        string
+		description string
+		expectFound bool
+		expectedID  int
+	}{
+		{
+			name:        "no parent",
+			description: "just a description",
+		},
+		{
+			name:        "parent recorded",
+			description: "description\n\n" + parentPRMarker(17),
+			expectFound: true,
+			expectedID:  17,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			id, ok := parseParentPRID(testCase.description)
+			require.Equal(t, testCase.expectFound, ok)
+			require.Equal(t, testCase.expectedID, id)
+		})
+	}
+}
+
+func TestParentPRRoundTrip(t *testing.T) {
+	var created git.GitPullRequest
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn:   fakeRepos("repo"),
+		createPullRequestFn: fakeCreatePullRequest(&created),
+		getPullRequestFn: func(
+			context.Context,
+			git.GetPullRequestArgs,
+		) (*git.GitPullRequest, error) {
+			return &created, nil
+		},
+	})
+	const repoURL = "https://dev.azure.com/org/proj/_git/repo"
+	creds := gitutil.RepoCredentials{Password: "pat"}
+	_, err := OpenPR(
+		context.Background(),
+		repoURL,
+		"title",
+		"description",
+		"env/dev",
+		"prs/kargo-render/env/dev",
+		creds,
+		&OpenPROptions{ParentPRID: 17},
+	)
+	require.NoError(t, err)
+	require.Contains(t, *created.Description, parentPRMarker(17))
+
+	parentID, ok, err := GetParentPRID(context.Background(), repoURL, 42, creds)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 17, parentID)
+}
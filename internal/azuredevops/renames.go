@@ -0,0 +1,91 @@
+package azuredevops
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+)
+
+// RenameFallback encapsulates settings for finding repositories that have
+// been renamed since the URLs used to reference them were configured. Azure
+// DevOps does not redirect API requests made using a repository's previous
+// name, so without a fallback, such repositories are simply not found.
+type RenameFallback struct {
+	// PreviousNames optionally maps the previous names of renamed repositories
+	// to their current names. Names are case insensitive.
+	PreviousNames map[string]string
+	// FuzzyMatch specifies whether a repository that is not found by name
+	// should be matched against the project's repositories while disregarding
+	// case and any characters other than letters and digits, e.g. to find
+	// "my-repo" by the name "My_Repo". A fuzzy match is only used if it is the
+	// only one.
+	FuzzyMatch bool
+	// OnRename, when non-nil, is called whenever a lookup finds a repository by
+	// its previous name, so that callers can warn that the reference to it
+	// should be updated. Lookups are cached, so this is not necessarily called
+	// for every operation against the repository.
+	OnRename func(project, previousName, currentName string)
+}
+
+// find returns the repository, among the specified ones, that was previously
+// named as specified, or nil if there is no such repository.
+func (r *RenameFallback) find(
+	repos []git.GitRepository,
+	project string,
+	previousName string,
+) *git.GitRepository {
+	if r == nil {
+		return nil
+	}
+	var found *git.GitRepository
+	for previous, current := range r.PreviousNames {
+		if strings.EqualFold(previous, previousName) {
+			found = findRepository(repos, func(name string) bool {
+				return strings.EqualFold(name, current)
+			})
+			break
+		}
+	}
+	if found == nil && r.FuzzyMatch {
+		key := fuzzyRepositoryName(previousName)
+		var matches []*git.GitRepository
+		for i := range repos {
+			if repos[i].Name != nil && fuzzyRepositoryName(*repos[i].Name) == key {
+				matches = append(matches, &repos[i])
+			}
+		}
+		if len(matches) == 1 {
+			found = matches[0]
+		}
+	}
+	if found != nil && r.OnRename != nil {
+		r.OnRename(project, previousName, *found.Name)
+	}
+	return found
+}
+
+// findRepository returns the first of the specified repositories whose name
+// satisfies the specified predicate, or nil if there is none.
+func findRepository(
+	repos []git.GitRepository,
+	match func(name string) bool,
+) *git.GitRepository {
+	for i := range repos {
+		if repos[i].Name != nil && match(*repos[i].Name) {
+			return &repos[i]
+		}
+	}
+	return nil
+}
+
+// fuzzyRepositoryName returns the specified repository name in lower case and
+// with any characters other than letters and digits removed.
+func fuzzyRepositoryName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
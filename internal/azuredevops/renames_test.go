@@ -0,0 +1,126 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestNewRepoClientRenameFallback(t *testing.T) {
+	ids := map[string]uuid.UUID{
+		"gitops":      uuid.New(),
+		"my-repo":     uuid.New(),
+		"other-repo":  uuid.New(),
+		"other_repo2": uuid.New(),
+		"Other.Repo2": uuid.New(),
+	}
+	type rename struct{ project, previous, current string }
+	testCases := []struct {
+		name       string
+		repo       string
+		fallback   func(renames *[]rename) *RenameFallback
+		assertions func(t *testing.T, repo *repoClient, renames []rename, err error)
+	}{
+		{
+			name: "strict by default",
+			repo: "old-gitops",
+			assertions: func(t *testing.T, _ *repoClient, _ []rename, err error) {
+				require.ErrorContains(t, err, "repository 'old-gitops' not found in project 'proj'")
+			},
+		},
+		{
+			name: "found by exact name without fallback",
+			repo: "gitops",
+			fallback: func(renames *[]rename) *RenameFallback {
+				return &RenameFallback{
+					FuzzyMatch: true,
+					OnRename: func(project, previous, current string) {
+						*renames = append(*renames, rename{project, previous, current})
+					},
+				}
+			},
+			assertions: func(t *testing.T, repo *repoClient, renames []rename, err error) {
+				require.NoError(t, err)
+				require.Equal(t, ids["gitops"].String(), repo.id)
+				require.Empty(t, renames)
+			},
+		},
+		{
+			name: "found by previous name",
+			repo: "Old-GitOps",
+			fallback: func(renames *[]rename) *RenameFallback {
+				return &RenameFallback{
+					PreviousNames: map[string]string{"old-gitops": "GITOPS"},
+					OnRename: func(project, previous, current string) {
+						*renames = append(*renames, rename{project, previous, current})
+					},
+				}
+			},
+			assertions: func(t *testing.T, repo *repoClient, renames []rename, err error) {
+				require.NoError(t, err)
+				require.Equal(t, ids["gitops"].String(), repo.id)
+				require.Equal(t, []rename{{"proj", "Old-GitOps", "gitops"}}, renames)
+			},
+		},
+		{
+			name: "found by fuzzy match",
+			repo: "My_Repo",
+			fallback: func(renames *[]rename) *RenameFallback {
+				return &RenameFallback{
+					FuzzyMatch: true,
+					OnRename: func(project, previous, current string) {
+						*renames = append(*renames, rename{project, previous, current})
+					},
+				}
+			},
+			assertions: func(t *testing.T, repo *repoClient, renames []rename, err error) {
+				require.NoError(t, err)
+				require.Equal(t, ids["my-repo"].String(), repo.id)
+				require.Equal(t, []rename{{"proj", "My_Repo", "my-repo"}}, renames)
+			},
+		},
+		{
+			name: "ambiguous fuzzy match",
+			repo: "other-repo-2",
+			fallback: func(*[]rename) *RenameFallback {
+				return &RenameFallback{FuzzyMatch: true}
+			},
+			assertions: func(t *testing.T, _ *repoClient, _ []rename, err error) {
+				require.ErrorContains(t, err, "repository 'other-repo-2' not found")
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: func(
+					context.Context,
+					git.GetRepositoriesArgs,
+				) (*[]git.GitRepository, error) {
+					repos := make([]git.GitRepository, 0, len(ids))
+					for name, id := range ids {
+						repos = append(repos, git.GitRepository{Id: &id, Name: &name})
+					}
+					return &repos, nil
+				},
+			})
+			var renames []rename
+			opts := &ConnectionOptions{}
+			if testCase.fallback != nil {
+				opts.RenameFallback = testCase.fallback(&renames)
+			}
+			repo, err := newRepoClient(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/"+testCase.repo,
+				gitutil.RepoCredentials{Password: "token"},
+				opts,
+			)
+			testCase.assertions(t, repo, renames, err)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+)
+
+// ErrInvalidRepositoryID is returned when a repository ID is specified that is
+// not a UUID.
+var ErrInvalidRepositoryID = errors.New("invalid repository ID")
+
+// parseRepositoryID parses the specified repository ID, which must be a UUID in
+// its canonical, hyphenated form, e.g. a4b6c4ed-2c6e-4a2f-9d11-3e0f1b1c2d3e.
+func parseRepositoryID(id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil || len(id) != len(uuid.Nil.String()) {
+		return uuid.Nil, fmt.Errorf("%w %q: must be a UUID", ErrInvalidRepositoryID, id)
+	}
+	return parsed, nil
+}
+
+// knownRepositoryID returns the ID of the repository with the specified name,
+// if it is known without looking the repository up, i.e. if an ID was
+// explicitly specified or if the name is itself an ID, as it is in URLs that
+// reference repositories by ID. Otherwise, it returns nil.
+func knownRepositoryID(name string, explicitID string) (*uuid.UUID, error) {
+	if explicitID != "" {
+		id, err := parseRepositoryID(explicitID)
+		if err != nil {
+			return nil, err
+		}
+		return &id, nil
+	}
+	if id, err := parseRepositoryID(name); err == nil {
+		return &id, nil
+	}
+	return nil, nil
+}
+
+// details returns the details of the repository, such as its default branch
+// and project, getting them from Azure DevOps if only the repository's ID is
+// known because it was not looked up.
+func (r *repoClient) details(ctx context.Context) (*git.GitRepository, error) {
+	if !r.idOnly {
+		return r.repository, nil
+	}
+	repo, err := r.client.GetRepository(ctx, git.GetRepositoryArgs{
+		Project:      &r.project,
+		RepositoryId: &r.id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting repository %s: %w", r.id, err)
+	}
+	if repo == nil {
+		return nil, fmt.Errorf("%w: repository %s was not returned", ErrIncompleteResponse, r.id)
+	}
+	r.repository, r.idOnly = repo, false
+	return repo, nil
+}
@@ -0,0 +1,145 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestParseRepositoryID(t *testing.T) {
+	id := uuid.New()
+	testCases := []struct {
+		name       string
+		id         string
+		assertions func(t *testing.T, parsed uuid.UUID, err error)
+	}{
+		{
+			name: "canonical",
+			id:   id.String(),
+			assertions: func(t *testing.T, parsed uuid.UUID, err error) {
+				require.NoError(t, err)
+				require.Equal(t, id, parsed)
+			},
+		},
+		{
+			name: "not a UUID",
+			id:   "repo",
+			assertions: func(t *testing.T, _ uuid.UUID, err error) {
+				require.ErrorIs(t, err, ErrInvalidRepositoryID)
+				require.ErrorContains(t, err, `"repo"`)
+			},
+		},
+		{
+			name: "not hyphenated",
+			id:   "a4b6c4ed2c6e4a2f9d113e0f1b1c2d3e",
+			assertions: func(t *testing.T, _ uuid.UUID, err error) {
+				require.ErrorIs(t, err, ErrInvalidRepositoryID)
+			},
+		},
+		{
+			name: "braced",
+			id:   "{" + id.String() + "}",
+			assertions: func(t *testing.T, _ uuid.UUID, err error) {
+				require.ErrorIs(t, err, ErrInvalidRepositoryID)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parsed, err := parseRepositoryID(testCase.id)
+			testCase.assertions(t, parsed, err)
+		})
+	}
+}
+
+func TestOpenPRRepositoryID(t *testing.T) {
+	id := uuid.New()
+	testCases := []struct {
+		name       string
+		repoURL    string
+		opts       OpenPROptions
+		assertions func(t *testing.T, created *git.GitPullRequest, repoID string, err error)
+	}{
+		{
+			name:    "ID specified",
+			repoURL: "https://dev.azure.com/org/proj/_git/repo",
+			opts:    OpenPROptions{RepositoryID: id.String()},
+			assertions: func(t *testing.T, created *git.GitPullRequest, repoID string, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, created)
+				require.Equal(t, id.String(), repoID)
+			},
+		},
+		{
+			name:    "ID in URL",
+			repoURL: "https://dev.azure.com/org/proj/_git/" + id.String(),
+			assertions: func(t *testing.T, created *git.GitPullRequest, repoID string, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, created)
+				require.Equal(t, id.String(), repoID)
+			},
+		},
+		{
+			name:    "invalid ID specified",
+			repoURL: "https://dev.azure.com/org/proj/_git/repo",
+			opts:    OpenPROptions{RepositoryID: "not-a-uuid"},
+			assertions: func(t *testing.T, created *git.GitPullRequest, _ string, err error) {
+				require.ErrorIs(t, err, ErrInvalidRepositoryID)
+				require.Nil(t, created)
+			},
+		},
+		{
+			name:    "details got by ID when needed",
+			repoURL: "https://dev.azure.com/org/proj/_git/repo",
+			opts: OpenPROptions{
+				RepositoryID:       id.String(),
+				GuardDefaultBranch: true,
+			},
+			assertions: func(t *testing.T, created *git.GitPullRequest, _ string, err error) {
+				require.ErrorIs(t, err, ErrDefaultBranchTarget)
+				require.Nil(t, created)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var created *git.GitPullRequest
+			var repoID string
+			// Repositories are never listed, so getRepositoriesFn is not set
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoryFn: func(
+					_ context.Context,
+					args git.GetRepositoryArgs,
+				) (*git.GitRepository, error) {
+					require.Equal(t, id.String(), *args.RepositoryId)
+					return &git.GitRepository{Id: &id, DefaultBranch: ptr("refs/heads/env/dev")}, nil
+				},
+				createPullRequestFn: func(
+					ctx context.Context,
+					args git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					repoID = *args.RepositoryId
+					created = args.GitPullRequestToCreate
+					return fakeCreatePullRequest(nil)(ctx, args)
+				},
+			})
+			opts := testCase.opts
+			_, err := OpenPR(
+				context.Background(),
+				testCase.repoURL,
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "token"},
+				&opts,
+			)
+			testCase.assertions(t, created, repoID, err)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// ErrBranchNotFound is returned when an operation references a branch that
+// does not exist.
+var ErrBranchNotFound = errors.New("branch not found")
+
+// RetargetPR changes the target branch of the specified PR to the specified
+// branch, for instance, because the environment-specific branch it targeted
+// was renamed. The branch name may be specified with or without a refs/heads/
+// prefix. If the new target branch does not exist, an error wrapping
+// ErrBranchNotFound is returned and the PR is left unchanged.
+func RetargetPR(
+	ctx context.Context,
+	repoURL string,
+	prID int,
+	newTarget string,
+	creds gitutil.RepoCredentials,
+) (err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return err
+	}
+	newTarget = ensureRefFormat(newTarget)
+	ref, err := getRef(ctx, repo, newTarget)
+	if err != nil {
+		return err
+	}
+	if ref == nil {
+		return fmt.Errorf("%w: %q", ErrBranchNotFound, newTarget)
+	}
+	if _, err = repo.client.UpdatePullRequest(ctx, git.UpdatePullRequestArgs{
+		Project:       &repo.project,
+		RepositoryId:  &repo.id,
+		PullRequestId: &prID,
+		GitPullRequestToUpdate: &git.GitPullRequest{
+			TargetRefName: &newTarget,
+		},
+	}); err != nil {
+		return fmt.Errorf(
+			"error retargeting pull request %d to %q: %w",
+			prID,
+			newTarget,
+			err,
+		)
+	}
+	return nil
+}
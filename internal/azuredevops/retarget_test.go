@@ -0,0 +1,83 @@
+package azuredevops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestRetargetPR(t *testing.T) {
+	testCases := []struct {
+		name       string
+		newTarget  string
+		assertions func(t *testing.T, updated *git.GitPullRequest, err error)
+	}{
+		{
+			name:      "successful retarget",
+			newTarget: "env/staging",
+			assertions: func(t *testing.T, updated *git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "refs/heads/env/staging", *updated.TargetRefName)
+			},
+		},
+		{
+			name:      "fully-qualified target",
+			newTarget: "refs/heads/env/staging",
+			assertions: func(t *testing.T, updated *git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "refs/heads/env/staging", *updated.TargetRefName)
+			},
+		},
+		{
+			name:      "missing target",
+			newTarget: "env/stage",
+			assertions: func(t *testing.T, updated *git.GitPullRequest, err error) {
+				require.ErrorIs(t, err, ErrBranchNotFound)
+				require.ErrorContains(t, err, "refs/heads/env/stage")
+				require.Nil(t, updated)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var updated *git.GitPullRequest
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getRefsFn: func(
+					_ context.Context,
+					args git.GetRefsArgs,
+				) (*git.GetRefsResponseValue, error) {
+					res := &git.GetRefsResponseValue{}
+					if strings.HasPrefix("heads/env/staging", *args.Filter) {
+						res.Value = []git.GitRef{{
+							Name:     ptr("refs/heads/env/staging"),
+							ObjectId: ptr("abc123"),
+						}}
+					}
+					return res, nil
+				},
+				updatePullRequestFn: func(
+					_ context.Context,
+					args git.UpdatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					updated = args.GitPullRequestToUpdate
+					return updated, nil
+				},
+			})
+			err := RetargetPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				42,
+				testCase.newTarget,
+				gitutil.RepoCredentials{Password: "token"},
+			)
+			testCase.assertions(t, updated, err)
+		})
+	}
+}
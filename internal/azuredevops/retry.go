@@ -0,0 +1,367 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+
+	"github.com/akuity/kargo-render/internal/transport"
+)
+
+// retryableStatusCodes are the HTTP status codes with which Azure DevOps
+// responds to requests that are likely to succeed if retried.
+var retryableStatusCodes = map[int]struct{}{
+	http.StatusTooManyRequests:    {},
+	http.StatusBadGateway:         {},
+	http.StatusServiceUnavailable: {},
+	http.StatusGatewayTimeout:     {},
+}
+
+// statusCodeOf returns the HTTP status code carried by the specified error, if
+// it is an error returned by the Azure DevOps API. The SDK returns
+// WrappedErrors both by value and by pointer, so both are handled.
+func statusCodeOf(err error) (int, bool) {
+	var wrappedPtr *azuredevops.WrappedError
+	if errors.As(err, &wrappedPtr) && wrappedPtr != nil &&
+		wrappedPtr.StatusCode != nil {
+		return *wrappedPtr.StatusCode, true
+	}
+	var wrapped azuredevops.WrappedError
+	if errors.As(err, &wrapped) && wrapped.StatusCode != nil {
+		return *wrapped.StatusCode, true
+	}
+	return 0, false
+}
+
+// isRetryable returns a bool indicating whether a failed, idempotent request
+// to Azure DevOps, including one that timed out, should be retried.
+func isRetryable(err error) bool {
+	if code, ok := statusCodeOf(err); ok {
+		_, retryable := retryableStatusCodes[code]
+		return retryable
+	}
+	return transport.IsTransient(err)
+}
+
+// isThrottled returns a bool indicating whether a request to Azure DevOps was
+// rejected due to rate limiting. Such requests were never processed, so even
+// non-idempotent ones are safe to retry.
+func isThrottled(err error) bool {
+	code, ok := statusCodeOf(err)
+	return ok && code == http.StatusTooManyRequests
+}
+
+// withRetryable returns a copy of the specified policy that uses the specified
+// function to decide which errors are retryable, unless the policy already
+// specifies one.
+func withRetryable(
+	policy *transport.Policy,
+	retryable func(error) bool,
+) *transport.Policy {
+	p := transport.Policy{}
+	if policy != nil {
+		p = *policy
+	}
+	if p.Retryable == nil {
+		p.Retryable = retryable
+	}
+	return &p
+}
+
+// throttledOnly returns a copy of the specified policy that retries only
+// errors that are throttling errors and, if the policy specifies its own
+// function for deciding which errors are retryable, that function accepts.
+func throttledOnly(policy *transport.Policy) *transport.Policy {
+	p := withRetryable(policy, isThrottled)
+	if retryable := p.Retryable; policy != nil && policy.Retryable != nil {
+		p.Retryable = func(err error) bool {
+			return isThrottled(err) && retryable(err)
+		}
+	}
+	return p
+}
+
+// read invokes the specified idempotent Azure DevOps API call, retrying it in
+// accordance with the specified policy.
+func read[T any](
+	ctx context.Context,
+	policy *transport.Policy,
+	fn func(context.Context) (T, error),
+) (T, error) {
+	return transport.Do(ctx, withRetryable(policy, isRetryable), fn)
+}
+
+// write invokes the specified non-idempotent Azure DevOps API call, retrying
+// it in accordance with the specified policy only if it was throttled, since
+// other failures, including timeouts, may have occurred after the change was
+// applied.
+func write[T any](
+	ctx context.Context,
+	policy *transport.Policy,
+	fn func(context.Context) (T, error),
+) (T, error) {
+	return transport.Do(ctx, throttledOnly(policy), fn)
+}
+
+// retryingGitClient decorates an Azure DevOps Git client so that every call
+// made through it by this package is retried in accordance with a policy and,
+// optionally, made again using a client created by rediscovering Azure DevOps'
+// APIs if it failed because the results of earlier discovery were stale.
+type retryingGitClient struct {
+	git.Client
+	policy *transport.Policy
+	// rediscover, when non-nil, creates a replacement client by rediscovering
+	// Azure DevOps' APIs.
+	rediscover func(context.Context) (git.Client, error)
+	// mu guards refreshed.
+	mu sync.RWMutex
+	// refreshed is the replacement for the embedded client, if it has been
+	// replaced.
+	refreshed git.Client
+}
+
+// withRetries returns a Git client that retries calls made through the
+// specified client in accordance with the specified policy. If the specified
+// rediscover function is non-nil, it is used to replace the client when calls
+// fail because of stale API discovery.
+func withRetries(
+	client git.Client,
+	policy *transport.Policy,
+	rediscover func(context.Context) (git.Client, error),
+) git.Client {
+	return &retryingGitClient{Client: client, policy: policy, rediscover: rediscover}
+}
+
+func (r *retryingGitClient) GetRepositories(
+	ctx context.Context,
+	args git.GetRepositoriesArgs,
+) (*[]git.GitRepository, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*[]git.GitRepository, error) {
+		return r.current().GetRepositories(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) GetRepository(
+	ctx context.Context,
+	args git.GetRepositoryArgs,
+) (*git.GitRepository, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*git.GitRepository, error) {
+		return r.current().GetRepository(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) GetPullRequests(
+	ctx context.Context,
+	args git.GetPullRequestsArgs,
+) (*[]git.GitPullRequest, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*[]git.GitPullRequest, error) {
+		return r.current().GetPullRequests(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) GetPullRequest(
+	ctx context.Context,
+	args git.GetPullRequestArgs,
+) (*git.GitPullRequest, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*git.GitPullRequest, error) {
+		return r.current().GetPullRequest(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) GetCommitDiffs(
+	ctx context.Context,
+	args git.GetCommitDiffsArgs,
+) (*git.GitCommitDiffs, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*git.GitCommitDiffs, error) {
+		return r.current().GetCommitDiffs(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) GetCommit(
+	ctx context.Context,
+	args git.GetCommitArgs,
+) (*git.GitCommit, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*git.GitCommit, error) {
+		return r.current().GetCommit(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) GetItems(
+	ctx context.Context,
+	args git.GetItemsArgs,
+) (*[]git.GitItem, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*[]git.GitItem, error) {
+		return r.current().GetItems(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) GetCommits(
+	ctx context.Context,
+	args git.GetCommitsArgs,
+) (*[]git.GitCommitRef, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*[]git.GitCommitRef, error) {
+		return r.current().GetCommits(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) GetPullRequestIterations(
+	ctx context.Context,
+	args git.GetPullRequestIterationsArgs,
+) (*[]git.GitPullRequestIteration, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*[]git.GitPullRequestIteration, error) {
+		return r.current().GetPullRequestIterations(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) GetPullRequestIterationChanges(
+	ctx context.Context,
+	args git.GetPullRequestIterationChangesArgs,
+) (*git.GitPullRequestIterationChanges, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*git.GitPullRequestIterationChanges, error) {
+		return r.current().GetPullRequestIterationChanges(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) GetRefs(
+	ctx context.Context,
+	args git.GetRefsArgs,
+) (*git.GetRefsResponseValue, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*git.GetRefsResponseValue, error) {
+		return r.current().GetRefs(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) GetPullRequestWorkItemRefs(
+	ctx context.Context,
+	args git.GetPullRequestWorkItemRefsArgs,
+) (*[]webapi.ResourceRef, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*[]webapi.ResourceRef, error) {
+		return r.current().GetPullRequestWorkItemRefs(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) GetPolicyConfigurations(
+	ctx context.Context,
+	args git.GetPolicyConfigurationsArgs,
+) (*git.GitPolicyConfigurationResponse, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*git.GitPolicyConfigurationResponse, error) {
+		return r.current().GetPolicyConfigurations(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) GetPullRequestReviewers(
+	ctx context.Context,
+	args git.GetPullRequestReviewersArgs,
+) (*[]git.IdentityRefWithVote, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*[]git.IdentityRefWithVote, error) {
+		return r.current().GetPullRequestReviewers(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) GetThreads(
+	ctx context.Context,
+	args git.GetThreadsArgs,
+) (*[]git.GitPullRequestCommentThread, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*[]git.GitPullRequestCommentThread, error) {
+		return r.current().GetThreads(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) CreatePullRequest(
+	ctx context.Context,
+	args git.CreatePullRequestArgs,
+) (*git.GitPullRequest, error) {
+	return write(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*git.GitPullRequest, error) {
+		return r.current().CreatePullRequest(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) UpdatePullRequest(
+	ctx context.Context,
+	args git.UpdatePullRequestArgs,
+) (*git.GitPullRequest, error) {
+	return write(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*git.GitPullRequest, error) {
+		return r.current().UpdatePullRequest(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) CreateThread(
+	ctx context.Context,
+	args git.CreateThreadArgs,
+) (*git.GitPullRequestCommentThread, error) {
+	return write(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*git.GitPullRequestCommentThread, error) {
+		return r.current().CreateThread(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) UpdateThread(
+	ctx context.Context,
+	args git.UpdateThreadArgs,
+) (*git.GitPullRequestCommentThread, error) {
+	return write(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*git.GitPullRequestCommentThread, error) {
+		return r.current().UpdateThread(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) CreatePullRequestLabel(
+	ctx context.Context,
+	args git.CreatePullRequestLabelArgs,
+) (*core.WebApiTagDefinition, error) {
+	return write(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*core.WebApiTagDefinition, error) {
+		return r.current().CreatePullRequestLabel(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) UpdateRefs(
+	ctx context.Context,
+	args git.UpdateRefsArgs,
+) (*[]git.GitRefUpdateResult, error) {
+	return write(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*[]git.GitRefUpdateResult, error) {
+		return r.current().UpdateRefs(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) CreateAnnotatedTag(
+	ctx context.Context,
+	args git.CreateAnnotatedTagArgs,
+) (*git.GitAnnotatedTag, error) {
+	return write(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*git.GitAnnotatedTag, error) {
+		return r.current().CreateAnnotatedTag(ctx, args)
+	}))
+}
+
+// UpdatePullRequestProperties adds or replaces properties, so it is
+// idempotent.
+func (r *retryingGitClient) UpdatePullRequestProperties(
+	ctx context.Context,
+	args git.UpdatePullRequestPropertiesArgs,
+) (interface{}, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (interface{}, error) {
+		return r.current().UpdatePullRequestProperties(ctx, args)
+	}))
+}
+
+func (r *retryingGitClient) CreateCommitStatus(
+	ctx context.Context,
+	args git.CreateCommitStatusArgs,
+) (*git.GitStatus, error) {
+	return write(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*git.GitStatus, error) {
+		return r.current().CreateCommitStatus(ctx, args)
+	}))
+}
+
+// CreatePullRequestReviewer adds or updates a reviewer, so it is idempotent.
+func (r *retryingGitClient) CreatePullRequestReviewer(
+	ctx context.Context,
+	args git.CreatePullRequestReviewerArgs,
+) (*git.IdentityRefWithVote, error) {
+	return read(ctx, r.policy, rediscovering(r, func(ctx context.Context) (*git.IdentityRefWithVote, error) {
+		return r.current().CreatePullRequestReviewer(ctx, args)
+	}))
+}
@@ -0,0 +1,151 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akuity/kargo-render/internal/transport"
+)
+
+func TestIsRetryable(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		retryable bool
+		throttled bool
+	}{
+		{
+			name:      "throttled by value",
+			err:       azuredevops.WrappedError{StatusCode: ptr(http.StatusTooManyRequests)},
+			retryable: true,
+			throttled: true,
+		},
+		{
+			name:      "throttled by pointer",
+			err:       &azuredevops.WrappedError{StatusCode: ptr(http.StatusTooManyRequests)},
+			retryable: true,
+			throttled: true,
+		},
+		{
+			name:      "service unavailable",
+			err:       &azuredevops.WrappedError{StatusCode: ptr(http.StatusServiceUnavailable)},
+			retryable: true,
+		},
+		{
+			name: "not found",
+			err:  &azuredevops.WrappedError{StatusCode: ptr(http.StatusNotFound)},
+		},
+		{
+			name: "other error",
+			err:  errors.New("something went wrong"),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.retryable, isRetryable(testCase.err))
+			require.Equal(t, testCase.throttled, isThrottled(testCase.err))
+		})
+	}
+}
+
+func TestRetryingGitClient(t *testing.T) {
+	unavailable := &azuredevops.WrappedError{StatusCode: ptr(http.StatusServiceUnavailable)}
+	policy := &transport.Policy{InitialBackoff: time.Nanosecond}
+
+	t.Run("reads are retried", func(t *testing.T) {
+		var calls int
+		client := withRetries(&fakeGitClient{
+			getPullRequestFn: func(context.Context, git.GetPullRequestArgs) (*git.GitPullRequest, error) {
+				if calls++; calls == 1 {
+					return nil, unavailable
+				}
+				return &git.GitPullRequest{PullRequestId: ptr(42)}, nil
+			},
+		}, policy, nil)
+		pr, err := client.GetPullRequest(context.Background(), git.GetPullRequestArgs{})
+		require.NoError(t, err)
+		require.Equal(t, 42, *pr.PullRequestId)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("writes are not retried unless throttled", func(t *testing.T) {
+		var calls int
+		client := withRetries(&fakeGitClient{
+			createPullRequestFn: func(context.Context, git.CreatePullRequestArgs) (*git.GitPullRequest, error) {
+				calls++
+				return nil, unavailable
+			},
+		}, policy, nil)
+		_, err := client.CreatePullRequest(context.Background(), git.CreatePullRequestArgs{})
+		require.ErrorIs(t, err, unavailable)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("writes that time out are not retried", func(t *testing.T) {
+		var calls int
+		client := withRetries(&fakeGitClient{
+			createPullRequestFn: func(ctx context.Context, _ git.CreatePullRequestArgs) (*git.GitPullRequest, error) {
+				calls++
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		}, &transport.Policy{InitialBackoff: time.Nanosecond, Timeout: time.Millisecond}, nil)
+		_, err := client.CreatePullRequest(context.Background(), git.CreatePullRequestArgs{})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("reads that time out are retried", func(t *testing.T) {
+		var calls int
+		client := withRetries(&fakeGitClient{
+			getPullRequestFn: func(ctx context.Context, _ git.GetPullRequestArgs) (*git.GitPullRequest, error) {
+				if calls++; calls == 1 {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}
+				return &git.GitPullRequest{PullRequestId: ptr(42)}, nil
+			},
+		}, &transport.Policy{InitialBackoff: time.Nanosecond, Timeout: time.Millisecond}, nil)
+		_, err := client.GetPullRequest(context.Background(), git.GetPullRequestArgs{})
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("custom classifier does not widen write retries", func(t *testing.T) {
+		var calls int
+		client := withRetries(&fakeGitClient{
+			createPullRequestFn: func(context.Context, git.CreatePullRequestArgs) (*git.GitPullRequest, error) {
+				calls++
+				return nil, unavailable
+			},
+		}, &transport.Policy{
+			InitialBackoff: time.Nanosecond,
+			Retryable:      func(error) bool { return true },
+		}, nil)
+		_, err := client.CreatePullRequest(context.Background(), git.CreatePullRequestArgs{})
+		require.ErrorIs(t, err, unavailable)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("throttled writes are retried", func(t *testing.T) {
+		var calls int
+		client := withRetries(&fakeGitClient{
+			createPullRequestFn: func(context.Context, git.CreatePullRequestArgs) (*git.GitPullRequest, error) {
+				if calls++; calls == 1 {
+					return nil, &azuredevops.WrappedError{StatusCode: ptr(http.StatusTooManyRequests)}
+				}
+				return &git.GitPullRequest{PullRequestId: ptr(42)}, nil
+			},
+		}, policy, nil)
+		_, err := client.CreatePullRequest(context.Background(), git.CreatePullRequestArgs{})
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,346 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/identity"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// Vote is a reviewer's vote on a PR.
+type Vote int
+
+const (
+	VoteApproved                Vote = 10
+	VoteApprovedWithSuggestions Vote = 5
+	VoteNone                    Vote = 0
+	VoteWaitingForAuthor        Vote = -5
+	VoteRejected                Vote = -10
+)
+
+// ErrReviewerVotesNotAllowed is returned when a PR is to be opened with a
+// reviewer vote already cast, but OpenPROptions.AllowReviewerVotes is false.
+var ErrReviewerVotesNotAllowed = errors.New("reviewer votes are not allowed")
+
+// Reviewer specifies a reviewer to request for a PR when it is opened.
+type Reviewer struct {
+	// ID is the ID of the reviewer's identity. When this is empty, the identity
+	// associated with the credentials used to open the PR is the reviewer.
+	ID string
+	// Required specifies whether the reviewer's approval is required for the PR
+	// to be completed.
+	Required bool
+	// Vote optionally specifies a vote the reviewer casts on the PR when it is
+	// opened. Azure DevOps only honors votes cast by the identity associated
+	// with the credentials used to open the PR, so this is chiefly useful for
+	// having that identity approve its own PRs, for instance, to low-risk
+	// environments. Because self-approval bypasses human review, any non-zero
+	// vote requires OpenPROptions.AllowReviewerVotes to be true.
+	Vote Vote
+}
+
+// ensureReviewerVotesAllowed returns an error wrapping
+// ErrReviewerVotesNotAllowed if any of the specified reviewers casts a vote
+// but votes are not allowed.
+func ensureReviewerVotesAllowed(reviewers []Reviewer, allowed bool) error {
+	if allowed {
+		return nil
+	}
+	for _, reviewer := range reviewers {
+		if reviewer.Vote != VoteNone {
+			return fmt.Errorf(
+				"%w: reviewer %q would vote %d; set AllowReviewerVotes to permit this",
+				ErrReviewerVotesNotAllowed,
+				reviewer.ID,
+				reviewer.Vote,
+			)
+		}
+	}
+	return nil
+}
+
+// toReviewers converts the specified reviewers to the form Azure DevOps
+// expects when creating a PR. Reviewers without an ID are resolved to the
+// identity associated with the repository's connection.
+func toReviewers(
+	ctx context.Context,
+	repo *repoClient,
+	reviewers []Reviewer,
+) (*[]git.IdentityRefWithVote, error) {
+	if len(reviewers) == 0 {
+		return nil, nil
+	}
+	refs := make([]git.IdentityRefWithVote, len(reviewers))
+	for i, reviewer := range reviewers {
+		id := reviewer.ID
+		if id == "" {
+			var err error
+			if id, err = getAuthenticatedIdentityID(ctx, repo.connection, repo.cacheTTL); err != nil {
+				return nil, fmt.Errorf("error resolving identity of reviewer: %w", err)
+			}
+		}
+		vote := int(reviewer.Vote)
+		refs[i] = git.IdentityRefWithVote{
+			Id:         &id,
+			IsRequired: &reviewers[i].Required,
+			Vote:       &vote,
+		}
+	}
+	return &refs, nil
+}
+
+// AddReviewers adds those of the specified reviewers that are not already
+// reviewers of the specified PR to it, leaving existing reviewers, and their
+// votes, untouched. This makes it safe to call repeatedly, e.g. whenever an
+// existing PR is updated. Reviewers may not cast votes. The IDs of the
+// reviewers that were added are returned.
+func AddReviewers(
+	ctx context.Context,
+	repoURL string,
+	prID int,
+	reviewers []Reviewer,
+	creds gitutil.RepoCredentials,
+) (_ []string, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	if err = ensureReviewerVotesAllowed(reviewers, false); err != nil {
+		return nil, err
+	}
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return nil, err
+	}
+	return addMissingReviewers(ctx, repo, prID, reviewers)
+}
+
+// addMissingReviewers adds those of the specified reviewers that are not
+// already reviewers of the specified PR to it and returns their IDs. If an
+// error is encountered, the IDs of the reviewers added until then are
+// returned along with it.
+func addMissingReviewers(
+	ctx context.Context,
+	repo *repoClient,
+	prID int,
+	reviewers []Reviewer,
+) ([]string, error) {
+	refs, err := toReviewers(ctx, repo, reviewers)
+	if err != nil || refs == nil {
+		return nil, err
+	}
+	existing, err := repo.client.GetPullRequestReviewers(
+		ctx,
+		git.GetPullRequestReviewersArgs{
+			Project:       &repo.project,
+			RepositoryId:  &repo.id,
+			PullRequestId: &prID,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error listing reviewers of pull request %d: %w", prID, err)
+	}
+	present := map[string]struct{}{}
+	if existing != nil {
+		for _, reviewer := range *existing {
+			if reviewer.Id != nil {
+				present[strings.ToLower(*reviewer.Id)] = struct{}{}
+			}
+		}
+	}
+	var added []string
+	for _, ref := range *refs {
+		if _, ok := present[strings.ToLower(*ref.Id)]; ok {
+			continue
+		}
+		if _, err = repo.client.CreatePullRequestReviewer(
+			ctx,
+			git.CreatePullRequestReviewerArgs{
+				Project:       &repo.project,
+				RepositoryId:  &repo.id,
+				PullRequestId: &prID,
+				ReviewerId:    ref.Id,
+				Reviewer:      &ref,
+			},
+		); err != nil {
+			return added, fmt.Errorf(
+				"error adding reviewer %s to pull request %d: %w",
+				*ref.Id,
+				prID,
+				err,
+			)
+		}
+		present[strings.ToLower(*ref.Id)] = struct{}{}
+		added = append(added, *ref.Id)
+	}
+	return added, nil
+}
+
+// addRequestedReviewers adds any of the reviewers requested by the specified
+// options that are not already reviewers of the specified existing PR to it.
+func addRequestedReviewers(
+	ctx context.Context,
+	repo *repoClient,
+	pr *git.GitPullRequest,
+	opts *OpenPROptions,
+) error {
+	if len(opts.Reviewers) == 0 && len(opts.ReviewerNames) == 0 {
+		return nil
+	}
+	if pr.PullRequestId == nil {
+		return fmt.Errorf("%w: pull request has no ID", ErrIncompleteResponse)
+	}
+	requested, err := withNamedReviewers(ctx, repo, opts)
+	if err != nil {
+		return err
+	}
+	_, err = addMissingReviewers(ctx, repo, *pr.PullRequestId, requested)
+	return err
+}
+
+// newIdentityClient creates an Azure DevOps Identity client. It is a
+// package-level variable so that it can be overridden in tests.
+var newIdentityClient = identity.NewClient
+
+// ResolvedReviewer is a prospective reviewer whose identity was resolved.
+type ResolvedReviewer struct {
+	// Input is the name or ID the identity was resolved from.
+	Input string
+	// ID is the ID of the resolved identity.
+	ID string
+	// DisplayName is the display name of the resolved identity.
+	DisplayName string
+	// IsGroup indicates whether the resolved identity is a group, such as a
+	// team, rather than an individual.
+	IsGroup bool
+}
+
+// ResolveReviewers resolves the identities of the specified prospective
+// reviewers of PRs in the specified repository, so that they can be
+// validated before any PR is opened. Each reviewer may be specified by identity
+// ID or by anything Azure DevOps can search identities by, such as an account
+// name, email address, or display name. Reviewers that match no identity, or
+// that match more than one, are not resolved and are instead returned, as they
+// were specified, in the second return value. Blank and duplicate reviewers are
+// ignored.
+func ResolveReviewers(
+	ctx context.Context,
+	repoURL string,
+	reviewers []string,
+	creds gitutil.RepoCredentials,
+) (_ []ResolvedReviewer, _ []string, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resolveReviewers(ctx, repo, reviewers)
+}
+
+// resolveReviewers resolves the identities of the specified prospective
+// reviewers using the specified repository's connection.
+func resolveReviewers(
+	ctx context.Context,
+	repo *repoClient,
+	reviewers []string,
+) ([]ResolvedReviewer, []string, error) {
+	if len(reviewers) == 0 {
+		return nil, nil, nil
+	}
+	client, err := newIdentityClient(ctx, repo.connection)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating identity client: %w", err)
+	}
+	var resolved []ResolvedReviewer
+	var unresolved []string
+	seen := map[string]struct{}{}
+	for _, input := range reviewers {
+		input = strings.TrimSpace(input)
+		key := strings.ToLower(input)
+		if _, ok := seen[key]; ok || input == "" {
+			continue
+		}
+		seen[key] = struct{}{}
+		searchFilter := "General"
+		args := identity.ReadIdentitiesArgs{
+			SearchFilter: &searchFilter,
+			FilterValue:  &input,
+		}
+		if uuid.Validate(input) == nil {
+			args = identity.ReadIdentitiesArgs{IdentityIds: &input}
+		}
+		matches, err := client.ReadIdentities(ctx, args)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error resolving reviewer %q: %w", input, err)
+		}
+		match, ok := soleIdentity(matches)
+		if !ok {
+			unresolved = append(unresolved, input)
+			continue
+		}
+		res := ResolvedReviewer{
+			Input:   input,
+			ID:      match.Id.String(),
+			IsGroup: match.IsContainer != nil && *match.IsContainer,
+		}
+		if match.ProviderDisplayName != nil {
+			res.DisplayName = *match.ProviderDisplayName
+		}
+		if match.CustomDisplayName != nil && *match.CustomDisplayName != "" {
+			res.DisplayName = *match.CustomDisplayName
+		}
+		resolved = append(resolved, res)
+	}
+	return resolved, unresolved, nil
+}
+
+// soleIdentity returns the only identity, among the specified ones, that has an
+// ID. It returns false if there is not exactly one such identity. Azure DevOps
+// reports unknown identity IDs as null entries, so these are disregarded.
+func soleIdentity(identities *[]identity.Identity) (identity.Identity, bool) {
+	var sole identity.Identity
+	var count int
+	if identities != nil {
+		for _, ident := range *identities {
+			if ident.Id != nil {
+				sole = ident
+				count++
+			}
+		}
+	}
+	return sole, count == 1
+}
+
+// withNamedReviewers returns the reviewers specified by the specified settings,
+// including those specified by name, once resolved. Each reviewer specified by
+// name that could not be resolved is reported using
+// opts.OnUnresolvedReviewers, if non-nil, and is otherwise ignored.
+func withNamedReviewers(
+	ctx context.Context,
+	repo *repoClient,
+	opts *OpenPROptions,
+) ([]Reviewer, error) {
+	if len(opts.ReviewerNames) == 0 {
+		return opts.Reviewers, nil
+	}
+	resolved, unresolved, err := resolveReviewers(ctx, repo, opts.ReviewerNames)
+	if err != nil {
+		return nil, err
+	}
+	if len(unresolved) > 0 && opts.OnUnresolvedReviewers != nil {
+		opts.OnUnresolvedReviewers(unresolved)
+	}
+	reviewers := slices.Clip(opts.Reviewers)
+	for _, res := range resolved {
+		if !slices.ContainsFunc(reviewers, func(r Reviewer) bool {
+			return strings.EqualFold(r.ID, res.ID)
+		}) {
+			reviewers = append(reviewers, Reviewer{ID: res.ID})
+		}
+	}
+	return reviewers, nil
+}
@@ -0,0 +1,314 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/identity"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestOpenPRReviewers(t *testing.T) {
+	tokenIdentity := uuid.New()
+	testCases := []struct {
+		name       string
+		opts       OpenPROptions
+		assertions func(t *testing.T, created *git.GitPullRequest, err error)
+	}{
+		{
+			name: "required reviewer without vote",
+			opts: OpenPROptions{
+				Reviewers: []Reviewer{{ID: "team-id", Required: true}},
+			},
+			assertions: func(t *testing.T, created *git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.Equal(
+					t,
+					[]git.IdentityRefWithVote{{
+						Id:         ptr("team-id"),
+						IsRequired: ptr(true),
+						Vote:       ptr(0),
+					}},
+					*created.Reviewers,
+				)
+			},
+		},
+		{
+			name: "service identity approves",
+			opts: OpenPROptions{
+				Reviewers: []Reviewer{
+					{ID: "team-id", Required: true},
+					{Vote: VoteApproved},
+				},
+				AllowReviewerVotes: true,
+			},
+			assertions: func(t *testing.T, created *git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.Len(t, *created.Reviewers, 2)
+				self := (*created.Reviewers)[1]
+				require.Equal(t, tokenIdentity.String(), *self.Id)
+				require.False(t, *self.IsRequired)
+				require.Equal(t, 10, *self.Vote)
+			},
+		},
+		{
+			name: "vote without explicit permission",
+			opts: OpenPROptions{
+				Reviewers: []Reviewer{{Vote: VoteApproved}},
+			},
+			assertions: func(t *testing.T, created *git.GitPullRequest, err error) {
+				require.ErrorIs(t, err, ErrReviewerVotesNotAllowed)
+				require.Nil(t, created.Title)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var created git.GitPullRequest
+			useFakeIdentity(t, tokenIdentity)
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn:   fakeRepos("repo"),
+				createPullRequestFn: fakeCreatePullRequest(&created),
+			})
+			opts := testCase.opts
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				&opts,
+			)
+			testCase.assertions(t, &created, err)
+		})
+	}
+}
+
+// fakeIdentityClient is a fake identity.Client. Only ReadIdentities is
+// implemented; calling any other method panics.
+type fakeIdentityClient struct {
+	identity.Client
+	readIdentitiesFn func(context.Context, identity.ReadIdentitiesArgs) (*[]identity.Identity, error)
+}
+
+func (f *fakeIdentityClient) ReadIdentities(
+	ctx context.Context,
+	args identity.ReadIdentitiesArgs,
+) (*[]identity.Identity, error) {
+	return f.readIdentitiesFn(ctx, args)
+}
+
+// useFakeIdentities overrides newIdentityClient, for the duration of the test,
+// with a fake whose identities are those in the specified directory, keyed by
+// the names and IDs they can be found by.
+func useFakeIdentities(t *testing.T, directory map[string][]identity.Identity) {
+	orig := newIdentityClient
+	newIdentityClient = func(context.Context, *azuredevops.Connection) (identity.Client, error) {
+		return &fakeIdentityClient{
+			readIdentitiesFn: func(
+				_ context.Context,
+				args identity.ReadIdentitiesArgs,
+			) (*[]identity.Identity, error) {
+				key := args.IdentityIds
+				if key == nil {
+					require.Equal(t, "General", *args.SearchFilter)
+					key = args.FilterValue
+				}
+				matches, ok := directory[*key]
+				if !ok {
+					// Unknown IDs, unlike unmatched searches, yield null entries
+					if args.IdentityIds != nil {
+						return &[]identity.Identity{{}}, nil
+					}
+					return &[]identity.Identity{}, nil
+				}
+				return &matches, nil
+			},
+		}, nil
+	}
+	t.Cleanup(func() { newIdentityClient = orig })
+}
+
+func TestResolveReviewers(t *testing.T) {
+	alice, platform, bob1, bob2 := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	unknown := uuid.NewString()
+	useFakeIdentities(t, map[string][]identity.Identity{
+		"alice@example.com": {{
+			Id:                  &alice,
+			ProviderDisplayName: ptr("Alice"),
+		}},
+		platform.String(): {{
+			Id:                  &platform,
+			ProviderDisplayName: ptr("[proj]\\Platform"),
+			CustomDisplayName:   ptr("Platform Team"),
+			IsContainer:         ptr(true),
+		}},
+		"Bob": {{Id: &bob1}, {Id: &bob2}},
+	})
+	useFakeGitClient(t, &fakeGitClient{getRepositoriesFn: fakeRepos("repo")})
+	resolved, unresolved, err := ResolveReviewers(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		[]string{
+			"alice@example.com",
+			"nobody@example.com",
+			" " + platform.String(),
+			"Bob",
+			"",
+			unknown,
+			"ALICE@example.com",
+		},
+		gitutil.RepoCredentials{Password: "pat"},
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		[]ResolvedReviewer{
+			{Input: "alice@example.com", ID: alice.String(), DisplayName: "Alice"},
+			{
+				Input:       platform.String(),
+				ID:          platform.String(),
+				DisplayName: "Platform Team",
+				IsGroup:     true,
+			},
+		},
+		resolved,
+	)
+	require.Equal(t, []string{"nobody@example.com", "Bob", unknown}, unresolved)
+}
+
+func TestOpenPRReviewerNames(t *testing.T) {
+	alice := uuid.New()
+	useFakeIdentities(t, map[string][]identity.Identity{
+		"alice@example.com": {{Id: &alice}},
+	})
+	var created git.GitPullRequest
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn:   fakeRepos("repo"),
+		createPullRequestFn: fakeCreatePullRequest(&created),
+	})
+	var warned []string
+	_, err := OpenPR(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"title",
+		"description",
+		"env/dev",
+		"prs/kargo-render/env/dev",
+		gitutil.RepoCredentials{Password: "pat"},
+		&OpenPROptions{
+			Reviewers:     []Reviewer{{ID: alice.String(), Required: true}},
+			ReviewerNames: []string{"alice@example.com", "nobody@example.com"},
+			OnUnresolvedReviewers: func(unresolved []string) {
+				warned = unresolved
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"nobody@example.com"}, warned)
+	// Alice was already requested as a required reviewer
+	require.Equal(
+		t,
+		[]git.IdentityRefWithVote{{
+			Id:         ptr(alice.String()),
+			IsRequired: ptr(true),
+			Vote:       ptr(0),
+		}},
+		*created.Reviewers,
+	)
+}
+
+func TestAddReviewers(t *testing.T) {
+	testCases := []struct {
+		name       string
+		existing   []git.IdentityRefWithVote
+		reviewers  []Reviewer
+		assertions func(t *testing.T, added []string, created []git.IdentityRefWithVote, err error)
+	}{
+		{
+			name: "missing reviewers are added",
+			existing: []git.IdentityRefWithVote{
+				{Id: ptr("ALICE-ID"), Vote: ptr(10)},
+			},
+			reviewers: []Reviewer{
+				{ID: "alice-id"},
+				{ID: "team-id", Required: true},
+				{ID: "team-id", Required: true},
+			},
+			assertions: func(t *testing.T, added []string, created []git.IdentityRefWithVote, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []string{"team-id"}, added)
+				require.Equal(
+					t,
+					[]git.IdentityRefWithVote{{
+						Id:         ptr("team-id"),
+						IsRequired: ptr(true),
+						Vote:       ptr(0),
+					}},
+					created,
+				)
+			},
+		},
+		{
+			name: "all reviewers already present",
+			existing: []git.IdentityRefWithVote{
+				{Id: ptr("alice-id")},
+				{Id: ptr("team-id")},
+			},
+			reviewers: []Reviewer{{ID: "team-id"}, {ID: "alice-id"}},
+			assertions: func(t *testing.T, added []string, created []git.IdentityRefWithVote, err error) {
+				require.NoError(t, err)
+				require.Empty(t, added)
+				require.Empty(t, created)
+			},
+		},
+		{
+			name:      "votes are not allowed",
+			reviewers: []Reviewer{{ID: "alice-id", Vote: VoteApproved}},
+			assertions: func(t *testing.T, added []string, created []git.IdentityRefWithVote, err error) {
+				require.ErrorIs(t, err, ErrReviewerVotesNotAllowed)
+				require.Empty(t, added)
+				require.Empty(t, created)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var created []git.IdentityRefWithVote
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPullRequestReviewersFn: func(
+					_ context.Context,
+					args git.GetPullRequestReviewersArgs,
+				) (*[]git.IdentityRefWithVote, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					return &testCase.existing, nil
+				},
+				createPullRequestReviewerFn: func(
+					_ context.Context,
+					args git.CreatePullRequestReviewerArgs,
+				) (*git.IdentityRefWithVote, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					require.Equal(t, *args.Reviewer.Id, *args.ReviewerId)
+					created = append(created, *args.Reviewer)
+					return args.Reviewer, nil
+				},
+			})
+			added, err := AddReviewers(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				42,
+				testCase.reviewers,
+				gitutil.RepoCredentials{Password: "token"},
+			)
+			testCase.assertions(t, added, created, err)
+		})
+	}
+}
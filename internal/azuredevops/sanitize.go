@@ -0,0 +1,62 @@
+package azuredevops
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// sanitizeTitle returns the specified PR title with all control characters
+// either removed or, if escape is true, escaped. Line breaks and tabs, which
+// are not meaningful in a title, are replaced with spaces instead.
+func sanitizeTitle(title string, escape bool) string {
+	return sanitize(title, escape, func(r rune) (string, bool) {
+		switch r {
+		case '\r':
+			return "", true
+		case '\n', '\t':
+			return " ", true
+		}
+		return "", false
+	})
+}
+
+// sanitizeDescription returns the specified PR description with all control
+// characters either removed or, if escape is true, escaped. Newlines and tabs
+// are preserved and carriage returns preceding newlines are removed.
+func sanitizeDescription(description string, escape bool) string {
+	description = strings.ReplaceAll(description, "\r\n", "\n")
+	return sanitize(description, escape, func(r rune) (string, bool) {
+		switch r {
+		case '\n', '\t':
+			return string(r), true
+		}
+		return "", false
+	})
+}
+
+// sanitize returns the specified string with each control character replaced
+// as specified by the special function or, if that does not handle it, either
+// removed or, if escape is true, escaped as \uXXXX.
+func sanitize(
+	s string,
+	escape bool,
+	special func(rune) (string, bool),
+) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for _, r := range s {
+		if !unicode.IsControl(r) {
+			sb.WriteRune(r)
+			continue
+		}
+		if replacement, ok := special(r); ok {
+			sb.WriteString(replacement)
+			continue
+		}
+		if escape {
+			fmt.Fprintf(&sb, "\\u%04x", r)
+		}
+	}
+	return sb.String()
+}
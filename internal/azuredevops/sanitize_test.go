@@ -0,0 +1,102 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestSanitizeTitle(t *testing.T) {
+	testCases := []struct {
+		name     string
+		title    string
+		escape   bool
+		expected string
+	}{
+		{
+			name:     "nothing to sanitize",
+			title:    "env/prod <-- Promote v1.2.3 ✨",
+			expected: "env/prod <-- Promote v1.2.3 ✨",
+		},
+		{
+			name:     "control characters removed",
+			title:    "Promote\x00 v1.2.3\x1b[0m\tnow\r\nplease",
+			expected: "Promote v1.2.3[0m now please",
+		},
+		{
+			name:     "control characters escaped",
+			title:    "Promote\x00 v1.2.3\x7f",
+			escape:   true,
+			expected: `Promote\u0000 v1.2.3\u007f`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(
+				t,
+				testCase.expected,
+				sanitizeTitle(testCase.title, testCase.escape),
+			)
+		})
+	}
+}
+
+func TestSanitizeDescription(t *testing.T) {
+	testCases := []struct {
+		name        string
+		description string
+		escape      bool
+		expected    string
+	}{
+		{
+			name:        "newlines and tabs preserved",
+			description: "line one\r\n\tline two\n",
+			expected:    "line one\n\tline two\n",
+		},
+		{
+			name:        "control characters removed",
+			description: "line\x00 one\x08\nline\x1b two",
+			expected:    "line one\nline two",
+		},
+		{
+			name:        "control characters escaped",
+			description: "line\x00 one\nline two\u0085",
+			escape:      true,
+			expected:    "line\\u0000 one\nline two\\u0085",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(
+				t,
+				testCase.expected,
+				sanitizeDescription(testCase.description, testCase.escape),
+			)
+		})
+	}
+}
+
+func TestOpenPRSanitizesControlCharacters(t *testing.T) {
+	var pr git.GitPullRequest
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn:   fakeRepos("repo"),
+		createPullRequestFn: fakeCreatePullRequest(&pr),
+	})
+	_, err := OpenPR(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"env/dev <-- \x1b[1mupdate\x1b[0m",
+		"Rendered\x00 manifests:\n- configmap\x07\n",
+		"env/dev",
+		"prs/kargo-render/env/dev",
+		gitutil.RepoCredentials{Password: "pat"},
+		&OpenPROptions{Footer: "footer\x0b", OmitVersion: true},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "env/dev <-- [1mupdate[0m", *pr.Title)
+	require.Equal(t, "Rendered manifests:\n- configmap\n\n\nfooter", *pr.Description)
+}
@@ -0,0 +1,110 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// kargoRenderMarkerPrefix prefixes every hidden comment Kargo Render embeds in
+// the descriptions of the PRs it opens, identifying them as automated.
+const kargoRenderMarkerPrefix = "<!-- kargo-render-"
+
+// prsPageSize is the number of PRs requested per page when listing PRs.
+const prsPageSize = 100
+
+// AbandonStalePRs abandons all active PRs in the specified repository that
+// were opened by Kargo Render and that were created more than the specified
+// duration ago. When the specified marker is non-empty, only PRs whose
+// descriptions contain it, i.e. those opened with the same
+// OpenPROptions.Marker, are considered to have been opened by Kargo Render.
+// Otherwise, any PR bearing the hidden comments Kargo Render embeds in
+// descriptions is. Other PRs, such as those opened by people, are never
+// abandoned. The abandoned PRs are returned, oldest first. If an error is
+// encountered, the PRs abandoned until then are returned along with it.
+func AbandonStalePRs(
+	ctx context.Context,
+	repoURL string,
+	olderThan time.Duration,
+	marker string,
+	creds gitutil.RepoCredentials,
+) (_ []git.GitPullRequest, err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return nil, err
+	}
+	prs, err := listAllActivePRs(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if marker == "" {
+		marker = kargoRenderMarkerPrefix
+	}
+	stale := stalePRs(prs, marker, now().Add(-olderThan))
+	sortOldestFirst(stale)
+	for i, pr := range stale {
+		if err = abandonPR(ctx, repo, pr); err != nil {
+			return stale[:i], err
+		}
+	}
+	return stale, nil
+}
+
+// stalePRs returns those of the specified PRs whose descriptions contain the
+// specified marker and that were created before the specified time. PRs
+// without a creation date are never considered stale.
+func stalePRs(
+	prs []git.GitPullRequest,
+	marker string,
+	cutoff time.Time,
+) []git.GitPullRequest {
+	var stale []git.GitPullRequest
+	for _, pr := range prs {
+		if !hasMarkers(pr, marker) {
+			continue
+		}
+		if pr.CreationDate == nil || !pr.CreationDate.Time.Before(cutoff) {
+			continue
+		}
+		stale = append(stale, pr)
+	}
+	return stale
+}
+
+// listAllActivePRs returns all active PRs in the specified repository,
+// regardless of their source and target branches, following pages until all
+// have been retrieved.
+func listAllActivePRs(
+	ctx context.Context,
+	repo *repoClient,
+) ([]git.GitPullRequest, error) {
+	var prs []git.GitPullRequest
+	top, skip := prsPageSize, 0
+	for {
+		page, err := repo.client.GetPullRequests(ctx, git.GetPullRequestsArgs{
+			Project:      &repo.project,
+			RepositoryId: &repo.id,
+			SearchCriteria: &git.GitPullRequestSearchCriteria{
+				Status: &git.PullRequestStatusValues.Active,
+			},
+			Top:  &top,
+			Skip: &skip,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing pull requests: %w", err)
+		}
+		if page == nil {
+			return prs, nil
+		}
+		prs = append(prs, *page...)
+		if len(*page) < top {
+			return prs, nil
+		}
+		skip += len(*page)
+	}
+}
@@ -0,0 +1,141 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestAbandonStalePRs(t *testing.T) {
+	useFakeClock(t)
+	daysAgo := func(days int) *azuredevops.Time {
+		return &azuredevops.Time{Time: now().Add(-time.Duration(days) * 24 * time.Hour)}
+	}
+	automated := ptr("Rendered manifests.\n<!-- kargo-render-version: v1.0.0 -->")
+	prs := []git.GitPullRequest{
+		{PullRequestId: ptr(1), Description: automated, CreationDate: daysAgo(10)},
+		{PullRequestId: ptr(2), Description: automated, CreationDate: daysAgo(2)},
+		{PullRequestId: ptr(3), Description: ptr("Manual change"), CreationDate: daysAgo(30)},
+		{PullRequestId: ptr(4), Description: automated},
+		{PullRequestId: ptr(5), CreationDate: daysAgo(30)},
+		{PullRequestId: ptr(6), Description: automated, CreationDate: daysAgo(20)},
+		{
+			PullRequestId: ptr(7),
+			Description:   ptr(*automated + "\n<!-- kargo-render: team-a -->"),
+			CreationDate:  daysAgo(40),
+		},
+	}
+	testCases := []struct {
+		name       string
+		marker     string
+		abandonErr error
+		assertions func(t *testing.T, abandoned []git.GitPullRequest, updated []int, err error)
+	}{
+		{
+			name: "old automated PRs are abandoned",
+			assertions: func(t *testing.T, abandoned []git.GitPullRequest, updated []int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []int{7, 6, 1}, updated)
+				require.Len(t, abandoned, 3)
+				require.Equal(t, 7, *abandoned[0].PullRequestId)
+				require.Equal(t, 6, *abandoned[1].PullRequestId)
+				require.Equal(t, 1, *abandoned[2].PullRequestId)
+			},
+		},
+		{
+			name:   "only old PRs with configured marker are abandoned",
+			marker: "<!-- kargo-render: team-a -->",
+			assertions: func(t *testing.T, abandoned []git.GitPullRequest, updated []int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []int{7}, updated)
+				require.Len(t, abandoned, 1)
+			},
+		},
+		{
+			name:       "error abandoning PR",
+			abandonErr: errors.New("something went wrong"),
+			assertions: func(t *testing.T, abandoned []git.GitPullRequest, updated []int, err error) {
+				require.ErrorContains(t, err, "error abandoning pull request 7")
+				require.Equal(t, []int{7}, updated)
+				require.Empty(t, abandoned)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var updated []int
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPullRequestsFn: func(
+					_ context.Context,
+					args git.GetPullRequestsArgs,
+				) (*[]git.GitPullRequest, error) {
+					require.Nil(t, args.SearchCriteria.SourceRefName)
+					require.Nil(t, args.SearchCriteria.TargetRefName)
+					require.Equal(
+						t,
+						git.PullRequestStatusValues.Active,
+						*args.SearchCriteria.Status,
+					)
+					return &prs, nil
+				},
+				updatePullRequestFn: func(
+					_ context.Context,
+					args git.UpdatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					updated = append(updated, *args.PullRequestId)
+					require.Equal(
+						t,
+						git.PullRequestStatusValues.Abandoned,
+						*args.GitPullRequestToUpdate.Status,
+					)
+					return &git.GitPullRequest{}, testCase.abandonErr
+				},
+			})
+			abandoned, err := AbandonStalePRs(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				7*24*time.Hour,
+				testCase.marker,
+				gitutil.RepoCredentials{Password: "token"},
+			)
+			testCase.assertions(t, abandoned, updated, err)
+		})
+	}
+}
+
+func TestListAllActivePRs(t *testing.T) {
+	var skips []int
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: fakeRepos("repo"),
+		getPullRequestsFn: func(
+			_ context.Context,
+			args git.GetPullRequestsArgs,
+		) (*[]git.GitPullRequest, error) {
+			skips = append(skips, *args.Skip)
+			page := make([]git.GitPullRequest, *args.Top)
+			if *args.Skip > 0 {
+				page = page[:3]
+			}
+			return &page, nil
+		},
+	})
+	repo, err := newRepoClient(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		gitutil.RepoCredentials{Password: "token"},
+		nil,
+	)
+	require.NoError(t, err)
+	prs, err := listAllActivePRs(context.Background(), repo)
+	require.NoError(t, err)
+	require.Len(t, prs, prsPageSize+3)
+	require.Equal(t, []int{0, prsPageSize}, skips)
+}
@@ -0,0 +1,161 @@
+package azuredevops
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// providerName identifies Azure DevOps in structured errors. It matches the
+// name by which Kargo Render's configuration refers to the provider.
+const providerName = "azuredevops"
+
+// ErrorCode classifies an error for machine consumption.
+type ErrorCode string
+
+const (
+	ErrorCodeUnknown                ErrorCode = "unknown"
+	ErrorCodeInvalidURL             ErrorCode = "invalid_url"
+	ErrorCodeUnreachable            ErrorCode = "unreachable"
+	ErrorCodeUnauthorized           ErrorCode = "unauthorized"
+	ErrorCodeCredentialsExpired     ErrorCode = "credentials_expired"
+	ErrorCodeForbidden              ErrorCode = "forbidden"
+	ErrorCodeNotFound               ErrorCode = "not_found"
+	ErrorCodeThrottled              ErrorCode = "throttled"
+	ErrorCodeUnavailable            ErrorCode = "unavailable"
+	ErrorCodeIncompleteResponse     ErrorCode = "incomplete_response"
+	ErrorCodeRepositoryDisabled     ErrorCode = "repository_disabled"
+	ErrorCodeRepositoryMismatch     ErrorCode = "repository_mismatch"
+	ErrorCodeBranchNotFound         ErrorCode = "branch_not_found"
+	ErrorCodeNoTarget               ErrorCode = "no_target"
+	ErrorCodeNoChanges              ErrorCode = "no_changes"
+	ErrorCodeConflict               ErrorCode = "conflict"
+	ErrorCodeDuplicatePRs           ErrorCode = "duplicate_prs"
+	ErrorCodeUnprotectedBranch      ErrorCode = "unprotected_branch"
+	ErrorCodeInsufficientPerms      ErrorCode = "insufficient_permissions"
+	ErrorCodeReviewerVotesDisabled  ErrorCode = "reviewer_votes_not_allowed"
+	ErrorCodePRNotMerged            ErrorCode = "pr_not_merged"
+	ErrorCodePRNotActive            ErrorCode = "pr_not_active"
+	ErrorCodePolicyNotRequeueable   ErrorCode = "policy_not_requeueable"
+	ErrorCodeTagExists              ErrorCode = "tag_exists"
+	ErrorCodeMergeFailed            ErrorCode = "merge_failed"
+	ErrorCodeInvalidMetadata        ErrorCode = "invalid_metadata"
+	ErrorCodeNoRepoDefault          ErrorCode = "no_repo_default"
+	ErrorCodeInvalidToken           ErrorCode = "invalid_token"
+	ErrorCodeUnrelatedHistories     ErrorCode = "unrelated_histories"
+	ErrorCodePathEmpty              ErrorCode = "path_empty"
+	ErrorCodeDefaultBranchTarget    ErrorCode = "default_branch_target"
+	ErrorCodeAutoCompleteNotEngaged ErrorCode = "auto_complete_not_engaged"
+	ErrorCodeInvalidRepositoryID    ErrorCode = "invalid_repository_id"
+)
+
+// errorCodes maps the errors this package returns to their codes. Errors are
+// matched in order, so more specific errors must precede less specific ones.
+var errorCodes = []struct {
+	target error
+	code   ErrorCode
+}{
+	{target: errInvalidURL, code: ErrorCodeInvalidURL},
+	{target: errUnsupportedURL, code: ErrorCodeInvalidURL},
+	{target: ErrInvalidBaseURL, code: ErrorCodeInvalidURL},
+	{target: ErrNoRepoDefault, code: ErrorCodeNoRepoDefault},
+	{target: ErrInvalidOnBehalfOfToken, code: ErrorCodeInvalidToken},
+	{target: ErrUnrelatedHistories, code: ErrorCodeUnrelatedHistories},
+	{target: ErrPathEmpty, code: ErrorCodePathEmpty},
+	{target: ErrDefaultBranchTarget, code: ErrorCodeDefaultBranchTarget},
+	{target: ErrAutoCompleteNotEngaged, code: ErrorCodeAutoCompleteNotEngaged},
+	{target: ErrInvalidRepositoryID, code: ErrorCodeInvalidRepositoryID},
+	{target: ErrUnreachable, code: ErrorCodeUnreachable},
+	{target: ErrCredentialsExpired, code: ErrorCodeCredentialsExpired},
+	{target: ErrIncompleteResponse, code: ErrorCodeIncompleteResponse},
+	{target: ErrRepositoryDisabled, code: ErrorCodeRepositoryDisabled},
+	{target: ErrRepositoryMismatch, code: ErrorCodeRepositoryMismatch},
+	{target: ErrBranchNotFound, code: ErrorCodeBranchNotFound},
+	{target: ErrNoTarget, code: ErrorCodeNoTarget},
+	{target: ErrNoChanges, code: ErrorCodeNoChanges},
+	{target: ErrConflict, code: ErrorCodeConflict},
+	{target: ErrDuplicatePRs, code: ErrorCodeDuplicatePRs},
+	{target: ErrUnprotectedBranch, code: ErrorCodeUnprotectedBranch},
+	{target: ErrInsufficientPermissions, code: ErrorCodeInsufficientPerms},
+	{target: ErrReviewerVotesNotAllowed, code: ErrorCodeReviewerVotesDisabled},
+	{target: ErrPRNotMerged, code: ErrorCodePRNotMerged},
+	{target: ErrPRNotActive, code: ErrorCodePRNotActive},
+	{target: ErrPolicyNotRequeueable, code: ErrorCodePolicyNotRequeueable},
+	{target: ErrTagExists, code: ErrorCodeTagExists},
+	{target: ErrMergeFailed, code: ErrorCodeMergeFailed},
+	{target: ErrInvalidMetadata, code: ErrorCodeInvalidMetadata},
+}
+
+// statusErrorCodes maps the HTTP status codes of Azure DevOps API errors that
+// are not otherwise classified to error codes.
+var statusErrorCodes = map[int]ErrorCode{
+	http.StatusUnauthorized:       ErrorCodeUnauthorized,
+	http.StatusForbidden:          ErrorCodeForbidden,
+	http.StatusNotFound:           ErrorCodeNotFound,
+	http.StatusConflict:           ErrorCodeConflict,
+	http.StatusTooManyRequests:    ErrorCodeThrottled,
+	http.StatusBadGateway:         ErrorCodeUnavailable,
+	http.StatusServiceUnavailable: ErrorCodeUnavailable,
+	http.StatusGatewayTimeout:     ErrorCodeUnavailable,
+}
+
+// StructuredError is a machine-readable representation of an error returned
+// by this package, suitable for serializing to JSON.
+type StructuredError struct {
+	// Code classifies the error.
+	Code ErrorCode `json:"code"`
+	// Message is the error's message.
+	Message string `json:"message"`
+	// Provider identifies the Git provider the error was encountered with.
+	Provider string `json:"provider"`
+	// Repo is the URL, without any credentials, of the repository the error
+	// was encountered with, if known.
+	Repo string `json:"repo,omitempty"`
+	// Retryable indicates whether the operation that failed may succeed if it
+	// is retried.
+	Retryable bool `json:"retryable"`
+	// StatusCode is the HTTP status code of the Azure DevOps API response that
+	// caused the error, if any.
+	StatusCode int `json:"statusCode,omitempty"`
+}
+
+// StructuredErrorOf returns a structured representation of the specified,
+// non-nil error, which was encountered with the repository referenced by the
+// specified URL. The URL may be empty if it is not known. Well-known
+// credential formats are masked within the error's message, but callers should
+// pass errors as they were returned by this package, which masks the
+// credentials it was provided with.
+func StructuredErrorOf(err error, repoURL string) StructuredError {
+	structured := StructuredError{
+		Code:     ErrorCodeUnknown,
+		Message:  redactError(err, nil, nil).Error(),
+		Provider: providerName,
+		Repo:     withoutCredentials(repoURL),
+	}
+	statusCode, hasStatusCode := statusCodeOf(err)
+	if hasStatusCode {
+		structured.StatusCode = statusCode
+		if code, ok := statusErrorCodes[statusCode]; ok {
+			structured.Code = code
+		}
+	}
+	for _, c := range errorCodes {
+		if errors.Is(err, c.target) {
+			structured.Code = c.code
+			break
+		}
+	}
+	structured.Retryable = errors.Is(err, ErrUnreachable) || isRetryable(err)
+	return structured
+}
+
+// withoutCredentials returns the specified URL with any credentials removed.
+// URLs that cannot be parsed are returned as is.
+func withoutCredentials(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	u.User = nil
+	return u.String()
+}
@@ -0,0 +1,78 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// ErrTagExists is returned when a tag is to be created, but a tag with the
+// same name already exists and overwriting it was not requested.
+var ErrTagExists = errors.New("tag already exists")
+
+// TagOptions encapsulates optional settings for TagCommit.
+type TagOptions struct {
+	// Force specifies that an existing tag with the same name should be
+	// replaced rather than an error wrapping ErrTagExists being returned.
+	Force bool
+}
+
+// TagCommit creates an annotated tag with the specified name and message
+// pointing at the specified commit, e.g. the merge commit of a completed PR.
+// The name may be given with or without the refs/tags/ prefix. If a tag with
+// the same name already exists, an error wrapping ErrTagExists is returned,
+// unless opts.Force is true, in which case the existing tag is replaced.
+func TagCommit(
+	ctx context.Context,
+	repoURL string,
+	commitSHA string,
+	tagName string,
+	message string,
+	creds gitutil.RepoCredentials,
+	opts *TagOptions,
+) (err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	if opts == nil {
+		opts = &TagOptions{}
+	}
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimPrefix(tagName, "refs/tags/")
+	ref := "refs/tags/" + name
+	existing, err := getRef(ctx, repo, ref)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ObjectId != nil {
+		if !opts.Force {
+			return fmt.Errorf("%w: %q", ErrTagExists, name)
+		}
+		if err = updateRef(ctx, repo, ref, *existing.ObjectId, nullObjectID); err != nil {
+			return fmt.Errorf("error deleting existing tag %q: %w", name, err)
+		}
+	}
+	if _, err = repo.client.CreateAnnotatedTag(ctx, git.CreateAnnotatedTagArgs{
+		Project:      &repo.project,
+		RepositoryId: &repo.id,
+		TagObject: &git.GitAnnotatedTag{
+			Name:         &name,
+			Message:      &message,
+			TaggedObject: &git.GitObject{ObjectId: &commitSHA},
+		},
+	}); err != nil {
+		// The tag may have been created concurrently
+		if code, ok := statusCodeOf(err); ok && code == http.StatusConflict {
+			return fmt.Errorf("%w: %q: %w", ErrTagExists, name, err)
+		}
+		return fmt.Errorf("error tagging commit %s as %q: %w", commitSHA, name, err)
+	}
+	return nil
+}
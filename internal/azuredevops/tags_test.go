@@ -0,0 +1,157 @@
+package azuredevops
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestTagCommit(t *testing.T) {
+	const tagRef = "refs/tags/v1.2.3"
+	testCases := []struct {
+		name       string
+		refs       map[string]string
+		force      bool
+		createErr  error
+		assertions func(
+			t *testing.T,
+			updates []git.GitRefUpdate,
+			created []git.GitAnnotatedTag,
+			err error,
+		)
+	}{
+		{
+			name: "new tag is created",
+			refs: map[string]string{"refs/tags/v1.2": "abc123"},
+			assertions: func(
+				t *testing.T,
+				updates []git.GitRefUpdate,
+				created []git.GitAnnotatedTag,
+				err error,
+			) {
+				require.NoError(t, err)
+				require.Empty(t, updates)
+				require.Equal(
+					t,
+					[]git.GitAnnotatedTag{{
+						Name:         ptr("v1.2.3"),
+						Message:      ptr("Release v1.2.3"),
+						TaggedObject: &git.GitObject{ObjectId: ptr("fedcba")},
+					}},
+					created,
+				)
+			},
+		},
+		{
+			name: "existing tag is not overwritten",
+			refs: map[string]string{tagRef: "abc123"},
+			assertions: func(
+				t *testing.T,
+				updates []git.GitRefUpdate,
+				created []git.GitAnnotatedTag,
+				err error,
+			) {
+				require.ErrorIs(t, err, ErrTagExists)
+				require.ErrorContains(t, err, `"v1.2.3"`)
+				require.Empty(t, updates)
+				require.Empty(t, created)
+			},
+		},
+		{
+			name:  "existing tag is replaced when forced",
+			refs:  map[string]string{tagRef: "abc123"},
+			force: true,
+			assertions: func(
+				t *testing.T,
+				updates []git.GitRefUpdate,
+				created []git.GitAnnotatedTag,
+				err error,
+			) {
+				require.NoError(t, err)
+				require.Equal(
+					t,
+					[]git.GitRefUpdate{{
+						Name:        ptr(tagRef),
+						OldObjectId: ptr("abc123"),
+						NewObjectId: ptr(nullObjectID),
+					}},
+					updates,
+				)
+				require.Len(t, created, 1)
+			},
+		},
+		{
+			name: "tag created concurrently",
+			createErr: azuredevops.WrappedError{
+				StatusCode: ptr(http.StatusConflict),
+				Message:    ptr("TF401289: The tag already exists."),
+			},
+			assertions: func(
+				t *testing.T,
+				_ []git.GitRefUpdate,
+				_ []git.GitAnnotatedTag,
+				err error,
+			) {
+				require.ErrorIs(t, err, ErrTagExists)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var updates []git.GitRefUpdate
+			var created []git.GitAnnotatedTag
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getRefsFn: func(
+					_ context.Context,
+					args git.GetRefsArgs,
+				) (*git.GetRefsResponseValue, error) {
+					require.Equal(t, "tags/v1.2.3", *args.Filter)
+					res := &git.GetRefsResponseValue{}
+					for name, objectID := range testCase.refs {
+						if name == "refs/"+*args.Filter {
+							res.Value = append(res.Value, git.GitRef{
+								Name:     ptr(name),
+								ObjectId: ptr(objectID),
+							})
+						}
+					}
+					return res, nil
+				},
+				updateRefsFn: func(
+					_ context.Context,
+					args git.UpdateRefsArgs,
+				) (*[]git.GitRefUpdateResult, error) {
+					updates = append(updates, *args.RefUpdates...)
+					return &[]git.GitRefUpdateResult{{Success: ptr(true)}}, nil
+				},
+				createAnnotatedTagFn: func(
+					_ context.Context,
+					args git.CreateAnnotatedTagArgs,
+				) (*git.GitAnnotatedTag, error) {
+					if testCase.createErr != nil {
+						return nil, testCase.createErr
+					}
+					created = append(created, *args.TagObject)
+					return args.TagObject, nil
+				},
+			})
+			err := TagCommit(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"fedcba",
+				"refs/tags/v1.2.3",
+				"Release v1.2.3",
+				gitutil.RepoCredentials{Password: "token"},
+				&TagOptions{Force: testCase.force},
+			)
+			testCase.assertions(t, updates, created, err)
+		})
+	}
+}
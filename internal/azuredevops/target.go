@@ -0,0 +1,97 @@
+package azuredevops
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrNoTarget is returned when no target branch was specified for a PR and
+// none could be resolved from its source branch.
+var ErrNoTarget = errors.New("no target branch could be resolved")
+
+// TargetRule maps source branches matching a regular expression to a target
+// branch.
+type TargetRule struct {
+	// Pattern is a regular expression that must match the entire name of a
+	// source branch, without any refs/heads/ prefix, for the rule to apply.
+	Pattern string
+	// Target is the target branch for PRs from matching source branches. It
+	// may reference capture groups of Pattern using the syntax of
+	// regexp.Regexp.Expand, e.g. `env/$1` or `env/${name}`.
+	Target string
+}
+
+// compiledTargetRule is a TargetRule whose pattern has been compiled.
+type compiledTargetRule struct {
+	pattern *regexp.Regexp
+	target  string
+}
+
+// TargetResolver resolves the target branch of a PR from its source branch
+// using an ordered list of rules. Instances should be created using
+// NewTargetResolver.
+type TargetResolver struct {
+	rules         []compiledTargetRule
+	defaultTarget string
+}
+
+// NewTargetResolver returns a TargetResolver that resolves target branches
+// using the first of the specified rules that matches a source branch, or the
+// specified default target, if non-empty, when none do. All rules are
+// compiled and validated up front so that errors in them are surfaced before
+// any PR is opened.
+func NewTargetResolver(rules []TargetRule, defaultTarget string) (*TargetResolver, error) {
+	r := &TargetResolver{
+		rules:         make([]compiledTargetRule, len(rules)),
+		defaultTarget: defaultTarget,
+	}
+	for i, rule := range rules {
+		if rule.Target == "" {
+			return nil, fmt.Errorf("target rule %d has no target", i)
+		}
+		pattern, err := regexp.Compile(`^(?:` + rule.Pattern + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error compiling pattern %q of target rule %d: %w",
+				rule.Pattern,
+				i,
+				err,
+			)
+		}
+		r.rules[i] = compiledTargetRule{pattern: pattern, target: rule.Target}
+	}
+	return r, nil
+}
+
+// Resolve returns the target branch for PRs from the specified source branch.
+// If no rule matches and there is no default target, an error wrapping
+// ErrNoTarget is returned.
+func (r *TargetResolver) Resolve(sourceBranch string) (string, error) {
+	sourceBranch = strings.TrimPrefix(sourceBranch, "refs/heads/")
+	for _, rule := range r.rules {
+		match := rule.pattern.FindStringSubmatchIndex(sourceBranch)
+		if match == nil {
+			continue
+		}
+		target := string(rule.pattern.ExpandString(nil, rule.target, sourceBranch, match))
+		if target == "" {
+			return "", fmt.Errorf(
+				"%w: rule %q resolved source branch %q to an empty target",
+				ErrNoTarget,
+				rule.pattern.String(),
+				sourceBranch,
+			)
+		}
+		return target, nil
+	}
+	if r.defaultTarget != "" {
+		return r.defaultTarget, nil
+	}
+	return "", fmt.Errorf(
+		"%w: source branch %q matches no target rule",
+		ErrNoTarget,
+		sourceBranch,
+	)
+}
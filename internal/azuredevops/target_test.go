@@ -0,0 +1,175 @@
+package azuredevops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestNewTargetResolver(t *testing.T) {
+	testCases := []struct {
+		name       string
+		rules      []TargetRule
+		assertions func(t *testing.T, err error)
+	}{
+		{
+			name: "valid rules",
+			rules: []TargetRule{
+				{Pattern: `release/(.*)`, Target: "env/$1"},
+				{Pattern: `hotfix/(?P<env>[^/]+)/.*`, Target: "env/${env}"},
+			},
+			assertions: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:  "invalid pattern",
+			rules: []TargetRule{{Pattern: `release/(.*`, Target: "env/$1"}},
+			assertions: func(t *testing.T, err error) {
+				require.ErrorContains(t, err, "error compiling pattern")
+			},
+		},
+		{
+			name:  "missing target",
+			rules: []TargetRule{{Pattern: `release/(.*)`}},
+			assertions: func(t *testing.T, err error) {
+				require.ErrorContains(t, err, "target rule 0 has no target")
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := NewTargetResolver(testCase.rules, "")
+			testCase.assertions(t, err)
+		})
+	}
+}
+
+func TestTargetResolverResolve(t *testing.T) {
+	rules := []TargetRule{
+		{Pattern: `release/(.*)`, Target: "env/$1"},
+		{Pattern: `hotfix/(?P<env>[^/]+)/.*`, Target: "env/${env}"},
+		{Pattern: `release/.*`, Target: "unreachable"},
+	}
+	testCases := []struct {
+		name          string
+		defaultTarget string
+		source        string
+		assertions    func(t *testing.T, target string, err error)
+	}{
+		{
+			name:   "numbered group",
+			source: "release/prod",
+			assertions: func(t *testing.T, target string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "env/prod", target)
+			},
+		},
+		{
+			name:   "named group with refs prefix",
+			source: "refs/heads/hotfix/stage/fix-123",
+			assertions: func(t *testing.T, target string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "env/stage", target)
+			},
+		},
+		{
+			name:          "no match falls back to default",
+			defaultTarget: "env/dev",
+			source:        "feature/foo",
+			assertions: func(t *testing.T, target string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "env/dev", target)
+			},
+		},
+		{
+			name:   "no match without default",
+			source: "feature/release/foo",
+			assertions: func(t *testing.T, _ string, err error) {
+				require.ErrorIs(t, err, ErrNoTarget)
+				require.ErrorContains(t, err, "matches no target rule")
+			},
+		},
+		{
+			name:   "empty capture group",
+			source: "release/",
+			assertions: func(t *testing.T, target string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "env/", target)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			resolver, err := NewTargetResolver(rules, testCase.defaultTarget)
+			require.NoError(t, err)
+			target, err := resolver.Resolve(testCase.source)
+			testCase.assertions(t, target, err)
+		})
+	}
+}
+
+func TestOpenPRResolvesTarget(t *testing.T) {
+	resolver, err := NewTargetResolver(
+		[]TargetRule{{Pattern: `release/(.*)`, Target: "env/$1"}},
+		"",
+	)
+	require.NoError(t, err)
+	testCases := []struct {
+		name       string
+		source     string
+		resolver   *TargetResolver
+		assertions func(t *testing.T, created git.GitPullRequest, err error)
+	}{
+		{
+			name:     "resolved target",
+			source:   "release/prod",
+			resolver: resolver,
+			assertions: func(t *testing.T, created git.GitPullRequest, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "refs/heads/env/prod", *created.TargetRefName)
+			},
+		},
+		{
+			name:     "unresolvable target",
+			source:   "feature/foo",
+			resolver: resolver,
+			assertions: func(t *testing.T, created git.GitPullRequest, err error) {
+				require.ErrorIs(t, err, ErrNoTarget)
+				require.Nil(t, created.TargetRefName)
+			},
+		},
+		{
+			name:   "no resolver",
+			source: "release/prod",
+			assertions: func(t *testing.T, created git.GitPullRequest, err error) {
+				require.ErrorIs(t, err, ErrNoTarget)
+				require.Nil(t, created.TargetRefName)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var created git.GitPullRequest
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn:   fakeRepos("repo"),
+				createPullRequestFn: fakeCreatePullRequest(&created),
+			})
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"",
+				testCase.source,
+				gitutil.RepoCredentials{Password: "pat"},
+				&OpenPROptions{TargetResolver: testCase.resolver},
+			)
+			testCase.assertions(t, created, err)
+		})
+	}
+}
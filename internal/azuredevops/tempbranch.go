@@ -0,0 +1,74 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TempBranchPrefix is the prefix of the names of the temporary branches from
+// which PRs for OpenPROptions.SourceCommit are opened.
+const TempBranchPrefix = "kargo-render/commits/"
+
+// commitSHARegex matches full SHA-1 commit IDs.
+var commitSHARegex = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+
+// TempBranchName returns the name of the temporary branch from which PRs for
+// the specified commit are opened. The name is derived from the commit alone,
+// so that repeated attempts to open a PR for the same commit reuse the same
+// branch.
+func TempBranchName(commitSHA string) string {
+	return TempBranchPrefix + strings.ToLower(commitSHA)
+}
+
+// tempBranchFor returns the name of the temporary branch from which a PR for
+// the specified commit should be opened. It returns an error if a source
+// branch was also specified or if the commit is not a full commit ID.
+func tempBranchFor(sourceBranch string, commitSHA string) (string, error) {
+	if sourceBranch != "" {
+		return "", errors.New(
+			"a source branch cannot be specified together with a source commit",
+		)
+	}
+	if !commitSHARegex.MatchString(commitSHA) {
+		return "", fmt.Errorf("source commit %q is not a full commit ID", commitSHA)
+	}
+	return TempBranchName(commitSHA), nil
+}
+
+// ensureBranchAt creates the specified fully-qualified branch ref, pointing at
+// the specified commit, if it does not already exist. It returns a bool
+// indicating whether the branch was created. It is an error if the branch
+// exists, but points at a different commit.
+func ensureBranchAt(
+	ctx context.Context,
+	repo *repoClient,
+	ref string,
+	commitSHA string,
+) (bool, error) {
+	existing, err := getRef(ctx, repo, ref)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		if existing.ObjectId == nil || !strings.EqualFold(*existing.ObjectId, commitSHA) {
+			return false, fmt.Errorf(
+				"branch %q already exists, but does not point at commit %s",
+				ref,
+				commitSHA,
+			)
+		}
+		return false, nil
+	}
+	if err = updateRef(ctx, repo, ref, nullObjectID, strings.ToLower(commitSHA)); err != nil {
+		return false, fmt.Errorf(
+			"error creating branch %q at commit %s: %w",
+			ref,
+			commitSHA,
+			err,
+		)
+	}
+	return true, nil
+}
@@ -0,0 +1,234 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestTempBranchName(t *testing.T) {
+	require.Equal(
+		t,
+		"kargo-render/commits/0123456789abcdef0123456789abcdef01234567",
+		TempBranchName("0123456789ABCDEF0123456789abcdef01234567"),
+	)
+}
+
+func TestOpenPRSourceCommit(t *testing.T) {
+	const (
+		commit  = "0123456789abcdef0123456789abcdef01234567"
+		tempRef = "refs/heads/kargo-render/commits/" + commit
+	)
+	testCases := []struct {
+		name          string
+		sourceBranch  string
+		sourceCommit  string
+		existingRefs  map[string]string
+		createErr     error
+		opts          OpenPROptions
+		expectUpdates int
+		assertions    func(t *testing.T, refs map[string]string, pr *git.GitPullRequest, err error)
+	}{
+		{
+			name:          "creates temporary branch",
+			sourceCommit:  commit,
+			expectUpdates: 1,
+			assertions: func(
+				t *testing.T,
+				refs map[string]string,
+				pr *git.GitPullRequest,
+				err error,
+			) {
+				require.NoError(t, err)
+				require.Equal(t, commit, refs[tempRef])
+				require.Equal(t, tempRef, *pr.SourceRefName)
+			},
+		},
+		{
+			name:         "reuses existing temporary branch",
+			sourceCommit: commit,
+			existingRefs: map[string]string{tempRef: commit},
+			assertions: func(
+				t *testing.T,
+				refs map[string]string,
+				pr *git.GitPullRequest,
+				err error,
+			) {
+				require.NoError(t, err)
+				require.Equal(t, commit, refs[tempRef])
+				require.Equal(t, tempRef, *pr.SourceRefName)
+			},
+		},
+		{
+			name:         "temporary branch points elsewhere",
+			sourceCommit: commit,
+			existingRefs: map[string]string{
+				tempRef: "89abcdef0123456789abcdef0123456789abcdef",
+			},
+			assertions: func(t *testing.T, _ map[string]string, pr *git.GitPullRequest, err error) {
+				require.ErrorContains(t, err, "error creating temporary source branch")
+				require.Nil(t, pr)
+			},
+		},
+		{
+			name:          "temporary branch deleted on failure",
+			sourceCommit:  commit,
+			createErr:     errors.New("something went wrong"),
+			opts:          OpenPROptions{DeleteTempBranch: true},
+			expectUpdates: 2,
+			assertions: func(t *testing.T, refs map[string]string, _ *git.GitPullRequest, err error) {
+				require.ErrorContains(t, err, "something went wrong")
+				require.NotContains(t, refs, tempRef)
+			},
+		},
+		{
+			name:          "temporary branch kept on failure",
+			sourceCommit:  commit,
+			createErr:     errors.New("something went wrong"),
+			expectUpdates: 1,
+			assertions: func(t *testing.T, refs map[string]string, _ *git.GitPullRequest, err error) {
+				require.ErrorContains(t, err, "something went wrong")
+				require.Equal(t, commit, refs[tempRef])
+			},
+		},
+		{
+			name:         "pre-existing temporary branch kept on failure",
+			sourceCommit: commit,
+			existingRefs: map[string]string{tempRef: commit},
+			createErr:    errors.New("something went wrong"),
+			opts:         OpenPROptions{DeleteTempBranch: true},
+			assertions: func(t *testing.T, refs map[string]string, _ *git.GitPullRequest, err error) {
+				require.ErrorContains(t, err, "something went wrong")
+				require.Equal(t, commit, refs[tempRef])
+			},
+		},
+		{
+			name:         "source branch and commit",
+			sourceBranch: "prs/kargo-render/env/dev",
+			sourceCommit: commit,
+			assertions: func(t *testing.T, _ map[string]string, _ *git.GitPullRequest, err error) {
+				require.ErrorContains(t, err, "cannot be specified together")
+			},
+		},
+		{
+			name:         "abbreviated commit",
+			sourceCommit: "0123456",
+			assertions: func(t *testing.T, _ map[string]string, _ *git.GitPullRequest, err error) {
+				require.ErrorContains(t, err, "is not a full commit ID")
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			refs := map[string]string{}
+			for name, objectID := range testCase.existingRefs {
+				refs[name] = objectID
+			}
+			var updates int
+			var created *git.GitPullRequest
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getRefsFn: func(
+					context.Context,
+					git.GetRefsArgs,
+				) (*git.GetRefsResponseValue, error) {
+					var res []git.GitRef
+					for name, objectID := range refs {
+						res = append(res, git.GitRef{Name: ptr(name), ObjectId: ptr(objectID)})
+					}
+					return &git.GetRefsResponseValue{Value: res}, nil
+				},
+				updateRefsFn: func(
+					_ context.Context,
+					args git.UpdateRefsArgs,
+				) (*[]git.GitRefUpdateResult, error) {
+					updates++
+					for _, update := range *args.RefUpdates {
+						if *update.NewObjectId == nullObjectID {
+							delete(refs, *update.Name)
+							continue
+						}
+						refs[*update.Name] = *update.NewObjectId
+					}
+					return &[]git.GitRefUpdateResult{{Success: ptr(true)}}, nil
+				},
+				createPullRequestFn: func(
+					ctx context.Context,
+					args git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					if testCase.createErr != nil {
+						return nil, testCase.createErr
+					}
+					created = &git.GitPullRequest{}
+					return fakeCreatePullRequest(created)(ctx, args)
+				},
+			})
+			opts := testCase.opts
+			opts.SourceCommit = testCase.sourceCommit
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				testCase.sourceBranch,
+				gitutil.RepoCredentials{Password: "token"},
+				&opts,
+			)
+			testCase.assertions(t, refs, created, err)
+			require.Equal(t, testCase.expectUpdates, updates)
+		})
+	}
+}
+
+func TestOpenPRSourceCommitDeletesTempBranchOnCompletion(t *testing.T) {
+	var completionOpts *git.GitPullRequestCompletionOptions
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: fakeRepos("repo"),
+		getRefsFn: func(
+			context.Context,
+			git.GetRefsArgs,
+		) (*git.GetRefsResponseValue, error) {
+			return &git.GetRefsResponseValue{}, nil
+		},
+		updateRefsFn: func(
+			context.Context,
+			git.UpdateRefsArgs,
+		) (*[]git.GitRefUpdateResult, error) {
+			return &[]git.GitRefUpdateResult{{Success: ptr(true)}}, nil
+		},
+		createPullRequestFn: fakeCreatePullRequest(nil),
+		updatePullRequestFn: func(
+			_ context.Context,
+			args git.UpdatePullRequestArgs,
+		) (*git.GitPullRequest, error) {
+			completionOpts = args.GitPullRequestToUpdate.CompletionOptions
+			return args.GitPullRequestToUpdate, nil
+		},
+	})
+	autoComplete := &AutoCompleteOptions{SetByID: "d3b07384-d9a0-4c9b-8f1e-000000000001"}
+	_, err := OpenPR(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"title",
+		"description",
+		"env/dev",
+		"",
+		gitutil.RepoCredentials{Password: "token"},
+		&OpenPROptions{
+			SourceCommit:     "0123456789abcdef0123456789abcdef01234567",
+			DeleteTempBranch: true,
+			AutoComplete:     autoComplete,
+		},
+	)
+	require.NoError(t, err)
+	require.NotNil(t, completionOpts)
+	require.True(t, *completionOpts.DeleteSourceBranch)
+	// The caller's options are not modified
+	require.False(t, autoComplete.DeleteSourceBranch)
+}
@@ -0,0 +1,90 @@
+package azuredevops
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// defaultMinTLSVersion is the minimum TLS version negotiated with Azure DevOps
+// when TLS settings are specified without one.
+const defaultMinTLSVersion = tls.VersionTLS12
+
+// TLSOptions specifies the TLS settings of connections to Azure DevOps. This
+// chiefly concerns Azure DevOps Server, since Azure DevOps Services already
+// refuses anything older than TLS 1.2.
+type TLSOptions struct {
+	// MinVersion is the minimum TLS version that is acceptable, e.g.
+	// tls.VersionTLS13. When this is zero, TLS 1.2 is the minimum.
+	MinVersion uint16
+	// CipherSuites optionally restricts the cipher suites that may be
+	// negotiated for TLS 1.2 and below, e.g. to
+	// tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384. TLS 1.3 cipher suites are not
+	// configurable. Only the suites reported by tls.CipherSuites, which have no
+	// known security issues, are permitted. When this is empty, Go's defaults
+	// are used.
+	CipherSuites []uint16
+}
+
+// config returns the TLS configuration specified by the options.
+func (t *TLSOptions) config() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: defaultMinTLSVersion}
+	if t.MinVersion != 0 {
+		switch t.MinVersion {
+		case tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13:
+		default:
+			return nil, fmt.Errorf("unknown TLS version %#04x", t.MinVersion)
+		}
+		cfg.MinVersion = t.MinVersion
+	}
+	if len(t.CipherSuites) > 0 {
+		secure := map[uint16]struct{}{}
+		for _, suite := range tls.CipherSuites() {
+			secure[suite.ID] = struct{}{}
+		}
+		for _, id := range t.CipherSuites {
+			if _, ok := secure[id]; !ok {
+				return nil, fmt.Errorf(
+					"cipher suite %s is insecure or unknown",
+					tls.CipherSuiteName(id),
+				)
+			}
+		}
+		cfg.CipherSuites = t.CipherSuites
+	}
+	return cfg, nil
+}
+
+var (
+	transportsMu sync.Mutex
+	// transports holds the transports returned by newTransport, indexed by the
+	// tlsConfigKey of their TLS configurations, so that operations with the same
+	// TLS settings share their underlying connections.
+	transports = map[string]http.RoundTripper{}
+)
+
+// newTransport returns an HTTP transport with the same settings as
+// http.DefaultTransport, including its proxy settings, except that it uses the
+// specified TLS configuration, if any. The same transport is returned for all
+// configurations with the same tlsConfigKey, so that its idle connections are
+// reused rather than accumulating with every operation.
+func newTransport(cfg *tls.Config) http.RoundTripper {
+	if cfg == nil {
+		return http.DefaultTransport
+	}
+	key := tlsConfigKey(cfg)
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	if t, ok := transports[key]; ok {
+		return t
+	}
+	var t http.RoundTripper = &http.Transport{TLSClientConfig: cfg}
+	if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok {
+		clone := defaultTransport.Clone()
+		clone.TLSClientConfig = cfg
+		t = clone
+	}
+	transports[key] = t
+	return t
+}
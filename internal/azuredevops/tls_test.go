@@ -0,0 +1,127 @@
+package azuredevops
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSOptionsConfig(t *testing.T) {
+	testCases := []struct {
+		name       string
+		opts       TLSOptions
+		assertions func(t *testing.T, cfg *tls.Config, err error)
+	}{
+		{
+			name: "defaults to TLS 1.2",
+			assertions: func(t *testing.T, cfg *tls.Config, err error) {
+				require.NoError(t, err)
+				require.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+				require.Empty(t, cfg.CipherSuites)
+			},
+		},
+		{
+			name: "configured minimum version",
+			opts: TLSOptions{MinVersion: tls.VersionTLS13},
+			assertions: func(t *testing.T, cfg *tls.Config, err error) {
+				require.NoError(t, err)
+				require.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion)
+			},
+		},
+		{
+			name: "unknown version",
+			opts: TLSOptions{MinVersion: 0x0999},
+			assertions: func(t *testing.T, _ *tls.Config, err error) {
+				require.ErrorContains(t, err, "unknown TLS version 0x0999")
+			},
+		},
+		{
+			name: "secure cipher suites",
+			opts: TLSOptions{
+				CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+			},
+			assertions: func(t *testing.T, cfg *tls.Config, err error) {
+				require.NoError(t, err)
+				require.Equal(
+					t,
+					[]uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+					cfg.CipherSuites,
+				)
+			},
+		},
+		{
+			name: "insecure cipher suite",
+			opts: TLSOptions{
+				CipherSuites: []uint16{tls.TLS_RSA_WITH_RC4_128_SHA},
+			},
+			assertions: func(t *testing.T, _ *tls.Config, err error) {
+				require.ErrorContains(t, err, "TLS_RSA_WITH_RC4_128_SHA is insecure")
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			cfg, err := testCase.opts.config()
+			testCase.assertions(t, cfg, err)
+		})
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	require.Same(t, http.DefaultTransport, newTransport(nil))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	serverTransport, ok := server.Client().Transport.(*http.Transport)
+	require.True(t, ok)
+	certs := serverTransport.TLSClientConfig.RootCAs
+
+	testCases := []struct {
+		name       string
+		minVersion uint16
+		assertions func(t *testing.T, err error)
+	}{
+		{
+			name:       "server meets minimum",
+			minVersion: tls.VersionTLS12,
+			assertions: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:       "server does not meet minimum",
+			minVersion: tls.VersionTLS13,
+			assertions: func(t *testing.T, err error) {
+				require.ErrorContains(t, err, "protocol version")
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			cfg, err := (&TLSOptions{MinVersion: testCase.minVersion}).config()
+			require.NoError(t, err)
+			cfg.RootCAs = certs
+			transport := newTransport(cfg)
+			httpTransport, ok := transport.(*http.Transport)
+			require.True(t, ok)
+			// Transports are shared among equivalent configurations
+			sameCfg, err := (&TLSOptions{MinVersion: testCase.minVersion}).config()
+			require.NoError(t, err)
+			sameCfg.RootCAs = certs
+			require.Same(t, transport, newTransport(sameCfg))
+			require.Equal(t, testCase.minVersion, httpTransport.TLSClientConfig.MinVersion)
+			// Proxy settings are preserved
+			require.NotNil(t, httpTransport.Proxy)
+			res, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if err == nil {
+				res.Body.Close()
+			}
+			testCase.assertions(t, err)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/akuity/kargo-render/internal/repourl"
+)
+
+// tracerName is the name of the tracer used to instrument this package.
+const tracerName = "github.com/akuity/kargo-render/internal/azuredevops"
+
+// Outcome describes the result of an attempt to open a PR.
+type Outcome string
+
+const (
+	// OutcomeCreated indicates that a new PR was opened.
+	OutcomeCreated Outcome = "created"
+	// OutcomeReused indicates that an existing PR was found and no new PR was
+	// opened.
+	OutcomeReused Outcome = "reused"
+	// OutcomeNoChange indicates that no PR was opened because there were no
+	// changes to propose.
+	OutcomeNoChange Outcome = "no-change"
+	// OutcomeError indicates that an error occurred.
+	OutcomeError Outcome = "error"
+)
+
+// outcomeOf derives an Outcome from the URL and error returned by OpenPR.
+func outcomeOf(url string, err error) Outcome {
+	switch {
+	case errors.Is(err, ErrNoChanges):
+		return OutcomeNoChange
+	case err != nil:
+		return OutcomeError
+	case url == "":
+		return OutcomeReused
+	default:
+		return OutcomeCreated
+	}
+}
+
+// startSpan starts a span for the named operation against the specified
+// repository using a tracer obtained from the specified provider. If the
+// provider is nil, the globally registered provider is used, which is a no-op
+// unless the embedding program has configured one.
+func startSpan(
+	ctx context.Context,
+	provider trace.TracerProvider,
+	operation string,
+	repoURL string,
+) (context.Context, trace.Span) {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	attrs := []attribute.KeyValue{attribute.String("provider", "azuredevops")}
+	if org, proj, repo, err :=
+		parseAzureDevOpsURL(repourl.Normalize(repoURL)); err == nil {
+		attrs = append(
+			attrs,
+			attribute.String("org", org),
+			attribute.String("project", proj),
+			attribute.String("repo", repo),
+		)
+	}
+	return provider.Tracer(tracerName).Start(
+		ctx,
+		"azuredevops."+operation,
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endSpan records the outcome of an attempt to open a PR, including any error,
+// on the specified span and ends it.
+func endSpan(span trace.Span, url string, err error) {
+	span.SetAttributes(attribute.String("outcome", string(outcomeOf(url, err))))
+	if err != nil && !errors.Is(err, ErrNoChanges) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
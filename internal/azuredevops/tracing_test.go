@@ -0,0 +1,108 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestOpenPRSpan(t *testing.T) {
+	testCases := []struct {
+		name       string
+		createErr  error
+		assertions func(t *testing.T, span sdktrace.ReadOnlySpan)
+	}{
+		{
+			name: "created",
+			assertions: func(t *testing.T, span sdktrace.ReadOnlySpan) {
+				require.Equal(t, codes.Unset, span.Status().Code)
+				require.Contains(
+					t,
+					span.Attributes(),
+					attribute.String("outcome", string(OutcomeCreated)),
+				)
+			},
+		},
+		{
+			name:      "error",
+			createErr: errors.New("something went wrong"),
+			assertions: func(t *testing.T, span sdktrace.ReadOnlySpan) {
+				require.Equal(t, codes.Error, span.Status().Code)
+				require.Contains(
+					t,
+					span.Attributes(),
+					attribute.String("outcome", string(OutcomeError)),
+				)
+				require.Len(t, span.Events(), 1)
+				require.Equal(t, "exception", span.Events()[0].Name)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				createPullRequestFn: func(
+					ctx context.Context,
+					args git.CreatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					if testCase.createErr != nil {
+						return nil, testCase.createErr
+					}
+					return fakeCreatePullRequest(nil)(ctx, args)
+				},
+			})
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			_, _ = OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "pat"},
+				&OpenPROptions{TracerProvider: provider},
+			)
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			span := spans[0]
+			require.Equal(t, "azuredevops.OpenPR", span.Name())
+			attrs := span.Attributes()
+			require.Contains(t, attrs, attribute.String("provider", "azuredevops"))
+			require.Contains(t, attrs, attribute.String("org", "org"))
+			require.Contains(t, attrs, attribute.String("project", "proj"))
+			require.Contains(t, attrs, attribute.String("repo", "repo"))
+			testCase.assertions(t, span)
+		})
+	}
+}
+
+func TestOpenPRWithoutTracer(t *testing.T) {
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn:   fakeRepos("repo"),
+		createPullRequestFn: fakeCreatePullRequest(nil),
+	})
+	// With no tracer configured, instrumentation must be a harmless no-op
+	url, err := OpenPR(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		"title",
+		"description",
+		"env/dev",
+		"prs/kargo-render/env/dev",
+		gitutil.RepoCredentials{Password: "pat"},
+		nil,
+	)
+	require.NoError(t, err)
+	require.NotEmpty(t, url)
+}
@@ -0,0 +1,207 @@
+package azuredevops
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/akuity/kargo-render/internal/repourl"
+)
+
+// urlShape describes one of the forms an Azure DevOps repository URL may
+// take.
+type urlShape struct {
+	// name identifies the shape in tests.
+	name string
+	// host is a substring of the host of any URL of this shape. URLs containing
+	// it that do not match pattern are reported as invalid rather than
+	// unsupported.
+	host string
+	// pattern matches URLs of this shape. It must have capture groups named
+	// org, project, and repo.
+	pattern *regexp.Regexp
+}
+
+// urlShapes are the supported shapes of Azure DevOps repository URLs, tried in
+// order. SSH URLs are normalized to the HTTPS form by repourl.Normalize before
+// they are parsed, so they need no entries of their own.
+var urlShapes = []urlShape{
+	{
+		name: "dev.azure.com",
+		host: "dev.azure.com",
+		pattern: regexp.MustCompile(
+			`^https?://(?:[^@/]+@)?dev\.azure\.com/(?P<org>[^/]+)/(?P<project>[^/]+)/[^/]+/(?P<repo>[^/]+)`,
+		),
+	},
+	{
+		name: "visualstudio.com",
+		host: ".visualstudio.com",
+		pattern: regexp.MustCompile(
+			`^https?://(?:[^@/]+@)?(?P<org>[^./]+)[^/]*\.visualstudio\.com/(?P<project>[^/]+)/[^/]+/(?P<repo>[^/]+)`,
+		),
+	},
+}
+
+// serverURLPattern matches the URLs of repositories hosted by Azure DevOps
+// Server, formerly Team Foundation Server, such as
+// https://tfs.example.com/tfs/DefaultCollection/proj/_git/repo, which may be
+// on any host. It is tried only after urlShapes. Collections serve in place of
+// organizations, so the collection's name is captured in a group named org and
+// its URL, which is the base URL of its API, in a group named collection.
+var serverURLPattern = regexp.MustCompile(
+	`^(?P<collection>https?://(?:[^@/]+@)?[^/]+(?:/[^/]+)*?/(?P<org>[^/]+))/(?P<project>[^/]+)/_git/(?P<repo>[^/]+)`,
+)
+
+// organizationURLPatterns match the URLs of Azure DevOps organizations, and of
+// anything within them, capturing the organization's name in a group named
+// org.
+var organizationURLPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^https?://(?:[^@/]+@)?dev\.azure\.com/(?P<org>[^/?#]+)`),
+	regexp.MustCompile(`^https?://(?:[^@/]+@)?(?P<org>[^./]+)[^/]*\.visualstudio\.com(?:[/?#]|$)`),
+}
+
+var (
+	errInvalidURL     = errors.New("invalid Azure DevOps repository URL format")
+	errUnsupportedURL = errors.New("unsupported Azure DevOps repository URL format")
+)
+
+// ErrNoRepoDefault is returned when a short repository reference omits an
+// organization or project for which no default is configured.
+var ErrNoRepoDefault = errors.New("no default configured for short repository reference")
+
+// RepoDefaults are the organization and project against which short
+// repository references, such as project/repo, are resolved.
+type RepoDefaults struct {
+	// Organization is the name of the organization of repositories referenced
+	// as project/repo or repo.
+	Organization string
+	// Project is the name of the project of repositories referenced as repo.
+	Project string
+}
+
+// ExpandRepoRef returns the URL of the Azure DevOps repository referenced by
+// the specified reference. URLs, including SSH URLs, are returned unchanged.
+// Otherwise, the reference is considered short and must take the form
+// org/project/repo, project/repo, or repo, with any omitted organization or
+// project taken from the specified defaults. If a default is needed, but not
+// configured, an error wrapping ErrNoRepoDefault is returned.
+func ExpandRepoRef(ref string, defaults RepoDefaults) (string, error) {
+	if ref == "" || strings.ContainsAny(ref, ":@") {
+		return ref, nil
+	}
+	segments := strings.Split(strings.Trim(ref, "/"), "/")
+	for _, segment := range segments {
+		if segment == "" {
+			return "", fmt.Errorf("%w: short repository reference %q", errInvalidURL, ref)
+		}
+	}
+	switch len(segments) {
+	case 1:
+		if defaults.Project == "" {
+			return "", fmt.Errorf(
+				"%w: short repository reference %q requires a default project",
+				ErrNoRepoDefault,
+				ref,
+			)
+		}
+		segments = append([]string{defaults.Project}, segments...)
+		fallthrough
+	case 2:
+		if defaults.Organization == "" {
+			return "", fmt.Errorf(
+				"%w: short repository reference %q requires a default organization",
+				ErrNoRepoDefault,
+				ref,
+			)
+		}
+		segments = append([]string{defaults.Organization}, segments...)
+	case 3:
+	default:
+		return "", fmt.Errorf(
+			"%w: short repository reference %q has too many segments",
+			errInvalidURL,
+			ref,
+		)
+	}
+	return fmt.Sprintf(
+		"https://dev.azure.com/%s/%s/_git/%s",
+		segments[0],
+		segments[1],
+		segments[2],
+	), nil
+}
+
+// ParseRepoURL parses an Azure DevOps repository URL, which may be an HTTPS or
+// SSH URL, and returns the names of the organization, project, and repository
+// it references. For repositories hosted by Azure DevOps Server, the name of
+// the collection is returned in place of that of the organization.
+func ParseRepoURL(repoURL string) (org, project, repo string, err error) {
+	return parseAzureDevOpsURL(repourl.Normalize(repoURL))
+}
+
+// parseAzureDevOpsURL parses an Azure DevOps repository URL and returns
+// organization, project, and repository names. For repositories hosted by
+// Azure DevOps Server, the collection's name is returned as the organization's.
+// Any query string or fragment, such as those of URLs copied from the web UI,
+// and any .git suffix are ignored.
+func parseAzureDevOpsURL(repoURL string) (org, proj, repo string, err error) {
+	repoURL = trimQueryAndFragment(repoURL)
+	for _, shape := range urlShapes {
+		if !strings.Contains(repoURL, shape.host) {
+			continue
+		}
+		match := shape.pattern.FindStringSubmatch(repoURL)
+		if match == nil {
+			return "", "", "", errInvalidURL
+		}
+		return match[shape.pattern.SubexpIndex("org")],
+			match[shape.pattern.SubexpIndex("project")],
+			strings.TrimSuffix(match[shape.pattern.SubexpIndex("repo")], ".git"),
+			nil
+	}
+	if match := serverURLPattern.FindStringSubmatch(repoURL); match != nil {
+		return match[serverURLPattern.SubexpIndex("org")],
+			match[serverURLPattern.SubexpIndex("project")],
+			strings.TrimSuffix(match[serverURLPattern.SubexpIndex("repo")], ".git"),
+			nil
+	}
+	return "", "", "", errUnsupportedURL
+}
+
+// serverCollectionURL returns the URL, without any credentials, of the Azure
+// DevOps Server collection hosting the repository at the specified URL or an
+// empty string if the repository is not hosted by Azure DevOps Server.
+func serverCollectionURL(repoURL string) string {
+	repoURL = trimQueryAndFragment(repoURL)
+	for _, shape := range urlShapes {
+		if strings.Contains(repoURL, shape.host) {
+			return ""
+		}
+	}
+	match := serverURLPattern.FindStringSubmatch(repoURL)
+	if match == nil {
+		return ""
+	}
+	return withoutCredentials(match[serverURLPattern.SubexpIndex("collection")])
+}
+
+// trimQueryAndFragment returns the specified URL without any query string or
+// fragment.
+func trimQueryAndFragment(rawURL string) string {
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		return rawURL[:i]
+	}
+	return rawURL
+}
+
+// parseOrganizationURL parses the URL of an Azure DevOps organization, or of
+// anything within one, and returns the organization's name.
+func parseOrganizationURL(orgURL string) (string, error) {
+	for _, pattern := range organizationURLPatterns {
+		if match := pattern.FindStringSubmatch(orgURL); match != nil {
+			return match[pattern.SubexpIndex("org")], nil
+		}
+	}
+	return "", fmt.Errorf("unsupported Azure DevOps organization URL %q", orgURL)
+}
@@ -0,0 +1,58 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+)
+
+// defaultVisibilityTimeout is the maximum amount of time to wait for a newly
+// created PR to become visible when no timeout is specified.
+const defaultVisibilityTimeout = 10 * time.Second
+
+// visibilityPollInterval is the amount of time to wait between attempts to
+// retrieve a newly created PR. It is a package-level variable so that it can
+// be overridden in tests.
+var visibilityPollInterval = 500 * time.Millisecond
+
+// waitUntilVisible polls the specified PR until Azure DevOps returns it, the
+// specified timeout elapses, or the context is canceled. Any error other than
+// the PR not being found is returned immediately.
+func waitUntilVisible(
+	ctx context.Context,
+	repo *repoClient,
+	prID int,
+	timeout time.Duration,
+) error {
+	if timeout <= 0 {
+		timeout = defaultVisibilityTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		pr, err := repo.client.GetPullRequest(ctx, git.GetPullRequestArgs{
+			Project:       &repo.project,
+			RepositoryId:  &repo.id,
+			PullRequestId: &prID,
+		})
+		if code, ok := statusCodeOf(err); err != nil &&
+			(!ok || code != http.StatusNotFound) {
+			return fmt.Errorf("error getting pull request %d: %w", prID, err)
+		}
+		if err == nil && pr != nil {
+			return nil
+		}
+		select {
+		case <-time.After(visibilityPollInterval):
+		case <-ctx.Done():
+			return fmt.Errorf(
+				"error waiting for pull request %d to become visible: %w",
+				prID,
+				ctx.Err(),
+			)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestOpenPRWaitUntilVisible(t *testing.T) {
+	notFound := azuredevops.WrappedError{StatusCode: ptr(http.StatusNotFound)}
+	testCases := []struct {
+		name       string
+		notFound   int
+		getErr     error
+		timeout    time.Duration
+		assertions func(t *testing.T, url string, gets int, err error)
+	}{
+		{
+			name:     "visible after initial not found",
+			notFound: 2,
+			assertions: func(t *testing.T, url string, gets int, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, url)
+				require.Equal(t, 3, gets)
+			},
+		},
+		{
+			name:     "never visible",
+			notFound: -1,
+			timeout:  20 * time.Millisecond,
+			assertions: func(t *testing.T, url string, _ int, err error) {
+				require.ErrorIs(t, err, context.DeadlineExceeded)
+				require.ErrorContains(t, err, "to become visible")
+				// The PR was still created
+				require.NotEmpty(t, url)
+			},
+		},
+		{
+			name:   "other errors are returned immediately",
+			getErr: errors.New("something went wrong"),
+			assertions: func(t *testing.T, url string, gets int, err error) {
+				require.ErrorContains(t, err, "something went wrong")
+				require.NotEmpty(t, url)
+				require.Equal(t, 1, gets)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			origInterval := visibilityPollInterval
+			visibilityPollInterval = time.Millisecond
+			t.Cleanup(func() { visibilityPollInterval = origInterval })
+			var gets int
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn:   fakeRepos("repo"),
+				createPullRequestFn: fakeCreatePullRequest(nil),
+				getPullRequestFn: func(
+					_ context.Context,
+					args git.GetPullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					gets++
+					require.Equal(t, 42, *args.PullRequestId)
+					if testCase.getErr != nil {
+						return nil, testCase.getErr
+					}
+					if testCase.notFound < 0 || gets <= testCase.notFound {
+						return nil, notFound
+					}
+					return &git.GitPullRequest{PullRequestId: args.PullRequestId}, nil
+				},
+			})
+			url, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "token"},
+				&OpenPROptions{
+					WaitUntilVisible:  true,
+					VisibilityTimeout: testCase.timeout,
+				},
+			)
+			testCase.assertions(t, url, gets, err)
+		})
+	}
+}
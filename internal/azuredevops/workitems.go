@@ -0,0 +1,189 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+)
+
+// newWorkItemClient creates an Azure DevOps Work Item Tracking client. It is a
+// package-level variable so that it can be overridden in tests.
+var newWorkItemClient = workitemtracking.NewClient
+
+const (
+	iterationPathField = "/fields/System.IterationPath"
+	areaPathField      = "/fields/System.AreaPath"
+)
+
+// workItemPathUpdates returns JSON patch operations moving a work item to the
+// iteration and area paths specified by the auto-complete options, if any.
+func workItemPathUpdates(opts AutoCompleteOptions) []webapi.JsonPatchOperation {
+	var ops []webapi.JsonPatchOperation
+	for _, update := range []struct{ field, value string }{
+		{iterationPathField, opts.WorkItemIterationPath},
+		{areaPathField, opts.WorkItemAreaPath},
+	} {
+		if update.value == "" {
+			continue
+		}
+		ops = append(ops, webapi.JsonPatchOperation{
+			Op:    &webapi.OperationValues.Add,
+			Path:  &update.field,
+			Value: update.value,
+		})
+	}
+	return ops
+}
+
+// validateWorkItemPaths returns an error if the auto-complete options specify
+// an iteration or area path for linked work items that does not exist in the
+// repository's project.
+func validateWorkItemPaths(
+	ctx context.Context,
+	repo *repoClient,
+	opts AutoCompleteOptions,
+) error {
+	if len(workItemPathUpdates(opts)) == 0 {
+		return nil
+	}
+	if !opts.TransitionWorkItems {
+		return fmt.Errorf(
+			"work item iteration and area paths can only be set when " +
+				"transitioning work items",
+		)
+	}
+	client, err := newWorkItemClient(ctx, repo.connection)
+	if err != nil {
+		return fmt.Errorf("error creating Azure DevOps Work Item Tracking client: %w", err)
+	}
+	for _, node := range []struct {
+		kind  string
+		group workitemtracking.TreeStructureGroup
+		path  string
+	}{
+		{"iteration", workitemtracking.TreeStructureGroupValues.Iterations, opts.WorkItemIterationPath},
+		{"area", workitemtracking.TreeStructureGroupValues.Areas, opts.WorkItemAreaPath},
+	} {
+		if node.path == "" {
+			continue
+		}
+		relPath, ok := classificationNodePath(repo.project, node.path)
+		if !ok {
+			return fmt.Errorf(
+				"%s path '%s' is not in project '%s'",
+				node.kind,
+				node.path,
+				repo.project,
+			)
+		}
+		args := workitemtracking.GetClassificationNodeArgs{
+			Project:        &repo.project,
+			StructureGroup: &node.group,
+		}
+		if relPath != "" {
+			args.Path = &relPath
+		}
+		if _, err = read(ctx, repo.retry, func(ctx context.Context) (*workitemtracking.WorkItemClassificationNode, error) {
+			return client.GetClassificationNode(ctx, args)
+		}); err != nil {
+			return fmt.Errorf("error validating %s path '%s': %w", node.kind, node.path, err)
+		}
+	}
+	return nil
+}
+
+// classificationNodePath converts an iteration or area path, which is rooted
+// at the project, e.g. `proj\Sprint 1`, to the path of its classification
+// node relative to the project's root node, e.g. `Sprint 1`. Classification
+// node paths are part of the REST route, so their segments are separated by
+// slashes rather than backslashes. It returns false if the path is not rooted
+// at the specified project.
+func classificationNodePath(project, path string) (string, bool) {
+	root, rest, _ := strings.Cut(strings.Trim(path, `\`), `\`)
+	if !strings.EqualFold(root, project) {
+		return "", false
+	}
+	return strings.ReplaceAll(rest, `\`, "/"), true
+}
+
+// moveLinkedWorkItemsOnCompletion waits for the specified PR to be completed
+// and then moves its linked work items as moveLinkedWorkItems does. It returns
+// immediately if the auto-complete options specify no iteration or area path.
+func moveLinkedWorkItemsOnCompletion(
+	ctx context.Context,
+	repo *repoClient,
+	prID int,
+	opts AutoCompleteOptions,
+) error {
+	if len(workItemPathUpdates(opts)) == 0 {
+		return nil
+	}
+	if _, err := awaitCompletion(ctx, repo, prID); err != nil {
+		return err
+	}
+	if err := moveLinkedWorkItems(ctx, repo, prID, opts); err != nil {
+		return fmt.Errorf(
+			"pull request %d was completed, but an error occurred moving its linked work items: %w",
+			prID,
+			err,
+		)
+	}
+	return nil
+}
+
+// moveLinkedWorkItems moves the work items linked to the specified PR to the
+// iteration and area paths specified by the auto-complete options, if any.
+// Azure DevOps offers no means of doing this as part of the transition that
+// occurs when the PR is completed, so it must only be done once the PR is
+// known to have been completed, lest work items be moved for a PR that is
+// never merged.
+func moveLinkedWorkItems(
+	ctx context.Context,
+	repo *repoClient,
+	prID int,
+	opts AutoCompleteOptions,
+) error {
+	ops := workItemPathUpdates(opts)
+	if len(ops) == 0 {
+		return nil
+	}
+	refs, err := repo.client.GetPullRequestWorkItemRefs(ctx, git.GetPullRequestWorkItemRefsArgs{
+		Project:       &repo.project,
+		RepositoryId:  &repo.id,
+		PullRequestId: &prID,
+	})
+	if err != nil {
+		return fmt.Errorf("error listing work items linked to pull request %d: %w", prID, err)
+	}
+	if refs == nil || len(*refs) == 0 {
+		return nil
+	}
+	client, err := newWorkItemClient(ctx, repo.connection)
+	if err != nil {
+		return fmt.Errorf("error creating Azure DevOps Work Item Tracking client: %w", err)
+	}
+	for _, ref := range *refs {
+		if ref.Id == nil {
+			return fmt.Errorf("%w: linked work item has no ID", ErrIncompleteResponse)
+		}
+		id, err := strconv.Atoi(*ref.Id)
+		if err != nil {
+			return fmt.Errorf("error parsing work item ID %q: %w", *ref.Id, err)
+		}
+		if _, err = write(ctx, repo.retry, func(ctx context.Context) (*workitemtracking.WorkItem, error) {
+			return client.UpdateWorkItem(ctx, workitemtracking.UpdateWorkItemArgs{
+				Project:  &repo.project,
+				Id:       &id,
+				Document: &ops,
+			})
+		}); err != nil {
+			return fmt.Errorf("error updating work item %d: %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,436 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/policy"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// fakeWorkItemClient is a fake implementation of the workitemtracking.Client
+// interface. Only the methods whose corresponding function fields are set may
+// be called.
+type fakeWorkItemClient struct {
+	workitemtracking.Client
+	getClassificationNodeFn func(
+		context.Context,
+		workitemtracking.GetClassificationNodeArgs,
+	) (*workitemtracking.WorkItemClassificationNode, error)
+	updateWorkItemFn func(
+		context.Context,
+		workitemtracking.UpdateWorkItemArgs,
+	) (*workitemtracking.WorkItem, error)
+}
+
+func (f *fakeWorkItemClient) GetClassificationNode(
+	ctx context.Context,
+	args workitemtracking.GetClassificationNodeArgs,
+) (*workitemtracking.WorkItemClassificationNode, error) {
+	return f.getClassificationNodeFn(ctx, args)
+}
+
+func (f *fakeWorkItemClient) UpdateWorkItem(
+	ctx context.Context,
+	args workitemtracking.UpdateWorkItemArgs,
+) (*workitemtracking.WorkItem, error) {
+	return f.updateWorkItemFn(ctx, args)
+}
+
+// useFakeWorkItemClient arranges for the specified fake to be used as the
+// Work Item Tracking client for the duration of the test.
+func useFakeWorkItemClient(t *testing.T, client *fakeWorkItemClient) {
+	orig := newWorkItemClient
+	newWorkItemClient = func(
+		context.Context,
+		*azuredevops.Connection,
+	) (workitemtracking.Client, error) {
+		return client, nil
+	}
+	t.Cleanup(func() { newWorkItemClient = orig })
+}
+
+func TestOpenPRWorkItemTransition(t *testing.T) {
+	testCases := []struct {
+		name       string
+		opts       AutoCompleteOptions
+		assertions func(
+			t *testing.T,
+			completion *git.GitPullRequestCompletionOptions,
+			nodes map[string]string,
+			updates map[int][]webapi.JsonPatchOperation,
+			err error,
+		)
+	}{
+		{
+			name: "transition without paths",
+			opts: AutoCompleteOptions{TransitionWorkItems: true},
+			assertions: func(
+				t *testing.T,
+				completion *git.GitPullRequestCompletionOptions,
+				nodes map[string]string,
+				updates map[int][]webapi.JsonPatchOperation,
+				err error,
+			) {
+				require.NoError(t, err)
+				require.True(t, *completion.TransitionWorkItems)
+				require.Empty(t, nodes)
+				require.Empty(t, updates)
+			},
+		},
+		{
+			name: "transition with paths",
+			opts: AutoCompleteOptions{
+				TransitionWorkItems:   true,
+				WorkItemIterationPath: `proj\Sprint 1`,
+				WorkItemAreaPath:      `proj\Team`,
+			},
+			assertions: func(
+				t *testing.T,
+				completion *git.GitPullRequestCompletionOptions,
+				nodes map[string]string,
+				updates map[int][]webapi.JsonPatchOperation,
+				err error,
+			) {
+				require.NoError(t, err)
+				require.True(t, *completion.TransitionWorkItems)
+				require.Equal(
+					t,
+					map[string]string{"iterations": "Sprint 1", "areas": "Team"},
+					nodes,
+				)
+				// Work items are only moved once the PR is known to be completed.
+				require.Empty(t, updates)
+			},
+		},
+		{
+			name: "nested paths",
+			opts: AutoCompleteOptions{
+				TransitionWorkItems:   true,
+				WorkItemIterationPath: `proj\Release 1\Sprint 2`,
+				WorkItemAreaPath:      `\proj\Platform\Team\`,
+			},
+			assertions: func(
+				t *testing.T,
+				_ *git.GitPullRequestCompletionOptions,
+				nodes map[string]string,
+				_ map[int][]webapi.JsonPatchOperation,
+				err error,
+			) {
+				require.NoError(t, err)
+				require.Equal(
+					t,
+					map[string]string{
+						"iterations": "Release 1/Sprint 2",
+						"areas":      "Platform/Team",
+					},
+					nodes,
+				)
+			},
+		},
+		{
+			name: "paths without transition",
+			opts: AutoCompleteOptions{WorkItemAreaPath: `proj\Team`},
+			assertions: func(
+				t *testing.T,
+				completion *git.GitPullRequestCompletionOptions,
+				_ map[string]string,
+				_ map[int][]webapi.JsonPatchOperation,
+				err error,
+			) {
+				require.ErrorContains(t, err, "only be set when transitioning")
+				require.Nil(t, completion)
+			},
+		},
+		{
+			name: "path in another project",
+			opts: AutoCompleteOptions{
+				TransitionWorkItems:   true,
+				WorkItemIterationPath: `other\Sprint 1`,
+			},
+			assertions: func(
+				t *testing.T,
+				completion *git.GitPullRequestCompletionOptions,
+				_ map[string]string,
+				_ map[int][]webapi.JsonPatchOperation,
+				err error,
+			) {
+				require.ErrorContains(t, err, "is not in project 'proj'")
+				require.Nil(t, completion)
+			},
+		},
+		{
+			name: "path does not exist",
+			opts: AutoCompleteOptions{
+				TransitionWorkItems:   true,
+				WorkItemIterationPath: `proj\Missing`,
+			},
+			assertions: func(
+				t *testing.T,
+				completion *git.GitPullRequestCompletionOptions,
+				_ map[string]string,
+				updates map[int][]webapi.JsonPatchOperation,
+				err error,
+			) {
+				require.ErrorContains(t, err, `error validating iteration path 'proj\Missing'`)
+				require.Nil(t, completion)
+				require.Empty(t, updates)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var completion *git.GitPullRequestCompletionOptions
+			nodes := map[string]string{}
+			updates := map[int][]webapi.JsonPatchOperation{}
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn:   fakeRepos("repo"),
+				createPullRequestFn: fakeCreatePullRequest(nil),
+				updatePullRequestFn: func(
+					_ context.Context,
+					args git.UpdatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					completion = args.GitPullRequestToUpdate.CompletionOptions
+					return args.GitPullRequestToUpdate, nil
+				},
+				getPullRequestWorkItemRefsFn: func(
+					_ context.Context,
+					args git.GetPullRequestWorkItemRefsArgs,
+				) (*[]webapi.ResourceRef, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					return &[]webapi.ResourceRef{{Id: ptr("7")}, {Id: ptr("8")}}, nil
+				},
+			})
+			useFakeWorkItemClient(t, &fakeWorkItemClient{
+				getClassificationNodeFn: func(
+					_ context.Context,
+					args workitemtracking.GetClassificationNodeArgs,
+				) (*workitemtracking.WorkItemClassificationNode, error) {
+					if *args.Path == "Missing" {
+						return nil, errors.New("node not found")
+					}
+					nodes[string(*args.StructureGroup)] = *args.Path
+					return &workitemtracking.WorkItemClassificationNode{}, nil
+				},
+				updateWorkItemFn: func(
+					_ context.Context,
+					args workitemtracking.UpdateWorkItemArgs,
+				) (*workitemtracking.WorkItem, error) {
+					updates[*args.Id] = *args.Document
+					return &workitemtracking.WorkItem{}, nil
+				},
+			})
+			opts := testCase.opts
+			opts.SetByID = "service-identity-id"
+			_, err := OpenPR(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				"title",
+				"description",
+				"env/dev",
+				"prs/kargo-render/env/dev",
+				gitutil.RepoCredentials{Password: "token"},
+				&OpenPROptions{AutoComplete: &opts},
+			)
+			testCase.assertions(t, completion, nodes, updates, err)
+		})
+	}
+}
+
+func TestCompleteAndWaitMovesWorkItems(t *testing.T) {
+	origInterval := completionPollInterval
+	completionPollInterval = 0
+	t.Cleanup(func() { completionPollInterval = origInterval })
+
+	completed := git.GitPullRequest{
+		PullRequestId:   ptr(42),
+		Status:          &git.PullRequestStatusValues.Completed,
+		LastMergeCommit: &git.GitCommitRef{CommitId: ptr("merge")},
+	}
+	abandoned := git.GitPullRequest{
+		PullRequestId: ptr(42),
+		Status:        &git.PullRequestStatusValues.Abandoned,
+	}
+	testCases := []struct {
+		name       string
+		polled     git.GitPullRequest
+		updateErr  error
+		assertions func(t *testing.T, commit string, updates []int, err error)
+	}{
+		{
+			name:   "merged",
+			polled: completed,
+			assertions: func(t *testing.T, commit string, updates []int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "merge", commit)
+				require.Equal(t, []int{7}, updates)
+			},
+		},
+		{
+			name:   "abandoned",
+			polled: abandoned,
+			assertions: func(t *testing.T, commit string, updates []int, err error) {
+				require.ErrorIs(t, err, ErrPRNotActive)
+				require.Empty(t, commit)
+				require.Empty(t, updates)
+			},
+		},
+		{
+			name:      "merged but moving work items fails",
+			polled:    completed,
+			updateErr: errors.New("something went wrong"),
+			assertions: func(t *testing.T, commit string, _ []int, err error) {
+				require.ErrorContains(t, err, "pull request 42 was merged, but an error occurred")
+				require.ErrorContains(t, err, "something went wrong")
+				require.Equal(t, "merge", commit)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var completing bool
+			var updates []int
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: fakeRepos("repo"),
+				getPullRequestFn: func(
+					context.Context,
+					git.GetPullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					if !completing {
+						return &git.GitPullRequest{
+							PullRequestId: ptr(42),
+							Status:        &git.PullRequestStatusValues.Active,
+						}, nil
+					}
+					pr := testCase.polled
+					return &pr, nil
+				},
+				updatePullRequestFn: func(
+					_ context.Context,
+					args git.UpdatePullRequestArgs,
+				) (*git.GitPullRequest, error) {
+					require.Empty(t, updates, "work items moved before completion")
+					completing = true
+					return args.GitPullRequestToUpdate, nil
+				},
+				getPullRequestWorkItemRefsFn: func(
+					context.Context,
+					git.GetPullRequestWorkItemRefsArgs,
+				) (*[]webapi.ResourceRef, error) {
+					return &[]webapi.ResourceRef{{Id: ptr("7")}}, nil
+				},
+			})
+			useFakeWorkItemClient(t, &fakeWorkItemClient{
+				updateWorkItemFn: func(
+					_ context.Context,
+					args workitemtracking.UpdateWorkItemArgs,
+				) (*workitemtracking.WorkItem, error) {
+					if testCase.updateErr != nil {
+						return nil, testCase.updateErr
+					}
+					updates = append(updates, *args.Id)
+					return &workitemtracking.WorkItem{}, nil
+				},
+			})
+			commit, err := CompleteAndWait(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				42,
+				AutoCompleteOptions{
+					TransitionWorkItems:   true,
+					WorkItemIterationPath: `proj\Sprint 1`,
+				},
+				0,
+				gitutil.RepoCredentials{Password: "token"},
+			)
+			testCase.assertions(t, commit, updates, err)
+		})
+	}
+}
+
+func TestCompleteAfterApprovalMovesWorkItemsOnCompletion(t *testing.T) {
+	origInterval := completionPollInterval
+	completionPollInterval = 0
+	t.Cleanup(func() { completionPollInterval = origInterval })
+
+	var autoCompleted bool
+	var polls int
+	var updates []int
+	useFakeIdentity(t, uuid.New())
+	useFakeGitClient(t, &fakeGitClient{
+		getRepositoriesFn: fakeRepos("repo"),
+		getPullRequestFn: func(
+			context.Context,
+			git.GetPullRequestArgs,
+		) (*git.GitPullRequest, error) {
+			pr := &git.GitPullRequest{
+				PullRequestId: ptr(42),
+				Status:        &git.PullRequestStatusValues.Active,
+				TargetRefName: ptr("refs/heads/env/prod"),
+			}
+			if autoCompleted {
+				polls++
+				if polls > 1 {
+					pr.Status = &git.PullRequestStatusValues.Completed
+				}
+			}
+			return pr, nil
+		},
+		getPolicyConfigurationsFn: func(
+			context.Context,
+			git.GetPolicyConfigurationsArgs,
+		) (*git.GitPolicyConfigurationResponse, error) {
+			return &git.GitPolicyConfigurationResponse{
+				PolicyConfigurations: &[]policy.PolicyConfiguration{{
+					IsEnabled:  ptr(true),
+					IsBlocking: ptr(true),
+					Type:       &policy.PolicyTypeRef{Id: ptr(minimumReviewersPolicyTypeID)},
+				}},
+			}, nil
+		},
+		updatePullRequestFn: func(
+			_ context.Context,
+			args git.UpdatePullRequestArgs,
+		) (*git.GitPullRequest, error) {
+			autoCompleted = true
+			return args.GitPullRequestToUpdate, nil
+		},
+		getPullRequestWorkItemRefsFn: func(
+			context.Context,
+			git.GetPullRequestWorkItemRefsArgs,
+		) (*[]webapi.ResourceRef, error) {
+			require.Equal(t, 2, polls, "work items moved before completion")
+			return &[]webapi.ResourceRef{{Id: ptr("7")}}, nil
+		},
+	})
+	useFakeWorkItemClient(t, &fakeWorkItemClient{
+		updateWorkItemFn: func(
+			_ context.Context,
+			args workitemtracking.UpdateWorkItemArgs,
+		) (*workitemtracking.WorkItem, error) {
+			updates = append(updates, *args.Id)
+			return &workitemtracking.WorkItem{}, nil
+		},
+	})
+	err := CompleteAfterApproval(
+		context.Background(),
+		"https://dev.azure.com/org/proj/_git/repo",
+		42,
+		gitutil.RepoCredentials{Password: "token"},
+		AutoCompleteOptions{
+			TransitionWorkItems: true,
+			WorkItemAreaPath:    `proj\Team`,
+		},
+	)
+	require.NoError(t, err)
+	require.True(t, autoCompleted)
+	require.Equal(t, []int{7}, updates)
+}
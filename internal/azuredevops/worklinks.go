@@ -0,0 +1,179 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+// maxWorkItemConcurrency is the maximum number of work items LinkWorkItems
+// links concurrently.
+const maxWorkItemConcurrency = 8
+
+// LinkWorkItemsError is returned by LinkWorkItems when some of the work items
+// could not be linked.
+type LinkWorkItemsError struct {
+	// PRID is the ID of the PR the work items were to be linked to.
+	PRID int
+	// Failures maps the IDs of the work items that could not be linked to the
+	// errors encountered linking them.
+	Failures map[int]error
+}
+
+func (e *LinkWorkItemsError) Error() string {
+	ids := make([]int, 0, len(e.Failures))
+	for id := range e.Failures {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	msgs := make([]string, len(ids))
+	for i, id := range ids {
+		msgs[i] = fmt.Sprintf("work item %d: %s", id, e.Failures[id])
+	}
+	return fmt.Sprintf(
+		"error linking %d work item(s) to pull request %d: %s",
+		len(ids),
+		e.PRID,
+		strings.Join(msgs, "; "),
+	)
+}
+
+func (e *LinkWorkItemsError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, err := range e.Failures {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// LinkWorkItems links the work items with the specified IDs to the specified
+// PR. Work items that are already linked to the PR are skipped. Azure DevOps
+// requires each work item to be updated separately, so the remaining work
+// items are linked concurrently, up to a bound. If any of them cannot be
+// linked, the others are linked nonetheless and a *LinkWorkItemsError
+// identifying each failure is returned.
+func LinkWorkItems(
+	ctx context.Context,
+	repoURL string,
+	prID int,
+	workItemIDs []int,
+	creds gitutil.RepoCredentials,
+) (err error) {
+	defer func() { err = redactError(err, []string{creds.Password}, nil) }()
+	repo, err := newRepoClient(ctx, repoURL, creds, nil)
+	if err != nil {
+		return err
+	}
+	details, err := repo.details(ctx)
+	if err != nil {
+		return err
+	}
+	if details.Project == nil || details.Project.Id == nil {
+		return fmt.Errorf(
+			"%w: repository %q does not identify its project",
+			ErrIncompleteResponse,
+			repo.name,
+		)
+	}
+	ids, err := unlinkedWorkItems(ctx, repo, prID, workItemIDs)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	client, err := newWorkItemClient(ctx, repo.connection)
+	if err != nil {
+		return fmt.Errorf("error creating Azure DevOps Work Item Tracking client: %w", err)
+	}
+	path, rel := "/relations/-", "ArtifactLink"
+	uri := pullRequestArtifactURI(details.Project.Id.String(), repo.id, prID)
+	ops := []webapi.JsonPatchOperation{{
+		Op:   &webapi.OperationValues.Add,
+		Path: &path,
+		Value: workitemtracking.WorkItemRelation{
+			Rel:        &rel,
+			Url:        &uri,
+			Attributes: &map[string]any{"name": "Pull Request"},
+		},
+	}}
+
+	var mu sync.Mutex
+	failures := map[int]error{}
+	sem := make(chan struct{}, maxWorkItemConcurrency)
+	wg := sync.WaitGroup{}
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if _, err := write(ctx, repo.retry, func(ctx context.Context) (*workitemtracking.WorkItem, error) {
+				return client.UpdateWorkItem(ctx, workitemtracking.UpdateWorkItemArgs{
+					Project:  &repo.project,
+					Id:       &id,
+					Document: &ops,
+				})
+			}); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				failures[id] = err
+			}
+		}(id)
+	}
+	wg.Wait()
+	if len(failures) > 0 {
+		return &LinkWorkItemsError{PRID: prID, Failures: failures}
+	}
+	return nil
+}
+
+// unlinkedWorkItems returns the distinct IDs, among those specified, of work
+// items that are not already linked to the specified PR.
+func unlinkedWorkItems(
+	ctx context.Context,
+	repo *repoClient,
+	prID int,
+	workItemIDs []int,
+) ([]int, error) {
+	refs, err := repo.client.GetPullRequestWorkItemRefs(ctx, git.GetPullRequestWorkItemRefsArgs{
+		Project:       &repo.project,
+		RepositoryId:  &repo.id,
+		PullRequestId: &prID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing work items linked to pull request %d: %w", prID, err)
+	}
+	linked := map[int]struct{}{}
+	if refs != nil {
+		for _, ref := range *refs {
+			if ref.Id == nil {
+				continue
+			}
+			if id, err := strconv.Atoi(*ref.Id); err == nil {
+				linked[id] = struct{}{}
+			}
+		}
+	}
+	var ids []int
+	for _, id := range workItemIDs {
+		if _, ok := linked[id]; ok {
+			continue
+		}
+		linked[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// pullRequestArtifactURI returns the URI by which work item relations refer
+// to the specified PR.
+func pullRequestArtifactURI(projectID string, repoID string, prID int) string {
+	return fmt.Sprintf("vstfs:///Git/PullRequestId/%s%%2F%s%%2F%d", projectID, repoID, prID)
+}
@@ -0,0 +1,142 @@
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/workitemtracking"
+	"github.com/stretchr/testify/require"
+
+	gitutil "github.com/akuity/kargo-render/pkg/git"
+)
+
+func TestLinkWorkItems(t *testing.T) {
+	repoID := uuid.New()
+	projectID := uuid.New()
+	testCases := []struct {
+		name        string
+		workItemIDs []int
+		failing     map[int]bool
+		assertions  func(t *testing.T, updated map[int]string, err error)
+	}{
+		{
+			name:        "all linked",
+			workItemIDs: []int{1, 2, 3, 2},
+			assertions: func(t *testing.T, updated map[int]string, err error) {
+				require.NoError(t, err)
+				require.Len(t, updated, 3)
+				uri := fmt.Sprintf("vstfs:///Git/PullRequestId/%s%%2F%s%%2F42", projectID, repoID)
+				for _, id := range []int{1, 2, 3} {
+					require.Equal(t, uri, updated[id])
+				}
+			},
+		},
+		{
+			name:        "already linked work items are skipped",
+			workItemIDs: []int{7, 1},
+			assertions: func(t *testing.T, updated map[int]string, err error) {
+				require.NoError(t, err)
+				require.Len(t, updated, 1)
+				require.Contains(t, updated, 1)
+			},
+		},
+		{
+			name:        "nothing to link",
+			workItemIDs: []int{7},
+			assertions: func(t *testing.T, updated map[int]string, err error) {
+				require.NoError(t, err)
+				require.Empty(t, updated)
+			},
+		},
+		{
+			name:        "partial failure",
+			workItemIDs: []int{1, 2, 3, 4},
+			failing:     map[int]bool{2: true, 4: true},
+			assertions: func(t *testing.T, updated map[int]string, err error) {
+				linkErr := &LinkWorkItemsError{}
+				require.ErrorAs(t, err, &linkErr)
+				require.Equal(t, 42, linkErr.PRID)
+				require.Len(t, linkErr.Failures, 2)
+				require.Contains(t, linkErr.Failures, 2)
+				require.Contains(t, linkErr.Failures, 4)
+				require.ErrorContains(t, err, "error linking 2 work item(s) to pull request 42")
+				require.ErrorContains(t, err, "work item 2: work item is locked; work item 4")
+				require.NotContains(t, err.Error(), "token")
+				require.Len(t, updated, 2)
+				require.Contains(t, updated, 1)
+				require.Contains(t, updated, 3)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			name := "repo"
+			useFakeGitClient(t, &fakeGitClient{
+				getRepositoriesFn: func(
+					context.Context,
+					git.GetRepositoriesArgs,
+				) (*[]git.GitRepository, error) {
+					return &[]git.GitRepository{{
+						Id:      &repoID,
+						Name:    &name,
+						Project: &core.TeamProjectReference{Id: &projectID},
+					}}, nil
+				},
+				getPullRequestWorkItemRefsFn: func(
+					_ context.Context,
+					args git.GetPullRequestWorkItemRefsArgs,
+				) (*[]webapi.ResourceRef, error) {
+					require.Equal(t, 42, *args.PullRequestId)
+					return &[]webapi.ResourceRef{{Id: ptr("7")}}, nil
+				},
+			})
+			var mu sync.Mutex
+			updated := map[int]string{}
+			orig := newWorkItemClient
+			newWorkItemClient = func(
+				context.Context,
+				*azuredevops.Connection,
+			) (workitemtracking.Client, error) {
+				return &fakeWorkItemClient{
+					updateWorkItemFn: func(
+						_ context.Context,
+						args workitemtracking.UpdateWorkItemArgs,
+					) (*workitemtracking.WorkItem, error) {
+						if testCase.failing[*args.Id] {
+							return nil, errors.New("work item is locked")
+						}
+						// This runs concurrently, so anything unexpected is recorded
+						// for the assertions rather than failing the test here
+						var uri string
+						ops := *args.Document
+						if rel, ok := ops[0].Value.(workitemtracking.WorkItemRelation); ok &&
+							len(ops) == 1 && *ops[0].Path == "/relations/-" && *rel.Rel == "ArtifactLink" {
+							uri = *rel.Url
+						}
+						mu.Lock()
+						defer mu.Unlock()
+						updated[*args.Id] = uri
+						return &workitemtracking.WorkItem{}, nil
+					},
+				}, nil
+			}
+			t.Cleanup(func() { newWorkItemClient = orig })
+			err := LinkWorkItems(
+				context.Background(),
+				"https://dev.azure.com/org/proj/_git/repo",
+				42,
+				testCase.workItemIDs,
+				gitutil.RepoCredentials{Password: "token"},
+			)
+			testCase.assertions(t, updated, err)
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/go-github/v47/github"
 	"golang.org/x/oauth2"
 
+	"github.com/akuity/kargo-render/internal/repourl"
 	"github.com/akuity/kargo-render/pkg/git"
 )
 
@@ -21,7 +22,7 @@ func OpenPR(
 	commitBranch string,
 	repoCreds git.RepoCredentials,
 ) (string, error) {
-	owner, repo, err := parseGitHubURL(repoURL)
+	owner, repo, err := parseGitHubURL(repourl.Normalize(repoURL))
 	if err != nil {
 		return "", err
 	}
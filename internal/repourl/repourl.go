@@ -0,0 +1,55 @@
+package repourl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// azureDevOpsSSHRegex matches Azure DevOps SSH URLs of the form
+	// git@ssh.dev.azure.com:v3/org/project/repo, including those using the
+	// legacy host, as in org@vs-ssh.visualstudio.com:v3/org/project/repo.
+	azureDevOpsSSHRegex = regexp.MustCompile(
+		`^(?:ssh://)?[\w.-]+@(?:ssh\.dev\.azure\.com|vs-ssh\.visualstudio\.com)(?::|(?::\d+)?/)` +
+			`v3/([^/]+)/([^/]+)/([^/]+?)/?$`,
+	)
+	// legacyAzureDevOpsSSHRegex matches the SSH URLs that Azure DevOps
+	// organizations on visualstudio.com hosts offered before v3 URLs, of the
+	// form ssh://org@org.visualstudio.com:22/DefaultCollection/project/_ssh/repo.
+	// The DefaultCollection segment is optional.
+	legacyAzureDevOpsSSHRegex = regexp.MustCompile(
+		`^ssh://[\w.-]+@([\w-]+)\.visualstudio\.com(?::\d+)?/(?:DefaultCollection/)?` +
+			`([^/]+)/_(?:ssh|git)/([^/]+?)/?$`,
+	)
+	// scpLikeSSHRegex matches SSH URLs of the scp-like form user@host:path.
+	scpLikeSSHRegex = regexp.MustCompile(`^[\w.-]+@([\w.-]+):(?:/)?([^/].*)$`)
+	// sshRegex matches SSH URLs of the form ssh://user@host[:port]/path.
+	sshRegex = regexp.MustCompile(`^ssh://(?:[\w.-]+@)?([\w.-]+)(?::\d+)?/(.+)$`)
+)
+
+// Normalize converts common SSH forms of a repository URL to their HTTPS
+// equivalents. This permits provider APIs that derive the organization,
+// project, owner, or repository name from an HTTPS URL to be used with
+// repositories that are cloned via SSH. URLs that are not recognized as SSH
+// URLs are returned with only surrounding whitespace removed.
+func Normalize(repoURL string) string {
+	repoURL = strings.TrimSpace(repoURL)
+	for _, regex := range []*regexp.Regexp{azureDevOpsSSHRegex, legacyAzureDevOpsSSHRegex} {
+		if parts := regex.FindStringSubmatch(repoURL); parts != nil {
+			return fmt.Sprintf(
+				"https://dev.azure.com/%s/%s/_git/%s",
+				parts[1],
+				parts[2],
+				parts[3],
+			)
+		}
+	}
+	if parts := sshRegex.FindStringSubmatch(repoURL); parts != nil {
+		return fmt.Sprintf("https://%s/%s", parts[1], parts[2])
+	}
+	if parts := scpLikeSSHRegex.FindStringSubmatch(repoURL); parts != nil {
+		return fmt.Sprintf("https://%s/%s", parts[1], parts[2])
+	}
+	return repoURL
+}
@@ -0,0 +1,96 @@
+package repourl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{
+			name:     "Azure DevOps v3 SSH",
+			url:      "git@ssh.dev.azure.com:v3/org/proj/repo",
+			expected: "https://dev.azure.com/org/proj/_git/repo",
+		},
+		{
+			name:     "Azure DevOps v3 SSH with scheme",
+			url:      "ssh://git@ssh.dev.azure.com/v3/org/proj/repo",
+			expected: "https://dev.azure.com/org/proj/_git/repo",
+		},
+		{
+			name:     "Azure DevOps v3 SSH with scheme and port",
+			url:      "ssh://git@ssh.dev.azure.com:22/v3/org/proj/repo",
+			expected: "https://dev.azure.com/org/proj/_git/repo",
+		},
+		{
+			name:     "Azure DevOps legacy host v3 SSH",
+			url:      "org@vs-ssh.visualstudio.com:v3/org/proj/repo",
+			expected: "https://dev.azure.com/org/proj/_git/repo",
+		},
+		{
+			name:     "Azure DevOps legacy host v3 SSH with scheme",
+			url:      "ssh://org@vs-ssh.visualstudio.com/v3/org/proj/repo",
+			expected: "https://dev.azure.com/org/proj/_git/repo",
+		},
+		{
+			name:     "Azure DevOps legacy SSH",
+			url:      "ssh://org@org.visualstudio.com:22/DefaultCollection/proj/_ssh/repo",
+			expected: "https://dev.azure.com/org/proj/_git/repo",
+		},
+		{
+			name:     "Azure DevOps legacy SSH without collection",
+			url:      "ssh://org@org.visualstudio.com:22/proj/_ssh/repo",
+			expected: "https://dev.azure.com/org/proj/_git/repo",
+		},
+		{
+			name:     "Azure DevOps Server SSH",
+			url:      "ssh://tfs.example.com:22/tfs/DefaultCollection/proj/_git/repo",
+			expected: "https://tfs.example.com/tfs/DefaultCollection/proj/_git/repo",
+		},
+		{
+			name:     "Azure DevOps HTTPS",
+			url:      "https://dev.azure.com/org/proj/_git/repo",
+			expected: "https://dev.azure.com/org/proj/_git/repo",
+		},
+		{
+			name:     "GitHub scp-like SSH",
+			url:      "git@github.com:akuity/kargo-render.git",
+			expected: "https://github.com/akuity/kargo-render.git",
+		},
+		{
+			name:     "GitHub SSH with scheme",
+			url:      "ssh://git@github.com/akuity/kargo-render.git",
+			expected: "https://github.com/akuity/kargo-render.git",
+		},
+		{
+			name:     "GitHub HTTPS",
+			url:      "https://github.com/akuity/kargo-render",
+			expected: "https://github.com/akuity/kargo-render",
+		},
+		{
+			name:     "GitLab scp-like SSH with subgroups",
+			url:      "git@gitlab.com:group/subgroup/repo.git",
+			expected: "https://gitlab.com/group/subgroup/repo.git",
+		},
+		{
+			name:     "GitLab SSH with scheme and port",
+			url:      "ssh://git@gitlab.example.com:2222/group/repo.git",
+			expected: "https://gitlab.example.com/group/repo.git",
+		},
+		{
+			name:     "surrounding whitespace",
+			url:      "  https://github.com/akuity/kargo-render  ",
+			expected: "https://github.com/akuity/kargo-render",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.expected, Normalize(testCase.url))
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"time"
+)
+
+const (
+	defaultMaxAttempts    = 3
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+	defaultMultiplier     = 2
+)
+
+// Policy encapsulates settings for bounding and retrying calls to a Git
+// provider's API. The zero value is a usable policy with sensible defaults.
+type Policy struct {
+	// MaxAttempts is the maximum number of times a call is attempted. When this
+	// is zero, a default of 3 is used, unless MaxElapsed is non-zero, in which
+	// case the number of attempts is bounded only by MaxElapsed. A value of 1
+	// disables retries.
+	MaxAttempts int
+	// InitialBackoff is the amount of time to wait before the first retry. When
+	// this is zero, a default of 500ms is used.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum amount of time to wait between attempts. When
+	// this is zero, a default of 10s is used.
+	MaxBackoff time.Duration
+	// MaxElapsed, when non-zero, is the maximum cumulative amount of time, from
+	// the start of the first attempt, within which retries may begin. No retry
+	// is made if waiting for it would exceed this or the caller's context
+	// deadline, whichever is sooner.
+	MaxElapsed time.Duration
+	// Multiplier is the factor by which the amount of time to wait increases
+	// after each retry. When this is zero, a default of 2 is used.
+	Multiplier float64
+	// Timeout is the maximum amount of time permitted for each attempt. When
+	// this is zero, attempts are bounded only by the caller's context. An
+	// attempt that times out is retried, if attempts remain, only if Retryable
+	// accepts its error, which generally wraps context.DeadlineExceeded, since
+	// the call may have taken effect before it timed out.
+	Timeout time.Duration
+	// Retryable decides whether a failed attempt should be retried. When this
+	// is nil, IsTransient is used.
+	Retryable func(error) bool
+	// Jitter is the fraction, between 0 and 1, of each wait between attempts
+	// that is randomized, so that many callers failing at once do not retry in
+	// lockstep. For instance, with a jitter of 0.5, a wait of 1s becomes a
+	// random wait of between 500ms and 1s. When this is zero, waits are not
+	// randomized.
+	Jitter float64
+	// Rand optionally specifies the source of the randomness used for Jitter.
+	// When this is nil, a cryptographically seeded source is used. A source
+	// with a fixed seed, such as rand.NewPCG(1, 2), makes the timing of retries
+	// reproducible, for instance, in tests and when investigating flaky retries.
+	// Sources are generally not safe for concurrent use, so a policy with a
+	// source should not be shared by concurrent calls.
+	Rand rand.Source
+}
+
+// withDefaults returns a copy of the policy with defaults applied. A nil
+// policy is treated as the zero value.
+func (p *Policy) withDefaults() Policy {
+	var policy Policy
+	if p != nil {
+		policy = *p
+	}
+	if policy.MaxAttempts <= 0 && policy.MaxElapsed <= 0 {
+		policy.MaxAttempts = defaultMaxAttempts
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaultInitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultMaxBackoff
+	}
+	if policy.Multiplier <= 0 {
+		policy.Multiplier = defaultMultiplier
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = IsTransient
+	}
+	policy.Jitter = min(max(policy.Jitter, 0), 1)
+	return policy
+}
+
+// jittered returns the specified wait, randomly shortened by up to the
+// policy's jitter fraction of it.
+func (p *Policy) jittered(wait time.Duration) time.Duration {
+	if p.Jitter == 0 {
+		return wait
+	}
+	r := rand.Float64()
+	if p.Rand != nil {
+		r = rand.New(p.Rand).Float64()
+	}
+	return wait - time.Duration(p.Jitter*r*float64(wait))
+}
+
+// now returns the current time. It is a package-level variable so that it can
+// be overridden in tests.
+var now = time.Now
+
+// sleep waits for the specified duration or until the specified context is
+// canceled, whichever comes first. It is a package-level variable so that it
+// can be overridden in tests.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Do invokes the specified function, retrying it with exponential backoff, as
+// specified by the policy, for as long as it fails with retryable errors. If
+// the policy is nil, defaults are used. The context passed to the function is
+// bounded by the policy's per-attempt timeout, if any. Retries stop once the
+// policy's cumulative deadline, if any, or the context's deadline would be
+// exceeded. If all attempts fail, the last error is returned.
+func Do[T any](
+	ctx context.Context,
+	policy *Policy,
+	fn func(context.Context) (T, error),
+) (T, error) {
+	p := policy.withDefaults()
+	deadline, hasDeadline := ctx.Deadline()
+	if p.MaxElapsed > 0 {
+		if elapsedDeadline := now().Add(p.MaxElapsed); !hasDeadline ||
+			elapsedDeadline.Before(deadline) {
+			deadline, hasDeadline = elapsedDeadline, true
+		}
+	}
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		res, err := attemptOnce(ctx, p.Timeout, fn)
+		if err == nil {
+			return res, nil
+		}
+		wait := p.jittered(backoff)
+		if (p.MaxAttempts > 0 && attempt >= p.MaxAttempts) || ctx.Err() != nil ||
+			!p.Retryable(err) ||
+			(hasDeadline && now().Add(wait).After(deadline)) {
+			if attempt > 1 {
+				err = fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+			}
+			return res, err
+		}
+		if sleepErr := sleep(ctx, wait); sleepErr != nil {
+			return res, err
+		}
+		backoff = time.Duration(float64(backoff) * p.Multiplier)
+		if backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+// attemptOnce invokes the specified function once, bounded by the specified
+// timeout, if non-zero.
+func attemptOnce[T any](
+	ctx context.Context,
+	timeout time.Duration,
+	fn func(context.Context) (T, error),
+) (T, error) {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return fn(attemptCtx)
+}
+
+// IsTransient returns a bool indicating whether the specified error is one of a
+// small set of network errors, including timeouts, that are likely to be
+// resolved by retrying.
+func IsTransient(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF)
+}
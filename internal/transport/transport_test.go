@@ -0,0 +1,332 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDo(t *testing.T) {
+	errTransient := io.ErrUnexpectedEOF
+	errPermanent := errors.New("something went wrong")
+	testCases := []struct {
+		name       string
+		policy     *Policy
+		errs       []error
+		assertions func(t *testing.T, calls int, backoffs []time.Duration, err error)
+	}{
+		{
+			name: "success on first attempt",
+			assertions: func(t *testing.T, calls int, backoffs []time.Duration, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, calls)
+				require.Empty(t, backoffs)
+			},
+		},
+		{
+			name: "success after transient errors",
+			errs: []error{errTransient, errTransient},
+			assertions: func(t *testing.T, calls int, backoffs []time.Duration, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 3, calls)
+				require.Equal(
+					t,
+					[]time.Duration{500 * time.Millisecond, time.Second},
+					backoffs,
+				)
+			},
+		},
+		{
+			name: "permanent error is not retried",
+			errs: []error{errPermanent},
+			assertions: func(t *testing.T, calls int, backoffs []time.Duration, err error) {
+				require.ErrorIs(t, err, errPermanent)
+				require.NotContains(t, err.Error(), "giving up")
+				require.Equal(t, 1, calls)
+				require.Empty(t, backoffs)
+			},
+		},
+		{
+			name: "gives up after max attempts",
+			errs: []error{errTransient, errTransient, errTransient, errTransient},
+			assertions: func(t *testing.T, calls int, _ []time.Duration, err error) {
+				require.ErrorIs(t, err, errTransient)
+				require.ErrorContains(t, err, "giving up after 3 attempts")
+				require.Equal(t, 3, calls)
+			},
+		},
+		{
+			name: "backoff is capped",
+			policy: &Policy{
+				MaxAttempts:    5,
+				InitialBackoff: time.Second,
+				MaxBackoff:     3 * time.Second,
+				Multiplier:     2,
+			},
+			errs: []error{errTransient, errTransient, errTransient, errTransient},
+			assertions: func(t *testing.T, calls int, backoffs []time.Duration, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 5, calls)
+				require.Equal(
+					t,
+					[]time.Duration{
+						time.Second,
+						2 * time.Second,
+						3 * time.Second,
+						3 * time.Second,
+					},
+					backoffs,
+				)
+			},
+		},
+		{
+			name: "custom retryable",
+			policy: &Policy{
+				Retryable: func(err error) bool { return errors.Is(err, errPermanent) },
+			},
+			errs: []error{errPermanent, errTransient},
+			assertions: func(t *testing.T, calls int, _ []time.Duration, err error) {
+				require.ErrorIs(t, err, errTransient)
+				require.ErrorContains(t, err, "giving up after 2 attempts")
+				require.Equal(t, 2, calls)
+			},
+		},
+		{
+			name:   "single attempt disables retries",
+			policy: &Policy{MaxAttempts: 1},
+			errs:   []error{errTransient},
+			assertions: func(t *testing.T, calls int, _ []time.Duration, err error) {
+				require.ErrorIs(t, err, errTransient)
+				require.Equal(t, 1, calls)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var backoffs []time.Duration
+			useFakeSleep(t, func(_ context.Context, d time.Duration) error {
+				backoffs = append(backoffs, d)
+				return nil
+			})
+			var calls int
+			res, err := Do(
+				context.Background(),
+				testCase.policy,
+				func(context.Context) (int, error) {
+					calls++
+					if calls <= len(testCase.errs) {
+						return 0, testCase.errs[calls-1]
+					}
+					return 42, nil
+				},
+			)
+			if err == nil {
+				require.Equal(t, 42, res)
+			}
+			testCase.assertions(t, calls, backoffs, err)
+		})
+	}
+}
+
+func TestDoTimeout(t *testing.T) {
+	testCases := []struct {
+		name      string
+		retryable func(error) bool
+		calls     int
+	}{
+		{
+			name:  "timeouts are transient by default",
+			calls: 2,
+		},
+		{
+			// The call may have taken effect before it timed out
+			name:      "timeouts are not retried unless retryable",
+			retryable: func(error) bool { return false },
+			calls:     1,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			useFakeSleep(t, func(context.Context, time.Duration) error { return nil })
+			var calls int
+			_, err := Do(
+				context.Background(),
+				&Policy{
+					MaxAttempts: 2,
+					Timeout:     time.Millisecond,
+					Retryable:   testCase.retryable,
+				},
+				func(ctx context.Context) (struct{}, error) {
+					calls++
+					<-ctx.Done()
+					return struct{}{}, ctx.Err()
+				},
+			)
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+			require.Equal(t, testCase.calls, calls)
+		})
+	}
+}
+
+func TestDoCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	useFakeSleep(t, func(context.Context, time.Duration) error {
+		require.Fail(t, "should not back off after cancellation")
+		return nil
+	})
+	var calls int
+	_, err := Do(ctx, nil, func(context.Context) (struct{}, error) {
+		calls++
+		cancel()
+		return struct{}{}, io.ErrUnexpectedEOF
+	})
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.Equal(t, 1, calls)
+}
+
+func TestDoMaxElapsed(t *testing.T) {
+	testCases := []struct {
+		name       string
+		policy     *Policy
+		deadline   time.Duration
+		assertions func(t *testing.T, calls int, backoffs []time.Duration, err error)
+	}{
+		{
+			name: "stops at cumulative deadline",
+			policy: &Policy{
+				InitialBackoff: time.Second,
+				MaxBackoff:     4 * time.Second,
+				MaxElapsed:     10 * time.Second,
+			},
+			assertions: func(t *testing.T, calls int, backoffs []time.Duration, err error) {
+				require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+				require.ErrorContains(t, err, "giving up after 4 attempts")
+				// The fourth attempt begins at exactly 10s; a fifth would begin at 15s
+				require.Equal(t, 4, calls)
+				require.Equal(
+					t,
+					[]time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+					backoffs,
+				)
+			},
+		},
+		{
+			name: "stops at sooner context deadline",
+			policy: &Policy{
+				InitialBackoff: time.Second,
+				MaxElapsed:     time.Minute,
+			},
+			deadline: 3 * time.Second,
+			assertions: func(t *testing.T, calls int, backoffs []time.Duration, err error) {
+				require.ErrorContains(t, err, "giving up after 2 attempts")
+				require.Equal(t, 2, calls)
+				require.Equal(t, []time.Duration{time.Second}, backoffs)
+			},
+		},
+		{
+			name:   "max attempts still honored",
+			policy: &Policy{MaxAttempts: 2, MaxElapsed: time.Hour},
+			assertions: func(t *testing.T, calls int, _ []time.Duration, err error) {
+				require.ErrorContains(t, err, "giving up after 2 attempts")
+				require.Equal(t, 2, calls)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			clock := time.Now()
+			origNow := now
+			now = func() time.Time { return clock }
+			t.Cleanup(func() { now = origNow })
+			var backoffs []time.Duration
+			useFakeSleep(t, func(_ context.Context, d time.Duration) error {
+				backoffs = append(backoffs, d)
+				clock = clock.Add(d)
+				return nil
+			})
+			ctx := context.Background()
+			if testCase.deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, clock.Add(testCase.deadline))
+				defer cancel()
+			}
+			var calls int
+			_, err := Do(ctx, testCase.policy, func(context.Context) (struct{}, error) {
+				calls++
+				// Each attempt takes a second
+				clock = clock.Add(time.Second)
+				return struct{}{}, io.ErrUnexpectedEOF
+			})
+			testCase.assertions(t, calls, backoffs, err)
+		})
+	}
+}
+
+func TestDoJitter(t *testing.T) {
+	base := []time.Duration{
+		500 * time.Millisecond,
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+	}
+	backoffsOf := func(t *testing.T, source rand.Source) []time.Duration {
+		var backoffs []time.Duration
+		useFakeSleep(t, func(_ context.Context, d time.Duration) error {
+			backoffs = append(backoffs, d)
+			return nil
+		})
+		_, err := Do(
+			context.Background(),
+			&Policy{MaxAttempts: len(base) + 1, Jitter: 0.5, Rand: source},
+			func(context.Context) (struct{}, error) {
+				return struct{}{}, io.ErrUnexpectedEOF
+			},
+		)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		require.Len(t, backoffs, len(base))
+		for i, backoff := range backoffs {
+			require.GreaterOrEqual(t, backoff, base[i]/2)
+			require.LessOrEqual(t, backoff, base[i])
+		}
+		return backoffs
+	}
+
+	t.Run("fixed seed is deterministic", func(t *testing.T) {
+		first := backoffsOf(t, rand.NewPCG(1, 2))
+		second := backoffsOf(t, rand.NewPCG(1, 2))
+		require.Equal(t, first, second)
+		require.NotEqual(t, base, first)
+	})
+
+	t.Run("different seeds differ", func(t *testing.T) {
+		require.NotEqual(
+			t,
+			backoffsOf(t, rand.NewPCG(1, 2)),
+			backoffsOf(t, rand.NewPCG(3, 4)),
+		)
+	})
+
+	t.Run("default source", func(t *testing.T) {
+		backoffsOf(t, nil)
+	})
+}
+
+func TestIsTransient(t *testing.T) {
+	require.True(t, IsTransient(io.ErrUnexpectedEOF))
+	require.True(t, IsTransient(context.DeadlineExceeded))
+	require.True(t, IsTransient(&net.DNSError{IsTimeout: true}))
+	require.False(t, IsTransient(&net.DNSError{IsNotFound: true}))
+	require.False(t, IsTransient(errors.New("something went wrong")))
+}
+
+func useFakeSleep(t *testing.T, fake func(context.Context, time.Duration) error) {
+	orig := sleep
+	sleep = fake
+	t.Cleanup(func() { sleep = orig })
+}
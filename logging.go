@@ -1,6 +1,12 @@
 package render
 
-import log "github.com/sirupsen/logrus"
+import (
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/akuity/kargo-render/internal/azuredevops"
+)
 
 // LogLevel represents the level of detail logged by the Kargo Render service's
 // internal logger.
@@ -15,3 +21,49 @@ const (
 	// LogLevelError represents ERROR level logging.
 	LogLevelError = LogLevel(log.ErrorLevel)
 )
+
+// contextFieldNames are the names of log fields that identify the repository
+// a rendering request is for and, for Azure DevOps repositories, the
+// organization and project it belongs to.
+var contextFieldNames = []string{"repo", "org", "project"}
+
+// contextFields returns log fields identifying the repository the specified
+// rendering request is for.
+func contextFields(req *Request) log.Fields {
+	fields := log.Fields{"repo": req.RepoURL}
+	if org, project, _, err := azuredevops.ParseRepoURL(req.RepoURL); err == nil {
+		fields["org"] = org
+		fields["project"] = project
+	}
+	return fields
+}
+
+// contextHook is a log hook that removes the fields named by contextFieldNames
+// from log entries at the levels it fires for.
+type contextHook struct {
+	levels []log.Level
+}
+
+// newContextHook returns a contextHook that fires for all levels except the
+// specified ones, i.e. one that limits contextual fields to entries at the
+// specified levels.
+func newContextHook(included []LogLevel) *contextHook {
+	h := &contextHook{}
+	for _, level := range log.AllLevels {
+		if !slices.Contains(included, LogLevel(level)) {
+			h.levels = append(h.levels, level)
+		}
+	}
+	return h
+}
+
+func (c *contextHook) Levels() []log.Level {
+	return c.levels
+}
+
+func (c *contextHook) Fire(entry *log.Entry) error {
+	for _, name := range contextFieldNames {
+		delete(entry.Data, name)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/akuity/kargo-render/internal/repourl"
+)
+
+// ProviderInfo describes the Git hosting provider of a repository as inferred
+// from the repository's URL alone.
+type ProviderInfo struct {
+	// Provider is the Git hosting provider of the repository. This is empty if
+	// the provider could not be inferred.
+	Provider GitProvider
+	// Host is the host name, in lowercase and without any port, from the
+	// repository's URL.
+	Host string
+	// Cloud indicates whether Host is a well-known host of the provider's
+	// cloud offering, as opposed to a self-hosted instance.
+	Cloud bool
+	// SupportsPRs indicates whether Kargo Render is able to open PRs in the
+	// repository using the provider's API. This is currently possible only for
+	// the cloud offerings of some providers and for Azure DevOps Server.
+	SupportsPRs bool
+}
+
+// cloudProviderHosts maps the hosts of Git providers' cloud offerings to those
+// providers.
+var cloudProviderHosts = map[string]GitProvider{
+	"bitbucket.org": GitProviderBitbucket,
+	"dev.azure.com": GitProviderAzureDevOps,
+	"github.com":    GitProviderGitHub,
+	"gitlab.com":    GitProviderGitLab,
+}
+
+// selfHostedProviderHints maps substrings that conventionally appear in the
+// hosts of self-hosted instances of Git providers to those providers.
+var selfHostedProviderHints = []struct {
+	hint     string
+	provider GitProvider
+}{
+	{hint: "github", provider: GitProviderGitHub},
+	{hint: "gitlab", provider: GitProviderGitLab},
+	{hint: "bitbucket", provider: GitProviderBitbucket},
+}
+
+// DetectProvider infers the Git hosting provider of the repository at the
+// specified URL, which may be an HTTPS or SSH URL, without making any network
+// calls. Self-hosted instances are recognized only by convention, for
+// instance, by a host such as github.example.com or, for Azure DevOps Server,
+// a /_git/ path segment, so a ProviderInfo with an empty Provider is returned
+// if none applies. An error is returned only if the URL has no host.
+func DetectProvider(repoURL string) (ProviderInfo, error) {
+	u, err := url.Parse(repourl.Normalize(repoURL))
+	if err != nil {
+		return ProviderInfo{}, fmt.Errorf("error parsing repository URL: %w", err)
+	}
+	info := ProviderInfo{Host: strings.ToLower(u.Hostname())}
+	if info.Host == "" {
+		return ProviderInfo{}, errors.New("repository URL has no host")
+	}
+	switch provider, ok := cloudProviderHosts[info.Host]; {
+	case ok:
+		info.Provider, info.Cloud = provider, true
+	case strings.HasSuffix(info.Host, ".visualstudio.com"):
+		// Legacy host of Azure DevOps Services organizations
+		info.Provider, info.Cloud = GitProviderAzureDevOps, true
+	case strings.Contains(u.Path, "/_git/"):
+		info.Provider = GitProviderAzureDevOps
+	default:
+		for _, h := range selfHostedProviderHints {
+			if strings.Contains(info.Host, h.hint) {
+				info.Provider = h.provider
+				break
+			}
+		}
+	}
+	if info.Cloud || info.Provider == GitProviderAzureDevOps {
+		_, info.SupportsPRs = prOpeners[info.Provider]
+	}
+	return info, nil
+}
@@ -0,0 +1,187 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectProvider(t *testing.T) {
+	testCases := []struct {
+		name       string
+		url        string
+		assertions func(t *testing.T, info ProviderInfo, err error)
+	}{
+		{
+			name: "GitHub HTTPS",
+			url:  "https://github.com/akuity/kargo-render",
+			assertions: func(t *testing.T, info ProviderInfo, err error) {
+				require.NoError(t, err)
+				require.Equal(
+					t,
+					ProviderInfo{
+						Provider:    GitProviderGitHub,
+						Host:        "github.com",
+						Cloud:       true,
+						SupportsPRs: true,
+					},
+					info,
+				)
+			},
+		},
+		{
+			name: "GitHub scp-like SSH",
+			url:  "git@github.com:akuity/kargo-render.git",
+			assertions: func(t *testing.T, info ProviderInfo, err error) {
+				require.NoError(t, err)
+				require.Equal(t, GitProviderGitHub, info.Provider)
+				require.Equal(t, "github.com", info.Host)
+				require.True(t, info.Cloud)
+			},
+		},
+		{
+			name: "GitHub Enterprise",
+			url:  "https://GitHub.Example.com:8443/akuity/kargo-render",
+			assertions: func(t *testing.T, info ProviderInfo, err error) {
+				require.NoError(t, err)
+				require.Equal(
+					t,
+					ProviderInfo{
+						Provider: GitProviderGitHub,
+						Host:     "github.example.com",
+					},
+					info,
+				)
+			},
+		},
+		{
+			name: "Azure DevOps HTTPS",
+			url:  "https://org@dev.azure.com/org/proj/_git/repo",
+			assertions: func(t *testing.T, info ProviderInfo, err error) {
+				require.NoError(t, err)
+				require.Equal(
+					t,
+					ProviderInfo{
+						Provider:    GitProviderAzureDevOps,
+						Host:        "dev.azure.com",
+						Cloud:       true,
+						SupportsPRs: true,
+					},
+					info,
+				)
+			},
+		},
+		{
+			name: "Azure DevOps v3 SSH",
+			url:  "git@ssh.dev.azure.com:v3/org/proj/repo",
+			assertions: func(t *testing.T, info ProviderInfo, err error) {
+				require.NoError(t, err)
+				require.Equal(t, GitProviderAzureDevOps, info.Provider)
+				require.Equal(t, "dev.azure.com", info.Host)
+				require.True(t, info.Cloud)
+			},
+		},
+		{
+			name: "Azure DevOps legacy visualstudio.com",
+			url:  "https://org.visualstudio.com/proj/_git/repo",
+			assertions: func(t *testing.T, info ProviderInfo, err error) {
+				require.NoError(t, err)
+				require.Equal(t, GitProviderAzureDevOps, info.Provider)
+				require.Equal(t, "org.visualstudio.com", info.Host)
+				require.True(t, info.Cloud)
+				require.True(t, info.SupportsPRs)
+			},
+		},
+		{
+			name: "Azure DevOps Server",
+			url:  "https://tfs.example.com/tfs/collection/proj/_git/repo",
+			assertions: func(t *testing.T, info ProviderInfo, err error) {
+				require.NoError(t, err)
+				require.Equal(
+					t,
+					ProviderInfo{
+						Provider:    GitProviderAzureDevOps,
+						Host:        "tfs.example.com",
+						SupportsPRs: true,
+					},
+					info,
+				)
+			},
+		},
+		{
+			name: "GitLab SSH with scheme and port",
+			url:  "ssh://git@gitlab.example.com:2222/group/repo.git",
+			assertions: func(t *testing.T, info ProviderInfo, err error) {
+				require.NoError(t, err)
+				require.Equal(
+					t,
+					ProviderInfo{
+						Provider: GitProviderGitLab,
+						Host:     "gitlab.example.com",
+					},
+					info,
+				)
+			},
+		},
+		{
+			name: "GitLab cloud",
+			url:  "https://gitlab.com/group/subgroup/repo.git",
+			assertions: func(t *testing.T, info ProviderInfo, err error) {
+				require.NoError(t, err)
+				require.Equal(
+					t,
+					ProviderInfo{
+						Provider: GitProviderGitLab,
+						Host:     "gitlab.com",
+						Cloud:    true,
+					},
+					info,
+				)
+			},
+		},
+		{
+			name: "Bitbucket cloud",
+			url:  "git@bitbucket.org:workspace/repo.git",
+			assertions: func(t *testing.T, info ProviderInfo, err error) {
+				require.NoError(t, err)
+				require.Equal(
+					t,
+					ProviderInfo{
+						Provider: GitProviderBitbucket,
+						Host:     "bitbucket.org",
+						Cloud:    true,
+					},
+					info,
+				)
+			},
+		},
+		{
+			name: "unknown host",
+			url:  "https://git.example.com/repo.git",
+			assertions: func(t *testing.T, info ProviderInfo, err error) {
+				require.NoError(t, err)
+				require.Equal(t, ProviderInfo{Host: "git.example.com"}, info)
+			},
+		},
+		{
+			name: "no host",
+			url:  "/path/to/repo",
+			assertions: func(t *testing.T, _ ProviderInfo, err error) {
+				require.ErrorContains(t, err, "has no host")
+			},
+		},
+		{
+			name: "unparseable",
+			url:  "https://example.com/%zz",
+			assertions: func(t *testing.T, _ ProviderInfo, err error) {
+				require.ErrorContains(t, err, "error parsing repository URL")
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			info, err := DetectProvider(testCase.url)
+			testCase.assertions(t, info, err)
+		})
+	}
+}
@@ -1,68 +1,83 @@
 package render
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"strings"
+	"text/template"
+
+	adogit "github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
 
 	"github.com/akuity/kargo-render/internal/azuredevops"
 	"github.com/akuity/kargo-render/internal/github"
 	"github.com/akuity/kargo-render/pkg/git"
 )
 
-func openPR(ctx context.Context, rc requestContext) (string, error) {
-	commitMsgParts := strings.SplitN(rc.target.commit.message, "\n", 2)
-	var title string
-	if rc.target.branchConfig.PRs.UseUniqueBranchNames {
-		// PR title is just the first line of the commit message
-		title = fmt.Sprintf("%s <-- %s", rc.request.TargetBranch, commitMsgParts[0])
-	} else {
-		// Something more generic because this PR can be updated with more commits
-		title =
-			fmt.Sprintf("%s <-- latest batched changes", rc.request.TargetBranch)
-	}
-	// TODO: Support git providers other than GitHub.
-	//
-	// Wish list:
-	//
-	// * GitHub Enterprise
-	// * Bitbucket
-	// * GitLab
-	// * Other?
+// prTitleContext encapsulates the values that are available to a PR title
+// template.
+type prTitleContext struct {
+	// Env is the name of the environment-specific branch the PR targets.
+	Env string
+	// Version is the tag of the first image incorporated into the rendered
+	// manifests. This is empty if no images were incorporated.
+	Version string
+	// Commit is the ID (sha) of the source commit the manifests were rendered
+	// from.
+	Commit string
+	// Subject is the first line of the commit message.
+	Subject string
+}
 
-	// Determine the Git provider based on the repository URL
-	var url string
-	var err error
+// prOpener is a function that opens a PR using a specific Git provider's API.
+// Implementations are expected to return an empty URL and no error if a PR
+// for the commit branch is already open.
+type prOpener func(
+	ctx context.Context,
+	rc requestContext,
+	title string,
+	description string,
+	creds git.RepoCredentials,
+) (string, error)
 
-	if strings.Contains(rc.request.RepoURL, "dev.azure.com") {
-		// Azure DevOps repository
-		url, err = azuredevops.OpenPR(
-			ctx,
-			rc.request.RepoURL,
-			title,
-			"See individual commit messages for details.",
-			rc.request.TargetBranch,
-			rc.target.commit.branch,
-			git.RepoCredentials{
-				Username: rc.request.RepoCreds.Username,
-				Password: rc.request.RepoCreds.Password,
-			},
-		)
-	} else {
-		// Default to GitHub
-		url, err = github.OpenPR(
-			ctx,
-			rc.request.RepoURL,
-			title,
-			"See individual commit messages for details.",
-			rc.request.TargetBranch,
-			rc.target.commit.branch,
-			git.RepoCredentials{
-				Username: rc.request.RepoCreds.Username,
-				Password: rc.request.RepoCreds.Password,
-			},
-		)
+// prOpeners is a registry of prOpeners indexed by Git provider.
+//
+// TODO: Support git providers other than GitHub and Azure DevOps.
+//
+// Wish list:
+//
+// * GitHub Enterprise
+// * Bitbucket
+// * GitLab
+// * Other?
+var prOpeners = map[GitProvider]prOpener{
+	GitProviderAzureDevOps: openAzureDevOpsPR,
+	GitProviderGitHub:      openGitHubPR,
+}
+
+func openPR(ctx context.Context, rc requestContext) (string, error) {
+	title, err := buildPRTitle(rc)
+	if err != nil {
+		return "", err
 	}
+	provider, err := resolveGitProvider(rc.request)
+	if err != nil {
+		return "", err
+	}
+	if err = ensurePRFeaturesSupported(rc, provider); err != nil {
+		return "", err
+	}
+	creds, err := resolvePRCredentials(provider, rc.request)
+	if err != nil {
+		return "", err
+	}
+	url, err := prOpeners[provider](
+		ctx,
+		rc,
+		title,
+		"See individual commit messages for details.",
+		creds,
+	)
 	// TODO: Catch specific errors that have to do with an open PR already being
 	// associated with the target branch
 	if err != nil {
@@ -71,3 +86,190 @@ func openPR(ctx context.Context, rc requestContext) (string, error) {
 	}
 	return url, nil
 }
+
+// resolveGitProvider returns the Git provider that should be used to open PRs
+// for the specified request. A provider explicitly specified by the request
+// takes precedence over one inferred from the repository URL. An error is
+// returned if the resulting provider has no registered implementation.
+func resolveGitProvider(req *Request) (GitProvider, error) {
+	provider := req.GitProvider
+	if provider == "" {
+		// Infer the Git provider based on the repository URL, defaulting to
+		// GitHub
+		provider = GitProviderGitHub
+		if info, err := DetectProvider(req.RepoURL); err == nil && info.SupportsPRs {
+			provider = info.Provider
+		}
+	}
+	if _, ok := prOpeners[provider]; !ok {
+		return "", fmt.Errorf(
+			"no registered implementation for git provider %q",
+			provider,
+		)
+	}
+	return provider, nil
+}
+
+func openAzureDevOpsPR(
+	ctx context.Context,
+	rc requestContext,
+	title string,
+	description string,
+	creds git.RepoCredentials,
+) (string, error) {
+	prCfg := rc.target.branchConfig.PRs
+	configHash, err := rc.target.branchConfig.hash()
+	if err != nil {
+		return "", err
+	}
+	authMode := azuredevops.AuthMode(prCfg.AzureDevOpsAuthMode)
+	return azuredevops.OpenPR(
+		ctx,
+		rc.request.RepoURL,
+		title,
+		description,
+		rc.request.TargetBranch,
+		rc.target.commit.branch,
+		azureDevOpsCredentials(rc, creds),
+		&azuredevops.OpenPROptions{
+			IdempotencyKey: azuredevops.IdempotencyKey(
+				rc.target.commit.branch,
+				rc.request.TargetBranch,
+				rc.target.commit.id,
+			),
+			Footer:                      prCfg.Footer,
+			Labels:                      prCfg.Labels,
+			LabelRules:                  azureDevOpsLabelRules(prCfg.LabelRules),
+			DeleteSourceBranchOnFailure: prCfg.DeleteBranchOnFailure,
+			SourceBranchCreated:         rc.target.commit.branchCreated,
+			ConfigHash:                  configHash,
+			Defaults:                    azureDevOpsPRDefaults(prCfg.AzureDevOpsDefaults),
+			Connection:                  azuredevops.ConnectionOptions{AuthMode: authMode},
+			OnUnresolvedReviewers: func(unresolved []string) {
+				rc.logger.WithField("reviewers", unresolved).
+					Warn("ignoring pull request reviewers that could not be resolved")
+			},
+			OnWarning: func(err error) {
+				rc.logger.WithError(err).Warn("error enriching pull request")
+			},
+		},
+	)
+}
+
+// azureDevOpsCredentials returns the credentials that should be presented to
+// Azure DevOps in place of the specified ones, given the authentication mode
+// configured for the request's target branch.
+func azureDevOpsCredentials(
+	rc requestContext,
+	creds git.RepoCredentials,
+) git.RepoCredentials {
+	authMode := azuredevops.AuthMode(rc.target.branchConfig.PRs.AzureDevOpsAuthMode)
+	if authMode == azuredevops.AuthModeBasic {
+		// Basic authentication presents a real account rather than a PAT
+		return git.RepoCredentials{
+			Username: rc.request.RepoCreds.Username,
+			Password: rc.request.RepoCreds.Password,
+		}
+	}
+	return creds
+}
+
+// azureDevOpsLabelRules converts the specified label rules to the form
+// expected by the azuredevops package.
+func azureDevOpsLabelRules(rules []labelRuleConfig) []azuredevops.LabelRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	adoRules := make([]azuredevops.LabelRule, len(rules))
+	for i, rule := range rules {
+		adoRules[i] = azuredevops.LabelRule{Path: rule.Path, Label: rule.Label}
+	}
+	return adoRules
+}
+
+// azureDevOpsPRDefaults converts the specified PR defaults to the form expected
+// by the azuredevops package.
+func azureDevOpsPRDefaults(
+	cfg *azureDevOpsPRDefaultsConfig,
+) *azuredevops.PRDefaults {
+	if cfg == nil {
+		return nil
+	}
+	defaults := &azuredevops.PRDefaults{
+		Draft:         cfg.Draft,
+		ReviewerNames: cfg.Reviewers,
+	}
+	if ac := cfg.AutoComplete; ac != nil {
+		defaults.AutoComplete = &azuredevops.AutoCompleteOptions{
+			MergeStrategy:      adogit.GitPullRequestMergeStrategy(ac.MergeStrategy),
+			DeleteSourceBranch: ac.DeleteSourceBranch,
+			MergeCommitMessage: ac.MergeCommitMessage,
+		}
+	}
+	return defaults
+}
+
+func openGitHubPR(
+	ctx context.Context,
+	rc requestContext,
+	title string,
+	description string,
+	creds git.RepoCredentials,
+) (string, error) {
+	if footer := rc.target.branchConfig.PRs.Footer; footer != "" {
+		description = fmt.Sprintf("%s\n\n%s", description, footer)
+	}
+	return github.OpenPR(
+		ctx,
+		rc.request.RepoURL,
+		title,
+		description,
+		rc.request.TargetBranch,
+		rc.target.commit.branch,
+		creds,
+	)
+}
+
+// buildPRTitle builds the title for a PR to the target branch. If the branch
+// configuration specifies a title template, it is executed to produce the
+// title. Otherwise, a default title is used.
+func buildPRTitle(rc requestContext) (string, error) {
+	commitMsgParts := strings.SplitN(rc.target.commit.message, "\n", 2)
+	prCfg := rc.target.branchConfig.PRs
+	if prCfg.TitleTemplate == "" {
+		if prCfg.UseUniqueBranchNames {
+			// PR title is just the first line of the commit message
+			return fmt.Sprintf("%s <-- %s", rc.request.TargetBranch, commitMsgParts[0]), nil
+		}
+		// Something more generic because this PR can be updated with more commits
+		return fmt.Sprintf("%s <-- latest batched changes", rc.request.TargetBranch), nil
+	}
+	tmpl, err := template.New("title").Parse(prCfg.TitleTemplate)
+	if err != nil {
+		return "", fmt.Errorf("error parsing PR title template: %w", err)
+	}
+	titleCtx := prTitleContext{
+		Env:     rc.request.TargetBranch,
+		Commit:  rc.source.commit,
+		Subject: commitMsgParts[0],
+	}
+	if len(rc.target.newBranchMetadata.ImageSubstitutions) > 0 {
+		image := rc.target.newBranchMetadata.ImageSubstitutions[0]
+		if i := strings.LastIndex(image, ":"); i >= 0 && !strings.Contains(image[i:], "/") {
+			titleCtx.Version = image[i+1:]
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err = tmpl.Execute(buf, titleCtx); err != nil {
+		return "", fmt.Errorf("error executing PR title template: %w", err)
+	}
+	title := strings.TrimSpace(buf.String())
+	if title == "" {
+		return "", fmt.Errorf(
+			"PR title template %q produced an empty title for branch %q",
+			prCfg.TitleTemplate,
+			rc.request.TargetBranch,
+		)
+	}
+	return title, nil
+}
@@ -0,0 +1,241 @@
+package render
+
+import (
+	"testing"
+
+	adogit "github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akuity/kargo-render/internal/azuredevops"
+)
+
+func TestBuildPRTitle(t *testing.T) {
+	testCases := []struct {
+		name       string
+		rc         requestContext
+		assertions func(*testing.T, string, error)
+	}{
+		{
+			name: "default title for batched changes",
+			rc: requestContext{
+				request: &Request{TargetBranch: "env/dev"},
+			},
+			assertions: func(t *testing.T, title string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "env/dev <-- latest batched changes", title)
+			},
+		},
+		{
+			name: "default title for unique branch names",
+			rc: requestContext{
+				request: &Request{TargetBranch: "env/dev"},
+				target: targetContext{
+					branchConfig: branchConfig{
+						PRs: pullRequestConfig{UseUniqueBranchNames: true},
+					},
+					commit: commitContext{message: "fix the thing\n\nmore details"},
+				},
+			},
+			assertions: func(t *testing.T, title string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "env/dev <-- fix the thing", title)
+			},
+		},
+		{
+			name: "template with env and version",
+			rc: requestContext{
+				request: &Request{TargetBranch: "env/prod"},
+				target: targetContext{
+					branchConfig: branchConfig{
+						PRs: pullRequestConfig{
+							TitleTemplate: "Promote {{.Version}} to {{.Env}}",
+						},
+					},
+					newBranchMetadata: branchMetadata{
+						ImageSubstitutions: []string{"registry.example.com:5000/app:v1.2.3"},
+					},
+				},
+			},
+			assertions: func(t *testing.T, title string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "Promote v1.2.3 to env/prod", title)
+			},
+		},
+		{
+			name: "template with commit and subject",
+			rc: requestContext{
+				request: &Request{TargetBranch: "env/stage"},
+				source:  sourceContext{commit: "abc1234"},
+				target: targetContext{
+					branchConfig: branchConfig{
+						PRs: pullRequestConfig{
+							TitleTemplate: "[{{.Env}}] {{.Subject}} ({{.Commit}})",
+						},
+					},
+					commit: commitContext{message: "bump replicas\n\ndetails"},
+				},
+			},
+			assertions: func(t *testing.T, title string, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "[env/stage] bump replicas (abc1234)", title)
+			},
+		},
+		{
+			name: "template referencing unknown field",
+			rc: requestContext{
+				request: &Request{TargetBranch: "env/stage"},
+				target: targetContext{
+					branchConfig: branchConfig{
+						PRs: pullRequestConfig{TitleTemplate: "{{.Bogus}}"},
+					},
+				},
+			},
+			assertions: func(t *testing.T, _ string, err error) {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "error executing PR title template")
+			},
+		},
+		{
+			name: "template producing an empty title",
+			rc: requestContext{
+				request: &Request{TargetBranch: "env/stage"},
+				target: targetContext{
+					branchConfig: branchConfig{
+						PRs: pullRequestConfig{TitleTemplate: " {{.Version}} "},
+					},
+				},
+			},
+			assertions: func(t *testing.T, title string, err error) {
+				require.ErrorContains(t, err, "produced an empty title")
+				require.Empty(t, title)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			title, err := buildPRTitle(testCase.rc)
+			testCase.assertions(t, title, err)
+		})
+	}
+}
+
+func TestResolveGitProvider(t *testing.T) {
+	testCases := []struct {
+		name       string
+		req        *Request
+		assertions func(*testing.T, GitProvider, error)
+	}{
+		{
+			name: "inferred GitHub",
+			req:  &Request{RepoURL: "https://github.com/akuity/foobar"},
+			assertions: func(t *testing.T, provider GitProvider, err error) {
+				require.NoError(t, err)
+				require.Equal(t, GitProviderGitHub, provider)
+			},
+		},
+		{
+			name: "inferred Azure DevOps",
+			req:  &Request{RepoURL: "https://dev.azure.com/org/proj/_git/repo"},
+			assertions: func(t *testing.T, provider GitProvider, err error) {
+				require.NoError(t, err)
+				require.Equal(t, GitProviderAzureDevOps, provider)
+			},
+		},
+		{
+			name: "inferred Azure DevOps from SSH URL",
+			req:  &Request{RepoURL: "git@ssh.dev.azure.com:v3/org/proj/repo"},
+			assertions: func(t *testing.T, provider GitProvider, err error) {
+				require.NoError(t, err)
+				require.Equal(t, GitProviderAzureDevOps, provider)
+			},
+		},
+		{
+			name: "inferred Azure DevOps from legacy host",
+			req:  &Request{RepoURL: "https://org.visualstudio.com/proj/_git/repo"},
+			assertions: func(t *testing.T, provider GitProvider, err error) {
+				require.NoError(t, err)
+				require.Equal(t, GitProviderAzureDevOps, provider)
+			},
+		},
+		{
+			name: "inferred Azure DevOps Server",
+			req: &Request{
+				RepoURL: "https://tfs.example.com/tfs/DefaultCollection/proj/_git/repo",
+			},
+			assertions: func(t *testing.T, provider GitProvider, err error) {
+				require.NoError(t, err)
+				require.Equal(t, GitProviderAzureDevOps, provider)
+			},
+		},
+		{
+			name: "unsupported provider defaults to GitHub",
+			req:  &Request{RepoURL: "https://gitlab.com/akuity/foobar"},
+			assertions: func(t *testing.T, provider GitProvider, err error) {
+				require.NoError(t, err)
+				require.Equal(t, GitProviderGitHub, provider)
+			},
+		},
+		{
+			name: "explicit provider overrides host detection",
+			req: &Request{
+				RepoURL:     "https://git.example.com/org/proj/_git/repo",
+				GitProvider: GitProviderAzureDevOps,
+			},
+			assertions: func(t *testing.T, provider GitProvider, err error) {
+				require.NoError(t, err)
+				require.Equal(t, GitProviderAzureDevOps, provider)
+			},
+		},
+		{
+			name: "explicit provider overrides conflicting host",
+			req: &Request{
+				RepoURL:     "https://dev.azure.com/org/proj/_git/repo",
+				GitProvider: GitProviderGitHub,
+			},
+			assertions: func(t *testing.T, provider GitProvider, err error) {
+				require.NoError(t, err)
+				require.Equal(t, GitProviderGitHub, provider)
+			},
+		},
+		{
+			name: "explicit provider without implementation",
+			req: &Request{
+				RepoURL:     "https://gitlab.com/akuity/foobar",
+				GitProvider: GitProviderGitLab,
+			},
+			assertions: func(t *testing.T, _ GitProvider, err error) {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "no registered implementation")
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			provider, err := resolveGitProvider(testCase.req)
+			testCase.assertions(t, provider, err)
+		})
+	}
+}
+
+func TestAzureDevOpsPRDefaults(t *testing.T) {
+	require.Nil(t, azureDevOpsPRDefaults(nil))
+	require.Equal(
+		t,
+		&azuredevops.PRDefaults{
+			Draft:         true,
+			ReviewerNames: []string{"team-a"},
+			AutoComplete: &azuredevops.AutoCompleteOptions{
+				MergeStrategy:      adogit.GitPullRequestMergeStrategyValues.Squash,
+				DeleteSourceBranch: true,
+			},
+		},
+		azureDevOpsPRDefaults(&azureDevOpsPRDefaultsConfig{
+			Draft:     true,
+			Reviewers: []string{"team-a"},
+			AutoComplete: &autoCompleteConfig{
+				MergeStrategy:      "squash",
+				DeleteSourceBranch: true,
+			},
+		}),
+	)
+}
@@ -0,0 +1,79 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Keys of the data of a Kubernetes Secret representing Git credentials in the
+// format used by Kargo and Argo CD.
+const (
+	secretKeyType          = "type"
+	secretKeyUsername      = "username"
+	secretKeyPassword      = "password"
+	secretKeySSHPrivateKey = "sshPrivateKey"
+	// secretKeyToken is an optional key holding a token for the Git hosting
+	// provider's API that differs from the password.
+	secretKeyToken = "token"
+	// secretKeyPAT is an alternative to secretKeyToken for holding an Azure
+	// DevOps personal access token.
+	secretKeyPAT = "pat"
+)
+
+// secretTypeGit is the only type of credentials, if a type is specified, that
+// can be loaded from a Secret.
+const secretTypeGit = "git"
+
+// CredentialsFromSecret maps the data of a Kubernetes Secret representing Git
+// credentials, in the format used by Kargo and Argo CD, to repository
+// credentials and, if the data includes a token for the Git hosting provider's
+// API, such as an Azure DevOps personal access token, PR credentials. This
+// permits the data of such a Secret to be passed directly into a Request. An
+// error is returned if the data is not of type git or does not include a
+// complete set of credentials for the repository.
+func CredentialsFromSecret(
+	data map[string][]byte,
+) (RepoCredentials, PRCredentials, error) {
+	get := func(key string) string {
+		return strings.TrimSpace(string(data[key]))
+	}
+	if credType := get(secretKeyType); credType != "" && credType != secretTypeGit {
+		return RepoCredentials{}, PRCredentials{}, fmt.Errorf(
+			"secret is of type %q; only type %q is supported",
+			credType,
+			secretTypeGit,
+		)
+	}
+	repoCreds := RepoCredentials{
+		// Leading and trailing whitespace is significant in a private key
+		SSHPrivateKey: string(data[secretKeySSHPrivateKey]),
+		Username:      get(secretKeyUsername),
+		Password:      get(secretKeyPassword),
+	}
+	switch {
+	case repoCreds.Username != "" && repoCreds.Password == "":
+		return RepoCredentials{}, PRCredentials{}, fmt.Errorf(
+			"secret specifies a %s, but no %s",
+			secretKeyUsername,
+			secretKeyPassword,
+		)
+	case repoCreds.Password != "" && repoCreds.Username == "":
+		return RepoCredentials{}, PRCredentials{}, fmt.Errorf(
+			"secret specifies a %s, but no %s",
+			secretKeyPassword,
+			secretKeyUsername,
+		)
+	case repoCreds.Password == "" && strings.TrimSpace(repoCreds.SSHPrivateKey) == "":
+		return RepoCredentials{}, PRCredentials{}, fmt.Errorf(
+			"secret specifies neither a %s and %s nor an %s",
+			secretKeyUsername,
+			secretKeyPassword,
+			secretKeySSHPrivateKey,
+		)
+	}
+	prCreds := PRCredentials{Token: get(secretKeyToken)}
+	if prCreds.Token == "" {
+		prCreds.Token = get(secretKeyPAT)
+	}
+	return repoCreds, prCreds, nil
+}
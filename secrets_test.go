@@ -0,0 +1,107 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialsFromSecret(t *testing.T) {
+	testCases := []struct {
+		name       string
+		data       map[string][]byte
+		assertions func(*testing.T, RepoCredentials, PRCredentials, error)
+	}{
+		{
+			name: "username and password",
+			data: map[string][]byte{
+				"type":     []byte("git"),
+				"url":      []byte("https://dev.azure.com/org/proj/_git/repo"),
+				"username": []byte("user"),
+				"password": []byte("pat\n"),
+			},
+			assertions: func(
+				t *testing.T,
+				repoCreds RepoCredentials,
+				prCreds PRCredentials,
+				err error,
+			) {
+				require.NoError(t, err)
+				require.Equal(t, RepoCredentials{Username: "user", Password: "pat"}, repoCreds)
+				require.Empty(t, prCreds)
+			},
+		},
+		{
+			name: "SSH key with token",
+			data: map[string][]byte{
+				"sshPrivateKey": []byte("key\n"),
+				"token":         []byte("token"),
+				"pat":           []byte("ignored"),
+			},
+			assertions: func(
+				t *testing.T,
+				repoCreds RepoCredentials,
+				prCreds PRCredentials,
+				err error,
+			) {
+				require.NoError(t, err)
+				require.Equal(t, RepoCredentials{SSHPrivateKey: "key\n"}, repoCreds)
+				require.Equal(t, PRCredentials{Token: "token"}, prCreds)
+			},
+		},
+		{
+			name: "SSH key with Azure DevOps PAT",
+			data: map[string][]byte{
+				"sshPrivateKey": []byte("key"),
+				"pat":           []byte("pat"),
+			},
+			assertions: func(
+				t *testing.T,
+				_ RepoCredentials,
+				prCreds PRCredentials,
+				err error,
+			) {
+				require.NoError(t, err)
+				require.Equal(t, PRCredentials{Token: "pat"}, prCreds)
+			},
+		},
+		{
+			name: "unsupported type",
+			data: map[string][]byte{
+				"type":     []byte("helm"),
+				"username": []byte("user"),
+				"password": []byte("pass"),
+			},
+			assertions: func(t *testing.T, _ RepoCredentials, _ PRCredentials, err error) {
+				require.ErrorContains(t, err, `secret is of type "helm"`)
+			},
+		},
+		{
+			name: "missing password",
+			data: map[string][]byte{"username": []byte("user")},
+			assertions: func(t *testing.T, _ RepoCredentials, _ PRCredentials, err error) {
+				require.ErrorContains(t, err, "specifies a username, but no password")
+			},
+		},
+		{
+			name: "missing username",
+			data: map[string][]byte{"password": []byte("pass")},
+			assertions: func(t *testing.T, _ RepoCredentials, _ PRCredentials, err error) {
+				require.ErrorContains(t, err, "specifies a password, but no username")
+			},
+		},
+		{
+			name: "no credentials",
+			data: map[string][]byte{"type": []byte("git"), "token": []byte("token")},
+			assertions: func(t *testing.T, _ RepoCredentials, _ PRCredentials, err error) {
+				require.ErrorContains(t, err, "specifies neither")
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			repoCreds, prCreds, err := CredentialsFromSecret(testCase.data)
+			testCase.assertions(t, repoCreds, prCreds, err)
+		})
+	}
+}
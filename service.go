@@ -17,6 +17,13 @@ import (
 
 type ServiceOptions struct {
 	LogLevel LogLevel
+	// ContextLogLevels, when non-empty, limits fields identifying the repository
+	// a request is for, including its URL and, for Azure DevOps repositories,
+	// its organization and project, to log entries at the specified levels. For
+	// instance, specifying LogLevelError and LogLevelDebug omits these fields
+	// from INFO level entries. When empty, these fields are logged at all
+	// levels.
+	ContextLogLevels []LogLevel
 }
 
 // Service is an interface for components that can handle rendering requests.
@@ -36,8 +43,13 @@ type service struct {
 }
 
 // NewService returns an implementation of the Service interface for
-// handling rendering requests.
+// handling rendering requests. The returned Service also implements the
+// Validator interface.
 func NewService(opts *ServiceOptions) Service {
+	return newService(opts)
+}
+
+func newService(opts *ServiceOptions) *service {
 	if opts == nil {
 		opts = &ServiceOptions{}
 	}
@@ -46,6 +58,9 @@ func NewService(opts *ServiceOptions) Service {
 	}
 	logger := log.New()
 	logger.SetLevel(log.Level(opts.LogLevel))
+	if len(opts.ContextLogLevels) > 0 {
+		logger.AddHook(newContextHook(opts.ContextLogLevels))
+	}
 	return &service{
 		logger:   logger,
 		renderFn: argocd.Render,
@@ -60,10 +75,8 @@ func (s *service) RenderManifests(
 	req.id = uuid.NewString()
 
 	logger := s.logger.WithField("request", req.id)
-	startEndLogger := logger.WithFields(log.Fields{
-		"repo":         req.RepoURL,
-		"targetBranch": req.TargetBranch,
-	})
+	startEndLogger := logger.WithFields(contextFields(req)).
+		WithField("targetBranch", req.TargetBranch)
 
 	startEndLogger.Debug("handling rendering request")
 
@@ -225,7 +238,8 @@ func (s *service) RenderManifests(
 		rc.target.oldBranchMetadata = *oldTargetBranchMetadata
 	}
 
-	if rc.target.commit.branch, err = switchToCommitBranch(rc); err != nil {
+	if rc.target.commit.branch, rc.target.commit.branchCreated, err =
+		switchToCommitBranch(rc); err != nil {
 		return res, fmt.Errorf("error switching to commit branch: %w", err)
 	}
 
@@ -2,10 +2,12 @@ package render
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 
 	"github.com/akuity/kargo-render/internal/file"
@@ -19,6 +21,80 @@ func TestNewService(t *testing.T) {
 	require.NotNil(t, svc.renderFn)
 }
 
+func TestNewValidator(t *testing.T) {
+	v := NewValidator(nil)
+	svc, ok := v.(*service)
+	require.True(t, ok)
+	require.NotNil(t, svc.logger)
+	// The Service returned by NewService can also be used as a Validator
+	_, ok = NewService(nil).(Validator)
+	require.True(t, ok)
+}
+
+func TestNewServiceContextLogLevels(t *testing.T) {
+	req := &Request{RepoURL: "https://dev.azure.com/org/proj/_git/repo"}
+	testCases := []struct {
+		name       string
+		levels     []LogLevel
+		assertions func(t *testing.T, fieldsByLevel map[string]map[string]any)
+	}{
+		{
+			name: "context logged at all levels by default",
+			assertions: func(t *testing.T, fieldsByLevel map[string]map[string]any) {
+				for level, fields := range fieldsByLevel {
+					require.Equal(t, req.RepoURL, fields["repo"], level)
+					require.Equal(t, "org", fields["org"], level)
+					require.Equal(t, "proj", fields["project"], level)
+				}
+			},
+		},
+		{
+			name:   "context limited to error and debug levels",
+			levels: []LogLevel{LogLevelError, LogLevelDebug},
+			assertions: func(t *testing.T, fieldsByLevel map[string]map[string]any) {
+				for _, level := range []string{"error", "debug"} {
+					require.Equal(t, req.RepoURL, fieldsByLevel[level]["repo"], level)
+					require.Equal(t, "org", fieldsByLevel[level]["org"], level)
+					require.Equal(t, "proj", fieldsByLevel[level]["project"], level)
+				}
+				require.NotContains(t, fieldsByLevel["info"], "repo")
+				require.NotContains(t, fieldsByLevel["info"], "org")
+				require.NotContains(t, fieldsByLevel["info"], "project")
+				// Other fields are unaffected
+				require.Equal(t, "env/dev", fieldsByLevel["info"]["targetBranch"])
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			svc, ok := NewService(&ServiceOptions{
+				LogLevel:         LogLevelDebug,
+				ContextLogLevels: testCase.levels,
+			}).(*service)
+			require.True(t, ok)
+			out := &bytes.Buffer{}
+			svc.logger.SetOutput(out)
+			svc.logger.SetFormatter(&log.JSONFormatter{})
+			logger := svc.logger.WithFields(contextFields(req)).
+				WithField("targetBranch", "env/dev")
+			logger.Error("error")
+			logger.Info("info")
+			logger.Debug("debug")
+			fieldsByLevel := map[string]map[string]any{}
+			decoder := json.NewDecoder(out)
+			for decoder.More() {
+				fields := map[string]any{}
+				require.NoError(t, decoder.Decode(&fields))
+				level, ok := fields["level"].(string)
+				require.True(t, ok)
+				fieldsByLevel[level] = fields
+			}
+			require.Len(t, fieldsByLevel, 3)
+			testCase.assertions(t, fieldsByLevel)
+		})
+	}
+}
+
 func TestWriteAppManifests(t *testing.T) {
 	testYAMLChunk1 := []byte(`kind: Deployment
 metadata:
@@ -0,0 +1,142 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/akuity/kargo-render/internal/azuredevops"
+)
+
+// runSummaryVersion is the version of the schema of the JSON written by
+// WriteRunSummary. It must be incremented whenever that schema changes in a
+// manner that is not backwards compatible.
+const runSummaryVersion = "v1alpha1"
+
+// runSummary is a machine-readable summary of a run that rendered manifests
+// for, and opened PRs against, one or more environments.
+type runSummary struct {
+	// Version is the version of the schema of the summary.
+	Version string `json:"version"`
+	// Environments summarizes the run for each environment.
+	Environments []envSummary `json:"environments"`
+}
+
+// envSummary is a machine-readable summary of the portion of a run pertaining
+// to a single environment.
+type envSummary struct {
+	// Env is the name of the environment.
+	Env string `json:"env"`
+	// RepoURL is the URL of the repository the environment's PR targeted.
+	RepoURL string `json:"repoURL,omitempty"`
+	// Branch is the environment-specific branch the environment's PR targeted.
+	Branch string `json:"branch,omitempty"`
+	// PullRequestURL is the URL of the environment's PR, if one was opened.
+	PullRequestURL string `json:"pullRequestURL,omitempty"`
+	// PullRequestNumber is the number of the environment's PR, if one was
+	// opened.
+	PullRequestNumber int `json:"pullRequestNumber,omitempty"`
+	// Outcome is the outcome of attempting to open the environment's PR. This
+	// is one of "created", "reused", "no-change", or "error".
+	Outcome azuredevops.Outcome `json:"outcome,omitempty"`
+	// Error is the message of any error encountered opening the environment's
+	// PR.
+	Error string `json:"error,omitempty"`
+	// Render summarizes the rendering of the environment's manifests, if
+	// applicable.
+	Render *renderSummary `json:"render,omitempty"`
+}
+
+// renderSummary is a machine-readable summary of the rendering of a single
+// environment's manifests.
+type renderSummary struct {
+	// ActionTaken is the action that was taken in response to the render
+	// request.
+	ActionTaken ActionTaken `json:"actionTaken,omitempty"`
+	// CommitID is the ID (sha) of the commit containing the rendered manifests,
+	// if one was pushed directly to the environment-specific branch.
+	CommitID string `json:"commitID,omitempty"`
+}
+
+// WriteRunSummary writes a machine-readable JSON summary of a run to the
+// specified io.Writer. The summary includes one entry for each of the
+// specified PR results, in order, combined with the render response having
+// the same environment name, if any. Render responses for environments
+// without a corresponding PR result follow, ordered by environment name.
+func WriteRunSummary(
+	w io.Writer,
+	results []azuredevops.PRResult,
+	renders map[string]Response,
+) error {
+	summary := runSummary{
+		Version:      runSummaryVersion,
+		Environments: make([]envSummary, 0, len(results)),
+	}
+	summarized := make(map[string]struct{}, len(results))
+	for _, res := range results {
+		env := envSummary{
+			Env:               res.Env,
+			RepoURL:           res.Route.RepoURL,
+			Branch:            res.Route.TargetBranch,
+			PullRequestURL:    res.URL,
+			PullRequestNumber: prNumberFromURL(res.URL),
+			Outcome:           res.Outcome,
+		}
+		if res.Err != nil {
+			env.Error = res.Err.Error()
+		}
+		if render, ok := renders[res.Env]; ok {
+			env.Render = summarizeRender(render)
+		}
+		summary.Environments = append(summary.Environments, env)
+		summarized[res.Env] = struct{}{}
+	}
+	var renderOnly []string
+	for env := range renders {
+		if _, ok := summarized[env]; !ok {
+			renderOnly = append(renderOnly, env)
+		}
+	}
+	sort.Strings(renderOnly)
+	for _, env := range renderOnly {
+		summary.Environments = append(summary.Environments, envSummary{
+			Env:            env,
+			PullRequestURL: renders[env].PullRequestURL,
+			PullRequestNumber: prNumberFromURL(
+				renders[env].PullRequestURL,
+			),
+			Render: summarizeRender(renders[env]),
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(summary); err != nil {
+		return fmt.Errorf("error writing run summary: %w", err)
+	}
+	return nil
+}
+
+// summarizeRender returns a renderSummary for the specified render response.
+func summarizeRender(res Response) *renderSummary {
+	return &renderSummary{
+		ActionTaken: res.ActionTaken,
+		CommitID:    res.CommitID,
+	}
+}
+
+// prNumberFromURL returns the number of the PR referenced by the specified
+// URL, which is expected to end with the number, as is the case for both
+// GitHub and Azure DevOps. Zero is returned if no number can be determined.
+func prNumberFromURL(prURL string) int {
+	if prURL == "" {
+		return 0
+	}
+	number, err := strconv.Atoi(path.Base(prURL))
+	if err != nil {
+		return 0
+	}
+	return number
+}
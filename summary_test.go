@@ -0,0 +1,86 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/akuity/kargo-render/internal/azuredevops"
+)
+
+func TestWriteRunSummary(t *testing.T) {
+	const repoURL = "https://dev.azure.com/org/proj/_git/repo"
+	buf := &bytes.Buffer{}
+	err := WriteRunSummary(
+		buf,
+		[]azuredevops.PRResult{
+			{
+				Env:     "dev",
+				Route:   azuredevops.Route{RepoURL: repoURL, TargetBranch: "env/dev"},
+				URL:     "https://dev.azure.com/org/proj/_git/repo/pullrequest/42",
+				Outcome: azuredevops.OutcomeCreated,
+			},
+			{
+				Env:     "test",
+				Route:   azuredevops.Route{RepoURL: repoURL, TargetBranch: "env/test"},
+				Outcome: azuredevops.OutcomeReused,
+			},
+			{
+				Env:     "prod",
+				Route:   azuredevops.Route{RepoURL: repoURL, TargetBranch: "env/prod"},
+				Outcome: azuredevops.OutcomeError,
+				Err:     errors.New("something went wrong"),
+			},
+		},
+		map[string]Response{
+			"dev":   {ActionTaken: ActionTakenOpenedPR},
+			"local": {ActionTaken: ActionTakenPushedDirectly, CommitID: "abc123"},
+		},
+	)
+	require.NoError(t, err)
+
+	// Validate the structure generically, as a consumer would see it
+	summary := map[string]any{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &summary))
+	require.Equal(t, runSummaryVersion, summary["version"])
+	envs, ok := summary["environments"].([]any)
+	require.True(t, ok)
+	require.Equal(
+		t,
+		[]any{
+			map[string]any{
+				"env":               "dev",
+				"repoURL":           repoURL,
+				"branch":            "env/dev",
+				"pullRequestURL":    "https://dev.azure.com/org/proj/_git/repo/pullrequest/42",
+				"pullRequestNumber": float64(42),
+				"outcome":           "created",
+				"render":            map[string]any{"actionTaken": "OPENED_PR"},
+			},
+			map[string]any{
+				"env":     "test",
+				"repoURL": repoURL,
+				"branch":  "env/test",
+				"outcome": "reused",
+			},
+			map[string]any{
+				"env":     "prod",
+				"repoURL": repoURL,
+				"branch":  "env/prod",
+				"outcome": "error",
+				"error":   "something went wrong",
+			},
+			map[string]any{
+				"env": "local",
+				"render": map[string]any{
+					"actionTaken": "PUSHED_DIRECTLY",
+					"commitID":    "abc123",
+				},
+			},
+		},
+		envs,
+	)
+}
@@ -26,6 +26,21 @@ const (
 	ActionTakenWroteToLocalPath ActionTaken = "WROTE_TO_LOCAL_PATH"
 )
 
+// GitProvider identifies a Git hosting provider whose API can be used to open
+// pull requests.
+type GitProvider string
+
+const (
+	// GitProviderAzureDevOps represents Azure DevOps.
+	GitProviderAzureDevOps GitProvider = "azuredevops"
+	// GitProviderBitbucket represents Bitbucket.
+	GitProviderBitbucket GitProvider = "bitbucket"
+	// GitProviderGitHub represents GitHub.
+	GitProviderGitHub GitProvider = "github"
+	// GitProviderGitLab represents GitLab.
+	GitProviderGitLab GitProvider = "gitlab"
+)
+
 // Request is a request for Kargo Render to render environment-specific
 // manifests from input in the  default branch of the repository specified by
 // RepoURL.
@@ -37,6 +52,14 @@ type Request struct {
 	// RepoCreds encapsulates read/write credentials for the remote GitOps
 	// repository referenced by the RepoURL field.
 	RepoCreds RepoCredentials `json:"repoCreds,omitempty"`
+	// PRCreds optionally encapsulates credentials specifically for the API of
+	// the Git hosting provider used to open any pull requests. When these are
+	// omitted, credentials are derived from the RepoCreds field.
+	PRCreds PRCredentials `json:"prCreds,omitempty"`
+	// GitProvider optionally specifies the Git hosting provider whose API should
+	// be used to open any pull requests. When this is omitted, the provider is
+	// inferred from the RepoURL field.
+	GitProvider GitProvider `json:"gitProvider,omitempty"`
 	// Ref specifies either a branch or a precise commit to render manifests from.
 	// When this is omitted, the request is assumed to be one to render from the
 	// head of the default branch.
@@ -89,6 +112,15 @@ type RepoCredentials struct {
 	Password string `json:"password,omitempty"`
 }
 
+// PRCredentials represents credentials for a Git hosting provider's API, used
+// for opening pull requests, that differ from the credentials used for reading
+// from and writing to the repository itself.
+type PRCredentials struct {
+	// Token is a token, such as a personal access token, an app installation
+	// token, or an OAuth access token, for the Git hosting provider's API.
+	Token string `json:"token,omitempty"`
+}
+
 // Response encapsulates details of a successful rendering of some
 // environment-specific manifests into an environment-specific branch.
 type Response struct {
@@ -0,0 +1,241 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/akuity/kargo-render/internal/azuredevops"
+	"github.com/akuity/kargo-render/pkg/git"
+)
+
+// ValidationCheck identifies a check performed by a Validator.
+type ValidationCheck string
+
+const (
+	// ValidationCheckRequest checks that the request itself is valid.
+	ValidationCheckRequest ValidationCheck = "request"
+	// ValidationCheckRepository checks that the repository can be read using
+	// the specified credentials.
+	ValidationCheckRepository ValidationCheck = "repository"
+	// ValidationCheckConfig checks that the repository's Kargo Render
+	// configuration is valid.
+	ValidationCheckConfig ValidationCheck = "config"
+	// ValidationCheckTargetBranch checks that the target branch exists if a PR
+	// must be opened against it.
+	ValidationCheckTargetBranch ValidationCheck = "targetBranch"
+	// ValidationCheckGitProvider checks that the Git provider used to open PRs
+	// is supported and supports all requested PR features.
+	ValidationCheckGitProvider ValidationCheck = "gitProvider"
+	// ValidationCheckCredentials checks that the credentials for the Git
+	// provider's API are present and accepted.
+	ValidationCheckCredentials ValidationCheck = "credentials"
+)
+
+// ValidationProblem is a problem found by a Validator.
+type ValidationProblem struct {
+	// Check identifies the check that found the problem.
+	Check ValidationCheck `json:"check"`
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+// ValidationReport is the outcome of validating a request.
+type ValidationReport struct {
+	// Problems are the problems that were found, in the order the checks that
+	// found them were performed. Checks that depend on a check that found a
+	// problem are not performed.
+	Problems []ValidationProblem `json:"problems,omitempty"`
+}
+
+// Valid returns a bool indicating whether no problems were found.
+func (v ValidationReport) Valid() bool {
+	return len(v.Problems) == 0
+}
+
+// add records the specified error, and any errors it joins, as problems found
+// by the specified check.
+func (v *ValidationReport) add(check ValidationCheck, err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			v.add(check, e)
+		}
+		return
+	}
+	v.Problems = append(v.Problems, ValidationProblem{Check: check, Message: err.Error()})
+}
+
+// cloneRepo clones the remote repository at the specified URL. It is a
+// package-level variable so that it can be overridden in tests.
+var cloneRepo = git.Clone
+
+// prCredentialValidator is a function that verifies, without making any
+// changes, that a specific Git provider's API accepts the specified
+// credentials.
+type prCredentialValidator func(
+	ctx context.Context,
+	rc requestContext,
+	creds git.RepoCredentials,
+) error
+
+// prCredentialValidators is a registry of prCredentialValidators indexed by Git
+// provider. Credentials for providers that are not present are only checked
+// for presence.
+var prCredentialValidators = map[GitProvider]prCredentialValidator{
+	GitProviderAzureDevOps: validateAzureDevOpsCredentials,
+}
+
+// validateAzureDevOpsCredentials verifies that Azure DevOps accepts the
+// specified credentials by listing the repository's branches.
+func validateAzureDevOpsCredentials(
+	ctx context.Context,
+	rc requestContext,
+	creds git.RepoCredentials,
+) error {
+	_, err := azuredevops.ListBranches(
+		ctx,
+		rc.request.RepoURL,
+		azureDevOpsCredentials(rc, creds),
+	)
+	return err
+}
+
+// Validator is an interface for components that can check rendering requests
+// without handling them. It is separate from the Service interface so that
+// existing implementations of Service need not implement it.
+type Validator interface {
+	// Validate performs read-only checks of a rendering request and the
+	// configuration it would be handled with, without making any changes, and
+	// reports any problems found. The repository is read, and the Git
+	// provider's API is accessed, using the specified credentials, when they
+	// are non-empty, rather than those in the request, so that read-only
+	// credentials may be used.
+	Validate(context.Context, *Request, RepoCredentials) ValidationReport
+}
+
+// NewValidator returns an implementation of the Validator interface for
+// checking rendering requests.
+func NewValidator(opts *ServiceOptions) Validator {
+	return newService(opts)
+}
+
+func (s *service) Validate(
+	ctx context.Context,
+	req *Request,
+	creds RepoCredentials,
+) ValidationReport {
+	report := ValidationReport{}
+	r := *req
+	r.id = uuid.NewString()
+	r.Images = slices.Clone(req.Images)
+	if creds != (RepoCredentials{}) {
+		r.RepoCreds = creds
+	}
+	if err := r.canonicalizeAndValidate(); err != nil {
+		report.add(ValidationCheckRequest, err)
+		return report
+	}
+	rc := requestContext{
+		logger:  s.logger.WithField("request", r.id),
+		request: &r,
+	}
+
+	var err error
+	if r.LocalInPath != "" {
+		rc.repo, err = git.CopyRepo(r.LocalInPath, git.RepoCredentials(r.RepoCreds))
+	} else {
+		rc.repo, err = cloneRepo(r.RepoURL, git.RepoCredentials(r.RepoCreds))
+	}
+	if err != nil {
+		report.add(ValidationCheckRepository, fmt.Errorf("error reading repository: %w", err))
+		return report
+	}
+	defer rc.repo.Close()
+	if err = checkoutSourceCommit(rc); err != nil {
+		report.add(ValidationCheckRepository, err)
+		return report
+	}
+
+	repoConfig, err := loadRepoConfig(rc.repo.WorkingDir())
+	if err != nil {
+		report.add(
+			ValidationCheckConfig,
+			fmt.Errorf("error loading Kargo Render configuration from repo: %w", err),
+		)
+		return report
+	}
+	if rc.target.branchConfig, err = repoConfig.GetBranchConfig(r.TargetBranch); err != nil {
+		report.add(
+			ValidationCheckConfig,
+			fmt.Errorf("error loading configuration for branch %q: %w", r.TargetBranch, err),
+		)
+		return report
+	}
+	if !rc.target.branchConfig.PRs.Enabled {
+		return report
+	}
+
+	// A PR can only be opened against a target branch that already exists
+	exists, err := rc.repo.RemoteBranchExists(r.TargetBranch)
+	switch {
+	case err != nil:
+		report.add(ValidationCheckRepository, err)
+	case !exists:
+		report.add(
+			ValidationCheckTargetBranch,
+			fmt.Errorf(
+				"target branch %q does not exist, so pull requests cannot be opened "+
+					"against it",
+				r.TargetBranch,
+			),
+		)
+	}
+
+	provider, err := resolveGitProvider(&r)
+	if err != nil {
+		report.add(ValidationCheckGitProvider, err)
+		return report
+	}
+	if err = ensurePRFeaturesSupported(rc, provider); err != nil {
+		report.add(ValidationCheckGitProvider, err)
+	}
+	prCreds, err := resolvePRCredentials(provider, &r)
+	if err != nil {
+		report.add(ValidationCheckCredentials, err)
+		return report
+	}
+	if validate, ok := prCredentialValidators[provider]; ok && r.RepoURL != "" {
+		if err = validate(ctx, rc, prCreds); err != nil {
+			report.add(
+				ValidationCheckCredentials,
+				fmt.Errorf("error verifying credentials for git provider %q: %w", provider, err),
+			)
+		}
+	}
+	return report
+}
+
+// checkoutSourceCommit checks out the commit referenced by the request, if
+// any, following any branch metadata found there back to the source commit it
+// was rendered from.
+func checkoutSourceCommit(rc requestContext) error {
+	if rc.request.Ref == "" {
+		return nil
+	}
+	if err := rc.repo.Checkout(rc.request.Ref); err != nil {
+		return fmt.Errorf("error checking out %q: %w", rc.request.Ref, err)
+	}
+	metadata, err := loadBranchMetadata(rc.repo.WorkingDir())
+	if err != nil {
+		return fmt.Errorf("error loading branch metadata: %w", err)
+	}
+	if metadata == nil {
+		return nil
+	}
+	if err = rc.repo.Checkout(metadata.SourceCommit); err != nil {
+		return fmt.Errorf("error checking out %q: %w", metadata.SourceCommit, err)
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akuity/kargo-render/pkg/git"
+)
+
+// fakeRepo is a fake implementation of the git.Repo interface. Only the
+// methods used by Validate may be called.
+type fakeRepo struct {
+	git.Repo
+	dir            string
+	remoteBranches map[string]struct{}
+}
+
+func (f *fakeRepo) WorkingDir() string {
+	return f.dir
+}
+
+func (f *fakeRepo) Checkout(string) error {
+	return nil
+}
+
+func (f *fakeRepo) RemoteBranchExists(branch string) (bool, error) {
+	_, ok := f.remoteBranches[branch]
+	return ok, nil
+}
+
+func (f *fakeRepo) Close() error {
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	const repoURL = "https://dev.azure.com/org/proj/_git/repo"
+	testCases := []struct {
+		name           string
+		req            Request
+		config         string
+		cloneErr       error
+		remoteBranches []string
+		credsErr       error
+		assertions     func(t *testing.T, report ValidationReport, cloned bool)
+	}{
+		{
+			name: "valid request",
+			req: Request{
+				RepoURL:      repoURL,
+				RepoCreds:    RepoCredentials{Password: "token"},
+				TargetBranch: "env/prod",
+			},
+			config: `configVersion: v1alpha1
+branchConfigs:
+  - name: env/prod
+    prs:
+      enabled: true
+      labels: ["kargo-render"]`,
+			remoteBranches: []string{"env/prod"},
+			assertions: func(t *testing.T, report ValidationReport, cloned bool) {
+				require.True(t, cloned)
+				require.True(t, report.Valid())
+			},
+		},
+		{
+			name: "invalid request",
+			req: Request{
+				RepoURL:     repoURL,
+				LocalInPath: "/tmp",
+			},
+			assertions: func(t *testing.T, report ValidationReport, cloned bool) {
+				require.False(t, cloned)
+				require.False(t, report.Valid())
+				// Each problem with the request is reported separately
+				require.Greater(t, len(report.Problems), 1)
+				for _, problem := range report.Problems {
+					require.Equal(t, ValidationCheckRequest, problem.Check)
+				}
+				require.Contains(t, report.Problems[0].Message, "input source is ambiguous")
+			},
+		},
+		{
+			name: "unreachable repository",
+			req: Request{
+				RepoURL:      repoURL,
+				TargetBranch: "env/prod",
+			},
+			cloneErr: errors.New("authentication failed"),
+			assertions: func(t *testing.T, report ValidationReport, _ bool) {
+				require.Equal(
+					t,
+					[]ValidationProblem{{
+						Check:   ValidationCheckRepository,
+						Message: "error reading repository: authentication failed",
+					}},
+					report.Problems,
+				)
+			},
+		},
+		{
+			name: "invalid configuration",
+			req: Request{
+				RepoURL:      repoURL,
+				TargetBranch: "env/prod",
+			},
+			config: "bogus",
+			assertions: func(t *testing.T, report ValidationReport, _ bool) {
+				require.Len(t, report.Problems, 1)
+				require.Equal(t, ValidationCheckConfig, report.Problems[0].Check)
+			},
+		},
+		{
+			name: "missing target branch and unsupported feature",
+			req: Request{
+				RepoURL:      "https://github.com/akuity/kargo-render",
+				RepoCreds:    RepoCredentials{Password: "token"},
+				TargetBranch: "env/prod",
+			},
+			config: `configVersion: v1alpha1
+branchConfigs:
+  - name: env/prod
+    prs:
+      enabled: true
+      labels: ["kargo-render"]`,
+			assertions: func(t *testing.T, report ValidationReport, _ bool) {
+				require.Len(t, report.Problems, 2)
+				require.Equal(t, ValidationCheckTargetBranch, report.Problems[0].Check)
+				require.Contains(t, report.Problems[0].Message, `"env/prod" does not exist`)
+				require.Equal(t, ValidationCheckGitProvider, report.Problems[1].Check)
+				require.Contains(t, report.Problems[1].Message, "labels is not supported")
+			},
+		},
+		{
+			name: "target branch need not exist without PRs",
+			req: Request{
+				RepoURL:      repoURL,
+				TargetBranch: "env/new",
+			},
+			assertions: func(t *testing.T, report ValidationReport, _ bool) {
+				require.True(t, report.Valid())
+			},
+		},
+		{
+			name: "missing credentials",
+			req: Request{
+				RepoURL:      repoURL,
+				TargetBranch: "env/prod",
+			},
+			config: `configVersion: v1alpha1
+branchConfigs:
+  - name: env/prod
+    prs:
+      enabled: true`,
+			remoteBranches: []string{"env/prod"},
+			assertions: func(t *testing.T, report ValidationReport, _ bool) {
+				require.Len(t, report.Problems, 1)
+				require.Equal(t, ValidationCheckCredentials, report.Problems[0].Check)
+				require.Contains(t, report.Problems[0].Message, "Personal Access Token")
+			},
+		},
+		{
+			name: "rejected credentials",
+			req: Request{
+				RepoURL:      repoURL,
+				RepoCreds:    RepoCredentials{Password: "token"},
+				TargetBranch: "env/prod",
+			},
+			config: `configVersion: v1alpha1
+branchConfigs:
+  - name: env/prod
+    prs:
+      enabled: true`,
+			remoteBranches: []string{"env/prod"},
+			credsErr:       errors.New("401 Unauthorized"),
+			assertions: func(t *testing.T, report ValidationReport, _ bool) {
+				require.Equal(
+					t,
+					[]ValidationProblem{{
+						Check: ValidationCheckCredentials,
+						Message: `error verifying credentials for git provider "azuredevops": ` +
+							"401 Unauthorized",
+					}},
+					report.Problems,
+				)
+			},
+		},
+	}
+	origClone := cloneRepo
+	t.Cleanup(func() { cloneRepo = origClone })
+	origValidator := prCredentialValidators[GitProviderAzureDevOps]
+	t.Cleanup(func() { prCredentialValidators[GitProviderAzureDevOps] = origValidator })
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if testCase.config != "" {
+				require.NoError(t, os.WriteFile(
+					filepath.Join(dir, "kargo-render.yaml"),
+					[]byte(testCase.config),
+					0600,
+				))
+			}
+			repo := &fakeRepo{dir: dir, remoteBranches: map[string]struct{}{}}
+			for _, branch := range testCase.remoteBranches {
+				repo.remoteBranches[branch] = struct{}{}
+			}
+			var cloned bool
+			cloneRepo = func(url string, _ git.RepoCredentials) (git.Repo, error) {
+				require.Equal(t, testCase.req.RepoURL, url)
+				cloned = true
+				if testCase.cloneErr != nil {
+					return nil, testCase.cloneErr
+				}
+				return repo, nil
+			}
+			prCredentialValidators[GitProviderAzureDevOps] = func(
+				context.Context,
+				requestContext,
+				git.RepoCredentials,
+			) error {
+				return testCase.credsErr
+			}
+			svc := &service{logger: log.New()}
+			report := svc.Validate(context.Background(), &testCase.req, RepoCredentials{})
+			testCase.assertions(t, report, cloned)
+		})
+	}
+}
+
+func TestValidateUsesSpecifiedCredentials(t *testing.T) {
+	origClone := cloneRepo
+	t.Cleanup(func() { cloneRepo = origClone })
+	var used git.RepoCredentials
+	cloneRepo = func(_ string, creds git.RepoCredentials) (git.Repo, error) {
+		used = creds
+		return &fakeRepo{dir: t.TempDir()}, nil
+	}
+	req := &Request{
+		RepoURL:      "https://github.com/akuity/kargo-render",
+		RepoCreds:    RepoCredentials{Username: "rw", Password: "read-write"},
+		TargetBranch: "env/prod",
+	}
+	svc := &service{logger: log.New()}
+	report := svc.Validate(
+		context.Background(),
+		req,
+		RepoCredentials{Username: "ro", Password: "read-only"},
+	)
+	require.True(t, report.Valid())
+	require.Equal(t, git.RepoCredentials{Username: "ro", Password: "read-only"}, used)
+	// The request itself is left unmodified
+	require.Equal(t, "read-write", req.RepoCreds.Password)
+}
@@ -22,6 +22,9 @@ func (r *Request) canonicalizeAndValidate() error {
 	r.RepoURL = strings.TrimSpace(r.RepoURL)
 	r.RepoCreds.Username = strings.TrimSpace(r.RepoCreds.Username)
 	r.RepoCreds.Password = strings.TrimSpace(r.RepoCreds.Password)
+	r.PRCreds.Token = strings.TrimSpace(r.PRCreds.Token)
+	r.GitProvider =
+		GitProvider(strings.ToLower(strings.TrimSpace(string(r.GitProvider))))
 	r.Ref = strings.TrimSpace(r.Ref)
 	r.TargetBranch = strings.TrimSpace(r.TargetBranch)
 	r.TargetBranch = strings.TrimPrefix(r.TargetBranch, "refs/heads/")
@@ -108,6 +111,16 @@ func (r *Request) canonicalizeAndValidate() error {
 		)
 	}
 
+	switch r.GitProvider {
+	case "", GitProviderAzureDevOps, GitProviderBitbucket, GitProviderGitHub,
+		GitProviderGitLab:
+	default:
+		errs = append(
+			errs,
+			fmt.Errorf("GitProvider %q is not a known git provider", r.GitProvider),
+		)
+	}
+
 	if r.TargetBranch == "" {
 		errs = append(errs, errors.New("TargetBranch is a required field"))
 	}
@@ -100,6 +100,18 @@ func TestValidateAndCanonicalizeRequest(t *testing.T) {
 				require.Contains(t, err.Error(), "is an invalid branch name")
 			},
 		},
+		{
+			name: "unknown GitProvider",
+			req: Request{
+				RepoURL:      "https://github.com/akuity/foobar",
+				TargetBranch: "env/dev",
+				GitProvider:  "bogus",
+			},
+			assertions: func(t *testing.T, _ Request, err error) {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "is not a known git provider")
+			},
+		},
 		{
 			name: "empty string image",
 			req: Request{
@@ -150,6 +162,7 @@ func TestValidateAndCanonicalizeRequest(t *testing.T) {
 				Ref:          "  1abcdef2 ",
 				TargetBranch: "  refs/heads/env/dev  ",
 				Images:       []string{" akuity/some-image "}, // no good
+				GitProvider:  " GitHub ",
 			},
 			assertions: func(t *testing.T, req Request, err error) {
 				require.NoError(t, err)
@@ -158,6 +171,7 @@ func TestValidateAndCanonicalizeRequest(t *testing.T) {
 				require.Equal(t, "1abcdef2", req.Ref)
 				require.Equal(t, "env/dev", req.TargetBranch)
 				require.Equal(t, []string{"akuity/some-image"}, req.Images)
+				require.Equal(t, GitProviderGitHub, req.GitProvider)
 			},
 		},
 	}